@@ -16,6 +16,7 @@ import (
 	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
 	"github.com/KyberNetwork/reserve-stats/lib/contracts"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
 	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
 )
 
@@ -111,7 +112,7 @@ func run(c *cli.Context) error {
 		if err != nil {
 			return err
 		}
-		st, err = postgres.NewPostgresStorage(db, sugar, blkTimeRsv, amountFmt)
+		st, err = postgres.NewPostgresStorage(db, sugar, blkTimeRsv, amountFmt, deployment.MustGetChainFromContext(c))
 		if err != nil {
 			return err
 		}
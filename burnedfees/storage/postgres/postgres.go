@@ -1,12 +1,14 @@
 package postgres
 
 import (
+	ethereum "github.com/ethereum/go-ethereum/common"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 
 	"github.com/KyberNetwork/reserve-stats/burnedfees/common"
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
 	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
 )
 
 const schema = `
@@ -19,6 +21,9 @@ const schema = `
 		sender TEXT,
 		reserve TEXT
 	);
+
+	ALTER TABLE "burned_fee" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+	CREATE INDEX IF NOT EXISTS "burned_fee_chain_block_number" ON "burned_fee"(chain_id, block_number);
 `
 
 // Storage postgres storage for burned fee component
@@ -27,11 +32,13 @@ type Storage struct {
 	db                   *sqlx.DB
 	blockTimeResolver    blockchain.BlockTimeResolverInterface
 	tokenAmountFormatter blockchain.TokenAmountFormatterInterface
+	chainID              uint64
+	kncAddr              ethereum.Address
 }
 
 // NewPostgresStorage return new instance of postgres storage
 func NewPostgresStorage(db *sqlx.DB, l *zap.SugaredLogger, blockTimeResolver blockchain.BlockTimeResolverInterface,
-	tokenAmountFormatter blockchain.TokenAmountFormatterInterface) (*Storage, error) {
+	tokenAmountFormatter blockchain.TokenAmountFormatterInterface, chain deployment.Chain) (*Storage, error) {
 	if _, err := db.Exec(schema); err != nil {
 		return nil, err
 	}
@@ -40,6 +47,8 @@ func NewPostgresStorage(db *sqlx.DB, l *zap.SugaredLogger, blockTimeResolver blo
 		l:                    l,
 		blockTimeResolver:    blockTimeResolver,
 		tokenAmountFormatter: tokenAmountFormatter,
+		chainID:              chain.ID,
+		kncAddr:              chain.KNCToken,
 	}, nil
 }
 
@@ -47,12 +56,12 @@ func NewPostgresStorage(db *sqlx.DB, l *zap.SugaredLogger, blockTimeResolver blo
 func (ps *Storage) Store(records []common.BurnAssignedFeesEvent) error {
 	var (
 		logger = ps.l.With("func", caller.GetCurrentFunctionName())
-		query  = `INSERT INTO "burned_fee" (block_number, timestamp, tx_hash, amount, sender, reserve)
-		VALUES ($1, $2, $3, $4, $5, $6);`
+		query  = `INSERT INTO "burned_fee" (block_number, timestamp, tx_hash, amount, sender, reserve, chain_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);`
 	)
 	logger.Infow("saving burn fee event into database", "query", query)
 	for _, record := range records {
-		qty, err := ps.tokenAmountFormatter.FromWei(blockchain.KNCAddr, record.Quantity)
+		qty, err := ps.tokenAmountFormatter.FromWei(ps.kncAddr, record.Quantity)
 		if err != nil {
 			return err
 		}
@@ -60,7 +69,7 @@ func (ps *Storage) Store(records []common.BurnAssignedFeesEvent) error {
 		if err != nil {
 			return err
 		}
-		if _, err := ps.db.Exec(query, record.BlockNumber, ts, record.TxHash.Hex(), qty, record.Sender.Hex(), record.Reserve.Hex()); err != nil {
+		if _, err := ps.db.Exec(query, record.BlockNumber, ts, record.TxHash.Hex(), qty, record.Sender.Hex(), record.Reserve.Hex(), ps.chainID); err != nil {
 			return err
 		}
 	}
@@ -71,11 +80,11 @@ func (ps *Storage) Store(records []common.BurnAssignedFeesEvent) error {
 func (ps *Storage) LastBlock() (int64, error) {
 	var (
 		logger    = ps.l.With("func", caller.GetCurrentFunctionName())
-		query     = `SELECT coalesce(max(block_number), 0) FROM "burned_fee";`
+		query     = `SELECT coalesce(max(block_number), 0) FROM "burned_fee" WHERE chain_id = $1;`
 		lastBlock int64
 	)
 	logger.Infow("get last block from db", "query", query)
-	if err := ps.db.Get(&lastBlock, query); err != nil {
+	if err := ps.db.Get(&lastBlock, query, ps.chainID); err != nil {
 		return 0, err
 	}
 	return lastBlock, nil
@@ -36,6 +36,11 @@ func NewApp() *cli.App {
 			Usage: "Kyber Network deployment name",
 			Value: productionMode,
 		},
+		cli.StringFlag{
+			Name:   deployment.ChainConfigFlag,
+			Usage:  "Path to chain config file with chain ID, tokens, contract addresses and starting blocks of deployment",
+			EnvVar: "CHAIN_CONFIG",
+		},
 	}
 	app.Flags = append(app.Flags, NewSentryFlags()...)
 	return app
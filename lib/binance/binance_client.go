@@ -444,3 +444,36 @@ func (bc *Client) GetAggregatedTrades(symbol string, startTime, endTime uint64,
 	err = json.Unmarshal(res, &result)
 	return result, err
 }
+
+// BookTicker is the best bid and ask of a symbol on the order book.
+type BookTicker struct {
+	Symbol   string `json:"symbol"`
+	BidPrice string `json:"bidPrice"`
+	BidQty   string `json:"bidQty"`
+	AskPrice string `json:"askPrice"`
+	AskQty   string `json:"askQty"`
+}
+
+// GetBookTicker returns the best bid and ask of given symbol.
+func (bc *Client) GetBookTicker(symbol string) (BookTicker, error) {
+	var result BookTicker
+	const weight = 1
+	if err := bc.waitN(weight); err != nil {
+		return result, err
+	}
+	endpoint := fmt.Sprintf("%s/api/v3/ticker/bookTicker", endpointPrefix)
+	res, err := bc.sendRequest(
+		http.MethodGet,
+		endpoint,
+		map[string]string{
+			"symbol": symbol,
+		},
+		false,
+		time.Now(),
+	)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(res, &result)
+	return result, err
+}
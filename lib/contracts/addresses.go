@@ -60,46 +60,47 @@ func KyberFeeHandlerContractAddress() deployment.Address {
 	return feeHandlerContractAddress
 }
 
+// Names given to WithContract are keys of contract addresses in chain config file.
 var (
 	networkContractAddress = deployment.NewAddress(
 		// update address for istanbul fork
 		[]common.Address{common.HexToAddress("0x7C66550C9c730B6fdd4C03bc2e73c5462c5F7ACC")},
 		[]common.Address{common.HexToAddress("0x9CB7bB6D4795A281860b9Bfb7B1441361Cc9A794")},
 		[]common.Address{common.HexToAddress("0x920B322D4B8BAB34fb6233646F5c87F87e79952b")},
-	)
+	).WithContract("network")
 	internalReserveAddress = deployment.NewAddress(
 		[]common.Address{common.HexToAddress("0x63825c174ab367968EC60f061753D3bbD36A0D8F")},
 		[]common.Address{common.HexToAddress("0x2C5a182d280EeB5824377B98CD74871f78d6b8BC")},
 		[]common.Address{common.HexToAddress("0xEB52Ce516a8d054A574905BDc3D4a176D3a2d51a")},
-	)
+	).WithContract("internal_reserve")
 	pricingContractAddress = deployment.NewAddress(
 		[]common.Address{common.HexToAddress("0x798AbDA6Cc246D0EDbA912092A2a3dBd3d11191B")},
 		[]common.Address{common.HexToAddress("0xe3E415a7a6c287a95DC68a01ff036828073fD2e6")},
 		[]common.Address{common.HexToAddress("0xE16E257a25e287AF50C5651A4c2728b32D7e5ef7")},
-	)
+	).WithContract("pricing")
 	proxyContractAddress = deployment.NewAddress(
 		[]common.Address{common.HexToAddress("0x9AAb3f75489902f3a48495025729a0AF77d4b11e")},
 		[]common.Address{common.HexToAddress("0xc153eeAD19e0DBbDb3462Dcc2B703cC6D738A37c")},
 		[]common.Address{common.HexToAddress("0xd719c34261e099Fdb33030ac8909d5788D3039C4")},
-	)
+	).WithContract("proxy")
 	burnerContractAddress = deployment.NewAddress(
 		// updated address for istanbul fork
 		[]common.Address{common.HexToAddress("0x8007aa43792A392b221DC091bdb2191E5fF626d1")},
 		[]common.Address{common.HexToAddress("0x39682A7b8E4A03b2c8dC6DA6E0146Aee4E29A306")},
 		[]common.Address{common.HexToAddress("0x06b0fbaba8fba5161f725f2159de1e1d6409c35f")},
-	)
+	).WithContract("burner")
 
 	feeHandlerContractAddress = deployment.NewAddress(
 		[]common.Address{common.HexToAddress("0xd3d2b5643e506c6d9B7099E9116D7aAa941114fe")}, // production
 		[]common.Address{common.HexToAddress("0xEc30037C9A8A6A3f42734c30Dfa0a208aF71b40C")}, // staging
 		[]common.Address{common.HexToAddress("0xfF456D9A8cbB5352eF77dEc2337bAC8dEC63bEAC")}, // ropsten
-	)
+	).WithContract("fee_handler")
 
 	kyberStorageContractAddress = deployment.NewAddress(
 		[]common.Address{common.HexToAddress("0xC8fb12402cB16970F3C5F4b48Ff68Eb9D1289301")},
 		[]common.Address{common.HexToAddress("0xB18D90bE9ADD2a6c9F2c3943B264c3dC86E30cF5")},
 		[]common.Address{common.HexToAddress("0x688bf5EeC43E0799c5B9c1612F625F7b93FE5434")},
-	)
+	).WithContract("kyber_storage")
 
 	oldProxyContractAddress = deployment.NewAddress(
 		[]common.Address{
@@ -113,7 +114,7 @@ var (
 		[]common.Address{
 			common.HexToAddress("0x818E6FECD516Ecc3849DAf6845e3EC868087B755"),
 		},
-	)
+	).WithContract("old_proxy")
 
 	oldNetworkContractAddress = deployment.NewAddress(
 		[]common.Address{
@@ -137,7 +138,7 @@ var (
 		[]common.Address{
 			common.HexToAddress("0x753fe1914db38ee744e071baadd123f50f9c8e46"),
 		},
-	)
+	).WithContract("old_network")
 
 	oldBurnerContractAddress = deployment.NewAddress(
 		[]common.Address{
@@ -154,7 +155,7 @@ var (
 			common.HexToAddress("0xd6703974Dc30155d768c058189A2936Cf7C62Da6"),
 		}, // staging
 		[]common.Address{}, // ropsten
-	)
+	).WithContract("old_burner")
 
 	volumeExcludedReserves = deployment.NewAddress(
 		[]common.Address{
@@ -169,5 +170,5 @@ var (
 		[]common.Address{
 			common.HexToAddress("0x0000000000000000000000000000000000000000"), // Self Reserve
 		}, // ropsten
-	)
+	).WithContract("volume_excluded_reserves")
 )
@@ -11,14 +11,20 @@ import (
 const Flag = "deployment"
 
 // Address is a wrapper of ethereum common Address that supports multiple deployments.
-type Address map[Deployment][]common.Address
+// If the address has a contract name, it is looked up in chain config first.
+type Address struct {
+	contract  string
+	addresses map[Deployment][]common.Address
+}
 
 // NewAddress returns an Address instance. Address of all deployments should be present.
 func NewAddress(prodAddr, stagingAddr, ropstenAddr []common.Address) Address {
-	return map[Deployment][]common.Address{
-		Production: prodAddr,
-		Staging:    stagingAddr,
-		Ropsten:    ropstenAddr,
+	return Address{
+		addresses: map[Deployment][]common.Address{
+			Production: prodAddr,
+			Staging:    stagingAddr,
+			Ropsten:    ropstenAddr,
+		},
 	}
 }
 
@@ -32,6 +38,10 @@ func MustGetDeploymentFromContext(c *cli.Context) Deployment {
 		return Production
 	case Ropsten.String():
 		return Ropsten
+	case BSC.String():
+		return BSC
+	case Polygon.String():
+		return Polygon
 	default:
 		panic(fmt.Errorf("invalid deployment %s", dpl))
 	}
@@ -39,31 +49,44 @@ func MustGetDeploymentFromContext(c *cli.Context) Deployment {
 
 // NewCrossDeploymentAddress returns an Address with given same address for all deployments.
 func NewCrossDeploymentAddress(addr []common.Address) Address {
-	return map[Deployment][]common.Address{
-		Production: addr,
-		Staging:    addr,
+	return Address{
+		addresses: map[Deployment][]common.Address{
+			Production: addr,
+			Staging:    addr,
+		},
 	}
 }
 
-// MustGetFromContext returns the common address for given deployment from context.
-func (a Address) MustGetFromContext(c *cli.Context) []common.Address {
+// WithContract returns a copy of the address which is looked up by given contract name in chain config.
+func (a Address) WithContract(contract string) Address {
+	a.contract = contract
+	return a
+}
+
+func (a Address) mustGet(c *cli.Context) []common.Address {
 	dpl := MustGetDeploymentFromContext(c)
-	addr, ok := a[dpl]
+	if a.contract != "" {
+		if addr, ok := MustGetChainFromContext(c).Contracts[a.contract]; ok {
+			return addr
+		}
+	}
+	addr, ok := a.addresses[dpl]
 	if !ok {
 		panic(fmt.Errorf("address is not available for deployment: %s", dpl))
 	}
 	return addr
 }
 
+// MustGetFromContext returns the common address for given deployment from context.
+func (a Address) MustGetFromContext(c *cli.Context) []common.Address {
+	return a.mustGet(c)
+}
+
 // MustGetOneFromContext returns one common address for given deployment from context
 func (a Address) MustGetOneFromContext(c *cli.Context) common.Address {
-	dpl := MustGetDeploymentFromContext(c)
-	addr, ok := a[dpl]
-	if !ok {
-		panic(fmt.Errorf("address is not available for deployment: %s", dpl))
-	}
+	addr := a.mustGet(c)
 	if len(addr) != 1 {
-		panic(fmt.Errorf("address should return only one address for this mode: %s", dpl))
+		panic(fmt.Errorf("address should return only one address for this mode: %s", MustGetDeploymentFromContext(c)))
 	}
 	return addr[0]
 }
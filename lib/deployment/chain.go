@@ -0,0 +1,134 @@
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli"
+)
+
+// ChainConfigFlag is the cli flag of chain config file.
+const ChainConfigFlag = "chain-config"
+
+// Chain is the EVM chain a deployment is running on.
+type Chain struct {
+	ID                 uint64         `json:"chain_id"`
+	Name               string         `json:"name"`
+	NativeToken        string         `json:"native_token"`
+	WrappedNativeToken common.Address `json:"wrapped_native_token"`
+	KNCToken           common.Address `json:"knc_token"`
+	// Contracts is the addresses of contracts by name, they take precedence over built-in addresses.
+	Contracts      map[string][]common.Address `json:"contracts"`
+	StartingBlocks *VersionedStartingBlocks    `json:"starting_blocks"`
+}
+
+// Chains map deployment to the chain it is running on. Contract addresses and starting blocks
+// of deployments on chains other than Ethereum should be provided by chain config file.
+var Chains = map[Deployment]Chain{
+	Production: {
+		ID:                 1,
+		Name:               "ethereum",
+		NativeToken:        "ETH",
+		WrappedNativeToken: common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"),
+		KNCToken:           common.HexToAddress("0xdd974D5C2e2928deA5F71b9825b8b646686BD200"),
+	},
+	Staging: {
+		ID:                 1,
+		Name:               "ethereum",
+		NativeToken:        "ETH",
+		WrappedNativeToken: common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"),
+		KNCToken:           common.HexToAddress("0xdd974D5C2e2928deA5F71b9825b8b646686BD200"),
+	},
+	Ropsten: {
+		ID:                 3,
+		Name:               "ropsten",
+		NativeToken:        "ETH",
+		WrappedNativeToken: common.HexToAddress("0xc778417E063141139Fce010982780140Aa0cD5Ab"),
+		KNCToken:           common.HexToAddress("0x4E470dc7321E84CA96FcAEDD0C8aBCebbAEB68C6"),
+	},
+	BSC: {
+		ID:                 56,
+		Name:               "bsc",
+		NativeToken:        "BNB",
+		WrappedNativeToken: common.HexToAddress("0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c"),
+		KNCToken:           common.HexToAddress("0xfe56d5892BDffC7BF58f2E84BE1b2C32D21C308b"),
+	},
+	Polygon: {
+		ID:                 137,
+		Name:               "polygon",
+		NativeToken:        "MATIC",
+		WrappedNativeToken: common.HexToAddress("0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270"),
+		KNCToken:           common.HexToAddress("0x1C954E8fe737F99f68Fa1CCda3e51ebDB291948C"),
+	},
+}
+
+var (
+	chainConfigsMu sync.Mutex
+	chainConfigs   = make(map[string]Chain) // chain config files by path
+)
+
+func loadChainConfig(path string) (Chain, error) {
+	chainConfigsMu.Lock()
+	defer chainConfigsMu.Unlock()
+	if cfg, ok := chainConfigs[path]; ok {
+		return cfg, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Chain{}, err
+	}
+	var cfg Chain
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return Chain{}, fmt.Errorf("invalid chain config %s: %v", path, err)
+	}
+	chainConfigs[path] = cfg
+	return cfg, nil
+}
+
+// merge returns a copy of the chain overridden by non empty fields of given config.
+func (ch Chain) merge(cfg Chain) Chain {
+	if cfg.ID != 0 {
+		ch.ID = cfg.ID
+	}
+	if cfg.Name != "" {
+		ch.Name = cfg.Name
+	}
+	if cfg.NativeToken != "" {
+		ch.NativeToken = cfg.NativeToken
+	}
+	if cfg.WrappedNativeToken != (common.Address{}) {
+		ch.WrappedNativeToken = cfg.WrappedNativeToken
+	}
+	if cfg.KNCToken != (common.Address{}) {
+		ch.KNCToken = cfg.KNCToken
+	}
+	if cfg.Contracts != nil {
+		ch.Contracts = cfg.Contracts
+	}
+	if cfg.StartingBlocks != nil {
+		ch.StartingBlocks = cfg.StartingBlocks
+	}
+	return ch
+}
+
+// MustGetChainFromContext returns the chain of deployment from context,
+// overridden by chain config file if provided.
+func MustGetChainFromContext(c *cli.Context) Chain {
+	dpl := MustGetDeploymentFromContext(c)
+	chain, ok := Chains[dpl]
+	if !ok {
+		panic(fmt.Errorf("chain is not available for deployment: %s", dpl))
+	}
+	path := c.GlobalString(ChainConfigFlag)
+	if path == "" {
+		return chain
+	}
+	cfg, err := loadChainConfig(path)
+	if err != nil {
+		panic(err)
+	}
+	return chain.merge(cfg)
+}
@@ -0,0 +1,66 @@
+package deployment
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+const testChainConfig = `{
+	"chain_id": 56,
+	"contracts": {
+		"network": ["0x0000000000000000000000000000000000000001"],
+		"burner": ["0x0000000000000000000000000000000000000002"]
+	},
+	"starting_blocks": {"v2": 100, "v3": 200, "v4": 300, "v5": 400}
+}`
+
+func newTestContext(t *testing.T, dpl, chainConfig string) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String(Flag, dpl, "")
+	set.String(ChainConfigFlag, chainConfig, "")
+	require.NoError(t, set.Parse(nil))
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func TestMustGetChainFromContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chain_config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "bsc.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(testChainConfig), 0644))
+
+	c := newTestContext(t, BSC.String(), path)
+	chain := MustGetChainFromContext(c)
+	assert.Equal(t, uint64(56), chain.ID)
+	assert.Equal(t, "BNB", chain.NativeToken)
+	assert.Equal(t, Chains[BSC].WrappedNativeToken, chain.WrappedNativeToken)
+
+	sb := MustGetStartingBlocksFromContext(c)
+	assert.Equal(t, uint64(100), sb.V2())
+	assert.Equal(t, uint64(200), sb.V3())
+	assert.Equal(t, uint64(300), sb.V4())
+	assert.Equal(t, uint64(300), sb.Version("v4"))
+	assert.Equal(t, uint64(400), sb.Version("v5"))
+	assert.Equal(t, uint64(0), sb.Version("v6"))
+
+	network := NewAddress(nil, nil, nil).WithContract("network")
+	assert.Equal(t, common.HexToAddress("0x0000000000000000000000000000000000000001"), network.MustGetOneFromContext(c))
+	assert.Panics(t, func() {
+		NewAddress(nil, nil, nil).WithContract("proxy").MustGetFromContext(c)
+	})
+
+	// built-in addresses and starting blocks are used without chain config
+	c = newTestContext(t, Production.String(), "")
+	assert.Equal(t, uint64(1), MustGetChainFromContext(c).ID)
+	prodNetwork := common.HexToAddress("0x0000000000000000000000000000000000000003")
+	assert.Equal(t, prodNetwork, NewAddress([]common.Address{prodNetwork}, nil, nil).WithContract("network").MustGetOneFromContext(c))
+	assert.Equal(t, StartingBlocks[Production], MustGetStartingBlocksFromContext(c))
+}
@@ -17,4 +17,8 @@ const (
 	Staging //staging
 	//Ropsten is ropsten mode for deployment
 	Ropsten //ropsten
+	//BSC is deployment on Binance Smart Chain, its contract addresses are read from chain config
+	BSC //bsc
+	//Polygon is deployment on Polygon, its contract addresses are read from chain config
+	Polygon //polygon
 )
@@ -1,6 +1,7 @@
 package deployment
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/urfave/cli"
@@ -11,6 +12,8 @@ type VersionedStartingBlocks struct {
 	v4 uint64
 	v3 uint64
 	v2 uint64
+	// others are starting blocks of versions without accessor, only set from chain config
+	others map[string]uint64
 }
 
 // V4 return starting block of KyberNetwork v4
@@ -28,6 +31,35 @@ func (v *VersionedStartingBlocks) V2() uint64 {
 	return v.v2
 }
 
+// Version returns starting block of given KyberNetwork version, 0 if it is unknown.
+func (v *VersionedStartingBlocks) Version(version string) uint64 {
+	switch version {
+	case "v4":
+		return v.v4
+	case "v3":
+		return v.v3
+	case "v2":
+		return v.v2
+	}
+	return v.others[version]
+}
+
+// UnmarshalJSON parses starting blocks from chain config, keyed by version.
+func (v *VersionedStartingBlocks) UnmarshalJSON(data []byte) error {
+	var blocks map[string]uint64
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return err
+	}
+	v.v4, v.v3, v.v2 = blocks["v4"], blocks["v3"], blocks["v2"]
+	delete(blocks, "v4")
+	delete(blocks, "v3")
+	delete(blocks, "v2")
+	if len(blocks) != 0 {
+		v.others = blocks
+	}
+	return nil
+}
+
 //StartingBlocks map deployment to its according starting blocks
 var StartingBlocks = map[Deployment]VersionedStartingBlocks{
 	Staging: {
@@ -51,6 +83,9 @@ var StartingBlocks = map[Deployment]VersionedStartingBlocks{
 
 //MustGetStartingBlocksFromContext return starting blocks from context
 func MustGetStartingBlocksFromContext(c *cli.Context) VersionedStartingBlocks {
+	if chain := MustGetChainFromContext(c); chain.StartingBlocks != nil {
+		return *chain.StartingBlocks
+	}
 	deploymentMode := MustGetDeploymentFromContext(c)
 	result, ok := StartingBlocks[deploymentMode]
 	if !ok {
@@ -0,0 +1,18 @@
+package httputil
+
+import (
+	"time"
+
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+)
+
+// TimezoneQuery is the timezone query parameter of aggregated HTTP APIs, either an IANA zone name
+// like Asia/Kolkata or a whole hour UTC offset for compatibility.
+type TimezoneQuery struct {
+	Timezone string `form:"timezone" binding:"isSupportedTimezone"`
+}
+
+// Location returns the location of the timezone query, default to UTC.
+func (q TimezoneQuery) Location() (*time.Location, error) {
+	return timeutil.ParseTimezone(q.Timezone)
+}
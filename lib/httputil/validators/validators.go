@@ -2,10 +2,13 @@ package validators
 
 import (
 	"reflect"
+	"strconv"
 	"strings"
 
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
 	tradelog "github.com/KyberNetwork/reserve-stats/tradelogs/common"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/gin-gonic/gin/binding"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
@@ -23,6 +26,18 @@ func isEthereumAddress(_ *validator.Validate, _ reflect.Value, _ reflect.Value,
 	return true
 }
 
+// isHash is a validator.Func function that returns true if given field is a valid 32 bytes hex string,
+// like a transaction hash or a reserve id.
+func isHash(_ *validator.Validate, _ reflect.Value, _ reflect.Value,
+	field reflect.Value, _ reflect.Type, _ reflect.Kind, _ string) bool {
+	hash := field.String()
+	if len(hash) == 0 {
+		return true
+	}
+	b, err := hexutil.Decode(hash)
+	return err == nil && len(b) == common.HashLength
+}
+
 // isEmail is a validator.Func function that returns true if given field
 // is a valid email address.
 func isEmail(_ *validator.Validate, _ reflect.Value, _ reflect.Value,
@@ -44,12 +59,16 @@ func isFreq(_ *validator.Validate, _ reflect.Value, _ reflect.Value,
 	return false
 }
 
-// isSupportedTimezone is a validator.Func that returns true if given field is a supported timezone
-// supported time range is from -11 to 14
+// isSupportedTimezone is a validator.Func that returns true if given field is a supported timezone,
+// either an IANA zone name or a whole hour offset, supported offset range is from -11 to 14
 func isSupportedTimezone(_ *validator.Validate, _ reflect.Value, _ reflect.Value,
 	field reflect.Value, _ reflect.Type, _ reflect.Kind, _ string) bool {
-	timezone := field.Int()
-	if timezone < -11 || timezone > 14 {
+	timezone := field.String()
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		timezone = strconv.FormatInt(field.Int(), 10)
+	}
+	if _, err := timeutil.ParseTimezone(timezone); err != nil {
 		return false
 	}
 	return true
@@ -77,6 +96,7 @@ func init() {
 		}{
 			{"isAddress", isEthereumAddress},
 			{"isEmail", isEmail},
+			{"isHash", isHash},
 			{"isFreq", isFreq},
 			{"isSupportedTimezone", isSupportedTimezone},
 			{"isValidCountryCode", isValidCountryCode},
@@ -0,0 +1,72 @@
+package httputil
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// WebhookSignatureHeader is the header of webhook requests keeping the HMAC-SHA256 signature of the body.
+const WebhookSignatureHeader = "X-Signature"
+
+// WebhookSignature returns the signature of a webhook body with the secret, as sha256=<hex>.
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PostJSON posts data as JSON to the url with given headers and returns the response body.
+// Responses with a non 2xx status code are returned as error.
+func PostJSON(client *http.Client, url string, data interface{}, headers map[string]string) ([]byte, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return postBody(client, url, body, headers)
+}
+
+// PostSignedJSON posts data as JSON to a webhook. If secret is not empty, the body is signed with
+// HMAC-SHA256 and the signature is sent in the X-Signature header.
+func PostSignedJSON(client *http.Client, url, secret string, data interface{}) ([]byte, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	if secret != "" {
+		headers[WebhookSignatureHeader] = WebhookSignature(secret, body)
+	}
+	return postBody(client, url, body, headers)
+}
+
+func postBody(client *http.Client, url string, body []byte, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+	rspBody, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode < http.StatusOK || rsp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", rsp.StatusCode, rspBody)
+	}
+	return rspBody, nil
+}
@@ -317,3 +317,31 @@ func (hc *Client) GetCurrencies() ([]string, error) {
 	}
 	return reply.Data, nil
 }
+
+//GetMergedTicker return the latest ticker with best bid and ask of a symbol
+func (hc *Client) GetMergedTicker(symbol string) (MergedTicker, error) {
+	var (
+		reply MergedTickerReply
+	)
+	endpoint := fmt.Sprintf("%s/market/detail/merged", huobiEndpoint)
+	res, err := hc.sendRequest(
+		http.MethodGet,
+		endpoint,
+		map[string]string{
+			"symbol": symbol,
+		},
+		false,
+	)
+	if err != nil {
+		return reply.Tick, err
+	}
+	err = json.Unmarshal(res, &reply)
+	if err != nil {
+		return reply.Tick, err
+	}
+	if reply.Status != StatusOK.String() {
+		return reply.Tick, fmt.Errorf("unexpected reply status %s, err_code=%s, err_msg=%s",
+			reply.Status, reply.ErrorCode, reply.ErrorMessage)
+	}
+	return reply.Tick, nil
+}
@@ -111,3 +111,18 @@ type CurrenciesReply struct {
 	Status string   `json:"status"`
 	Data   []string `json:"data"`
 }
+
+//MergedTickerReply hold huobi's reply on merged ticker endpoint
+type MergedTickerReply struct {
+	Status       string       `json:"status"`
+	ErrorCode    string       `json:"err-code"`
+	ErrorMessage string       `json:"err-msg"`
+	Tick         MergedTicker `json:"tick"`
+}
+
+//MergedTicker is the latest ticker of a symbol, Bid and Ask are [price, size] of the best bid and ask
+type MergedTicker struct {
+	Close float64    `json:"close"`
+	Bid   [2]float64 `json:"bid"`
+	Ask   [2]float64 `json:"ask"`
+}
@@ -1,6 +1,10 @@
 package timeutil
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
 
 // TimestampMsToTime turn a uint64 timestamp in millisecond to a golang time object
 func TimestampMsToTime(ms uint64) time.Time {
@@ -23,3 +27,32 @@ func UnixMilliSecond() uint64 {
 func Midnight(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
+
+const (
+	minTimezoneOffset = -11
+	maxTimezoneOffset = 14
+)
+
+// ParseTimezone returns the location of given timezone, which is either an IANA zone name like
+// Asia/Kolkata or a whole hour UTC offset from -11 to 14. Offsets are kept for compatibility, they are
+// named after the equivalent Etc/GMT zone, which has inverted sign. Empty timezone is UTC.
+func ParseTimezone(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	if offset, err := strconv.Atoi(timezone); err == nil {
+		if offset < minTimezoneOffset || offset > maxTimezoneOffset {
+			return nil, fmt.Errorf("timezone offset is out of range [%d, %d]: %d",
+				minTimezoneOffset, maxTimezoneOffset, offset)
+		}
+		if offset == 0 {
+			return time.UTC, nil
+		}
+		return time.FixedZone(fmt.Sprintf("Etc/GMT%+d", -offset), offset*60*60), nil
+	}
+	// Local is the zone of the server, it is not an IANA zone name
+	if timezone == "Local" {
+		return nil, fmt.Errorf("invalid timezone: %s", timezone)
+	}
+	return time.LoadLocation(timezone)
+}
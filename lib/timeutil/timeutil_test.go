@@ -0,0 +1,36 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimezone(t *testing.T) {
+	ts := time.Date(2020, 6, 1, 20, 0, 0, 0, time.UTC)
+
+	loc, err := ParseTimezone("")
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	loc, err = ParseTimezone("7")
+	require.NoError(t, err)
+	assert.Equal(t, "Etc/GMT-7", loc.String())
+	assert.Equal(t, time.Date(2020, 6, 2, 0, 0, 0, 0, loc), Midnight(ts.In(loc)))
+
+	loc, err = ParseTimezone("-5")
+	require.NoError(t, err)
+	assert.Equal(t, "Etc/GMT+5", loc.String())
+
+	loc, err = ParseTimezone("Asia/Kolkata")
+	require.NoError(t, err)
+	_, offset := ts.In(loc).Zone()
+	assert.Equal(t, 5*60*60+30*60, offset)
+
+	for _, tz := range []string{"15", "-12", "Local", "Mars/Olympus"} {
+		_, err = ParseTimezone(tz)
+		assert.Error(t, err, tz)
+	}
+}
@@ -0,0 +1,105 @@
+package tokenrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+)
+
+const (
+	binanceProviderName = "binance"
+	binanceBaseURL      = "https://api.binance.com"
+	binanceETHUSDSymbol = "ETHUSDT"
+	// binanceDefaultRateLimit keeps requests per second well below the request weight limit of Binance
+	// (1200 per minute), as the fiat backfiller requests rates of every minute with trades in a row.
+	binanceDefaultRateLimit = 10
+)
+
+// BinanceRateProviderOption is option for BinanceRateProvider constructor
+type BinanceRateProviderOption func(*BinanceRateProvider)
+
+// WithBinanceBaseURL is option to create BinanceRateProvider with a custom api url
+func WithBinanceBaseURL(baseURL string) BinanceRateProviderOption {
+	return func(brp *BinanceRateProvider) {
+		brp.baseURL = baseURL
+	}
+}
+
+// WithBinanceRateLimiter is option to create BinanceRateProvider with a custom rate limiter
+func WithBinanceRateLimiter(limiter *rate.Limiter) BinanceRateProviderOption {
+	return func(brp *BinanceRateProvider) {
+		brp.rateLimiter = limiter
+	}
+}
+
+// NewBinanceRateProvider returns ETH/USD rate provider from ETHUSDT candles of Binance.
+func NewBinanceRateProvider(options ...BinanceRateProviderOption) *BinanceRateProvider {
+	brp := &BinanceRateProvider{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		baseURL:     binanceBaseURL,
+		rateLimiter: rate.NewLimiter(rate.Limit(binanceDefaultRateLimit), 1),
+	}
+	for _, option := range options {
+		option(brp)
+	}
+	return brp
+}
+
+// BinanceRateProvider is ETH/USD rate provider returning the open price of the ETHUSDT one minute
+// candle at the given time.
+type BinanceRateProvider struct {
+	client      *http.Client
+	baseURL     string
+	rateLimiter *rate.Limiter
+}
+
+// USDRate returns ETH/USD rate at the given time. It is an error if Binance has no candle of that minute.
+func (brp *BinanceRateProvider) USDRate(timestamp time.Time) (float64, error) {
+	var candles [][]interface{}
+	// without end time, Binance returns the next candle if the one of the minute is missing
+	startTime := timeutil.TimeToTimestampMs(timestamp.Truncate(time.Minute))
+	endpoint := fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=1m&startTime=%d&endTime=%d&limit=1",
+		brp.baseURL, binanceETHUSDSymbol, startTime, startTime+uint64(time.Minute/time.Millisecond)-1)
+	if err := brp.rateLimiter.Wait(context.Background()); err != nil {
+		return 0, err
+	}
+	rsp, err := brp.client.Get(endpoint)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code from binance: %d, body: %s", rsp.StatusCode, body)
+	}
+	if err = json.Unmarshal(body, &candles); err != nil {
+		return 0, err
+	}
+	// a candle is [open time, open, high, low, close, ...]
+	if len(candles) == 0 || len(candles[0]) < 2 {
+		return 0, fmt.Errorf("no ETH/USD rate from binance at %s", timestamp)
+	}
+	open, ok := candles[0][1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid open price from binance: %v", candles[0][1])
+	}
+	return strconv.ParseFloat(open, 64)
+}
+
+// Name returns the name of the provider.
+func (brp *BinanceRateProvider) Name() string {
+	return binanceProviderName
+}
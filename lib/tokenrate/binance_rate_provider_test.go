@@ -0,0 +1,39 @@
+package tokenrate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinanceRateProvider_USDRate(t *testing.T) {
+	var query map[string][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/klines", r.URL.Path)
+		query = r.URL.Query()
+		if r.URL.Query().Get("startTime") == "0" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[[1594373400000,"241.50000000","241.80000000","241.40000000","241.70000000","1000.0",1594373459999]]`))
+	}))
+	defer ts.Close()
+
+	provider := NewBinanceRateProvider(WithBinanceBaseURL(ts.URL))
+	assert.Equal(t, "binance", provider.Name())
+	rate, err := provider.USDRate(time.Date(2020, 7, 10, 9, 30, 25, 0, time.UTC))
+	require.NoError(t, err)
+	assert.InDelta(t, 241.5, rate, 1e-9)
+	assert.Equal(t, "ETHUSDT", query["symbol"][0])
+	assert.Equal(t, "1594373400000", query["startTime"][0])
+	// only the candle of the minute is accepted
+	assert.Equal(t, "1594373459999", query["endTime"][0])
+
+	// no candle in the minute
+	_, err = provider.USDRate(time.Unix(0, 0))
+	assert.Error(t, err)
+}
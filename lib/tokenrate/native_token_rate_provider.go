@@ -0,0 +1,44 @@
+package tokenrate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/KyberNetwork/tokenrate"
+)
+
+const usdCurrency = "usd"
+
+// coinGeckoIDs is the CoinGecko coin id of supported chain native tokens by symbol.
+var coinGeckoIDs = map[string]string{
+	"ETH":   "ethereum",
+	"BNB":   "binancecoin",
+	"MATIC": "matic-network",
+}
+
+// NewNativeTokenUSDRateProvider returns the USD rate provider of the native token of a chain with
+// given symbol, the token is queried by its CoinGecko coin id.
+func NewNativeTokenUSDRateProvider(provider tokenrate.Provider, nativeToken string) (*NativeTokenUSDRateProvider, error) {
+	coinID, ok := coinGeckoIDs[nativeToken]
+	if !ok {
+		return nil, fmt.Errorf("USD rate of native token not supported: %q", nativeToken)
+	}
+	return &NativeTokenUSDRateProvider{provider: provider, coinID: coinID}, nil
+}
+
+// NativeTokenUSDRateProvider is the USD rate provider of a chain native token, it satisfies the
+// tokenrate.ETHUSDRateProvider interface so rates of chains other than Ethereum are in native token.
+type NativeTokenUSDRateProvider struct {
+	provider tokenrate.Provider
+	coinID   string
+}
+
+// USDRate returns USD rate of the native token at the given time.
+func (p *NativeTokenUSDRateProvider) USDRate(timestamp time.Time) (float64, error) {
+	return p.provider.Rate(p.coinID, usdCurrency, timestamp)
+}
+
+// Name returns name of the underlying provider.
+func (p *NativeTokenUSDRateProvider) Name() string {
+	return p.provider.Name()
+}
@@ -0,0 +1,24 @@
+package tokenrate
+
+import (
+	"fmt"
+
+	"github.com/KyberNetwork/tokenrate"
+	"github.com/KyberNetwork/tokenrate/coingecko"
+	"go.uber.org/zap"
+)
+
+// NewETHUSDRateProvider returns the ETH/USD rate provider of the given name. Daily rates of CoinGecko
+// are cached, minute rates of Binance are not.
+func NewETHUSDRateProvider(sugar *zap.SugaredLogger, name string) (tokenrate.ETHUSDRateProvider, error) {
+	cgk := coingecko.New()
+	switch name {
+	case cgk.Name():
+		return NewCachedRateProvider(sugar, cgk), nil
+	case binanceProviderName:
+		return NewBinanceRateProvider(), nil
+	default:
+		return nil, fmt.Errorf("ETH/USD rate provider not supported: %q, supported providers: %s, %s",
+			name, cgk.Name(), binanceProviderName)
+	}
+}
@@ -0,0 +1,135 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// Thresholds configures the rules a rate is checked against. A nil threshold is inherited from the
+// upper level of the config.
+type Thresholds struct {
+	// SanityTolerance is the fraction a rate may exceed its sanity rate by, default to 0.
+	SanityTolerance *float64 `json:"sanity_tolerance,omitempty"`
+	// MinSpread is the minimum spread 1 - buy rate * sell rate, the rates are considered crossed
+	// below it, default to 0.
+	MinSpread *float64 `json:"min_spread,omitempty"`
+	// MaxJump is the maximum fraction a rate may change by between two crawled blocks, the rule
+	// is disabled if it is not configured or 0.
+	MaxJump *float64 `json:"max_jump,omitempty"`
+}
+
+// merge returns the thresholds with values of t overridden by non nil values of o.
+func (t Thresholds) merge(o Thresholds) Thresholds {
+	if o.SanityTolerance != nil {
+		t.SanityTolerance = o.SanityTolerance
+	}
+	if o.MinSpread != nil {
+		t.MinSpread = o.MinSpread
+	}
+	if o.MaxJump != nil {
+		t.MaxJump = o.MaxJump
+	}
+	return t
+}
+
+func (t Thresholds) validate() error {
+	for name, v := range map[string]*float64{
+		"sanity_tolerance": t.SanityTolerance,
+		"min_spread":       t.MinSpread,
+		"max_jump":         t.MaxJump,
+	} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("%s must not be negative: %f", name, *v)
+		}
+	}
+	return nil
+}
+
+// ReserveConfig is the thresholds of a reserve, overridden per pair.
+type ReserveConfig struct {
+	Thresholds
+	Pairs map[string]Thresholds `json:"pairs,omitempty"`
+}
+
+// Config is the thresholds of alert rules, the default thresholds are overridden per reserve then
+// per pair.
+//
+//	{
+//	  "default": {"max_jump": 0.1},
+//	  "reserves": {
+//	    "0x63825c174ab367968EC60f061753D3bbD36A0D8F": {
+//	      "sanity_tolerance": 0.01,
+//	      "pairs": {"ETH-KNC": {"max_jump": 0.2}}
+//	    }
+//	  }
+//	}
+type Config struct {
+	Default  Thresholds               `json:"default"`
+	Reserves map[string]ReserveConfig `json:"reserves,omitempty"`
+}
+
+// NewConfigFromFile reads the config from a JSON file.
+func NewConfigFromFile(path string) (Config, error) {
+	var cfg Config
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate returns an error if any threshold of the config is invalid.
+func (c Config) Validate() error {
+	if err := c.Default.validate(); err != nil {
+		return fmt.Errorf("invalid default thresholds: %s", err)
+	}
+	for reserve, rc := range c.Reserves {
+		if err := rc.validate(); err != nil {
+			return fmt.Errorf("invalid thresholds of reserve %s: %s", reserve, err)
+		}
+		for pair, t := range rc.Pairs {
+			if err := t.validate(); err != nil {
+				return fmt.Errorf("invalid thresholds of reserve %s pair %s: %s", reserve, pair, err)
+			}
+		}
+	}
+	return nil
+}
+
+// thresholds returns the resolved thresholds of reserve for pair. Reserve addresses are matched
+// case insensitively.
+func (c Config) thresholds(reserve, pair string) resolvedThresholds {
+	t := c.Default
+	for addr, rc := range c.Reserves {
+		if !strings.EqualFold(addr, reserve) {
+			continue
+		}
+		t = t.merge(rc.Thresholds)
+		if pt, ok := rc.Pairs[pair]; ok {
+			t = t.merge(pt)
+		}
+		break
+	}
+	var resolved resolvedThresholds
+	if t.SanityTolerance != nil {
+		resolved.sanityTolerance = *t.SanityTolerance
+	}
+	if t.MinSpread != nil {
+		resolved.minSpread = *t.MinSpread
+	}
+	if t.MaxJump != nil {
+		resolved.maxJump = *t.MaxJump
+	}
+	return resolved
+}
+
+type resolvedThresholds struct {
+	sanityTolerance float64
+	minSpread       float64
+	maxJump         float64
+}
@@ -0,0 +1,106 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+const (
+	sideBuy  = "buy"
+	sideSell = "sell"
+)
+
+// Engine checks crawled rate snapshots against the rules of a config. It keeps the last snapshot
+// to detect rate jumps, so rates are expected to be checked in block order.
+type Engine struct {
+	cfg  Config
+	last map[string]map[string]common.ReserveRateEntry
+}
+
+// NewEngine returns a new Engine instance.
+func NewEngine(cfg Config) *Engine {
+	return &Engine{cfg: cfg, last: make(map[string]map[string]common.ReserveRateEntry)}
+}
+
+// Check returns alerts of the rates snapshot at given block, ordered by reserve and pair.
+// Rates of 0 mean the reserve does not quote the pair and are not checked.
+func (e *Engine) Check(block uint64, timestamp time.Time, rates map[string]map[string]common.ReserveRateEntry) []common.RateAlert {
+	var alerts []common.RateAlert
+	for reserve, pairRates := range rates {
+		last := e.last[reserve]
+		for pair, rate := range pairRates {
+			newAlert := func(alertType common.RateAlertType, side string, value, threshold float64, message string) {
+				alerts = append(alerts, common.RateAlert{
+					Timestamp: timestamp,
+					Block:     block,
+					Reserve:   reserve,
+					Pair:      pair,
+					Type:      alertType,
+					Side:      side,
+					Value:     value,
+					Threshold: threshold,
+					Message:   fmt.Sprintf("%s %s at block %d: %s", reserve, pair, block, message),
+				})
+			}
+			t := e.cfg.thresholds(reserve, pair)
+
+			for _, r := range []struct {
+				side   string
+				rate   float64
+				sanity float64
+			}{
+				{sideBuy, rate.BuyReserveRate, rate.BuySanityRate},
+				{sideSell, rate.SellReserveRate, rate.SellSanityRate},
+			} {
+				if r.sanity <= 0 || r.rate <= 0 {
+					continue
+				}
+				if limit := r.sanity * (1 + t.sanityTolerance); r.rate > limit {
+					newAlert(common.RateAlertSanityViolation, r.side, r.rate, limit,
+						fmt.Sprintf("%s rate %g is above sanity rate %g", r.side, r.rate, r.sanity))
+				}
+			}
+
+			if rate.BuyReserveRate > 0 && rate.SellReserveRate > 0 {
+				if spread := 1 - rate.BuyReserveRate*rate.SellReserveRate; spread < t.minSpread {
+					newAlert(common.RateAlertCrossed, "", spread, t.minSpread,
+						fmt.Sprintf("buy rate %g and sell rate %g are crossed, spread %g", rate.BuyReserveRate, rate.SellReserveRate, spread))
+				}
+			}
+
+			lastRate, ok := last[pair]
+			if t.maxJump <= 0 || !ok {
+				continue
+			}
+			for _, r := range []struct {
+				side     string
+				rate     float64
+				lastRate float64
+			}{
+				{sideBuy, rate.BuyReserveRate, lastRate.BuyReserveRate},
+				{sideSell, rate.SellReserveRate, lastRate.SellReserveRate},
+			} {
+				if r.rate <= 0 || r.lastRate <= 0 {
+					continue
+				}
+				if change := math.Abs(r.rate-r.lastRate) / r.lastRate; change > t.maxJump {
+					newAlert(common.RateAlertJump, r.side, change, t.maxJump,
+						fmt.Sprintf("%s rate changed by %.2f%% from %g to %g", r.side, change*100, r.lastRate, r.rate))
+				}
+			}
+		}
+		e.last[reserve] = pairRates
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		if alerts[i].Reserve != alerts[j].Reserve {
+			return alerts[i].Reserve < alerts[j].Reserve
+		}
+		return alerts[i].Pair < alerts[j].Pair
+	})
+	return alerts
+}
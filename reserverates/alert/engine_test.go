@@ -0,0 +1,91 @@
+package alert
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+const (
+	testReserve      = "0x63825c174ab367968EC60f061753D3bbD36A0D8F"
+	testOtherReserve = "0x21433Dec9Cb634A23c6A4BbcCe08c83f5aC2EC18"
+)
+
+func TestConfigThresholds(t *testing.T) {
+	var cfg Config
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"default": {"max_jump": 0.1},
+		"reserves": {
+			"0x63825c174ab367968ec60f061753d3bbd36a0d8f": {
+				"sanity_tolerance": 0.01,
+				"pairs": {"ETH-KNC": {"max_jump": 0.2}}
+			}
+		}
+	}`), &cfg))
+	require.NoError(t, cfg.Validate())
+
+	assert.Equal(t, resolvedThresholds{sanityTolerance: 0.01, maxJump: 0.2}, cfg.thresholds(testReserve, "ETH-KNC"))
+	assert.Equal(t, resolvedThresholds{sanityTolerance: 0.01, maxJump: 0.1}, cfg.thresholds(testReserve, "ETH-ZRX"))
+	assert.Equal(t, resolvedThresholds{maxJump: 0.1}, cfg.thresholds(testOtherReserve, "ETH-KNC"))
+
+	negative := -1.0
+	cfg.Reserves[testReserve] = ReserveConfig{Thresholds: Thresholds{MinSpread: &negative}}
+	assert.Error(t, cfg.Validate())
+}
+
+func TestEngineCheck(t *testing.T) {
+	var (
+		maxJump   = 0.1
+		tolerance = 0.01
+		engine    = NewEngine(Config{
+			Default: Thresholds{MaxJump: &maxJump},
+			Reserves: map[string]ReserveConfig{
+				testOtherReserve: {Thresholds: Thresholds{SanityTolerance: &tolerance}},
+			},
+		})
+		ts = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	alerts := engine.Check(100, ts, map[string]map[string]common.ReserveRateEntry{
+		testReserve: {
+			"ETH-KNC": {BuyReserveRate: 100, BuySanityRate: 110, SellReserveRate: 0.0099, SellSanityRate: 0.011},
+			// not quoted
+			"ETH-ZRX": {BuyReserveRate: 0, BuySanityRate: 0, SellReserveRate: 0, SellSanityRate: 0},
+		},
+		testOtherReserve: {
+			// buy rate is above sanity rate but within tolerance, buy and sell are crossed
+			"ETH-KNC": {BuyReserveRate: 111, BuySanityRate: 110, SellReserveRate: 0.01, SellSanityRate: 0.011},
+		},
+	})
+	require.Len(t, alerts, 1)
+	assert.Equal(t, common.RateAlertCrossed, alerts[0].Type)
+	assert.Equal(t, testOtherReserve, alerts[0].Reserve)
+	assert.Equal(t, uint64(100), alerts[0].Block)
+	assert.Equal(t, ts, alerts[0].Timestamp)
+	assert.InDelta(t, -0.11, alerts[0].Value, 1e-9)
+
+	alerts = engine.Check(101, ts.Add(15*time.Second), map[string]map[string]common.ReserveRateEntry{
+		testReserve: {
+			// buy rate jumps 20% above its sanity rate
+			"ETH-KNC": {BuyReserveRate: 120, BuySanityRate: 110, SellReserveRate: 0.0081, SellSanityRate: 0.011},
+			// listed
+			"ETH-ZRX": {BuyReserveRate: 1000, BuySanityRate: 0, SellReserveRate: 0.00099, SellSanityRate: 0},
+		},
+	})
+	var types []common.RateAlertType
+	var sides []string
+	for _, alert := range alerts {
+		assert.Equal(t, "ETH-KNC", alert.Pair)
+		types = append(types, alert.Type)
+		sides = append(sides, alert.Side)
+	}
+	assert.Equal(t, []common.RateAlertType{common.RateAlertSanityViolation, common.RateAlertJump, common.RateAlertJump}, types)
+	assert.Equal(t, []string{sideBuy, sideBuy, sideSell}, sides)
+	assert.InDelta(t, 0.2, alerts[1].Value, 1e-9)
+	assert.InDelta(t, 0.1818, alerts[2].Value, 1e-4)
+}
@@ -0,0 +1,134 @@
+package alert
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/urfave/cli"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+	"github.com/KyberNetwork/reserve-stats/reserverates/storage"
+)
+
+const (
+	configFileFlag    = "alert-config-file"
+	webhookURLFlag    = "alert-webhook-url"
+	webhookSecretFlag = "alert-webhook-secret"
+
+	defaultHTTPTimeout = 10 * time.Second
+	// queueSize is the number of alert batches waiting to be sent, alerts are persisted so dropped
+	// batches can still be listed with the API.
+	queueSize = 64
+)
+
+// NewCliFlags returns cli flags to configure reserve rate alerting.
+func NewCliFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:   configFileFlag,
+			Usage:  "path to the JSON file of rate alert thresholds, alerting is disabled if not provided",
+			EnvVar: "ALERT_CONFIG_FILE",
+		},
+		cli.StringFlag{
+			Name:   webhookURLFlag,
+			Usage:  "url rate alerts are posted to",
+			EnvVar: "ALERT_WEBHOOK_URL",
+		},
+		cli.StringFlag{
+			Name:   webhookSecretFlag,
+			Usage:  "secret to sign rate alerts posted to the webhook",
+			EnvVar: "ALERT_WEBHOOK_SECRET",
+		},
+	}
+}
+
+// Monitor checks crawled rates, persists the alerts and queues them to be sent to the notifier by Run, so
+// a slow webhook does not slow down crawling.
+type Monitor struct {
+	sugar      *zap.SugaredLogger
+	engine     *Engine
+	st         storage.RateAlertStorage
+	notifier   Notifier
+	blkTimeRsv blockchain.BlockTimeResolverInterface
+	queue      chan []common.RateAlert
+}
+
+// NewMonitor returns a new Monitor instance, notifier is optional.
+func NewMonitor(sugar *zap.SugaredLogger, engine *Engine, st storage.RateAlertStorage, notifier Notifier,
+	blkTimeRsv blockchain.BlockTimeResolverInterface) *Monitor {
+	return &Monitor{
+		sugar:      sugar,
+		engine:     engine,
+		st:         st,
+		notifier:   notifier,
+		blkTimeRsv: blkTimeRsv,
+		queue:      make(chan []common.RateAlert, queueSize),
+	}
+}
+
+// NewMonitorFromContext returns the monitor configured by cli flags, or nil if alerting is disabled.
+func NewMonitorFromContext(c *cli.Context, sugar *zap.SugaredLogger, st storage.RateAlertStorage,
+	blkTimeRsv blockchain.BlockTimeResolverInterface) (*Monitor, error) {
+	configFile := c.String(configFileFlag)
+	if configFile == "" {
+		sugar.Info("no alert config file provided, rate alerting is disabled")
+		return nil, nil
+	}
+	cfg, err := NewConfigFromFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	var notifier Notifier
+	if url := c.String(webhookURLFlag); url != "" {
+		notifier = NewWebhookNotifier(&http.Client{Timeout: defaultHTTPTimeout}, url, c.String(webhookSecretFlag))
+	}
+	return NewMonitor(sugar, NewEngine(cfg), st, notifier, blkTimeRsv), nil
+}
+
+// CheckRates checks the rates crawled at given block. Alerts are persisted, then queued to be sent by Run.
+// It never waits for the notifier, alerts are not sent if the queue is full.
+func (m *Monitor) CheckRates(block uint64, rates map[string]map[string]common.ReserveRateEntry) error {
+	logger := m.sugar.With("func", caller.GetCurrentFunctionName(), "block", block)
+	timestamp, err := m.blkTimeRsv.Resolve(block)
+	if err != nil {
+		return err
+	}
+	alerts := m.engine.Check(block, timestamp, rates)
+	if len(alerts) == 0 {
+		return nil
+	}
+	logger.Infow("rate alerts raised", "alerts", len(alerts))
+	if err = m.st.SaveRateAlerts(alerts); err != nil {
+		return err
+	}
+	if m.notifier == nil {
+		return nil
+	}
+	select {
+	case m.queue <- alerts:
+	default:
+		logger.Warnw("alert queue is full, skipping notification", "alerts", len(alerts))
+	}
+	return nil
+}
+
+// Run sends queued alerts to the notifier until Stop is called. A failure to send them is only logged as
+// they can still be listed with the API.
+func (m *Monitor) Run() {
+	for alerts := range m.queue {
+		if err := m.notifier.Notify(alerts); err != nil {
+			m.sugar.Errorw("failed to send rate alerts",
+				"func", caller.GetCurrentFunctionName(),
+				"block", alerts[0].Block,
+				"error", err)
+		}
+	}
+}
+
+// Stop stops Run once queued alerts are sent, CheckRates must not be called afterward.
+func (m *Monitor) Stop() {
+	close(m.queue)
+}
@@ -0,0 +1,65 @@
+package alert
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/testutil"
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+type mockStorage struct {
+	alerts []common.RateAlert
+}
+
+func (s *mockStorage) SaveRateAlerts(alerts []common.RateAlert) error {
+	s.alerts = append(s.alerts, alerts...)
+	return nil
+}
+
+func (s *mockStorage) GetRateAlerts(from, to time.Time, reserves []ethereum.Address, alertType common.RateAlertType) ([]common.RateAlert, error) {
+	return s.alerts, nil
+}
+
+type mockNotifier struct {
+	mu     sync.Mutex
+	blocks []uint64
+}
+
+func (n *mockNotifier) Notify(alerts []common.RateAlert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.blocks = append(n.blocks, alerts[0].Block)
+	return nil
+}
+
+func TestMonitorQueue(t *testing.T) {
+	var (
+		st       = &mockStorage{}
+		notifier = &mockNotifier{}
+		// buy and sell rates are crossed
+		rates = map[string]map[string]common.ReserveRateEntry{
+			testReserve: {"ETH-KNC": {BuyReserveRate: 111, SellReserveRate: 0.01}},
+		}
+	)
+	monitor := NewMonitor(testutil.MustNewDevelopmentSugaredLogger(), NewEngine(Config{}), st, notifier,
+		blockchain.NewMockBlockTimeResolve(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	// checking rates does not wait for the notifier, alerts are only persisted when the queue is full
+	for block := uint64(0); block < queueSize+1; block++ {
+		require.NoError(t, monitor.CheckRates(block, rates))
+	}
+	assert.Len(t, st.alerts, queueSize+1)
+	assert.Empty(t, notifier.blocks)
+
+	monitor.Stop()
+	monitor.Run()
+	require.Len(t, notifier.blocks, queueSize)
+	assert.Equal(t, uint64(queueSize-1), notifier.blocks[queueSize-1])
+}
@@ -0,0 +1,36 @@
+package alert
+
+import (
+	"net/http"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+// Notifier sends alerts to a destination.
+type Notifier interface {
+	Notify(alerts []common.RateAlert) error
+}
+
+// webhookPayload is the body posted to the webhook.
+type webhookPayload struct {
+	Alerts []common.RateAlert `json:"alerts"`
+}
+
+// WebhookNotifier posts alerts as JSON to an url, the body is signed if a secret is configured.
+type WebhookNotifier struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+// NewWebhookNotifier returns a new WebhookNotifier instance.
+func NewWebhookNotifier(client *http.Client, url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{client: client, url: url, secret: secret}
+}
+
+// Notify posts the alerts to the webhook.
+func (n *WebhookNotifier) Notify(alerts []common.RateAlert) error {
+	_, err := httputil.PostSignedJSON(n.client, n.url, n.secret, webhookPayload{Alerts: alerts})
+	return err
+}
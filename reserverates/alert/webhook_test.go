@@ -0,0 +1,53 @@
+package alert
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	const secret = "secret"
+	var (
+		alerts = []common.RateAlert{{
+			Timestamp: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Block:     100,
+			Reserve:   testReserve,
+			Pair:      "ETH-KNC",
+			Type:      common.RateAlertCrossed,
+			Value:     -0.1,
+			Message:   "crossed",
+		}}
+		received webhookPayload
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, httputil.WebhookSignature(secret, body), r.Header.Get(httputil.WebhookSignatureHeader))
+		assert.NoError(t, json.Unmarshal(body, &received))
+	}))
+	defer server.Close()
+
+	require.NoError(t, NewWebhookNotifier(server.Client(), server.URL, secret).Notify(alerts))
+	require.Len(t, received.Alerts, 1)
+	assert.True(t, alerts[0].Timestamp.Equal(received.Alerts[0].Timestamp))
+	received.Alerts[0].Timestamp = alerts[0].Timestamp
+	assert.Equal(t, alerts, received.Alerts)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	assert.Error(t, NewWebhookNotifier(failing.Client(), failing.URL, "").Notify(alerts))
+}
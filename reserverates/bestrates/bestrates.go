@@ -0,0 +1,129 @@
+package bestrates
+
+import (
+	"sort"
+	"time"
+
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+// quote is the rate offered by a reserve for a side of a pair.
+type quote struct {
+	reserve string
+	rate    float64
+}
+
+// Compute returns the best buy and sell rates of a pair across reserves in time range [from, to] from the
+// rate intervals of every reserve, sorted by from block. As rates are stored as intervals, a best rate is
+// only returned at the blocks it changes, the first one is the best rate valid at from. A best rate also
+// changes at the end of an interval without successor, when the reserve stops quoting the pair, it is
+// timestamped with the last time the reserve quoted it.
+func Compute(rates map[string][]common.ReserveRates, from, to time.Time) []common.BestRate {
+	var (
+		reserves   []string
+		timestamps = make(map[uint64]time.Time)
+		blocks     []uint64
+		result     []common.BestRate
+		lastBlock  uint64 // the end of crawled blocks, nothing is known from it
+	)
+	for reserve, intervals := range rates {
+		reserves = append(reserves, reserve)
+		for _, interval := range intervals {
+			timestamps[interval.FromBlock] = interval.Timestamp
+			if interval.ToBlock > lastBlock {
+				lastBlock = interval.ToBlock
+			}
+		}
+	}
+	for _, intervals := range rates {
+		for _, interval := range intervals {
+			if _, ok := timestamps[interval.ToBlock]; ok || interval.ToBlock >= lastBlock {
+				continue
+			}
+			endTime := interval.ToTimestamp
+			if endTime.IsZero() {
+				endTime = interval.Timestamp
+			}
+			if endTime.After(to) {
+				continue
+			}
+			timestamps[interval.ToBlock] = endTime
+		}
+	}
+	// ties are resolved in favor of the first reserve by address
+	sort.Strings(reserves)
+	for block := range timestamps {
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+
+	start := 0
+	for i, block := range blocks {
+		if timestamps[block].After(from) {
+			break
+		}
+		start = i
+	}
+
+	var (
+		current = make(map[string]int) // reserve -> index of the interval valid at the block
+		last    common.BestRate
+	)
+	for _, block := range blocks[start:] {
+		var buys, sells []quote
+		for _, reserve := range reserves {
+			intervals := rates[reserve]
+			i := current[reserve]
+			for i < len(intervals) && intervals[i].ToBlock <= block {
+				i++
+			}
+			current[reserve] = i
+			if i == len(intervals) || intervals[i].FromBlock > block {
+				continue
+			}
+			rate := intervals[i].Rates
+			if rate.BuyReserveRate > 0 {
+				buys = append(buys, quote{reserve: reserve, rate: rate.BuyReserveRate})
+			}
+			if rate.SellReserveRate > 0 {
+				sells = append(sells, quote{reserve: reserve, rate: rate.SellReserveRate})
+			}
+		}
+
+		best := common.BestRate{
+			Timestamp: timestamps[block],
+			Block:     block,
+			Buy:       bestSide(buys),
+			Sell:      bestSide(sells),
+		}
+		if best.Buy == last.Buy && best.Sell == last.Sell {
+			continue
+		}
+		result = append(result, best)
+		last = best
+	}
+	return result
+}
+
+// bestSide returns the best of given quotes and the spread with the runner-up.
+func bestSide(quotes []quote) common.BestRateSide {
+	var first, second quote
+	for _, q := range quotes {
+		switch {
+		case q.rate > first.rate:
+			first, second = q, first
+		case q.rate > second.rate:
+			second = q
+		}
+	}
+	side := common.BestRateSide{
+		Reserve:       first.reserve,
+		Rate:          first.rate,
+		SecondReserve: second.reserve,
+		SecondRate:    second.rate,
+	}
+	if second.rate > 0 {
+		side.Spread = (first.rate - second.rate) / first.rate
+	}
+	return side
+}
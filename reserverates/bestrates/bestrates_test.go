@@ -0,0 +1,107 @@
+package bestrates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+func TestCompute(t *testing.T) {
+	var (
+		genesis  = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		reserveA = "0x21433Dec9Cb634A23c6A4BbcCe08c83f5aC2EC18"
+		reserveB = "0x63825c174ab367968EC60f061753D3bbD36A0D8F"
+		reserveC = "0x9D27a2D71Ac44E075f764d5612581E9Afc1964fd"
+		reserveD = "0xA467b88BBF9706622be2784aF724C4B44a9d26F4"
+	)
+	blockTime := func(block uint64) time.Time {
+		return genesis.Add(time.Duration(block) * 15 * time.Second)
+	}
+	interval := func(from, to uint64, buy, sell float64) common.ReserveRates {
+		return common.ReserveRates{
+			Timestamp:   blockTime(from),
+			ToTimestamp: blockTime(to - 1),
+			FromBlock:   from,
+			ToBlock:     to,
+			Rates:       common.ReserveRateEntry{BuyReserveRate: buy, SellReserveRate: sell},
+		}
+	}
+	rates := map[string][]common.ReserveRates{
+		reserveA: {
+			interval(100, 104, 100, 0.0098),
+			interval(104, 110, 102, 0.0098),
+			interval(110, 120, 102, 0.0097),
+		},
+		reserveB: {
+			interval(102, 106, 101, 0.0099),
+			// reserveB stops quoting the sell side
+			interval(106, 120, 101, 0),
+		},
+		reserveC: {
+			interval(104, 108, 50, 0.001),
+			// the change at block 108 does not change the best rates
+			interval(108, 120, 60, 0.001),
+		},
+		reserveD: {
+			// reserveD offers the best buy rate then stops quoting the pair
+			interval(111, 115, 200, 0),
+		},
+	}
+
+	best := Compute(rates, blockTime(101), blockTime(119))
+	require.Len(t, best, 7)
+
+	// best rates valid at from, only reserveA quotes rates
+	assert.Equal(t, uint64(100), best[0].Block)
+	assert.Equal(t, blockTime(100), best[0].Timestamp)
+	assert.Equal(t, common.BestRateSide{Reserve: reserveA, Rate: 100}, best[0].Buy)
+	assert.Equal(t, common.BestRateSide{Reserve: reserveA, Rate: 0.0098}, best[0].Sell)
+
+	assert.Equal(t, uint64(102), best[1].Block)
+	assert.Equal(t, reserveB, best[1].Buy.Reserve)
+	assert.Equal(t, reserveA, best[1].Buy.SecondReserve)
+	assert.InDelta(t, 1.0/101, best[1].Buy.Spread, 1e-12)
+	assert.Equal(t, reserveB, best[1].Sell.Reserve)
+	assert.InDelta(t, 0.0001/0.0099, best[1].Sell.Spread, 1e-9)
+
+	assert.Equal(t, uint64(104), best[2].Block)
+	assert.Equal(t, reserveA, best[2].Buy.Reserve)
+	assert.Equal(t, 102.0, best[2].Buy.Rate)
+	assert.Equal(t, reserveB, best[2].Buy.SecondReserve)
+	assert.Equal(t, reserveB, best[2].Sell.Reserve)
+
+	assert.Equal(t, uint64(106), best[3].Block)
+	assert.Equal(t, reserveA, best[3].Sell.Reserve)
+	assert.Equal(t, reserveC, best[3].Sell.SecondReserve)
+
+	assert.Equal(t, uint64(110), best[4].Block)
+	assert.Equal(t, 0.0097, best[4].Sell.Rate)
+
+	assert.Equal(t, uint64(111), best[5].Block)
+	assert.Equal(t, reserveD, best[5].Buy.Reserve)
+	assert.Equal(t, reserveA, best[5].Buy.SecondReserve)
+
+	// reserveD drops out at the end of its interval
+	assert.Equal(t, uint64(115), best[6].Block)
+	assert.Equal(t, blockTime(114), best[6].Timestamp)
+	assert.Equal(t, reserveA, best[6].Buy.Reserve)
+	assert.Equal(t, reserveB, best[6].Buy.SecondReserve)
+	assert.Equal(t, best[4].Sell, best[6].Sell)
+
+	// the first best rate is the one valid at from
+	best = Compute(rates, blockTime(107), blockTime(119))
+	require.Len(t, best, 4)
+	assert.Equal(t, uint64(106), best[0].Block)
+	assert.Equal(t, uint64(110), best[1].Block)
+
+	// the end of reserveD interval is after to
+	best = Compute(rates, blockTime(101), blockTime(113))
+	require.Len(t, best, 6)
+	assert.Equal(t, uint64(111), best[5].Block)
+
+	assert.Len(t, Compute(nil, genesis, genesis), 0)
+}
@@ -0,0 +1,231 @@
+package cexref
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/binance"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/huobi"
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+	"github.com/KyberNetwork/reserve-stats/reserverates/storage"
+)
+
+const (
+	exchangesFlag = "cex-reference-exchanges"
+	maxLagFlag    = "cex-reference-max-lag"
+
+	defaultMaxLag = 5 * time.Minute
+	// queueSize is the number of crawled snapshots waiting to be sampled, prices of blocks older
+	// than max lag are not sampled anyway.
+	queueSize = 64
+
+	pairPrefix = "ETH-"
+)
+
+// NewCliFlags returns cli flags to configure sampling of CEX reference prices.
+func NewCliFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringSliceFlag{
+			Name: exchangesFlag,
+			Usage: fmt.Sprintf("exchanges to sample reference prices from when rates are crawled, one of: %s, %s. Sampling is disabled if not provided",
+				ExchangeBinance, ExchangeHuobi),
+			EnvVar: "CEX_REFERENCE_EXCHANGES",
+		},
+		cli.DurationFlag{
+			Name:   maxLagFlag,
+			Usage:  "prices are only sampled for blocks mined in this duration as exchanges only provide current prices",
+			EnvVar: "CEX_REFERENCE_MAX_LAG",
+			Value:  defaultMaxLag,
+		},
+	}
+}
+
+// snapshot is the rates of reserves crawled at a block.
+type snapshot struct {
+	block uint64
+	rates map[string]map[string]common.ReserveRateEntry
+}
+
+// Sampler samples mid prices of tokens on exchanges when reserve rates are crawled and compares them with
+// the rates. Crawled rates are queued and sampled by Run, so requests to exchanges do not slow down crawling.
+type Sampler struct {
+	sugar      *zap.SugaredLogger
+	sources    []PriceSource
+	st         storage.CEXReferenceStorage
+	blkTimeRsv blockchain.BlockTimeResolverInterface
+	maxLag     time.Duration
+	now        func() time.Time
+	snapshots  chan snapshot
+}
+
+// NewSampler returns a new Sampler instance.
+func NewSampler(sugar *zap.SugaredLogger, sources []PriceSource, st storage.CEXReferenceStorage,
+	blkTimeRsv blockchain.BlockTimeResolverInterface, maxLag time.Duration) *Sampler {
+	return &Sampler{
+		sugar:      sugar,
+		sources:    sources,
+		st:         st,
+		blkTimeRsv: blkTimeRsv,
+		maxLag:     maxLag,
+		now:        time.Now,
+		snapshots:  make(chan snapshot, queueSize),
+	}
+}
+
+// NewSamplerFromContext returns the sampler configured by cli flags, or nil if sampling is disabled.
+func NewSamplerFromContext(c *cli.Context, sugar *zap.SugaredLogger, st storage.CEXReferenceStorage,
+	blkTimeRsv blockchain.BlockTimeResolverInterface) (*Sampler, error) {
+	var sources []PriceSource
+	for _, exchange := range c.StringSlice(exchangesFlag) {
+		switch exchange {
+		case ExchangeBinance:
+			// reference prices are public, no API key is required
+			client, err := binance.NewBinance("", "", sugar)
+			if err != nil {
+				return nil, err
+			}
+			source, err := NewBinanceSource(client)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		case ExchangeHuobi:
+			client, err := huobi.NewClient("", "", sugar)
+			if err != nil {
+				return nil, err
+			}
+			source, err := NewHuobiSource(client)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		default:
+			return nil, fmt.Errorf("exchange not supported: %s", exchange)
+		}
+	}
+	if len(sources) == 0 {
+		sugar.Info("no CEX reference exchange provided, sampling is disabled")
+		return nil, nil
+	}
+	return NewSampler(sugar, sources, st, blkTimeRsv, c.Duration(maxLagFlag)), nil
+}
+
+// CheckRates queues the rates crawled at given block to be sampled by Run. It never blocks, the rates are
+// dropped if the queue is full.
+func (s *Sampler) CheckRates(block uint64, rates map[string]map[string]common.ReserveRateEntry) error {
+	select {
+	case s.snapshots <- snapshot{block: block, rates: rates}:
+	default:
+		s.sugar.Warnw("sampling queue is full, skipping block",
+			"func", caller.GetCurrentFunctionName(),
+			"block", block)
+	}
+	return nil
+}
+
+// Run samples queued rates until Stop is called.
+func (s *Sampler) Run() {
+	for snap := range s.snapshots {
+		if err := s.sample(snap.block, snap.rates); err != nil {
+			s.sugar.Errorw("failed to sample CEX reference prices",
+				"func", caller.GetCurrentFunctionName(),
+				"block", snap.block,
+				"error", err)
+		}
+	}
+}
+
+// Stop stops Run once queued rates are sampled, CheckRates must not be called afterward.
+func (s *Sampler) Stop() {
+	close(s.snapshots)
+}
+
+// sample samples prices of tokens of the rates crawled at given block and saves comparisons with
+// the rates. Blocks older than max lag are skipped as exchanges only provide current prices.
+func (s *Sampler) sample(block uint64, rates map[string]map[string]common.ReserveRateEntry) error {
+	logger := s.sugar.With("func", caller.GetCurrentFunctionName(), "block", block)
+	timestamp, err := s.blkTimeRsv.Resolve(block)
+	if err != nil {
+		return err
+	}
+	if lag := s.now().Sub(timestamp); lag > s.maxLag {
+		logger.Debugw("block is too old to sample reference prices", "lag", lag)
+		return nil
+	}
+
+	var (
+		refs   []common.CEXReference
+		prices = make(map[string]map[string]float64) // exchange -> token -> mid price, 0 if not available
+	)
+	for reserve, pairRates := range rates {
+		for pair, rate := range pairRates {
+			if !strings.HasPrefix(pair, pairPrefix) || rate.BuyReserveRate <= 0 || rate.SellReserveRate <= 0 {
+				continue
+			}
+			token := strings.TrimPrefix(pair, pairPrefix)
+			for _, source := range s.sources {
+				exchange := source.Exchange()
+				if prices[exchange] == nil {
+					prices[exchange] = make(map[string]float64)
+				}
+				price, ok := prices[exchange][token]
+				if !ok {
+					if price, err = source.MidPrice(token); err != nil {
+						if err != ErrNotListed {
+							logger.Warnw("failed to get mid price", "exchange", exchange, "token", token, "error", err)
+						}
+						price = 0
+					}
+					prices[exchange][token] = price
+				}
+				if price == 0 {
+					continue
+				}
+				refs = append(refs, NewCEXReference(block, timestamp, reserve, pair, exchange, price, rate))
+			}
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Reserve != refs[j].Reserve {
+			return refs[i].Reserve < refs[j].Reserve
+		}
+		if refs[i].Pair != refs[j].Pair {
+			return refs[i].Pair < refs[j].Pair
+		}
+		return refs[i].Exchange < refs[j].Exchange
+	})
+	logger.Debugw("saving CEX references", "references", len(refs))
+	return s.st.SaveCEXReferences(refs)
+}
+
+// NewCEXReference compares the rate of a reserve with the mid price of the token on an exchange.
+func NewCEXReference(block uint64, timestamp time.Time, reserve, pair, exchange string, cexMidPrice float64,
+	rate common.ReserveRateEntry) common.CEXReference {
+	var (
+		buyPrice  = 1 / rate.BuyReserveRate
+		sellPrice = rate.SellReserveRate
+		mid       = (buyPrice + sellPrice) / 2
+	)
+	return common.CEXReference{
+		Timestamp:   timestamp,
+		Block:       block,
+		Reserve:     reserve,
+		Pair:        pair,
+		Exchange:    exchange,
+		CEXMidPrice: cexMidPrice,
+		BuyPrice:    buyPrice,
+		SellPrice:   sellPrice,
+		Spread:      (buyPrice - sellPrice) / mid,
+		Deviation:   (mid - cexMidPrice) / cexMidPrice,
+	}
+}
@@ -0,0 +1,124 @@
+package cexref
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/testutil"
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+const testReserve = "0x63825c174ab367968EC60f061753D3bbD36A0D8F"
+
+type mockSource struct {
+	exchange string
+	prices   map[string]float64
+	calls    int
+}
+
+func (s *mockSource) Exchange() string {
+	return s.exchange
+}
+
+func (s *mockSource) MidPrice(token string) (float64, error) {
+	s.calls++
+	price, ok := s.prices[token]
+	if !ok {
+		return 0, ErrNotListed
+	}
+	if price < 0 {
+		return 0, errors.New("exchange is down")
+	}
+	return price, nil
+}
+
+type mockStorage struct {
+	refs []common.CEXReference
+}
+
+func (s *mockStorage) SaveCEXReferences(refs []common.CEXReference) error {
+	s.refs = append(s.refs, refs...)
+	return nil
+}
+
+func (s *mockStorage) GetCEXReferences(_ ethereum.Address, _, _ string, _, _ time.Time) ([]common.CEXReference, error) {
+	return s.refs, nil
+}
+
+func TestSampler(t *testing.T) {
+	var (
+		now          = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		otherReserve = "0x21433Dec9Cb634A23c6A4BbcCe08c83f5aC2EC18"
+		binanceSrc   = &mockSource{exchange: ExchangeBinance, prices: map[string]float64{"KNC": 0.0102, "ZRX": 0.002}}
+		huobiSrc     = &mockSource{exchange: ExchangeHuobi, prices: map[string]float64{"KNC": -1}}
+		st           = &mockStorage{}
+		rates        = map[string]map[string]common.ReserveRateEntry{
+			testReserve: {
+				"ETH-KNC": {BuyReserveRate: 100, SellReserveRate: 0.0098},
+				"ETH-ZRX": {BuyReserveRate: 0, SellReserveRate: 0},
+				"ETH-DAI": {BuyReserveRate: 200, SellReserveRate: 0.0049},
+			},
+			otherReserve: {
+				"ETH-KNC": {BuyReserveRate: 95, SellReserveRate: 0.01},
+			},
+		}
+	)
+	sampler := NewSampler(testutil.MustNewDevelopmentSugaredLogger(), []PriceSource{binanceSrc, huobiSrc}, st,
+		blockchain.NewMockBlockTimeResolve(now.Add(-time.Minute)), 5*time.Minute)
+	sampler.now = func() time.Time { return now }
+
+	require.NoError(t, sampler.sample(100, rates))
+	require.Len(t, st.refs, 2)
+	// prices are fetched once per token
+	assert.Equal(t, 2, binanceSrc.calls)
+	assert.Equal(t, 2, huobiSrc.calls)
+
+	assert.Equal(t, otherReserve, st.refs[0].Reserve)
+	ref := st.refs[1]
+	assert.Equal(t, testReserve, ref.Reserve)
+	assert.Equal(t, "ETH-KNC", ref.Pair)
+	assert.Equal(t, ExchangeBinance, ref.Exchange)
+	assert.Equal(t, uint64(100), ref.Block)
+	assert.Equal(t, now.Add(-time.Minute), ref.Timestamp)
+	assert.InDelta(t, 0.0102, ref.CEXMidPrice, 1e-12)
+	assert.InDelta(t, 0.01, ref.BuyPrice, 1e-12)
+	assert.InDelta(t, 0.0098, ref.SellPrice, 1e-12)
+	assert.InDelta(t, 0.0002/0.0099, ref.Spread, 1e-9)
+	assert.InDelta(t, (0.0099-0.0102)/0.0102, ref.Deviation, 1e-9)
+
+	// blocks older than max lag are not sampled
+	sampler.now = func() time.Time { return now.Add(time.Hour) }
+	require.NoError(t, sampler.sample(101, rates))
+	assert.Len(t, st.refs, 2)
+}
+
+func TestSamplerQueue(t *testing.T) {
+	var (
+		now   = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		src   = &mockSource{exchange: ExchangeBinance, prices: map[string]float64{"KNC": 0.0102}}
+		st    = &mockStorage{}
+		rates = map[string]map[string]common.ReserveRateEntry{
+			testReserve: {"ETH-KNC": {BuyReserveRate: 100, SellReserveRate: 0.0098}},
+		}
+	)
+	sampler := NewSampler(testutil.MustNewDevelopmentSugaredLogger(), []PriceSource{src}, st,
+		blockchain.NewMockBlockTimeResolve(now), 5*time.Minute)
+	sampler.now = func() time.Time { return now }
+
+	// checking rates does not wait for sampling, blocks are dropped when the queue is full
+	for block := uint64(0); block < queueSize+1; block++ {
+		require.NoError(t, sampler.CheckRates(block, rates))
+	}
+	assert.Len(t, st.refs, 0)
+
+	sampler.Stop()
+	sampler.Run()
+	require.Len(t, st.refs, queueSize)
+	assert.Equal(t, uint64(queueSize-1), st.refs[queueSize-1].Block)
+}
@@ -0,0 +1,123 @@
+package cexref
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/KyberNetwork/reserve-stats/lib/binance"
+	"github.com/KyberNetwork/reserve-stats/lib/huobi"
+)
+
+const (
+	// ExchangeBinance is the name of Binance exchange.
+	ExchangeBinance = "binance"
+	// ExchangeHuobi is the name of Huobi exchange.
+	ExchangeHuobi = "huobi"
+
+	binanceTradingStatus = "TRADING"
+)
+
+// ErrNotListed is returned when the token is not traded against ETH on the exchange.
+var ErrNotListed = errors.New("token is not listed against ETH")
+
+// PriceSource returns the current mid price of tokens on an exchange.
+type PriceSource interface {
+	Exchange() string
+	// MidPrice returns the average of the best bid and ask of the token in ETH.
+	MidPrice(token string) (float64, error)
+}
+
+// BinanceSource is the PriceSource of Binance, it uses markets quoted in ETH.
+type BinanceSource struct {
+	client  *binance.Client
+	symbols map[string]string
+}
+
+// NewBinanceSource returns a new BinanceSource instance, listing ETH markets with exchange info.
+func NewBinanceSource(client *binance.Client) (*BinanceSource, error) {
+	info, err := client.GetExchangeInfo()
+	if err != nil {
+		return nil, err
+	}
+	symbols := make(map[string]string)
+	for _, symbol := range info.Symbols {
+		if symbol.QuoteAsset == "ETH" && symbol.Status == binanceTradingStatus {
+			symbols[symbol.BaseAsset] = symbol.Symbol
+		}
+	}
+	return &BinanceSource{client: client, symbols: symbols}, nil
+}
+
+// Exchange returns the name of the exchange.
+func (s *BinanceSource) Exchange() string {
+	return ExchangeBinance
+}
+
+// MidPrice returns the mid price of the token from the book ticker.
+func (s *BinanceSource) MidPrice(token string) (float64, error) {
+	symbol, ok := s.symbols[token]
+	if !ok {
+		return 0, ErrNotListed
+	}
+	ticker, err := s.client.GetBookTicker(symbol)
+	if err != nil {
+		return 0, err
+	}
+	bid, err := strconv.ParseFloat(ticker.BidPrice, 64)
+	if err != nil {
+		return 0, err
+	}
+	ask, err := strconv.ParseFloat(ticker.AskPrice, 64)
+	if err != nil {
+		return 0, err
+	}
+	return midPrice(bid, ask)
+}
+
+// HuobiSource is the PriceSource of Huobi, it uses markets quoted in ETH.
+type HuobiSource struct {
+	client  *huobi.Client
+	symbols map[string]string
+}
+
+// NewHuobiSource returns a new HuobiSource instance, listing ETH markets with exchange symbols.
+func NewHuobiSource(client *huobi.Client) (*HuobiSource, error) {
+	pairs, err := client.GetSymbolsPair()
+	if err != nil {
+		return nil, err
+	}
+	symbols := make(map[string]string)
+	for _, pair := range pairs {
+		if strings.EqualFold(pair.Quote, "eth") {
+			symbols[strings.ToUpper(pair.Base)] = pair.SymBol
+		}
+	}
+	return &HuobiSource{client: client, symbols: symbols}, nil
+}
+
+// Exchange returns the name of the exchange.
+func (s *HuobiSource) Exchange() string {
+	return ExchangeHuobi
+}
+
+// MidPrice returns the mid price of the token from the merged ticker.
+func (s *HuobiSource) MidPrice(token string) (float64, error) {
+	symbol, ok := s.symbols[token]
+	if !ok {
+		return 0, ErrNotListed
+	}
+	ticker, err := s.client.GetMergedTicker(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return midPrice(ticker.Bid[0], ticker.Ask[0])
+}
+
+func midPrice(bid, ask float64) (float64, error) {
+	if bid <= 0 || ask <= 0 {
+		return 0, fmt.Errorf("invalid book ticker, bid: %f, ask: %f", bid, ask)
+	}
+	return (bid + ask) / 2, nil
+}
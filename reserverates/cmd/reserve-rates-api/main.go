@@ -5,6 +5,7 @@ import (
 	"os"
 
 	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
 	"github.com/KyberNetwork/reserve-stats/lib/httputil"
 	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
 	"github.com/KyberNetwork/reserve-stats/reserverates/common"
@@ -53,7 +54,7 @@ func newServerCli() *cli.App {
 			if err != nil {
 				return err
 			}
-			if rateStorage, err = postgres.NewPostgresStorage(db, sugar, nil); err != nil {
+			if rateStorage, err = postgres.NewPostgresStorage(db, sugar, nil, deployment.MustGetChainFromContext(c).ID); err != nil {
 				return err
 			}
 		} else {
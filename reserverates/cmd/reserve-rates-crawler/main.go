@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/urfave/cli"
@@ -18,7 +20,10 @@ import (
 	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
 	"github.com/KyberNetwork/reserve-stats/lib/contracts"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
 	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
+	"github.com/KyberNetwork/reserve-stats/reserverates/alert"
+	"github.com/KyberNetwork/reserve-stats/reserverates/cexref"
 	"github.com/KyberNetwork/reserve-stats/reserverates/common"
 	"github.com/KyberNetwork/reserve-stats/reserverates/storage"
 	influxRateStorage "github.com/KyberNetwork/reserve-stats/reserverates/storage/influx"
@@ -27,7 +32,8 @@ import (
 )
 
 const (
-	addressesFlag = "addresses"
+	addressesFlag  = "addresses"
+	reserveIDsFlag = "reserve-ids"
 
 	fromBlockFlag = "from-block"
 	toBlockFlag   = "to-block"
@@ -61,6 +67,11 @@ func main() {
 			EnvVar: "RESERVE_ADDRESSES",
 			Usage:  "list of reserve contract addresses. Example: --addresses={\"0x1111\",\"0x222\"}",
 		},
+		cli.StringSliceFlag{
+			Name:   reserveIDsFlag,
+			EnvVar: "RESERVE_IDS",
+			Usage:  "list of Katalyst reserve ids, resolved to reserve addresses through KyberStorage at each block. Example: --reserve-ids={\"0xaa12\",\"0xff34\"}",
+		},
 		cli.StringFlag{
 			Name:   fromBlockFlag,
 			Usage:  "Fetch rates from block",
@@ -109,6 +120,8 @@ func main() {
 		blockchain.NewEthereumNodeFlags(),
 	)
 	app.Flags = append(app.Flags, influxdb.NewCliFlags()...)
+	app.Flags = append(app.Flags, alert.NewCliFlags()...)
+	app.Flags = append(app.Flags, cexref.NewCliFlags()...)
 	app.Flags = append(app.Flags, libapp.NewPostgreSQLFlags(defaultPostgresDB)...)
 
 	if err := app.Run(os.Args); err != nil {
@@ -147,7 +160,10 @@ func run(c *cli.Context) error {
 		return err
 	}
 
-	var rateStorage storage.ReserveRatesStorage
+	var (
+		rateStorage   storage.ReserveRatesStorage
+		ratesCheckers []workers.RatesChecker
+	)
 	if c.String(dbEngineFlag) == "influxdb" {
 		influxClient, err := influxdb.NewClientFromContext(c)
 		if err != nil {
@@ -168,9 +184,29 @@ func run(c *cli.Context) error {
 		if err != nil {
 			return err
 		}
-		if rateStorage, err = postgres.NewPostgresStorage(db, sugar, blockTimeResolver); err != nil {
+		pgStorage, err := postgres.NewPostgresStorage(db, sugar, blockTimeResolver, deployment.MustGetChainFromContext(c).ID)
+		if err != nil {
+			return err
+		}
+		rateStorage = pgStorage
+		monitor, err := alert.NewMonitorFromContext(c, sugar, pgStorage, blockTimeResolver)
+		if err != nil {
+			return err
+		}
+		if monitor != nil {
+			// alerts are sent in background not to hold the workers pool
+			go monitor.Run()
+			ratesCheckers = append(ratesCheckers, monitor)
+		}
+		sampler, err := cexref.NewSamplerFromContext(c, sugar, pgStorage, blockTimeResolver)
+		if err != nil {
 			return err
 		}
+		if sampler != nil {
+			// sampling runs in background not to hold the workers pool
+			go sampler.Run()
+			ratesCheckers = append(ratesCheckers, sampler)
+		}
 	}
 
 	if c.String(fromBlockFlag) == "" {
@@ -196,8 +232,27 @@ func run(c *cli.Context) error {
 	attempts := c.Int(attemptsFlag)
 	delayTime := c.Duration(delayFlag)
 
+	var (
+		reserveIDs   []ethereum.Hash
+		kyberStorage ethereum.Address
+	)
+	for _, reserveID := range c.StringSlice(reserveIDsFlag) {
+		id, err := hexutil.Decode(reserveID)
+		if err != nil || len(id) != ethereum.HashLength {
+			return fmt.Errorf("invalid reserve id input %s", reserveID)
+		}
+		reserveIDs = append(reserveIDs, ethereum.BytesToHash(id))
+	}
+	if len(reserveIDs) != 0 {
+		if c.String(dbEngineFlag) == "influxdb" {
+			return errors.New("reserve ids are not supported by influxdb storage")
+		}
+		kyberStorage = contracts.KyberStorageContractAddress().MustGetOneFromContext(c)
+		sugar.Infow("resolving reserve ids with KyberStorage", "kyber_storage", kyberStorage.Hex(), "reserve_ids", len(reserveIDs))
+	}
+
 	addrs := c.StringSlice(addressesFlag)
-	if len(addrs) == 0 {
+	if len(addrs) == 0 && len(reserveIDs) == 0 {
 		addr := contracts.InternalReserveAddress().MustGetOneFromContext(c)
 		addrs = append(addrs, addr.Hex())
 		sugar.Infow("using internal reserve address as user does not input any", "address", addr.Hex())
@@ -236,7 +291,7 @@ func run(c *cli.Context) error {
 			sugar.Infow("fetching reserve rates up to latest known block number", "to_block", toBlock.String())
 		}
 
-		pool := workers.NewPool(sugar, maxWorkers, rateStorage)
+		pool := workers.NewPool(sugar, maxWorkers, rateStorage, ratesCheckers...)
 		doneCh := make(chan struct{})
 
 		go func(fromBlock, toBlock int64) {
@@ -244,7 +299,7 @@ func run(c *cli.Context) error {
 
 			for block := fromBlock; block < toBlock; block++ {
 				jobOrder++
-				pool.Run(workers.NewFetcherJob(c, jobOrder, uint64(block), ethAddrs, attempts))
+				pool.Run(workers.NewFetcherJob(c, jobOrder, uint64(block), ethAddrs, reserveIDs, kyberStorage, attempts))
 			}
 
 			for pool.GetLastCompleteJobOrder() < jobOrder {
@@ -50,12 +50,22 @@ func fromWeiETH(amount *big.Int) float64 {
 	return result
 }
 
+// RateQuery identifies the rate of a reserve for a pair at a block.
+type RateQuery struct {
+	Reserve string
+	Pair    string
+	Block   uint64
+}
+
 // ReserveRates hold all the pairs's rate for a particular reserve and metadata
 type ReserveRates struct {
-	Timestamp time.Time        `json:"timestamp"`
-	FromBlock uint64           `json:"from_block"`
-	ToBlock   uint64           `json:"to_block"`
-	Rates     ReserveRateEntry `json:"rates"`
+	Timestamp time.Time `json:"timestamp"`
+	// ToTimestamp is the time of the last block the rates are known to be valid at, or of the block
+	// replacing them. It is only available with rates stored as intervals.
+	ToTimestamp time.Time        `json:"-"`
+	FromBlock   uint64           `json:"from_block"`
+	ToBlock     uint64           `json:"to_block"`
+	Rates       ReserveRateEntry `json:"rates"`
 }
 
 // MarshalJSON implements custom JSON marshaler for ReserveRates to format timestamp in unix millis instead of RFC3339.
@@ -87,3 +97,130 @@ func (rr *ReserveRates) UnmarshalJSON(data []byte) error {
 	rr.Rates = decoded.Rates
 	return nil
 }
+
+// RateAlertType is the kind of anomaly a reserve rate alert is raised for.
+type RateAlertType string
+
+const (
+	// RateAlertSanityViolation is raised when a reserve quotes a rate above its sanity rate.
+	RateAlertSanityViolation RateAlertType = "sanity_violation"
+	// RateAlertCrossed is raised when buy and sell rates of a reserve cross, buying then selling
+	// a token back is profitable.
+	RateAlertCrossed RateAlertType = "crossed"
+	// RateAlertJump is raised when a rate changes more than allowed between two crawled blocks.
+	RateAlertJump RateAlertType = "jump"
+)
+
+// RateAlert is an anomaly of the rate quoted by a reserve for a pair at a block.
+type RateAlert struct {
+	ID        uint64        `json:"id" db:"id"`
+	Timestamp time.Time     `json:"timestamp" db:"timestamp"`
+	Block     uint64        `json:"block" db:"block"`
+	Reserve   string        `json:"reserve" db:"reserve"`
+	Pair      string        `json:"pair" db:"pair"`
+	Type      RateAlertType `json:"type" db:"type"`
+	// Side is the side of the rate the alert is raised for, buy or sell, empty if it applies to both.
+	Side      string  `json:"side,omitempty" db:"side"`
+	Value     float64 `json:"value" db:"value"`
+	Threshold float64 `json:"threshold" db:"threshold"`
+	Message   string  `json:"message" db:"message"`
+}
+
+// MarshalJSON implements custom JSON marshaler for RateAlert to format timestamp in unix millis instead of RFC3339.
+func (ra RateAlert) MarshalJSON() ([]byte, error) {
+	type AliasRateAlert RateAlert
+	return json.Marshal(struct {
+		Timestamp uint64 `json:"timestamp"`
+		AliasRateAlert
+	}{
+		AliasRateAlert: (AliasRateAlert)(ra),
+		Timestamp:      timeutil.TimeToTimestampMs(ra.Timestamp),
+	})
+}
+
+// UnmarshalJSON implements custom JSON unmarshaler for RateAlert to format timestamp in unix millis instead of RFC3339.
+func (ra *RateAlert) UnmarshalJSON(data []byte) error {
+	type AliasRateAlert RateAlert
+	decoded := new(struct {
+		Timestamp uint64 `json:"timestamp"`
+		AliasRateAlert
+	})
+
+	if err := json.Unmarshal(data, decoded); err != nil {
+		return err
+	}
+	*ra = RateAlert(decoded.AliasRateAlert)
+	ra.Timestamp = timeutil.TimestampMsToTime(decoded.Timestamp)
+	return nil
+}
+
+// CEXReference compares the rate quoted by a reserve for a pair at a block with the mid price of the
+// token on a centralized exchange sampled when the block is crawled. Prices are in ETH per token.
+type CEXReference struct {
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+	Block     uint64    `json:"block" db:"block"`
+	Reserve   string    `json:"reserve" db:"reserve"`
+	Pair      string    `json:"pair" db:"pair"`
+	Exchange  string    `json:"exchange" db:"exchange"`
+	// CEXMidPrice is the average of the best bid and ask on the exchange.
+	CEXMidPrice float64 `json:"cex_mid_price" db:"cex_mid_price"`
+	// BuyPrice is the price users pay to buy the token from the reserve, 1 / buy rate.
+	BuyPrice float64 `json:"buy_price" db:"buy_price"`
+	// SellPrice is the price users receive selling the token to the reserve, the sell rate.
+	SellPrice float64 `json:"sell_price" db:"sell_price"`
+	// Spread is (BuyPrice - SellPrice) / reserve mid price.
+	Spread float64 `json:"spread" db:"spread"`
+	// Deviation is (reserve mid price - CEXMidPrice) / CEXMidPrice.
+	Deviation float64 `json:"deviation" db:"deviation"`
+}
+
+// MarshalJSON implements custom JSON marshaler for CEXReference to format timestamp in unix millis instead of RFC3339.
+func (cr CEXReference) MarshalJSON() ([]byte, error) {
+	type AliasCEXReference CEXReference
+	return json.Marshal(struct {
+		Timestamp uint64 `json:"timestamp"`
+		AliasCEXReference
+	}{
+		AliasCEXReference: (AliasCEXReference)(cr),
+		Timestamp:         timeutil.TimeToTimestampMs(cr.Timestamp),
+	})
+}
+
+// ReserveAddress is the address a reserve id was resolved to in KyberStorage, valid from FromBlock
+// until before ToBlock.
+type ReserveAddress struct {
+	ReserveID string `json:"reserve_id" db:"reserve_id"`
+	Address   string `json:"address" db:"address"`
+	FromBlock uint64 `json:"from_block" db:"from_block"`
+	ToBlock   uint64 `json:"to_block" db:"to_block"`
+}
+
+// BestRate is the best buy and sell rates of a pair across reserves from a block.
+type BestRate struct {
+	Timestamp time.Time    `json:"timestamp"`
+	Block     uint64       `json:"block"`
+	Buy       BestRateSide `json:"buy"`
+	Sell      BestRateSide `json:"sell"`
+}
+
+// BestRateSide is the best rate of a side of a pair, the reserve offering it and the runner-up.
+type BestRateSide struct {
+	Reserve       string  `json:"reserve"`
+	Rate          float64 `json:"rate"`
+	SecondReserve string  `json:"second_reserve,omitempty"`
+	SecondRate    float64 `json:"second_rate,omitempty"`
+	// Spread is (Rate - SecondRate) / Rate, 0 if no other reserve offers a rate.
+	Spread float64 `json:"spread"`
+}
+
+// MarshalJSON implements custom JSON marshaler for BestRate to format timestamp in unix millis instead of RFC3339.
+func (br BestRate) MarshalJSON() ([]byte, error) {
+	type AliasBestRate BestRate
+	return json.Marshal(struct {
+		Timestamp uint64 `json:"timestamp"`
+		AliasBestRate
+	}{
+		AliasBestRate: (AliasBestRate)(br),
+		Timestamp:     timeutil.TimeToTimestampMs(br.Timestamp),
+	})
+}
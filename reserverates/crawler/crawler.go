@@ -26,33 +26,44 @@ type ReserveRatesCrawler struct {
 	sugar           *zap.SugaredLogger
 	wrapperContract reserveRateGetter
 	rtf             reserveTokenFetcherInterface
+	symbolResolver  blockchain.TokenSymbolResolver
+	reserveResolver ReserveIDResolver
+}
+
+// Option sets the initialization behavior of ReserveRatesCrawler.
+type Option func(rrc *ReserveRatesCrawler)
+
+// WithReserveIDResolver sets the resolver of reserve ids, it is required to crawl rates by reserve ids.
+func WithReserveIDResolver(resolver ReserveIDResolver) Option {
+	return func(rrc *ReserveRatesCrawler) {
+		rrc.reserveResolver = resolver
+	}
 }
 
 // NewReserveRatesCrawler returns an instant of ReserveRatesCrawler.
 func NewReserveRatesCrawler(
 	sugar *zap.SugaredLogger,
 	client bind.ContractBackend,
-	symbolResolver blockchain.TokenSymbolResolver) (*ReserveRatesCrawler, error) {
+	symbolResolver blockchain.TokenSymbolResolver,
+	options ...Option) (*ReserveRatesCrawler, error) {
 	wrpContract, err := contracts.NewVersionedWrapperFallback(sugar, client)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ReserveRatesCrawler{
+	rrc := &ReserveRatesCrawler{
 		sugar:           sugar,
 		wrapperContract: wrpContract,
 		rtf:             blockchain.NewReserveTokenFetcher(sugar, client, symbolResolver),
-	}, nil
+		symbolResolver:  symbolResolver,
+	}
+	for _, option := range options {
+		option(rrc)
+	}
+	return rrc, nil
 }
 
 func (rrc *ReserveRatesCrawler) getEachReserveRate(block uint64, rsvAddr ethereum.Address) (map[string]rsvRateCommon.ReserveRateEntry, error) {
-	var (
-		err           error
-		rates         = make(map[string]rsvRateCommon.ReserveRateEntry)
-		srcAddresses  []ethereum.Address
-		destAddresses []ethereum.Address
-	)
-
 	logger := rrc.sugar.With(
 		"func", caller.GetCurrentFunctionName(),
 		"block", block,
@@ -69,6 +80,22 @@ func (rrc *ReserveRatesCrawler) getEachReserveRate(block uint64, rsvAddr ethereu
 		logger.Errorw("cannot get supported token for reserve", "reserve", rsvAddr.Hex(), "error", err)
 		return nil, errorCannotGetSupportedTokens
 	}
+	return rrc.getTokenRates(block, rsvAddr, tokens)
+}
+
+// getTokenRates returns rates of reserve for given tokens.
+func (rrc *ReserveRatesCrawler) getTokenRates(block uint64, rsvAddr ethereum.Address, tokens []blockchain.TokenInfo) (map[string]rsvRateCommon.ReserveRateEntry, error) {
+	var (
+		rates         = make(map[string]rsvRateCommon.ReserveRateEntry)
+		srcAddresses  []ethereum.Address
+		destAddresses []ethereum.Address
+	)
+
+	logger := rrc.sugar.With(
+		"func", caller.GetCurrentFunctionName(),
+		"block", block,
+		"reserve_address", rsvAddr.Hex(),
+	)
 
 	for _, token := range tokens {
 		srcAddresses = append(srcAddresses, token.Address, blockchain.ETHAddr)
@@ -86,7 +113,7 @@ func (rrc *ReserveRatesCrawler) getEachReserveRate(block uint64, rsvAddr ethereu
 	}
 
 	logger.Debug("reserve rates fetched successfully")
-	return rates, err
+	return rates, nil
 }
 
 //GetReserveRatesWithAddresses fetch rates with a list of input addresses and given block number
@@ -152,3 +179,66 @@ func (rrc *ReserveRatesCrawler) GetReserveRatesWithAddresses(addresses []ethereu
 
 	return result, err
 }
+
+// GetReserveRatesWithIDs fetches rates of Katalyst reserves with given ids at given block. The reserve ids
+// are resolved to their addresses at the block and tokens are listed from KyberStorage. Rates are keyed by
+// reserve address as GetReserveRatesWithAddresses, with the reserve id of each address.
+func (rrc *ReserveRatesCrawler) GetReserveRatesWithIDs(reserveIDs []ethereum.Hash, block uint64) (
+	map[string]map[string]rsvRateCommon.ReserveRateEntry, map[string]ethereum.Hash, error) {
+	var (
+		g         errgroup.Group
+		mu        sync.Mutex
+		result    = make(map[string]map[string]rsvRateCommon.ReserveRateEntry)
+		addresses = make(map[string]ethereum.Hash)
+	)
+	if rrc.reserveResolver == nil {
+		return nil, nil, errors.New("reserve id resolver is not configured")
+	}
+
+	logger := rrc.sugar.With(
+		"func", caller.GetCurrentFunctionName(),
+		"block", block,
+		"reserve_ids", len(reserveIDs),
+	)
+	logger.Debug("fetching rates for all reserve ids")
+	for _, reserveID := range reserveIDs {
+		reserveID := reserveID
+		g.Go(func() error {
+			rsvAddr, err := rrc.reserveResolver.ReserveAddress(reserveID, block)
+			if err != nil {
+				return fmt.Errorf("cannot resolve reserve id %s: %s", reserveID.Hex(), err)
+			}
+			if rsvAddr == (ethereum.Address{}) {
+				logger.Infow("reserve id is not listed, skipping", "reserve_id", reserveID.Hex())
+				return nil
+			}
+			listed, err := rrc.reserveResolver.ListedTokens(reserveID, block)
+			if err != nil {
+				return fmt.Errorf("cannot get listed tokens of reserve id %s: %s", reserveID.Hex(), err)
+			}
+			var tokens []blockchain.TokenInfo
+			for _, token := range listed {
+				symbol, err := rrc.symbolResolver.Symbol(token)
+				if err != nil {
+					return err
+				}
+				tokens = append(tokens, blockchain.TokenInfo{Address: token, Symbol: symbol})
+			}
+			rates, err := rrc.getTokenRates(block, rsvAddr, tokens)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result[rsvAddr.Hex()] = rates
+			addresses[rsvAddr.Hex()] = reserveID
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+	return result, addresses, nil
+}
@@ -1,11 +1,13 @@
 package crawler
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
 	ethereum "github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
@@ -38,16 +40,51 @@ func (mst *mockSupportedTokens) Tokens(ethereum.Address, uint64) ([]blockchain.T
 	}, nil
 }
 
-func newTestCrawler(sugar *zap.SugaredLogger) (*ReserveRatesCrawler, error) {
+type mockSymbolResolver struct{}
+
+func (msr *mockSymbolResolver) Symbol(token ethereum.Address) (string, error) {
+	switch token {
+	case knc:
+		return "KNC", nil
+	case zrx:
+		return "ZRX", nil
+	}
+	return "", fmt.Errorf("unknown token %s", token.Hex())
+}
+
+// mockReserveResolver resolves reserve ids to the addresses they are listed with from given block.
+type mockReserveResolver struct {
+	upgradeBlock uint64
+	addresses    map[ethereum.Hash][2]ethereum.Address
+}
+
+func (mrr *mockReserveResolver) ReserveAddress(reserveID ethereum.Hash, block uint64) (ethereum.Address, error) {
+	addresses := mrr.addresses[reserveID]
+	if block < mrr.upgradeBlock {
+		return addresses[0], nil
+	}
+	return addresses[1], nil
+}
+
+func (mrr *mockReserveResolver) ListedTokens(ethereum.Hash, uint64) ([]ethereum.Address, error) {
+	return []ethereum.Address{knc}, nil
+}
+
+func newTestCrawler(sugar *zap.SugaredLogger, options ...Option) (*ReserveRatesCrawler, error) {
 	var (
 		wrpContract = contracts.MockVersionedWrapper{}
 	)
 
-	return &ReserveRatesCrawler{
+	rrc := &ReserveRatesCrawler{
 		wrapperContract: &wrpContract,
 		rtf:             &mockSupportedTokens{},
+		symbolResolver:  &mockSymbolResolver{},
 		sugar:           sugar,
-	}, nil
+	}
+	for _, option := range options {
+		option(rrc)
+	}
+	return rrc, nil
 }
 
 // TestGetReserveRate query the mock blockchain for reserve rate result
@@ -87,3 +124,50 @@ func TestGetReserveRate(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestGetReserveRatesWithIDs(t *testing.T) {
+	var (
+		reserveID   = ethereum.HexToHash("0xaa4b4c4d4e000000000000000000000000000000000000000000000000000000")
+		unlistedID  = ethereum.HexToHash("0xbb4b4c4d4e000000000000000000000000000000000000000000000000000000")
+		oldAddr     = ethereum.HexToAddress(testRsvAddress)
+		newAddr     = ethereum.HexToAddress("0x21433Dec9Cb634A23c6A4BbcCe08c83f5aC2EC18")
+		reserveIDs  = []ethereum.Hash{reserveID, unlistedID}
+		upgradeAt   = uint64(100)
+		expectedKNC = rsvRateCommon.ReserveRateEntry{
+			BuyReserveRate:  1.0,
+			SellReserveRate: 2.0,
+			BuySanityRate:   3.0,
+			SellSanityRate:  4.0,
+		}
+	)
+	sugar := testutil.MustNewDevelopmentSugaredLogger()
+
+	crawler, err := newTestCrawler(sugar)
+	require.NoError(t, err)
+	_, _, err = crawler.GetReserveRatesWithIDs(reserveIDs, upgradeAt)
+	require.Error(t, err, "reserve id resolver is required")
+
+	crawler, err = newTestCrawler(sugar, WithReserveIDResolver(&mockReserveResolver{
+		upgradeBlock: upgradeAt,
+		addresses: map[ethereum.Hash][2]ethereum.Address{
+			reserveID: {oldAddr, newAddr},
+		},
+	}))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		block   uint64
+		address ethereum.Address
+	}{
+		{upgradeAt - 1, oldAddr},
+		{upgradeAt, newAddr},
+	} {
+		rates, addresses, err := crawler.GetReserveRatesWithIDs(reserveIDs, tc.block)
+		require.NoError(t, err)
+		// unlisted reserve ids resolve to zero address and are skipped
+		assert.Equal(t, map[string]ethereum.Hash{tc.address.Hex(): reserveID}, addresses)
+		assert.Equal(t, map[string]map[string]rsvRateCommon.ReserveRateEntry{
+			tc.address.Hex(): {"ETH-KNC": expectedKNC},
+		}, rates)
+	}
+}
@@ -0,0 +1,65 @@
+package crawler
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethereum "github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/contracts"
+)
+
+// ReserveIDResolver resolves Katalyst reserve ids at a block.
+type ReserveIDResolver interface {
+	// ReserveAddress returns the current address of the reserve, zero address if the reserve is not listed.
+	ReserveAddress(reserveID ethereum.Hash, block uint64) (ethereum.Address, error)
+	// ListedTokens returns tokens listed for the reserve in any direction.
+	ListedTokens(reserveID ethereum.Hash, block uint64) ([]ethereum.Address, error)
+}
+
+// KyberStorageReserveResolver resolves reserve ids with the KyberStorage contract.
+type KyberStorageReserveResolver struct {
+	contract *contracts.KyberStorage
+}
+
+// NewKyberStorageReserveResolver returns a new KyberStorageReserveResolver instance.
+func NewKyberStorageReserveResolver(kyberStorage ethereum.Address, client bind.ContractBackend) (*KyberStorageReserveResolver, error) {
+	contract, err := contracts.NewKyberStorage(kyberStorage, client)
+	if err != nil {
+		return nil, err
+	}
+	return &KyberStorageReserveResolver{contract: contract}, nil
+}
+
+// ReserveAddress returns the address of the reserve at given block, KyberStorage keeps the current
+// address first in the addresses of a reserve id and resets it to zero when the reserve is removed.
+func (r *KyberStorageReserveResolver) ReserveAddress(reserveID ethereum.Hash, block uint64) (ethereum.Address, error) {
+	callOpts := &bind.CallOpts{BlockNumber: big.NewInt(0).SetUint64(block)}
+	details, err := r.contract.GetReserveDetailsById(callOpts, reserveID)
+	if err != nil {
+		return ethereum.Address{}, err
+	}
+	return details.ReserveAddress, nil
+}
+
+// ListedTokens returns tokens listed for the reserve at given block.
+func (r *KyberStorageReserveResolver) ListedTokens(reserveID ethereum.Hash, block uint64) ([]ethereum.Address, error) {
+	var (
+		callOpts = &bind.CallOpts{BlockNumber: big.NewInt(0).SetUint64(block)}
+		tokens   []ethereum.Address
+		seen     = make(map[ethereum.Address]struct{})
+	)
+	listed, err := r.contract.GetListedTokensByReserveId(callOpts, reserveID)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range append(listed.SrcTokens, listed.DestTokens...) {
+		if _, ok := seen[token]; ok || token == blockchain.ETHAddr {
+			continue
+		}
+		seen[token] = struct{}{}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
@@ -26,6 +26,7 @@ const (
 	dbName         = "test_reserve_rate"
 	testFromBlock  = 123
 	testToBlock    = 124
+	testReserveID  = "0xaa4b4c4d4e000000000000000000000000000000000000000000000000000000"
 )
 
 var (
@@ -90,6 +91,72 @@ func TestHTTPRateServer(t *testing.T) {
 			Method:   http.MethodGet,
 			Assert:   expectCorrectRate,
 		},
+		{
+			Msg:      "rate at a point requires block or timestamp",
+			Endpoint: fmt.Sprintf("%s/reserve-rate?reserve=%s&pair=ETH-KNC", host, testRsvAddress),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusBadRequest),
+		},
+		{
+			Msg:      "rate at a point is not supported by influxdb storage",
+			Endpoint: fmt.Sprintf("%s/reserve-rate?reserve=%s&pair=ETH-KNC&block=%d", host, testRsvAddress, testFromBlock),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusNotImplemented),
+		},
+		{
+			Msg:      "invalid rate alert type",
+			Endpoint: fmt.Sprintf("%s/reserve-rate-alerts?from=%d&to=%d&type=invalid", host, testTS, testTS),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusBadRequest),
+		},
+		{
+			Msg:      "rate alerts are not supported by influxdb storage",
+			Endpoint: fmt.Sprintf("%s/reserve-rate-alerts?from=%d&to=%d&type=crossed", host, testTS, testTS),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusNotImplemented),
+		},
+		{
+			Msg:      "CEX references require pair",
+			Endpoint: fmt.Sprintf("%s/cex-references?from=%d&to=%d&reserve=%s", host, testTS, testTS, testRsvAddress),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusBadRequest),
+		},
+		{
+			Msg:      "CEX references are not supported by influxdb storage",
+			Endpoint: fmt.Sprintf("%s/cex-references?from=%d&to=%d&reserve=%s&pair=ETH-KNC", host, testTS, testTS, testRsvAddress),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusNotImplemented),
+		},
+		{
+			Msg:      "invalid reserve id",
+			Endpoint: fmt.Sprintf("%s/%s?from=%d&to=%d&reserve_id=%s", host, requestEndpoint, testTS, testTS, testRsvAddress),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusBadRequest),
+		},
+		{
+			Msg:      "reserve ids are not supported by influxdb storage",
+			Endpoint: fmt.Sprintf("%s/%s?from=%d&to=%d&reserve_id=%s", host, requestEndpoint, testTS, testTS, testReserveID),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusNotImplemented),
+		},
+		{
+			Msg:      "reserve addresses require reserve id",
+			Endpoint: fmt.Sprintf("%s/reserve-addresses", host),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusBadRequest),
+		},
+		{
+			Msg:      "best rates require token",
+			Endpoint: fmt.Sprintf("%s/best-rates?from=%d&to=%d", host, testTS, testTS),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusBadRequest),
+		},
+		{
+			Msg:      "best rates are not supported by influxdb storage",
+			Endpoint: fmt.Sprintf("%s/best-rates?from=%d&to=%d&token=KNC", host, testTS, testTS),
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusNotImplemented),
+		},
 	}
 	for _, tc := range tests {
 		tc := tc
@@ -1,7 +1,9 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	ethereum "github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
@@ -10,10 +12,21 @@ import (
 	"github.com/KyberNetwork/reserve-stats/lib/caller"
 	"github.com/KyberNetwork/reserve-stats/lib/httputil"
 	_ "github.com/KyberNetwork/reserve-stats/lib/httputil/validators" // import custom validator functions
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/reserverates/bestrates"
 	"github.com/KyberNetwork/reserve-stats/reserverates/common"
 	"github.com/KyberNetwork/reserve-stats/reserverates/storage"
 )
 
+const (
+	// maxRateAlertsTimeFrame is the maximum time range rate alerts can be listed for.
+	maxRateAlertsTimeFrame = 7 * 24 * time.Hour
+	// maxCEXReferencesTimeFrame is the maximum time range CEX references can be listed for.
+	maxCEXReferencesTimeFrame = 7 * 24 * time.Hour
+	// maxBestRatesTimeFrame is the maximum time range best rates can be computed for.
+	maxBestRatesTimeFrame = 7 * 24 * time.Hour
+)
+
 // Server is the engine to serve reserve-rate API query
 type Server struct {
 	r     *gin.Engine
@@ -25,13 +38,16 @@ type Server struct {
 type reserveRatesQuery struct {
 	httputil.TimeRangeQuery
 	ReserveAddrs []string `form:"reserve" binding:"dive,isAddress"`
+	ReserveIDs   []string `form:"reserve_id" binding:"dive,isHash"`
 }
 
 func (sv *Server) reserveRates(c *gin.Context) {
 	var (
-		query    reserveRatesQuery
-		logger   = sv.sugar.With("func", caller.GetCurrentFunctionName())
-		rsvAddrs []ethereum.Address
+		query      reserveRatesQuery
+		logger     = sv.sugar.With("func", caller.GetCurrentFunctionName())
+		rsvAddrs   []ethereum.Address
+		reserveIDs []ethereum.Hash
+		result     = make(map[string]map[string][]common.ReserveRates)
 	)
 
 	if err := c.ShouldBindQuery(&query); err != nil {
@@ -56,9 +72,346 @@ func (sv *Server) reserveRates(c *gin.Context) {
 	for _, rsvAddr := range query.ReserveAddrs {
 		rsvAddrs = append(rsvAddrs, ethereum.HexToAddress(rsvAddr))
 	}
-	result, err := sv.db.GetRatesByTimePoint(rsvAddrs, query.From, query.To)
+	if len(rsvAddrs) != 0 || len(query.ReserveIDs) == 0 {
+		rates, err := sv.db.GetRatesByTimePoint(rsvAddrs, query.From, query.To)
+		if err != nil {
+			sv.sugar.Errorw(err.Error(), "query", query)
+			c.JSON(
+				http.StatusInternalServerError,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+		for reserve, pairRates := range rates {
+			result[reserve] = pairRates
+		}
+	}
+
+	if len(query.ReserveIDs) != 0 {
+		idStorage, ok := sv.db.(storage.ReserveIDStorage)
+		if !ok {
+			c.JSON(
+				http.StatusNotImplemented,
+				gin.H{"error": "reserve ids are not supported by the storage"},
+			)
+			return
+		}
+		for _, reserveID := range query.ReserveIDs {
+			reserveIDs = append(reserveIDs, ethereum.HexToHash(reserveID))
+		}
+		// rates of reserve ids are keyed by reserve id
+		rates, err := idStorage.GetRatesByReserveIDs(reserveIDs, query.From, query.To)
+		if err != nil {
+			sv.sugar.Errorw(err.Error(), "query", query)
+			c.JSON(
+				http.StatusInternalServerError,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+		for reserveID, pairRates := range rates {
+			result[reserveID] = pairRates
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+type reserveAddressesQuery struct {
+	ReserveID string `form:"reserve_id" binding:"required,isHash"`
+}
+
+// reserveAddresses returns the history of addresses of a Katalyst reserve id.
+func (sv *Server) reserveAddresses(c *gin.Context) {
+	var (
+		query  reserveAddressesQuery
+		logger = sv.sugar.With("func", caller.GetCurrentFunctionName())
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	idStorage, ok := sv.db.(storage.ReserveIDStorage)
+	if !ok {
+		c.JSON(
+			http.StatusNotImplemented,
+			gin.H{"error": "reserve ids are not supported by the storage"},
+		)
+		return
+	}
+
+	reserveID := ethereum.HexToHash(query.ReserveID)
+	logger = logger.With("reserve_id", reserveID.Hex())
+	logger.Debug("querying reserve addresses from database")
+	addresses, err := idStorage.GetReserveAddresses(reserveID)
+	if err != nil {
+		logger.Errorw(err.Error(), "query", query)
+		c.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	if addresses == nil {
+		addresses = []common.ReserveAddress{}
+	}
+
+	c.JSON(http.StatusOK, addresses)
+}
+
+type reserveRateQuery struct {
+	Reserve   string `form:"reserve" binding:"required,isAddress"`
+	Pair      string `form:"pair" binding:"required"`
+	Block     uint64 `form:"block"`
+	Timestamp uint64 `form:"timestamp"`
+}
+
+// reserveRate returns the rate of a reserve for a pair at a block or at a time.
+func (sv *Server) reserveRate(c *gin.Context) {
+	var (
+		query  reserveRateQuery
+		logger = sv.sugar.With("func", caller.GetCurrentFunctionName())
+		result *common.ReserveRates
+		err    error
+	)
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	if (query.Block == 0) == (query.Timestamp == 0) {
+		c.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "either block or timestamp is required"},
+		)
+		return
+	}
+
+	pointStorage, ok := sv.db.(storage.RatePointStorage)
+	if !ok {
+		c.JSON(
+			http.StatusNotImplemented,
+			gin.H{"error": "rate at a point is not supported by the storage"},
+		)
+		return
+	}
+
+	reserve := ethereum.HexToAddress(query.Reserve)
+	logger = logger.With("reserve", reserve.Hex(), "pair", query.Pair, "block", query.Block, "timestamp", query.Timestamp)
+	logger.Debug("querying reserve rate from database")
+	if query.Block != 0 {
+		result, err = pointStorage.GetRateAtBlock(reserve, query.Pair, query.Block)
+	} else {
+		result, err = pointStorage.GetRateAtTime(reserve, query.Pair, timeutil.TimestampMsToTime(query.Timestamp))
+	}
+	if err != nil {
+		logger.Errorw(err.Error(), "query", query)
+		c.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	if result == nil {
+		c.JSON(
+			http.StatusNotFound,
+			gin.H{"error": "rate not found"},
+		)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+type rateAlertsQuery struct {
+	httputil.TimeRangeQuery
+	ReserveAddrs []string `form:"reserve" binding:"dive,isAddress"`
+	Type         string   `form:"type"`
+}
+
+// rateAlerts returns alerts raised for reserve rates in a time range.
+func (sv *Server) rateAlerts(c *gin.Context) {
+	var (
+		query    rateAlertsQuery
+		logger   = sv.sugar.With("func", caller.GetCurrentFunctionName())
+		rsvAddrs []ethereum.Address
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	from, to, err := query.Validate(httputil.TimeRangeQueryWithMaxTimeFrame(maxRateAlertsTimeFrame))
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	alertType := common.RateAlertType(query.Type)
+	switch alertType {
+	case "", common.RateAlertSanityViolation, common.RateAlertCrossed, common.RateAlertJump:
+	default:
+		c.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": fmt.Sprintf("invalid alert type: %s", query.Type)},
+		)
+		return
+	}
+
+	alertStorage, ok := sv.db.(storage.RateAlertStorage)
+	if !ok {
+		c.JSON(
+			http.StatusNotImplemented,
+			gin.H{"error": "rate alerts are not supported by the storage"},
+		)
+		return
+	}
+
+	for _, rsvAddr := range query.ReserveAddrs {
+		rsvAddrs = append(rsvAddrs, ethereum.HexToAddress(rsvAddr))
+	}
+	logger = logger.With("from", from, "to", to, "type", alertType)
+	logger.Debug("querying rate alerts from database")
+	alerts, err := alertStorage.GetRateAlerts(from, to, rsvAddrs, alertType)
+	if err != nil {
+		logger.Errorw(err.Error(), "query", query)
+		c.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	if alerts == nil {
+		alerts = []common.RateAlert{}
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+type cexReferencesQuery struct {
+	httputil.TimeRangeQuery
+	Reserve  string `form:"reserve" binding:"required,isAddress"`
+	Pair     string `form:"pair" binding:"required"`
+	Exchange string `form:"exchange"`
+}
+
+// cexReferences returns the time series of comparisons of the rates of a reserve for a pair with
+// centralized exchanges prices.
+func (sv *Server) cexReferences(c *gin.Context) {
+	var (
+		query  cexReferencesQuery
+		logger = sv.sugar.With("func", caller.GetCurrentFunctionName())
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	from, to, err := query.Validate(httputil.TimeRangeQueryWithMaxTimeFrame(maxCEXReferencesTimeFrame))
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	refStorage, ok := sv.db.(storage.CEXReferenceStorage)
+	if !ok {
+		c.JSON(
+			http.StatusNotImplemented,
+			gin.H{"error": "CEX references are not supported by the storage"},
+		)
+		return
+	}
+
+	reserve := ethereum.HexToAddress(query.Reserve)
+	logger = logger.With("reserve", reserve.Hex(), "pair", query.Pair, "exchange", query.Exchange, "from", from, "to", to)
+	logger.Debug("querying CEX references from database")
+	refs, err := refStorage.GetCEXReferences(reserve, query.Pair, query.Exchange, from, to)
+	if err != nil {
+		logger.Errorw(err.Error(), "query", query)
+		c.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	if refs == nil {
+		refs = []common.CEXReference{}
+	}
+
+	c.JSON(http.StatusOK, refs)
+}
+
+type bestRatesQuery struct {
+	httputil.TimeRangeQuery
+	Token string `form:"token" binding:"required"`
+}
+
+// bestRates returns the best buy and sell rates of a token across all reserves in a time range.
+func (sv *Server) bestRates(c *gin.Context) {
+	var (
+		query  bestRatesQuery
+		logger = sv.sugar.With("func", caller.GetCurrentFunctionName())
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	from, to, err := query.Validate(httputil.TimeRangeQueryWithMaxTimeFrame(maxBestRatesTimeFrame))
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	pairStorage, ok := sv.db.(storage.PairRatesStorage)
+	if !ok {
+		c.JSON(
+			http.StatusNotImplemented,
+			gin.H{"error": "best rates are not supported by the storage"},
+		)
+		return
+	}
+
+	pair := fmt.Sprintf("ETH-%s", query.Token)
+	logger = logger.With("pair", pair, "from", query.From, "to", query.To)
+	logger.Debug("querying rates of pair from database")
+	rates, err := pairStorage.GetRatesByPair(pair, query.From, query.To)
 	if err != nil {
-		sv.sugar.Errorw(err.Error(), "query", query)
+		logger.Errorw(err.Error(), "query", query)
 		c.JSON(
 			http.StatusInternalServerError,
 			gin.H{"error": err.Error()},
@@ -66,8 +419,9 @@ func (sv *Server) reserveRates(c *gin.Context) {
 		return
 	}
 
+	result := bestrates.Compute(rates, from, to)
 	if result == nil {
-		result = make(map[string]map[string][]common.ReserveRates)
+		result = []common.BestRate{}
 	}
 
 	c.JSON(http.StatusOK, result)
@@ -75,6 +429,11 @@ func (sv *Server) reserveRates(c *gin.Context) {
 
 func (sv *Server) register() {
 	sv.r.GET("/reserve-rates", sv.reserveRates)
+	sv.r.GET("/reserve-rate", sv.reserveRate)
+	sv.r.GET("/reserve-rate-alerts", sv.rateAlerts)
+	sv.r.GET("/cex-references", sv.cexReferences)
+	sv.r.GET("/reserve-addresses", sv.reserveAddresses)
+	sv.r.GET("/best-rates", sv.bestRates)
 }
 
 // Run starts HTTP server on preconfigure-host. Return error if occurs
@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"time"
+
 	"github.com/KyberNetwork/reserve-stats/reserverates/common"
 	ethereum "github.com/ethereum/go-ethereum/common"
 )
@@ -11,3 +13,35 @@ type ReserveRatesStorage interface {
 	GetRatesByTimePoint(addrs []ethereum.Address, fromTime, toTime uint64) (map[string]map[string][]common.ReserveRates, error)
 	LastBlock() (int64, error)
 }
+
+// RatePointStorage looks up the rate of a reserve at a point in time, it is implemented by storages
+// keeping rates as validity intervals.
+type RatePointStorage interface {
+	GetRateAtBlock(reserve ethereum.Address, pair string, block uint64) (*common.ReserveRates, error)
+	GetRateAtTime(reserve ethereum.Address, pair string, t time.Time) (*common.ReserveRates, error)
+}
+
+// RateAlertStorage persists alerts raised for reserve rates.
+type RateAlertStorage interface {
+	SaveRateAlerts(alerts []common.RateAlert) error
+	GetRateAlerts(from, to time.Time, reserves []ethereum.Address, alertType common.RateAlertType) ([]common.RateAlert, error)
+}
+
+// CEXReferenceStorage persists comparisons of reserve rates with centralized exchanges prices.
+type CEXReferenceStorage interface {
+	SaveCEXReferences(refs []common.CEXReference) error
+	GetCEXReferences(reserve ethereum.Address, pair, exchange string, from, to time.Time) ([]common.CEXReference, error)
+}
+
+// ReserveIDStorage persists the addresses Katalyst reserve ids are resolved to, so rates of a reserve
+// can be queried by its id across upgrades.
+type ReserveIDStorage interface {
+	UpdateReserveAddresses(block uint64, addresses map[string]ethereum.Hash) error
+	GetReserveAddresses(reserveID ethereum.Hash) ([]common.ReserveAddress, error)
+	GetRatesByReserveIDs(ids []ethereum.Hash, fromTime, toTime uint64) (map[string]map[string][]common.ReserveRates, error)
+}
+
+// PairRatesStorage returns rates of a pair of all reserves.
+type PairRatesStorage interface {
+	GetRatesByPair(pair string, fromTime, toTime uint64) (map[string][]common.ReserveRates, error)
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+const alertsSchema = `
+	CREATE TABLE IF NOT EXISTS "reserve_rate_alerts" (
+		id SERIAL PRIMARY KEY,
+		chain_id INTEGER NOT NULL,
+		block INTEGER NOT NULL,
+		timestamp TIMESTAMP NOT NULL,
+		reserve TEXT NOT NULL,
+		pair TEXT NOT NULL,
+		type TEXT NOT NULL,
+		side TEXT NOT NULL,
+		value FLOAT NOT NULL,
+		threshold FLOAT NOT NULL,
+		message TEXT NOT NULL
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS "reserve_rate_alerts_uk" ON "reserve_rate_alerts"(chain_id, block, reserve, pair, type, side);
+	CREATE INDEX IF NOT EXISTS "reserve_rate_alerts_timestamp_idx" ON "reserve_rate_alerts"(chain_id, timestamp);
+	`
+
+// SaveRateAlerts persists rate alerts, alerts already raised for the same block are ignored.
+func (s *Storage) SaveRateAlerts(alerts []common.RateAlert) error {
+	var (
+		logger = s.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"alerts", len(alerts),
+		)
+		blocks                              []uint64
+		timestamps                          []time.Time
+		reserves, pairs, types, sides, msgs []string
+		values, thresholds                  []float64
+	)
+	if len(alerts) == 0 {
+		return nil
+	}
+	for _, alert := range alerts {
+		blocks = append(blocks, alert.Block)
+		timestamps = append(timestamps, alert.Timestamp.UTC())
+		reserves = append(reserves, alert.Reserve)
+		pairs = append(pairs, alert.Pair)
+		types = append(types, string(alert.Type))
+		sides = append(sides, alert.Side)
+		values = append(values, alert.Value)
+		thresholds = append(thresholds, alert.Threshold)
+		msgs = append(msgs, alert.Message)
+	}
+	query := `INSERT INTO reserve_rate_alerts
+	(chain_id, block, timestamp, reserve, pair, type, side, value, threshold, message)
+	VALUES(
+		$1,
+		UNNEST($2::INTEGER[]),
+		UNNEST($3::TIMESTAMP[]),
+		UNNEST($4::TEXT[]),
+		UNNEST($5::TEXT[]),
+		UNNEST($6::TEXT[]),
+		UNNEST($7::TEXT[]),
+		UNNEST($8::FLOAT[]),
+		UNNEST($9::FLOAT[]),
+		UNNEST($10::TEXT[])
+	) ON CONFLICT (chain_id, block, reserve, pair, type, side) DO NOTHING;`
+	logger.Debugw("save rate alerts", "query", query)
+	if _, err := s.db.Exec(query, s.chainID, pq.Array(blocks), pq.Array(timestamps), pq.StringArray(reserves),
+		pq.StringArray(pairs), pq.StringArray(types), pq.StringArray(sides), pq.Array(values), pq.Array(thresholds),
+		pq.StringArray(msgs)); err != nil {
+		logger.Errorw("failed to save rate alerts", "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetRateAlerts returns rate alerts raised in time range [from, to], optionally filtered by reserves and type.
+func (s *Storage) GetRateAlerts(from, to time.Time, reserves []ethereum.Address, alertType common.RateAlertType) ([]common.RateAlert, error) {
+	var (
+		logger = s.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"from", from,
+			"to", to,
+			"type", alertType,
+		)
+		reserveAddrs []string
+		alerts       []common.RateAlert
+	)
+	for _, reserve := range reserves {
+		reserveAddrs = append(reserveAddrs, reserve.Hex())
+	}
+	query := `SELECT id, timestamp, block, reserve, pair, type, side, value, threshold, message
+	FROM reserve_rate_alerts
+	WHERE chain_id = $1 AND timestamp >= $2 AND timestamp <= $3
+	AND (CARDINALITY($4::TEXT[]) = 0 OR reserve = ANY($4::TEXT[]))
+	AND ($5::TEXT = '' OR type = $5::TEXT)
+	ORDER BY timestamp, id`
+	logger.Debugw("get rate alerts", "query", query)
+	if err := s.db.Select(&alerts, query, s.chainID, from.UTC(), to.UTC(), pq.StringArray(reserveAddrs), string(alertType)); err != nil {
+		logger.Errorw("failed to get rate alerts", "error", err)
+		return nil, err
+	}
+	return alerts, nil
+}
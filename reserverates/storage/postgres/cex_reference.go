@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+const cexReferencesSchema = `
+	CREATE TABLE IF NOT EXISTS "reserve_rate_cex_references" (
+		id SERIAL PRIMARY KEY,
+		chain_id INTEGER NOT NULL,
+		block INTEGER NOT NULL,
+		timestamp TIMESTAMP NOT NULL,
+		reserve TEXT NOT NULL,
+		pair TEXT NOT NULL,
+		exchange TEXT NOT NULL,
+		cex_mid_price FLOAT NOT NULL,
+		buy_price FLOAT NOT NULL,
+		sell_price FLOAT NOT NULL,
+		spread FLOAT NOT NULL,
+		deviation FLOAT NOT NULL
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS "reserve_rate_cex_references_uk"
+		ON "reserve_rate_cex_references"(chain_id, reserve, pair, exchange, block);
+	`
+
+// SaveCEXReferences persists comparisons of reserve rates with exchanges prices, a comparison already
+// saved for the same block is replaced.
+func (s *Storage) SaveCEXReferences(refs []common.CEXReference) error {
+	var (
+		logger = s.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"references", len(refs),
+		)
+		blocks                                          []uint64
+		timestamps                                      []time.Time
+		reserves, pairs, exchanges                      []string
+		midPrices, buyPrices, sellPrices, spreads, devs []float64
+	)
+	if len(refs) == 0 {
+		return nil
+	}
+	for _, ref := range refs {
+		blocks = append(blocks, ref.Block)
+		timestamps = append(timestamps, ref.Timestamp.UTC())
+		reserves = append(reserves, ref.Reserve)
+		pairs = append(pairs, ref.Pair)
+		exchanges = append(exchanges, ref.Exchange)
+		midPrices = append(midPrices, ref.CEXMidPrice)
+		buyPrices = append(buyPrices, ref.BuyPrice)
+		sellPrices = append(sellPrices, ref.SellPrice)
+		spreads = append(spreads, ref.Spread)
+		devs = append(devs, ref.Deviation)
+	}
+	query := `INSERT INTO reserve_rate_cex_references
+	(chain_id, block, timestamp, reserve, pair, exchange, cex_mid_price, buy_price, sell_price, spread, deviation)
+	VALUES(
+		$1,
+		UNNEST($2::INTEGER[]),
+		UNNEST($3::TIMESTAMP[]),
+		UNNEST($4::TEXT[]),
+		UNNEST($5::TEXT[]),
+		UNNEST($6::TEXT[]),
+		UNNEST($7::FLOAT[]),
+		UNNEST($8::FLOAT[]),
+		UNNEST($9::FLOAT[]),
+		UNNEST($10::FLOAT[]),
+		UNNEST($11::FLOAT[])
+	) ON CONFLICT (chain_id, reserve, pair, exchange, block) DO UPDATE SET timestamp = EXCLUDED.timestamp,
+	cex_mid_price = EXCLUDED.cex_mid_price, buy_price = EXCLUDED.buy_price, sell_price = EXCLUDED.sell_price,
+	spread = EXCLUDED.spread, deviation = EXCLUDED.deviation;`
+	logger.Debugw("save CEX references", "query", query)
+	if _, err := s.db.Exec(query, s.chainID, pq.Array(blocks), pq.Array(timestamps), pq.StringArray(reserves),
+		pq.StringArray(pairs), pq.StringArray(exchanges), pq.Array(midPrices), pq.Array(buyPrices),
+		pq.Array(sellPrices), pq.Array(spreads), pq.Array(devs)); err != nil {
+		logger.Errorw("failed to save CEX references", "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetCEXReferences returns the time series of comparisons of the rates of reserve for pair with exchanges
+// prices in time range [from, to], optionally filtered by exchange.
+func (s *Storage) GetCEXReferences(reserve ethereum.Address, pair, exchange string, from, to time.Time) ([]common.CEXReference, error) {
+	var (
+		logger = s.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"reserve", reserve.Hex(),
+			"pair", pair,
+			"exchange", exchange,
+			"from", from,
+			"to", to,
+		)
+		refs []common.CEXReference
+	)
+	query := `SELECT timestamp, block, reserve, pair, exchange, cex_mid_price, buy_price, sell_price, spread, deviation
+	FROM reserve_rate_cex_references
+	WHERE chain_id = $1 AND reserve = $2 AND pair = $3 AND ($4::TEXT = '' OR exchange = $4::TEXT)
+	AND timestamp >= $5 AND timestamp <= $6
+	ORDER BY block, exchange`
+	logger.Debugw("get CEX references", "query", query)
+	if err := s.db.Select(&refs, query, s.chainID, reserve.Hex(), pair, exchange, from.UTC(), to.UTC()); err != nil {
+		logger.Errorw("failed to get CEX references", "error", err)
+		return nil, err
+	}
+	return refs, nil
+}
@@ -30,6 +30,21 @@ const (
 		timestamp TIMESTAMP,
 		PRIMARY KEY (reserve, pair, from_block)
 	);
+
+	ALTER TABLE "reserve_rates" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+	ALTER TABLE "reserve_rates" DROP CONSTRAINT IF EXISTS reserve_rates_pkey;
+	CREATE UNIQUE INDEX IF NOT EXISTS "reserve_rates_chain_pk" ON "reserve_rates"(chain_id, reserve, pair, from_block);
+
+	-- to_timestamp is the time of the last block the rate is known to be valid at, or of the block replacing it
+	ALTER TABLE "reserve_rates" ADD COLUMN IF NOT EXISTS to_timestamp TIMESTAMP;
+	WITH next_rates AS (
+		SELECT id, LEAD(timestamp) OVER (PARTITION BY chain_id, reserve, pair ORDER BY from_block) AS next_timestamp
+		FROM reserve_rates
+	)
+	UPDATE reserve_rates SET to_timestamp = COALESCE(next_rates.next_timestamp, reserve_rates.timestamp)
+	FROM next_rates
+	WHERE reserve_rates.id = next_rates.id AND reserve_rates.to_timestamp IS NULL;
+	CREATE INDEX IF NOT EXISTS "reserve_rates_timestamp_idx" ON "reserve_rates"(chain_id, reserve, pair, timestamp);
 	`
 )
 
@@ -38,37 +53,44 @@ type Storage struct {
 	sugar      *zap.SugaredLogger
 	db         *sqlx.DB
 	blkTimeRsv blockchain.BlockTimeResolverInterface
+	chainID    uint64
 }
 
 // NewPostgresStorage return new storage
-func NewPostgresStorage(db *sqlx.DB, sugar *zap.SugaredLogger, blkTimeRsv blockchain.BlockTimeResolverInterface) (*Storage, error) {
-	if _, err := db.Exec(schema); err != nil {
-		sugar.Errorw("failed to init database", "error", err)
-		return nil, err
+func NewPostgresStorage(db *sqlx.DB, sugar *zap.SugaredLogger, blkTimeRsv blockchain.BlockTimeResolverInterface, chainID uint64) (*Storage, error) {
+	for _, stmt := range []string{schema, alertsSchema, cexReferencesSchema, reserveIDsSchema} {
+		if _, err := db.Exec(stmt); err != nil {
+			sugar.Errorw("failed to init database", "error", err)
+			return nil, err
+		}
 	}
 	return &Storage{
 		db:         db,
 		sugar:      sugar,
 		blkTimeRsv: blkTimeRsv,
+		chainID:    chainID,
 	}, nil
 }
 
-// UpdateRatesRecords save rate records to db
+// UpdateRatesRecords saves rates of reserves at given block. Rates are stored as validity intervals
+// [from_block, to_block): the current interval of a pair is extended while its rate remains the same,
+// a new interval is started when the rate changes.
 func (s *Storage) UpdateRatesRecords(blockNumber uint64, rateRecords map[string]map[string]common.ReserveRateEntry) error {
 	var (
 		logger = s.sugar.With(
 			"func", caller.GetCurrentFunctionName(),
+			"block_number", blockNumber,
 		)
-		reserves, pairs                                      []string
+		rsvAddrs, reserves, pairs                            []string
 		buyRates, sellRates, buySanityRates, sellSanityRates []float64
 		fromBlocks, toBlocks                                 []uint64
-		timestamps                                           []time.Time
+		timestamps, toTimestamps                             []time.Time
 	)
-	query := `INSERT INTO reserve_rates 
-	(reserve, pair, buy_rate, sell_rate, 
-		buy_sanity_rate, sell_sanity_rate, from_block, to_block, timestamp)
+	query := `INSERT INTO reserve_rates
+	(reserve, pair, buy_rate, sell_rate,
+		buy_sanity_rate, sell_sanity_rate, from_block, to_block, timestamp, to_timestamp, chain_id)
 	VALUES(
-		UNNEST($1::TEXT[]), 
+		UNNEST($1::TEXT[]),
 		UNNEST($2::TEXT[]),
 		UNNEST($3::FLOAT[]),
 		UNNEST($4::FLOAT[]),
@@ -76,127 +98,147 @@ func (s *Storage) UpdateRatesRecords(blockNumber uint64, rateRecords map[string]
 		UNNEST($6::FLOAT[]),
 		UNNEST($7::INTEGER[]),
 		UNNEST($8::INTEGER[]),
-		UNNEST($9::TIMESTAMP[])
-	) ON CONFLICT (reserve, pair, from_block) DO UPDATE SET from_block = EXCLUDED.from_block, 
-	to_block = EXCLUDED.to_block, timestamp = EXCLUDED.timestamp;`
+		UNNEST($9::TIMESTAMP[]),
+		UNNEST($10::TIMESTAMP[]),
+		$11
+	) ON CONFLICT (chain_id, reserve, pair, from_block) DO UPDATE SET buy_rate = EXCLUDED.buy_rate,
+	sell_rate = EXCLUDED.sell_rate, buy_sanity_rate = EXCLUDED.buy_sanity_rate, sell_sanity_rate = EXCLUDED.sell_sanity_rate,
+	to_block = EXCLUDED.to_block, timestamp = EXCLUDED.timestamp, to_timestamp = EXCLUDED.to_timestamp;`
 	if s.blkTimeRsv == nil {
 		return errors.New("block time resolver is not available")
 	}
+	if len(rateRecords) == 0 {
+		return nil
+	}
+	timestamp, err := s.blkTimeRsv.Resolve(blockNumber)
+	if err != nil {
+		return err
+	}
+
+	for rsvAddr := range rateRecords {
+		rsvAddrs = append(rsvAddrs, rsvAddr)
+	}
+	lastRates, err := s.lastRates(rsvAddrs)
+	if err != nil {
+		return err
+	}
+
+	appendRecord := func(rsvAddr, pair string, rate common.ReserveRateEntry, fromBlock, toBlock uint64, fromTime, toTime time.Time) {
+		reserves = append(reserves, rsvAddr)
+		pairs = append(pairs, pair)
+		buyRates = append(buyRates, rate.BuyReserveRate)
+		sellRates = append(sellRates, rate.SellReserveRate)
+		buySanityRates = append(buySanityRates, rate.BuySanityRate)
+		sellSanityRates = append(sellSanityRates, rate.SellSanityRate)
+		fromBlocks = append(fromBlocks, fromBlock)
+		toBlocks = append(toBlocks, toBlock)
+		timestamps = append(timestamps, fromTime)
+		toTimestamps = append(toTimestamps, toTime)
+	}
+
 	for rsvAddr, rateRecord := range rateRecords {
-		lastRates, fErr := s.lastRates(rsvAddr)
-		if fErr != nil {
-			return fErr
-		}
 		for pair, rate := range rateRecord {
-			var (
-				fromBlock uint64
-				toBlock   uint64
-			)
-			lastRate := lastRates[pair].Rate
-			lastFromBlock := lastRates[pair].FromBlock
-			lastToBlock := lastRates[pair].ToBlock
+			last, ok := lastRates[rsvAddr][pair]
 			switch {
-			case lastRate == nil:
+			case !ok:
 				logger.Debugw("no last rate available",
 					"reserve_addr", rsvAddr,
 					"pair", pair)
-				fromBlock = blockNumber
-				toBlock = fromBlock + 1
-			case *lastRate != rate:
-				logger.Debugw("rate changed, starting new rate group",
+				appendRecord(rsvAddr, pair, rate, blockNumber, blockNumber+1, timestamp, timestamp)
+			case blockNumber < last.FromBlock:
+				logger.Warnw("block is older than the current rate interval, ignoring",
 					"reserve_addr", rsvAddr,
-					"last_to_block", lastToBlock,
 					"pair", pair,
-					"last_rate", lastRate,
-					"rate", rate,
-				)
-				fromBlock = blockNumber
-				toBlock = fromBlock + 1
-			default:
-				logger.Debugw("rate is remain the same as last stored record",
+					"last_from_block", last.FromBlock)
+			case last.rate() == rate:
+				if blockNumber < last.ToBlock {
+					continue // the block is already covered by the current interval
+				}
+				logger.Debugw("rate is remain the same as last stored record, extending",
 					"reserve_addr", rsvAddr,
-					"last_to_block", lastToBlock,
+					"last_to_block", last.ToBlock,
 					"pair", pair)
-				fromBlock = lastFromBlock
-				toBlock = lastToBlock + 1
-			}
-			timestamp, err := s.blkTimeRsv.Resolve(fromBlock)
-			if err != nil {
-				return err
+				appendRecord(rsvAddr, pair, rate, last.FromBlock, blockNumber+1, last.Timestamp, timestamp)
+			default:
+				logger.Debugw("rate changed, starting new rate interval",
+					"reserve_addr", rsvAddr,
+					"last_to_block", last.ToBlock,
+					"pair", pair,
+					"last_rate", last.rate(),
+					"rate", rate,
+				)
+				if blockNumber > last.FromBlock {
+					// closes the current interval, otherwise it is replaced by the new one
+					appendRecord(rsvAddr, pair, last.rate(), last.FromBlock, blockNumber, last.Timestamp, timestamp)
+				}
+				appendRecord(rsvAddr, pair, rate, blockNumber, blockNumber+1, timestamp, timestamp)
 			}
-
-			// append a record
-			reserves = append(reserves, rsvAddr)
-			pairs = append(pairs, pair)
-			buyRates = append(buyRates, rate.BuyReserveRate)
-			sellRates = append(sellRates, rate.SellReserveRate)
-			buySanityRates = append(buySanityRates, rate.BuySanityRate)
-			sellSanityRates = append(sellSanityRates, rate.SellSanityRate)
-			fromBlocks = append(fromBlocks, fromBlock)
-			toBlocks = append(toBlocks, toBlock)
-			timestamps = append(timestamps, timestamp)
-			logger.Debugw("fromBlock, toBlock has the same rate", "from block", fromBlock, "to block", toBlock)
 		}
 	}
+	if len(reserves) == 0 {
+		logger.Debug("all rates are already stored")
+		return nil
+	}
+	logger.Debugw("updating rate intervals", "records", len(reserves))
 	if _, err := s.db.Exec(query, pq.StringArray(reserves), pq.StringArray(pairs), pq.Array(buyRates),
-		pq.Array(sellRates), pq.Array(buySanityRates), pq.Array(sellSanityRates), pq.Array(fromBlocks), pq.Array(toBlocks), pq.Array(timestamps)); err != nil {
+		pq.Array(sellRates), pq.Array(buySanityRates), pq.Array(sellSanityRates), pq.Array(fromBlocks), pq.Array(toBlocks),
+		pq.Array(timestamps), pq.Array(toTimestamps), s.chainID); err != nil {
 		return err
 	}
 	return nil
 }
 
 type lastRatesResponse struct {
-	Pair           string  `db:"pair"`
-	BuyRate        float64 `db:"buy_rate"`
-	SellRate       float64 `db:"sell_rate"`
-	BuySanityRate  float64 `db:"buy_sanity_rate"`
-	SellSanityRate float64 `db:"sell_sanity_rate"`
-	FromBlock      uint64  `db:"from_block"`
-	ToBlock        uint64  `db:"to_block"`
-	Rk             uint64  `db:"rk"` // row number used for find latest rates for each pair of token
+	Reserve        string    `db:"reserve"`
+	Pair           string    `db:"pair"`
+	BuyRate        float64   `db:"buy_rate"`
+	SellRate       float64   `db:"sell_rate"`
+	BuySanityRate  float64   `db:"buy_sanity_rate"`
+	SellSanityRate float64   `db:"sell_sanity_rate"`
+	FromBlock      uint64    `db:"from_block"`
+	ToBlock        uint64    `db:"to_block"`
+	Timestamp      time.Time `db:"timestamp"`
+}
+
+func (r lastRatesResponse) rate() common.ReserveRateEntry {
+	return common.ReserveRateEntry{
+		BuyReserveRate:  r.BuyRate,
+		SellReserveRate: r.SellRate,
+		BuySanityRate:   r.BuySanityRate,
+		SellSanityRate:  r.SellSanityRate,
+	}
 }
 
-func (s *Storage) lastRates(rsvAddr string) (map[string]common.LastRate, error) {
+// lastRates returns the latest rate interval of every pair of given reserves.
+func (s *Storage) lastRates(rsvAddrs []string) (map[string]map[string]lastRatesResponse, error) {
 	var (
 		logger = s.sugar.With(
 			"func", caller.GetCurrentFunctionName(),
-			"reserve_addr", rsvAddr,
+			"reserve_addrs", rsvAddrs,
 		)
-		lastRates   = make(map[string]common.LastRate)
+		lastRates   = make(map[string]map[string]lastRatesResponse)
 		lastRatesDB []lastRatesResponse
 	)
-	query := `
-	WITH latest as (
-		SELECT buy_rate, sell_rate, buy_sanity_rate, sell_sanity_rate, from_block, to_block, pair,
-		       ROW_NUMBER() OVER (PARTITION BY pair ORDER BY timestamp DESC) as rk
-		FROM reserve_rates
-		WHERE reserve = $1
-	) 
-	SELECT latest.*
-	FROM latest WHERE latest.rk = 1
-	`
-	logger.Infow("get last rates", "query", query)
-	if err := s.db.Select(&lastRatesDB, query, rsvAddr); err != nil {
-		return lastRates, err
+	query := `SELECT DISTINCT ON (reserve, pair) reserve, pair, buy_rate, sell_rate, buy_sanity_rate, sell_sanity_rate,
+		from_block, to_block, timestamp
+	FROM reserve_rates
+	WHERE chain_id = $1 AND reserve = ANY($2::TEXT[])
+	ORDER BY reserve, pair, from_block DESC`
+	logger.Debugw("get last rates", "query", query)
+	if err := s.db.Select(&lastRatesDB, query, s.chainID, pq.StringArray(rsvAddrs)); err != nil {
+		return nil, err
 	}
 
 	for _, rate := range lastRatesDB {
-		lastRates[rate.Pair] = common.LastRate{
-			FromBlock: rate.FromBlock,
-			ToBlock:   rate.ToBlock,
-			Rate: &common.ReserveRateEntry{
-				BuyReserveRate:  rate.BuyRate,
-				SellReserveRate: rate.SellRate,
-				BuySanityRate:   rate.BuySanityRate,
-				SellSanityRate:  rate.SellSanityRate,
-			},
+		if _, ok := lastRates[rate.Reserve]; !ok {
+			lastRates[rate.Reserve] = make(map[string]lastRatesResponse)
 		}
+		lastRates[rate.Reserve][rate.Pair] = rate
 	}
 	return lastRates, nil
 }
 
 type ratesQueryResponse struct {
-	ID             uint64    `db:"id"`
 	Reserve        string    `db:"reserve"`
 	Pair           string    `db:"pair"`
 	BuyRate        float64   `db:"buy_rate"`
@@ -206,9 +248,25 @@ type ratesQueryResponse struct {
 	FromBlock      uint64    `db:"from_block"`
 	ToBlock        uint64    `db:"to_block"`
 	Timestamp      time.Time `db:"timestamp"`
+	ToTimestamp    time.Time `db:"to_timestamp"`
 }
 
-// GetRatesByTimePoint return rates by from time and to time
+func (r ratesQueryResponse) reserveRates() common.ReserveRates {
+	return common.ReserveRates{
+		Timestamp:   r.Timestamp,
+		ToTimestamp: r.ToTimestamp,
+		FromBlock:   r.FromBlock,
+		ToBlock:     r.ToBlock,
+		Rates: common.ReserveRateEntry{
+			BuyReserveRate:  r.BuyRate,
+			SellReserveRate: r.SellRate,
+			BuySanityRate:   r.BuySanityRate,
+			SellSanityRate:  r.SellSanityRate,
+		},
+	}
+}
+
+// GetRatesByTimePoint returns rate intervals of reserves valid in given time range.
 func (s *Storage) GetRatesByTimePoint(addrs []ethereum.Address, fromTime, toTime uint64) (map[string]map[string][]common.ReserveRates, error) {
 	var (
 		result = make(map[string]map[string][]common.ReserveRates)
@@ -223,49 +281,167 @@ func (s *Storage) GetRatesByTimePoint(addrs []ethereum.Address, fromTime, toTime
 	for _, addr := range addrs {
 		reserves = append(reserves, addr.Hex())
 	}
-	logger.With("reserve", reserves)
-	query := `SELECT * FROM reserve_rates WHERE EXTRACT(EPOCH FROM timestamp)*1000 > $1 AND EXTRACT (EPOCH FROM timestamp)*1000 < $2 AND reserve = any($3::TEXT[])`
+	logger = logger.With("reserve", reserves)
+	query := `SELECT reserve, pair, buy_rate, sell_rate, buy_sanity_rate, sell_sanity_rate, from_block, to_block, timestamp
+	FROM reserve_rates
+	WHERE EXTRACT(EPOCH FROM timestamp)*1000 < $2 AND EXTRACT(EPOCH FROM COALESCE(to_timestamp, timestamp))*1000 > $1
+	AND reserve = any($3::TEXT[]) AND chain_id = $4
+	ORDER BY from_block`
 	logger.Infow("get rates by time point", "query", query)
-	if err := s.db.Select(&rateResponse, query, fromTime, toTime, pq.StringArray(reserves)); err != nil {
+	if err := s.db.Select(&rateResponse, query, fromTime, toTime, pq.StringArray(reserves), s.chainID); err != nil {
 		return result, err
 	}
 	for _, rate := range rateResponse {
-		if len(result) == 0 {
-			result[rate.Reserve] = make(map[string][]common.ReserveRates)
-		}
 		ratePair := result[rate.Reserve]
 		if ratePair == nil {
 			ratePair = make(map[string][]common.ReserveRates)
 		}
-		ratePair[rate.Pair] = append(ratePair[rate.Pair], common.ReserveRates{
-			Timestamp: rate.Timestamp,
-			FromBlock: rate.FromBlock,
-			ToBlock:   rate.ToBlock,
-			Rates: common.ReserveRateEntry{
-				BuyReserveRate:  rate.BuyRate,
-				SellReserveRate: rate.SellRate,
-				BuySanityRate:   rate.BuySanityRate,
-				SellSanityRate:  rate.SellSanityRate,
-			},
-		})
+		ratePair[rate.Pair] = append(ratePair[rate.Pair], rate.reserveRates())
 		result[rate.Reserve] = ratePair
 	}
 	return result, nil
 }
 
-// LastBlock return last block saved in db
+// GetRatesByPair returns rate intervals of all reserves for pair valid in given time range, keyed by reserve.
+func (s *Storage) GetRatesByPair(pair string, fromTime, toTime uint64) (map[string][]common.ReserveRates, error) {
+	var (
+		result = make(map[string][]common.ReserveRates)
+		logger = s.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"pair", pair,
+			"from", fromTime,
+			"to", toTime,
+		)
+		rateResponse []ratesQueryResponse
+	)
+	query := `SELECT reserve, pair, buy_rate, sell_rate, buy_sanity_rate, sell_sanity_rate, from_block, to_block, timestamp,
+		COALESCE(to_timestamp, timestamp) AS to_timestamp
+	FROM reserve_rates
+	WHERE EXTRACT(EPOCH FROM timestamp)*1000 < $2 AND EXTRACT(EPOCH FROM COALESCE(to_timestamp, timestamp))*1000 > $1
+	AND pair = $3 AND chain_id = $4
+	ORDER BY from_block`
+	logger.Debugw("get rates by pair", "query", query)
+	if err := s.db.Select(&rateResponse, query, fromTime, toTime, pair, s.chainID); err != nil {
+		logger.Errorw("failed to get rates by pair", "error", err)
+		return nil, err
+	}
+	for _, rate := range rateResponse {
+		result[rate.Reserve] = append(result[rate.Reserve], rate.reserveRates())
+	}
+	return result, nil
+}
+
+// GetRateAtBlock returns the rate interval of reserve for pair which is valid at given block,
+// nil if there is no rate recorded at the block.
+func (s *Storage) GetRateAtBlock(reserve ethereum.Address, pair string, block uint64) (*common.ReserveRates, error) {
+	query := `SELECT reserve, pair, buy_rate, sell_rate, buy_sanity_rate, sell_sanity_rate, from_block, to_block, timestamp
+	FROM reserve_rates
+	WHERE chain_id = $1 AND reserve = $2 AND pair = $3 AND from_block <= $4 AND to_block > $4
+	ORDER BY from_block DESC
+	LIMIT 1`
+	return s.getRate(query, reserve, pair, block)
+}
+
+// GetRateAtTime returns the rate interval of reserve for pair which is valid at given time,
+// nil if there is no rate recorded at the time.
+func (s *Storage) GetRateAtTime(reserve ethereum.Address, pair string, t time.Time) (*common.ReserveRates, error) {
+	// at the boundary of two intervals, the later one is valid
+	query := `SELECT reserve, pair, buy_rate, sell_rate, buy_sanity_rate, sell_sanity_rate, from_block, to_block, timestamp
+	FROM reserve_rates
+	WHERE chain_id = $1 AND reserve = $2 AND pair = $3 AND timestamp <= $4 AND COALESCE(to_timestamp, timestamp) >= $4
+	ORDER BY from_block DESC
+	LIMIT 1`
+	return s.getRate(query, reserve, pair, t.UTC())
+}
+
+func (s *Storage) getRate(query string, reserve ethereum.Address, pair string, point interface{}) (*common.ReserveRates, error) {
+	var (
+		logger = s.sugar.With(
+			"func", caller.GetCallerFunctionName(),
+			"reserve", reserve.Hex(),
+			"pair", pair,
+			"point", point,
+		)
+		rate ratesQueryResponse
+	)
+	logger.Debugw("get rate at point", "query", query)
+	if err := s.db.Get(&rate, query, s.chainID, reserve.Hex(), pair, point); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logger.Errorw("failed to get rate", "error", err)
+		return nil, err
+	}
+	result := rate.reserveRates()
+	return &result, nil
+}
+
+// LastBlock returns the block next to the last block saved in db
 func (s *Storage) LastBlock() (int64, error) {
 	var (
 		lastBlock int64
 		logger    = s.sugar.With("func", caller.GetCallerFunctionName())
 	)
-	query := `SELECT to_block FROM reserve_rates ORDER BY timestamp DESC LIMIT 1;`
+	query := `SELECT COALESCE(MAX(to_block), 0) FROM reserve_rates WHERE chain_id = $1;`
 	logger.Infow("Getting last block stored in db", "query", query)
-	if err := s.db.Get(&lastBlock, query); err != nil {
-		if err == sql.ErrNoRows {
-			return lastBlock, nil
-		}
+	if err := s.db.Get(&lastBlock, query, s.chainID); err != nil {
 		return lastBlock, err
 	}
 	return lastBlock, nil
 }
+
+// GetRatesAtBlocks returns the rates quoted by reserves at given blocks, a query is missing from the
+// result if the reserve has no rate of the pair recorded at the block.
+func (s *Storage) GetRatesAtBlocks(queries []common.RateQuery) (map[common.RateQuery]common.ReserveRateEntry, error) {
+	var (
+		logger = s.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"queries", len(queries),
+		)
+		reserves, pairs []string
+		blocks          []int64
+		rows            []struct {
+			Reserve        string  `db:"reserve"`
+			Pair           string  `db:"pair"`
+			Block          uint64  `db:"block"`
+			BuyRate        float64 `db:"buy_rate"`
+			SellRate       float64 `db:"sell_rate"`
+			BuySanityRate  float64 `db:"buy_sanity_rate"`
+			SellSanityRate float64 `db:"sell_sanity_rate"`
+		}
+		result = make(map[common.RateQuery]common.ReserveRateEntry)
+	)
+	if len(queries) == 0 {
+		return result, nil
+	}
+	for _, q := range queries {
+		reserves = append(reserves, q.Reserve)
+		pairs = append(pairs, q.Pair)
+		blocks = append(blocks, int64(q.Block))
+	}
+	// a rate is valid from from_block until before to_block
+	query := `SELECT q.reserve, q.pair, q.block, r.buy_rate, r.sell_rate, r.buy_sanity_rate, r.sell_sanity_rate
+	FROM UNNEST($1::TEXT[], $2::TEXT[], $3::INTEGER[]) AS q(reserve, pair, block)
+	JOIN LATERAL (
+		SELECT buy_rate, sell_rate, buy_sanity_rate, sell_sanity_rate
+		FROM reserve_rates
+		WHERE chain_id = $4 AND reserve = q.reserve AND pair = q.pair
+		AND from_block <= q.block AND to_block > q.block
+		ORDER BY from_block DESC
+		LIMIT 1
+	) AS r ON TRUE;`
+	logger.Debugw("get rates at blocks", "query", query)
+	if err := s.db.Select(&rows, query, pq.StringArray(reserves), pq.StringArray(pairs), pq.Array(blocks), s.chainID); err != nil {
+		logger.Errorw("failed to get rates at blocks", "error", err)
+		return nil, err
+	}
+	for _, r := range rows {
+		result[common.RateQuery{Reserve: r.Reserve, Pair: r.Pair, Block: r.Block}] = common.ReserveRateEntry{
+			BuyReserveRate:  r.BuyRate,
+			SellReserveRate: r.SellRate,
+			BuySanityRate:   r.BuySanityRate,
+			SellSanityRate:  r.SellSanityRate,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,301 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/testutil"
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+// blockTimeResolver resolves block N to 15 seconds after block N-1.
+type blockTimeResolver struct {
+	genesis time.Time
+}
+
+func (r blockTimeResolver) Resolve(blockNumber uint64) (time.Time, error) {
+	return r.genesis.Add(time.Duration(blockNumber) * 15 * time.Second), nil
+}
+
+func TestRateIntervals(t *testing.T) {
+	var (
+		sugar   = testutil.MustNewDevelopmentSugaredLogger()
+		reserve = ethereum.HexToAddress("0x63825c174ab367968EC60f061753D3bbD36A0D8F")
+		rateA   = common.ReserveRateEntry{BuyReserveRate: 1, BuySanityRate: 2, SellReserveRate: 3, SellSanityRate: 4}
+		rateB   = common.ReserveRateEntry{BuyReserveRate: 5, BuySanityRate: 6, SellReserveRate: 7, SellSanityRate: 8}
+		btr     = blockTimeResolver{genesis: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	)
+	db, teardown := testutil.MustNewDevelopmentDB()
+	defer func() {
+		require.NoError(t, teardown())
+	}()
+	s, err := NewPostgresStorage(db, sugar, btr, 1)
+	require.NoError(t, err)
+
+	blockTime := func(block uint64) time.Time {
+		ts, err := btr.Resolve(block)
+		require.NoError(t, err)
+		return ts
+	}
+	for _, record := range []struct {
+		block uint64
+		rate  common.ReserveRateEntry
+	}{
+		{100, rateA},
+		{101, rateA},
+		{102, rateA},
+		{101, rateA}, // already stored blocks are ignored
+		{105, rateA}, // missing blocks are covered by the interval
+		{106, rateB},
+		{107, rateB},
+	} {
+		require.NoError(t, s.UpdateRatesRecords(record.block, map[string]map[string]common.ReserveRateEntry{
+			reserve.Hex(): {"ETH-KNC": record.rate},
+		}))
+	}
+
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM reserve_rates`))
+	assert.Equal(t, 2, count)
+
+	lastBlock, err := s.LastBlock()
+	require.NoError(t, err)
+	assert.Equal(t, int64(108), lastBlock)
+
+	intervalA := &common.ReserveRates{Timestamp: blockTime(100), FromBlock: 100, ToBlock: 106, Rates: rateA}
+	intervalB := &common.ReserveRates{Timestamp: blockTime(106), FromBlock: 106, ToBlock: 108, Rates: rateB}
+	for _, tc := range []struct {
+		block    uint64
+		expected *common.ReserveRates
+	}{
+		{99, nil},
+		{100, intervalA},
+		{105, intervalA},
+		{106, intervalB},
+		{107, intervalB},
+		{108, nil},
+	} {
+		rate, err := s.GetRateAtBlock(reserve, "ETH-KNC", tc.block)
+		require.NoError(t, err)
+		assertRate(t, tc.expected, rate)
+	}
+
+	for _, tc := range []struct {
+		ts       time.Time
+		expected *common.ReserveRates
+	}{
+		{blockTime(100).Add(-time.Second), nil},
+		{blockTime(103).Add(time.Second), intervalA},
+		{blockTime(106), intervalB},
+		{blockTime(107), intervalB},
+		{blockTime(107).Add(time.Second), nil},
+	} {
+		rate, err := s.GetRateAtTime(reserve, "ETH-KNC", tc.ts)
+		require.NoError(t, err)
+		assertRate(t, tc.expected, rate)
+	}
+
+	rate, err := s.GetRateAtBlock(reserve, "ETH-ZRX", 100)
+	require.NoError(t, err)
+	assert.Nil(t, rate)
+
+	rates, err := s.GetRatesByTimePoint([]ethereum.Address{reserve},
+		uint64(blockTime(103).UnixNano()/int64(time.Millisecond)),
+		uint64(blockTime(104).UnixNano()/int64(time.Millisecond)))
+	require.NoError(t, err)
+	require.Len(t, rates[reserve.Hex()]["ETH-KNC"], 1)
+	assert.Equal(t, uint64(100), rates[reserve.Hex()]["ETH-KNC"][0].FromBlock)
+
+	pairRates, err := s.GetRatesByPair("ETH-KNC",
+		uint64(blockTime(100).UnixNano()/int64(time.Millisecond)),
+		uint64(blockTime(108).UnixNano()/int64(time.Millisecond)))
+	require.NoError(t, err)
+	require.Len(t, pairRates[reserve.Hex()], 2)
+	assertRate(t, intervalA, &pairRates[reserve.Hex()][0])
+	assertRate(t, intervalB, &pairRates[reserve.Hex()][1])
+}
+
+func assertRate(t *testing.T, expected, actual *common.ReserveRates) {
+	t.Helper()
+	if expected == nil {
+		assert.Nil(t, actual)
+		return
+	}
+	require.NotNil(t, actual)
+	assert.True(t, expected.Timestamp.Equal(actual.Timestamp))
+	assert.Equal(t, expected.FromBlock, actual.FromBlock)
+	assert.Equal(t, expected.ToBlock, actual.ToBlock)
+	assert.Equal(t, expected.Rates, actual.Rates)
+}
+
+func TestRateAlerts(t *testing.T) {
+	var (
+		sugar   = testutil.MustNewDevelopmentSugaredLogger()
+		reserve = ethereum.HexToAddress("0x63825c174ab367968EC60f061753D3bbD36A0D8F")
+		ts      = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		alerts  = []common.RateAlert{
+			{
+				Timestamp: ts,
+				Block:     100,
+				Reserve:   reserve.Hex(),
+				Pair:      "ETH-KNC",
+				Type:      common.RateAlertSanityViolation,
+				Side:      "buy",
+				Value:     120,
+				Threshold: 110,
+				Message:   "buy rate 120 is above sanity rate 110",
+			},
+			{
+				Timestamp: ts.Add(15 * time.Second),
+				Block:     101,
+				Reserve:   reserve.Hex(),
+				Pair:      "ETH-KNC",
+				Type:      common.RateAlertCrossed,
+				Value:     -0.1,
+				Message:   "crossed",
+			},
+		}
+	)
+	db, teardown := testutil.MustNewDevelopmentDB()
+	defer func() {
+		require.NoError(t, teardown())
+	}()
+	s, err := NewPostgresStorage(db, sugar, nil, 1)
+	require.NoError(t, err)
+
+	require.NoError(t, s.SaveRateAlerts(alerts))
+	// alerts of a block crawled again are not duplicated
+	require.NoError(t, s.SaveRateAlerts(alerts[:1]))
+
+	stored, err := s.GetRateAlerts(ts, ts.Add(time.Minute), nil, "")
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	for i := range stored {
+		assert.True(t, alerts[i].Timestamp.Equal(stored[i].Timestamp))
+		assert.Equal(t, alerts[i].Type, stored[i].Type)
+		assert.Equal(t, alerts[i].Side, stored[i].Side)
+		assert.Equal(t, alerts[i].Block, stored[i].Block)
+	}
+
+	stored, err = s.GetRateAlerts(ts, ts.Add(time.Minute), []ethereum.Address{reserve}, common.RateAlertCrossed)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, uint64(101), stored[0].Block)
+
+	stored, err = s.GetRateAlerts(ts, ts.Add(time.Minute),
+		[]ethereum.Address{ethereum.HexToAddress("0x21433Dec9Cb634A23c6A4BbcCe08c83f5aC2EC18")}, "")
+	require.NoError(t, err)
+	assert.Len(t, stored, 0)
+}
+
+func TestCEXReferences(t *testing.T) {
+	var (
+		sugar   = testutil.MustNewDevelopmentSugaredLogger()
+		reserve = ethereum.HexToAddress("0x63825c174ab367968EC60f061753D3bbD36A0D8F")
+		ts      = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		refs    []common.CEXReference
+	)
+	db, teardown := testutil.MustNewDevelopmentDB()
+	defer func() {
+		require.NoError(t, teardown())
+	}()
+	s, err := NewPostgresStorage(db, sugar, nil, 1)
+	require.NoError(t, err)
+
+	for i, exchange := range []string{"binance", "huobi", "binance"} {
+		refs = append(refs, common.CEXReference{
+			Timestamp:   ts.Add(time.Duration(i) * 15 * time.Second),
+			Block:       uint64(100 + i),
+			Reserve:     reserve.Hex(),
+			Pair:        "ETH-KNC",
+			Exchange:    exchange,
+			CEXMidPrice: 0.0102,
+			BuyPrice:    0.01,
+			SellPrice:   0.0098,
+			Spread:      0.02,
+			Deviation:   -0.03,
+		})
+	}
+	require.NoError(t, s.SaveCEXReferences(refs))
+	// a block sampled again is replaced
+	refs[0].Deviation = -0.04
+	require.NoError(t, s.SaveCEXReferences(refs[:1]))
+
+	stored, err := s.GetCEXReferences(reserve, "ETH-KNC", "", ts, ts.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+	assert.Equal(t, uint64(100), stored[0].Block)
+	assert.True(t, ts.Equal(stored[0].Timestamp))
+	assert.InDelta(t, -0.04, stored[0].Deviation, 1e-12)
+
+	stored, err = s.GetCEXReferences(reserve, "ETH-KNC", "binance", ts, ts.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, uint64(102), stored[1].Block)
+
+	stored, err = s.GetCEXReferences(reserve, "ETH-ZRX", "", ts, ts.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, stored, 0)
+}
+
+func TestReserveAddresses(t *testing.T) {
+	var (
+		sugar     = testutil.MustNewDevelopmentSugaredLogger()
+		reserveID = ethereum.HexToHash("0xaa4b4c4d4e000000000000000000000000000000000000000000000000000000")
+		oldAddr   = ethereum.HexToAddress("0x63825c174ab367968EC60f061753D3bbD36A0D8F")
+		newAddr   = ethereum.HexToAddress("0x21433Dec9Cb634A23c6A4BbcCe08c83f5aC2EC18")
+		rateA     = common.ReserveRateEntry{BuyReserveRate: 1, BuySanityRate: 2, SellReserveRate: 3, SellSanityRate: 4}
+		rateB     = common.ReserveRateEntry{BuyReserveRate: 5, BuySanityRate: 6, SellReserveRate: 7, SellSanityRate: 8}
+		btr       = blockTimeResolver{genesis: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	)
+	db, teardown := testutil.MustNewDevelopmentDB()
+	defer func() {
+		require.NoError(t, teardown())
+	}()
+	s, err := NewPostgresStorage(db, sugar, btr, 1)
+	require.NoError(t, err)
+
+	// the reserve is upgraded to a new address at block 102
+	for _, record := range []struct {
+		block   uint64
+		address ethereum.Address
+		rate    common.ReserveRateEntry
+	}{
+		{100, oldAddr, rateA},
+		{101, oldAddr, rateA},
+		{102, newAddr, rateB},
+		{103, newAddr, rateB},
+	} {
+		require.NoError(t, s.UpdateRatesRecords(record.block, map[string]map[string]common.ReserveRateEntry{
+			record.address.Hex(): {"ETH-KNC": record.rate},
+		}))
+		require.NoError(t, s.UpdateReserveAddresses(record.block, map[string]ethereum.Hash{
+			record.address.Hex(): reserveID,
+		}))
+	}
+
+	addresses, err := s.GetReserveAddresses(reserveID)
+	require.NoError(t, err)
+	assert.Equal(t, []common.ReserveAddress{
+		{ReserveID: reserveID.Hex(), Address: oldAddr.Hex(), FromBlock: 100, ToBlock: 102},
+		{ReserveID: reserveID.Hex(), Address: newAddr.Hex(), FromBlock: 102, ToBlock: 104},
+	}, addresses)
+
+	blockTime := func(block uint64) uint64 {
+		ts, err := btr.Resolve(block)
+		require.NoError(t, err)
+		return uint64(ts.UnixNano() / int64(time.Millisecond))
+	}
+	rates, err := s.GetRatesByReserveIDs([]ethereum.Hash{reserveID}, blockTime(99), blockTime(105))
+	require.NoError(t, err)
+	pairRates := rates[reserveID.Hex()]["ETH-KNC"]
+	require.Len(t, pairRates, 2)
+	assert.Equal(t, rateA, pairRates[0].Rates)
+	assert.Equal(t, uint64(100), pairRates[0].FromBlock)
+	assert.Equal(t, rateB, pairRates[1].Rates)
+	assert.Equal(t, uint64(102), pairRates[1].FromBlock)
+}
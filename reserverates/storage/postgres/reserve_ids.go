@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/reserverates/common"
+)
+
+// reserve ids addresses are stored as validity intervals [from_block, to_block) as reserve rates.
+const reserveIDsSchema = `
+	CREATE TABLE IF NOT EXISTS "reserve_id_addresses" (
+		id SERIAL PRIMARY KEY,
+		chain_id INTEGER NOT NULL,
+		reserve_id TEXT NOT NULL,
+		address TEXT NOT NULL,
+		from_block INTEGER NOT NULL,
+		to_block INTEGER NOT NULL
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS "reserve_id_addresses_uk"
+		ON "reserve_id_addresses"(chain_id, reserve_id, from_block);
+	CREATE INDEX IF NOT EXISTS "reserve_id_addresses_address_idx" ON "reserve_id_addresses"(chain_id, address);
+	`
+
+// UpdateReserveAddresses saves the addresses reserve ids are resolved to at given block, addresses is
+// keyed by the reserve address. The current interval of a reserve id is extended while its address
+// remains the same, a new interval is started when the reserve is upgraded to a new address.
+func (s *Storage) UpdateReserveAddresses(blockNumber uint64, addresses map[string]ethereum.Hash) error {
+	var (
+		logger = s.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"block_number", blockNumber,
+		)
+		reserveIDs, rsvAddrs []string
+		fromBlocks, toBlocks []uint64
+	)
+	if len(addresses) == 0 {
+		return nil
+	}
+	for _, reserveID := range addresses {
+		reserveIDs = append(reserveIDs, reserveID.Hex())
+	}
+	lastAddresses, err := s.lastReserveAddresses(reserveIDs)
+	if err != nil {
+		return err
+	}
+
+	reserveIDs = nil
+	appendRecord := func(reserveID, rsvAddr string, fromBlock, toBlock uint64) {
+		reserveIDs = append(reserveIDs, reserveID)
+		rsvAddrs = append(rsvAddrs, rsvAddr)
+		fromBlocks = append(fromBlocks, fromBlock)
+		toBlocks = append(toBlocks, toBlock)
+	}
+	for rsvAddr, id := range addresses {
+		reserveID := id.Hex()
+		last, ok := lastAddresses[reserveID]
+		switch {
+		case !ok:
+			appendRecord(reserveID, rsvAddr, blockNumber, blockNumber+1)
+		case blockNumber < last.FromBlock:
+			logger.Warnw("block is older than the current address interval, ignoring",
+				"reserve_id", reserveID,
+				"last_from_block", last.FromBlock)
+		case last.Address == rsvAddr:
+			if blockNumber < last.ToBlock {
+				continue // the block is already covered by the current interval
+			}
+			appendRecord(reserveID, rsvAddr, last.FromBlock, blockNumber+1)
+		default:
+			logger.Infow("reserve address changed",
+				"reserve_id", reserveID,
+				"last_address", last.Address,
+				"address", rsvAddr)
+			if blockNumber > last.FromBlock {
+				// closes the current interval, otherwise it is replaced by the new one
+				appendRecord(reserveID, last.Address, last.FromBlock, blockNumber)
+			}
+			appendRecord(reserveID, rsvAddr, blockNumber, blockNumber+1)
+		}
+	}
+	if len(reserveIDs) == 0 {
+		return nil
+	}
+	query := `INSERT INTO reserve_id_addresses (chain_id, reserve_id, address, from_block, to_block)
+	VALUES(
+		$1,
+		UNNEST($2::TEXT[]),
+		UNNEST($3::TEXT[]),
+		UNNEST($4::INTEGER[]),
+		UNNEST($5::INTEGER[])
+	) ON CONFLICT (chain_id, reserve_id, from_block) DO UPDATE SET address = EXCLUDED.address,
+	to_block = EXCLUDED.to_block;`
+	logger.Debugw("updating reserve addresses", "query", query, "records", len(reserveIDs))
+	if _, err := s.db.Exec(query, s.chainID, pq.StringArray(reserveIDs), pq.StringArray(rsvAddrs),
+		pq.Array(fromBlocks), pq.Array(toBlocks)); err != nil {
+		logger.Errorw("failed to update reserve addresses", "error", err)
+		return err
+	}
+	return nil
+}
+
+// lastReserveAddresses returns the latest address interval of given reserve ids.
+func (s *Storage) lastReserveAddresses(reserveIDs []string) (map[string]common.ReserveAddress, error) {
+	var (
+		logger = s.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"reserve_ids", reserveIDs,
+		)
+		records []common.ReserveAddress
+		result  = make(map[string]common.ReserveAddress)
+	)
+	query := `SELECT DISTINCT ON (reserve_id) reserve_id, address, from_block, to_block
+	FROM reserve_id_addresses
+	WHERE chain_id = $1 AND reserve_id = ANY($2::TEXT[])
+	ORDER BY reserve_id, from_block DESC`
+	logger.Debugw("get last reserve addresses", "query", query)
+	if err := s.db.Select(&records, query, s.chainID, pq.StringArray(reserveIDs)); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		result[record.ReserveID] = record
+	}
+	return result, nil
+}
+
+// GetReserveAddresses returns the history of addresses of given reserve id.
+func (s *Storage) GetReserveAddresses(reserveID ethereum.Hash) ([]common.ReserveAddress, error) {
+	var (
+		logger = s.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"reserve_id", reserveID.Hex(),
+		)
+		result []common.ReserveAddress
+	)
+	query := `SELECT reserve_id, address, from_block, to_block
+	FROM reserve_id_addresses
+	WHERE chain_id = $1 AND reserve_id = $2
+	ORDER BY from_block`
+	logger.Debugw("get reserve addresses", "query", query)
+	if err := s.db.Select(&result, query, s.chainID, reserveID.Hex()); err != nil {
+		logger.Errorw("failed to get reserve addresses", "error", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetRatesByReserveIDs returns rate intervals of reserves valid in given time range keyed by reserve id,
+// rates of all addresses of a reserve id are included while they are resolved from it so the history
+// is continuous across reserve upgrades.
+func (s *Storage) GetRatesByReserveIDs(ids []ethereum.Hash, fromTime, toTime uint64) (map[string]map[string][]common.ReserveRates, error) {
+	var (
+		result = make(map[string]map[string][]common.ReserveRates)
+		logger = s.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"from", fromTime,
+			"to", toTime,
+		)
+		reserveIDs   []string
+		rateResponse []ratesQueryResponse
+	)
+	for _, id := range ids {
+		reserveIDs = append(reserveIDs, id.Hex())
+	}
+	logger = logger.With("reserve_ids", reserveIDs)
+	query := `SELECT a.reserve_id AS reserve, r.pair, r.buy_rate, r.sell_rate, r.buy_sanity_rate, r.sell_sanity_rate,
+		r.from_block, r.to_block, r.timestamp
+	FROM reserve_id_addresses AS a
+	JOIN reserve_rates AS r ON r.chain_id = a.chain_id AND r.reserve = a.address
+		AND r.from_block < a.to_block AND r.to_block > a.from_block
+	WHERE EXTRACT(EPOCH FROM r.timestamp)*1000 < $2 AND EXTRACT(EPOCH FROM COALESCE(r.to_timestamp, r.timestamp))*1000 > $1
+	AND a.reserve_id = ANY($3::TEXT[]) AND a.chain_id = $4
+	ORDER BY r.from_block`
+	logger.Debugw("get rates by reserve ids", "query", query)
+	if err := s.db.Select(&rateResponse, query, fromTime, toTime, pq.StringArray(reserveIDs), s.chainID); err != nil {
+		logger.Errorw("failed to get rates by reserve ids", "error", err)
+		return nil, err
+	}
+	for _, rate := range rateResponse {
+		ratePair := result[rate.Reserve]
+		if ratePair == nil {
+			ratePair = make(map[string][]common.ReserveRates)
+		}
+		ratePair[rate.Pair] = append(ratePair[rate.Pair], rate.reserveRates())
+		result[rate.Reserve] = ratePair
+	}
+	return result, nil
+}
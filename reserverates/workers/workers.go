@@ -17,34 +17,47 @@ import (
 )
 
 type job interface {
-	execute(sugar *zap.SugaredLogger) (map[string]map[string]common.ReserveRateEntry, error)
+	execute(sugar *zap.SugaredLogger) (fetchResult, error)
 	info() (order int, block uint64)
 }
 
+// fetchResult is the result of a job: rates by reserve address and the reserve ids of the crawled
+// addresses of Katalyst reserves.
+type fetchResult struct {
+	rates      map[string]map[string]common.ReserveRateEntry
+	reserveIDs map[string]ethereum.Hash
+}
+
 // FetcherJob represent a job to crawl rates at given block
 type FetcherJob struct {
-	c        *cli.Context
-	order    int
-	block    uint64
-	attempts int
-	addrs    []ethereum.Address
+	c            *cli.Context
+	order        int
+	block        uint64
+	attempts     int
+	addrs        []ethereum.Address
+	reserveIDs   []ethereum.Hash
+	kyberStorage ethereum.Address
 }
 
-// NewFetcherJob return an instance of FetcherJob
-func NewFetcherJob(c *cli.Context, order int, block uint64, addrs []ethereum.Address, attempts int) *FetcherJob {
+// NewFetcherJob return an instance of FetcherJob, reserves with reserveIDs are resolved
+// through kyberStorage contract at the block of the job.
+func NewFetcherJob(c *cli.Context, order int, block uint64, addrs []ethereum.Address,
+	reserveIDs []ethereum.Hash, kyberStorage ethereum.Address, attempts int) *FetcherJob {
 	return &FetcherJob{
-		c:        c,
-		order:    order,
-		block:    block,
-		attempts: attempts,
-		addrs:    addrs,
+		c:            c,
+		order:        order,
+		block:        block,
+		attempts:     attempts,
+		addrs:        addrs,
+		reserveIDs:   reserveIDs,
+		kyberStorage: kyberStorage,
 	}
 }
 
 // retry the given fn function for attempts time with sleep duration between before returns an error.
-func retry(fn func(*zap.SugaredLogger) (map[string]map[string]common.ReserveRateEntry, error), attempts int, logger *zap.SugaredLogger) (map[string]map[string]common.ReserveRateEntry, error) {
+func retry(fn func(*zap.SugaredLogger) (fetchResult, error), attempts int, logger *zap.SugaredLogger) (fetchResult, error) {
 	var (
-		result map[string]map[string]common.ReserveRateEntry
+		result fetchResult
 		err    error
 	)
 
@@ -58,34 +71,53 @@ func retry(fn func(*zap.SugaredLogger) (map[string]map[string]common.ReserveRate
 		time.Sleep(time.Second)
 	}
 
-	return nil, err
+	return fetchResult{}, err
 }
 
-func (fj *FetcherJob) fetch(sugar *zap.SugaredLogger) (map[string]map[string]common.ReserveRateEntry, error) {
+func (fj *FetcherJob) fetch(sugar *zap.SugaredLogger) (fetchResult, error) {
 	client, err := blockchain.NewEthereumClientFromFlag(fj.c)
 	if err != nil {
-		return nil, err
+		return fetchResult{}, err
 	}
 
 	symbolResolver, err := blockchain.NewTokenInfoGetterFromContext(fj.c, nil)
 	if err != nil {
-		return nil, err
+		return fetchResult{}, err
+	}
+
+	var options []crawler.Option
+	if len(fj.reserveIDs) != 0 {
+		resolver, err := crawler.NewKyberStorageReserveResolver(fj.kyberStorage, client)
+		if err != nil {
+			return fetchResult{}, err
+		}
+		options = append(options, crawler.WithReserveIDResolver(resolver))
 	}
 
-	ratesCrawler, err := crawler.NewReserveRatesCrawler(sugar, client, symbolResolver)
+	ratesCrawler, err := crawler.NewReserveRatesCrawler(sugar, client, symbolResolver, options...)
 	if err != nil {
-		return nil, err
+		return fetchResult{}, err
 	}
 
 	rates, err := ratesCrawler.GetReserveRatesWithAddresses(fj.addrs, fj.block)
 	if err != nil {
-		return nil, err
+		return fetchResult{}, err
+	}
+	if len(fj.reserveIDs) == 0 {
+		return fetchResult{rates: rates}, nil
 	}
 
-	return rates, nil
+	idRates, reserveIDs, err := ratesCrawler.GetReserveRatesWithIDs(fj.reserveIDs, fj.block)
+	if err != nil {
+		return fetchResult{}, err
+	}
+	for rsvAddr, pairRates := range idRates {
+		rates[rsvAddr] = pairRates
+	}
+	return fetchResult{rates: rates, reserveIDs: reserveIDs}, nil
 }
 
-func (fj *FetcherJob) execute(sugar *zap.SugaredLogger) (map[string]map[string]common.ReserveRateEntry, error) {
+func (fj *FetcherJob) execute(sugar *zap.SugaredLogger) (fetchResult, error) {
 	return retry(fj.fetch, fj.attempts, sugar)
 }
 
@@ -93,6 +125,12 @@ func (fj *FetcherJob) info() (int, uint64) {
 	return fj.order, fj.block
 }
 
+// RatesChecker checks rates after they are saved to storage, rates are checked in block order. Checkers
+// are called while the pool is locked, slow checks should be queued to be run in background.
+type RatesChecker interface {
+	CheckRates(block uint64, rates map[string]map[string]common.ReserveRateEntry) error
+}
+
 // Pool represents a group of workers
 type Pool struct {
 	sugar *zap.SugaredLogger
@@ -106,11 +144,12 @@ type Pool struct {
 	lastCompletedJobOrder int  // Keep the order of the last completed job
 	failed                bool // mark as failed, all subsequent persistent storage will be passed
 
-	rateStorage storage.ReserveRatesStorage
+	rateStorage   storage.ReserveRatesStorage
+	ratesCheckers []RatesChecker
 }
 
-// NewPool returns a pool of workers
-func NewPool(sugar *zap.SugaredLogger, maxWorkers int, rateStorage storage.ReserveRatesStorage) *Pool {
+// NewPool returns a pool of workers, rates are checked by ratesCheckers after being saved.
+func NewPool(sugar *zap.SugaredLogger, maxWorkers int, rateStorage storage.ReserveRatesStorage, ratesCheckers ...RatesChecker) *Pool {
 	var pool = &Pool{
 		sugar:                 sugar,
 		jobCh:                 make(chan job),
@@ -118,6 +157,7 @@ func NewPool(sugar *zap.SugaredLogger, maxWorkers int, rateStorage storage.Reser
 		mutex:                 &sync.Mutex{},
 		lastCompletedJobOrder: 0,
 		rateStorage:           rateStorage,
+		ratesCheckers:         ratesCheckers,
 	}
 
 	pool.wg.Add(maxWorkers)
@@ -130,7 +170,7 @@ func NewPool(sugar *zap.SugaredLogger, maxWorkers int, rateStorage storage.Reser
 
 			for j := range pool.jobCh {
 				order, block := j.info()
-				rates, err := j.execute(sugar)
+				result, err := j.execute(sugar)
 				if err != nil {
 					logger.Errorw("fetcher job execution failed",
 						"block", block,
@@ -142,7 +182,7 @@ func NewPool(sugar *zap.SugaredLogger, maxWorkers int, rateStorage storage.Reser
 
 				logger.Infow("fetcher job executed successfully",
 					"block", block)
-				if err = pool.serialSaveTradeLogs(order, block, rates); err != nil {
+				if err = pool.serialSaveTradeLogs(order, block, result); err != nil {
 					pool.errCh <- err
 					break
 				}
@@ -182,7 +222,7 @@ func (p *Pool) markAsFailed(order int) {
 func (p *Pool) serialSaveTradeLogs( // TODO: rename this as it is rates record not tradelogs
 	order int,
 	blockNumber uint64,
-	rates map[string]map[string]common.ReserveRateEntry) error {
+	result fetchResult) error {
 	var (
 		logger = p.sugar.With(
 			"func", caller.GetCurrentFunctionName(),
@@ -201,7 +241,7 @@ func (p *Pool) serialSaveTradeLogs( // TODO: rename this as it is rates record n
 		}
 
 		if order == p.lastCompletedJobOrder+1 {
-			if err = p.rateStorage.UpdateRatesRecords(blockNumber, rates); err != nil {
+			if err = p.rateStorage.UpdateRatesRecords(blockNumber, result.rates); err != nil {
 				logger.Errorw("saving rates to persistent storage",
 					"err", err)
 				p.mutex.Unlock()
@@ -209,6 +249,23 @@ func (p *Pool) serialSaveTradeLogs( // TODO: rename this as it is rates record n
 				return err
 			}
 
+			if len(result.reserveIDs) != 0 {
+				if err = p.saveReserveAddresses(blockNumber, result.reserveIDs); err != nil {
+					logger.Errorw("saving reserve addresses to persistent storage",
+						"err", err)
+					p.mutex.Unlock()
+					p.markAsFailed(order)
+					return err
+				}
+			}
+
+			for _, checker := range p.ratesCheckers {
+				// checking rates must not stop crawling
+				if cErr := checker.CheckRates(blockNumber, result.rates); cErr != nil {
+					logger.Errorw("failed to check rates", "err", cErr)
+				}
+			}
+
 			p.lastCompletedJobOrder++
 			logger.Infow("save rates to storage success")
 			p.mutex.Unlock()
@@ -222,6 +279,14 @@ func (p *Pool) serialSaveTradeLogs( // TODO: rename this as it is rates record n
 	}
 }
 
+func (p *Pool) saveReserveAddresses(blockNumber uint64, reserveIDs map[string]ethereum.Hash) error {
+	idStorage, ok := p.rateStorage.(storage.ReserveIDStorage)
+	if !ok {
+		return errors.New("rates storage does not support reserve ids")
+	}
+	return idStorage.UpdateReserveAddresses(blockNumber, reserveIDs)
+}
+
 // GetLastCompleteJobOrder return the order of the latest completed job
 func (p *Pool) GetLastCompleteJobOrder() int {
 	p.mutex.Lock()
@@ -50,11 +50,11 @@ type mockJob struct {
 	failure bool
 }
 
-func (j *mockJob) execute(sugar *zap.SugaredLogger) (map[string]map[string]common.ReserveRateEntry, error) {
+func (j *mockJob) execute(sugar *zap.SugaredLogger) (fetchResult, error) {
 	if j.failure {
-		return nil, fmt.Errorf("failed to execute job %d", j.order)
+		return fetchResult{}, fmt.Errorf("failed to execute job %d", j.order)
 	}
-	return nil, nil
+	return fetchResult{}, nil
 }
 
 func (j *mockJob) info() (order int, block uint64) {
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// Storage is the trade logs storage to audit.
+type Storage interface {
+	LastBlock() (int64, error)
+	AuditTradeLogs(fromBlock, toBlock, samples uint64) ([]common.AuditCheck, error)
+	GetBlockGaps(fromBlock, toBlock, maxGap uint64) ([]common.BlockRange, error)
+}
+
+// Requeuer crawls a block range again.
+type Requeuer interface {
+	Requeue(r common.BlockRange) error
+}
+
+// Report is the result of an audit of crawled trade logs.
+type Report struct {
+	Timestamp     time.Time           `json:"timestamp"`
+	FromBlock     uint64              `json:"from_block"`
+	ToBlock       uint64              `json:"to_block"`
+	Checks        []common.AuditCheck `json:"checks"`
+	Gaps          []common.BlockRange `json:"gaps"`
+	MissingBlocks uint64              `json:"missing_blocks"`
+	Requeued      []common.BlockRange `json:"requeued,omitempty"`
+}
+
+// WriteReport writes the report to w as indented JSON.
+func WriteReport(w io.Writer, report Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// Auditor checks data quality invariants of crawled trade logs and finds block ranges that were never saved.
+type Auditor struct {
+	sugar    *zap.SugaredLogger
+	st       Storage
+	maxGap   uint64   // max number of blocks between two crawled blocks
+	samples  uint64   // number of sample tx hashes per check
+	requeuer Requeuer // nil if gaps are only reported
+}
+
+// NewAuditor returns a new Auditor instance. maxGap should be the max blocks of a crawl job, as the crawler
+// records hash of the last block of every crawled range.
+func NewAuditor(sugar *zap.SugaredLogger, st Storage, maxGap, samples uint64, requeuer Requeuer) *Auditor {
+	return &Auditor{
+		sugar:    sugar,
+		st:       st,
+		maxGap:   maxGap,
+		samples:  samples,
+		requeuer: requeuer,
+	}
+}
+
+// Audit audits trade logs in the block range, toBlock is the last crawled block if zero. If the auditor
+// has a requeuer, gaps are crawled again, a failed gap is reported but does not stop the others.
+func (a *Auditor) Audit(fromBlock, toBlock uint64) (Report, error) {
+	var (
+		logger = a.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"from_block", fromBlock,
+			"to_block", toBlock,
+		)
+		report = Report{Timestamp: time.Now().UTC(), FromBlock: fromBlock, ToBlock: toBlock}
+		err    error
+	)
+	if toBlock == 0 {
+		lastBlock, err := a.st.LastBlock()
+		if err != nil {
+			return report, err
+		}
+		report.ToBlock = uint64(lastBlock)
+	}
+	if report.Checks, err = a.st.AuditTradeLogs(report.FromBlock, report.ToBlock, a.samples); err != nil {
+		return report, err
+	}
+	if report.Gaps, err = a.st.GetBlockGaps(report.FromBlock, report.ToBlock, a.maxGap); err != nil {
+		return report, err
+	}
+	for _, gap := range report.Gaps {
+		report.MissingBlocks += gap.ToBlock - gap.FromBlock + 1
+	}
+	for _, check := range report.Checks {
+		if check.Count > 0 {
+			logger.Warnw("trade logs failed check", "check", check.Name, "count", check.Count)
+		}
+	}
+	logger.Infow("audited trade logs", "gaps", len(report.Gaps), "missing_blocks", report.MissingBlocks)
+
+	if a.requeuer == nil {
+		return report, nil
+	}
+	for _, gap := range report.Gaps {
+		if err = a.requeuer.Requeue(gap); err != nil {
+			logger.Errorw("failed to requeue gap", "gap_from", gap.FromBlock, "gap_to", gap.ToBlock, "error", err)
+			continue
+		}
+		report.Requeued = append(report.Requeued, gap)
+	}
+	return report, nil
+}
+
+// Run audits trade logs from fromBlock to the last crawled block every interval and passes the reports
+// to the handler.
+func (a *Auditor) Run(fromBlock uint64, interval time.Duration, handler func(Report) error) {
+	logger := a.sugar.With("func", caller.GetCurrentFunctionName())
+	for {
+		report, err := a.Audit(fromBlock, 0)
+		if err != nil {
+			logger.Errorw("failed to audit trade logs", "error", err)
+		} else if err = handler(report); err != nil {
+			logger.Errorw("failed to handle audit report", "error", err)
+		}
+		time.Sleep(interval)
+	}
+}
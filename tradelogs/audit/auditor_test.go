@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+type mockStorage struct {
+	lastBlock int64
+	toBlock   uint64 // to block of the last audit
+	gaps      []common.BlockRange
+}
+
+func (s *mockStorage) LastBlock() (int64, error) {
+	return s.lastBlock, nil
+}
+
+func (s *mockStorage) AuditTradeLogs(fromBlock, toBlock, samples uint64) ([]common.AuditCheck, error) {
+	s.toBlock = toBlock
+	return []common.AuditCheck{
+		{Name: "zero_fiat_amount", Count: 2, Samples: []string{"0x01", "0x02"}[:samples]},
+	}, nil
+}
+
+func (s *mockStorage) GetBlockGaps(fromBlock, toBlock, maxGap uint64) ([]common.BlockRange, error) {
+	return s.gaps, nil
+}
+
+type mockRequeuer struct {
+	failed   map[uint64]bool // gaps to fail by from block
+	requeued []common.BlockRange
+}
+
+func (r *mockRequeuer) Requeue(gap common.BlockRange) error {
+	if r.failed[gap.FromBlock] {
+		return errors.New("crawl failed")
+	}
+	r.requeued = append(r.requeued, gap)
+	return nil
+}
+
+func TestAuditor(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	st := &mockStorage{
+		lastBlock: 1000,
+		gaps: []common.BlockRange{
+			{FromBlock: 101, ToBlock: 299},
+			{FromBlock: 501, ToBlock: 600},
+		},
+	}
+	report, err := NewAuditor(logger.Sugar(), st, 100, 1, nil).Audit(10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), st.toBlock)
+	assert.Equal(t, uint64(1000), report.ToBlock)
+	assert.Equal(t, []common.AuditCheck{{Name: "zero_fiat_amount", Count: 2, Samples: []string{"0x01"}}}, report.Checks)
+	assert.Equal(t, uint64(299), report.MissingBlocks)
+	assert.Empty(t, report.Requeued)
+
+	requeuer := &mockRequeuer{failed: map[uint64]bool{101: true}}
+	report, err = NewAuditor(logger.Sugar(), st, 100, 1, requeuer).Audit(10, 800)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(800), st.toBlock)
+	// a failed gap does not stop the others
+	assert.Equal(t, []common.BlockRange{{FromBlock: 501, ToBlock: 600}}, requeuer.requeued)
+	assert.Equal(t, requeuer.requeued, report.Requeued)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReport(&buf, report))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report.Gaps, decoded.Gaps)
+	assert.Equal(t, report.MissingBlocks, decoded.MissingBlocks)
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/KyberNetwork/httpsign-utils/sign"
+	ethereum "github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+const (
+	nextCursorHeader   = "X-Next-Cursor"
+	defaultPageSize    = 1000
+	tradeLogsPathQuery = "%s/trade-logs?%s"
+)
+
+// TradeLogsQuery is the query of trade logs, empty filters are not applied.
+type TradeLogsQuery struct {
+	FromTime uint64 // unix milliseconds
+	ToTime   uint64 // unix milliseconds
+	PageSize uint64 // number of trade logs of each page, default to 1000
+
+	Tokens          []ethereum.Address
+	Reserves        []ethereum.Address
+	Wallets         []ethereum.Address
+	Users           []ethereum.Address
+	IntegrationApps []string
+}
+
+func (q TradeLogsQuery) values(cursor string) url.Values {
+	values := url.Values{}
+	values.Set("from", strconv.FormatUint(q.FromTime, 10))
+	values.Set("to", strconv.FormatUint(q.ToTime, 10))
+	pageSize := q.PageSize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	values.Set("limit", strconv.FormatUint(pageSize, 10))
+	if cursor != "" {
+		values.Set("cursor", cursor)
+	}
+	for _, addr := range q.Tokens {
+		values.Add("token", addr.Hex())
+	}
+	for _, addr := range q.Reserves {
+		values.Add("reserve", addr.Hex())
+	}
+	for _, addr := range q.Wallets {
+		values.Add("wallet", addr.Hex())
+	}
+	for _, addr := range q.Users {
+		values.Add("user", addr.Hex())
+	}
+	for _, app := range q.IntegrationApps {
+		values.Add("integration_app", app)
+	}
+	return values
+}
+
+// TradeLogIterator walks all pages of trade logs matching a query.
+//
+//	it := client.NewTradeLogIterator(query)
+//	for it.Next() {
+//		process(it.Page())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type TradeLogIterator struct {
+	c      *Client
+	query  TradeLogsQuery
+	cursor string
+	page   []common.TradelogV4
+	done   bool
+	err    error
+}
+
+// NewTradeLogIterator returns an iterator over trade logs matching given query.
+func (c *Client) NewTradeLogIterator(query TradeLogsQuery) *TradeLogIterator {
+	return &TradeLogIterator{c: c, query: query}
+}
+
+// Next fetches the next page, it returns false when there is no more page or an error occurred.
+func (it *TradeLogIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	it.page, it.cursor, it.err = it.c.getTradeLogsPage(it.query, it.cursor)
+	if it.err != nil {
+		it.page = nil
+		return false
+	}
+	if it.cursor == "" {
+		it.done = true
+	}
+	return len(it.page) != 0 || !it.done
+}
+
+// Page returns trade logs of the current page.
+func (it *TradeLogIterator) Page() []common.TradelogV4 {
+	return it.page
+}
+
+// Err returns the error occurred while fetching pages, if any.
+func (it *TradeLogIterator) Err() error {
+	return it.err
+}
+
+// getTradeLogsPage returns trade logs of the page at given cursor and the cursor of next page,
+// which is empty on the last page.
+func (c *Client) getTradeLogsPage(query TradeLogsQuery, cursor string) ([]common.TradelogV4, string, error) {
+	var (
+		tradeLogs []common.TradelogV4
+		endpoint  = fmt.Sprintf(tradeLogsPathQuery, c.host, query.values(cursor).Encode())
+	)
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if c.accessKeyID != "" && c.secretAccessKey != "" {
+		req, err = sign.Sign(req, c.accessKeyID, c.secretAccessKey)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if cErr := resp.Body.Close(); cErr != nil {
+			c.sugar.Errorw("failed to close body", "err", cErr.Error())
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpcted status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&tradeLogs); err != nil {
+		return nil, "", err
+	}
+	return tradeLogs, resp.Header.Get(nextCursorHeader), nil
+}
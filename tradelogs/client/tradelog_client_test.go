@@ -77,3 +77,61 @@ func TestValidGetTradeLog(t *testing.T) {
 		t.Error("Get invalid eth amount", "result", l.EthAmount, "expected", new(big.Int))
 	}
 }
+
+func TestTradeLogIterator(t *testing.T) {
+	var (
+		pages = map[string][]common.TradelogV4{
+			"":      {{BlockNumber: 1, Index: 1}, {BlockNumber: 1, Index: 2}},
+			"page2": {{BlockNumber: 2, Index: 1}},
+		}
+		nextCursors = map[string]string{"": "page2"}
+		tokenAddr   = ethereum.HexToAddress(userAddress)
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		if query.Get("limit") != "2" || query.Get("token") != tokenAddr.Hex() {
+			t.Error("Request with wrong query", "result", req.URL.String())
+		}
+		cursor := query.Get("cursor")
+		page, ok := pages[cursor]
+		if !ok {
+			http.Error(rw, "unknown cursor", http.StatusBadRequest)
+			return
+		}
+		js, err := json.Marshal(page)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if next := nextCursors[cursor]; next != "" {
+			rw.Header().Set(nextCursorHeader, next)
+		}
+		rw.WriteHeader(http.StatusOK)
+		if _, err := rw.Write(js); err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}))
+	defer server.Close()
+
+	it := newTestTradeLog(server).NewTradeLogIterator(TradeLogsQuery{
+		FromTime: fromTime,
+		ToTime:   toTime,
+		PageSize: 2,
+		Tokens:   []ethereum.Address{tokenAddr},
+	})
+	var result []common.TradelogV4
+	for it.Next() {
+		result = append(result, it.Page()...)
+	}
+	if err := it.Err(); err != nil {
+		t.Error("Could not iterate trade logs", "err", err)
+	}
+	if len(result) != 3 {
+		t.Error("Get invalid number of trade logs", "result", len(result), "expected", 3)
+	}
+	if it.Next() {
+		t.Error("Iterator should be exhausted")
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/urfave/cli"
+
+	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
+	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
+	"github.com/KyberNetwork/reserve-stats/tradelogs"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/audit"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+)
+
+const (
+	fromBlockFlag = "from-block"
+	toBlockFlag   = "to-block"
+
+	maxBlocksFlag    = "max-blocks"
+	defaultMaxBlocks = 100
+
+	samplesFlag    = "samples"
+	defaultSamples = 10
+
+	outputFlag   = "output"
+	intervalFlag = "interval"
+
+	requeueFlag = "requeue"
+)
+
+func main() {
+	app := libapp.NewApp()
+	app.Name = "Trade Logs Audit"
+	app.Usage = "Check data quality of crawled trade logs and find block ranges that were never saved"
+	app.Version = "0.0.1"
+	app.Action = run
+
+	app.Flags = append(app.Flags,
+		cli.Uint64Flag{
+			Name:   fromBlockFlag,
+			Usage:  "Audit trade logs from block, blocks before the first crawled block are gaps, default to the first starting block of the deployment",
+			EnvVar: "FROM_BLOCK",
+		},
+		cli.Uint64Flag{
+			Name:   toBlockFlag,
+			Usage:  "Audit trade logs to block, default to the last crawled block",
+			EnvVar: "TO_BLOCK",
+		},
+		cli.Uint64Flag{
+			Name:   maxBlocksFlag,
+			Usage:  "The maximum number of block on each query of the crawler, longer ranges without crawled blocks are gaps",
+			EnvVar: "MAX_BLOCKS",
+			Value:  defaultMaxBlocks,
+		},
+		cli.Uint64Flag{
+			Name:   samplesFlag,
+			Usage:  "The number of sample tx hashes of each check in report",
+			EnvVar: "SAMPLES",
+			Value:  defaultSamples,
+		},
+		cli.StringFlag{
+			Name:   outputFlag,
+			Usage:  "The file to write report to, default to stdout",
+			EnvVar: "OUTPUT",
+		},
+		cli.DurationFlag{
+			Name:   intervalFlag,
+			Usage:  "The interval to audit again, 0 to audit once and exit",
+			EnvVar: "INTERVAL",
+		},
+		cli.BoolFlag{
+			Name:   requeueFlag,
+			Usage:  "Add gaps to the crawl queue to be crawled by trade logs crawlers in queue mode",
+			EnvVar: "REQUEUE",
+		},
+	)
+	app.Flags = append(app.Flags, storage.NewCliFlags()...)
+	app.Flags = append(app.Flags, influxdb.NewCliFlags()...)
+	app.Flags = append(app.Flags, libapp.NewPostgreSQLFlags(storage.PostgresDefaultDB)...)
+	app.Flags = append(app.Flags, blockchain.NewEthereumNodeFlags())
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeReport writes the report to the output file, replacing the previous report, or to stdout.
+func writeReport(output string, report audit.Report) error {
+	if output == "" {
+		return audit.WriteReport(os.Stdout, report)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err = audit.WriteReport(f, report); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func run(c *cli.Context) error {
+	if err := libapp.Validate(c); err != nil {
+		return err
+	}
+
+	sugar, flush, err := libapp.NewSugaredLogger(c)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	maxBlocks := c.Uint64(maxBlocksFlag)
+	if maxBlocks == 0 {
+		return errors.New("max blocks must be positive")
+	}
+	interval := c.Duration(intervalFlag)
+	if interval < 0 {
+		return errors.New("interval must not be negative")
+	}
+	fromBlock, toBlock := c.Uint64(fromBlockFlag), c.Uint64(toBlockFlag)
+	if interval > 0 && toBlock != 0 {
+		return errors.New("to block is not supported in periodic mode")
+	}
+
+	tokenAmountFormatter, err := blockchain.NewToKenAmountFormatterFromContext(c)
+	if err != nil {
+		return err
+	}
+	st, err := storage.NewStorageInterfaceFromContext(sugar, c, tokenAmountFormatter)
+	if err != nil {
+		return err
+	}
+	splitPoints := tradelogs.SplitPoints(deployment.MustGetStartingBlocksFromContext(c))
+	// blocks before the first deployed version have no trade, they must not be reported or requeued as a gap
+	if fromBlock == 0 {
+		if len(splitPoints) == 0 {
+			return errors.New("from block is required as the deployment has no starting block")
+		}
+		fromBlock = splitPoints[0]
+	}
+	// requeuer is left as a nil interface when gaps are only reported
+	var requeuer audit.Requeuer
+	if c.Bool(requeueFlag) {
+		requeuer = &queueRequeuer{
+			st:          st,
+			splitPoints: splitPoints,
+			maxBlocks:   maxBlocks,
+		}
+	}
+	auditor := audit.NewAuditor(sugar, st, maxBlocks, c.Uint64(samplesFlag), requeuer)
+
+	output := c.String(outputFlag)
+	if interval > 0 {
+		auditor.Run(fromBlock, interval, func(report audit.Report) error {
+			return writeReport(output, report)
+		})
+		return nil
+	}
+	report, err := auditor.Audit(fromBlock, toBlock)
+	if err != nil {
+		return err
+	}
+	return writeReport(output, report)
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/workers"
+)
+
+// queueRequeuer adds gaps to the crawl queue, they are crawled by crawlers running in queue mode.
+type queueRequeuer struct {
+	st          storage.Interface
+	splitPoints []uint64 // starting blocks of trade log decoders
+	maxBlocks   uint64
+}
+
+// Requeue enqueues the gap in ranges of max blocks.
+func (r *queueRequeuer) Requeue(gap common.BlockRange) error {
+	return r.st.EnqueueCrawlJobs(workers.SplitRanges(gap.FromBlock, gap.ToBlock, r.maxBlocks, r.splitPoints))
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+
+	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+)
+
+// rebuildStep is the time range rebuilt in a transaction.
+const rebuildStep = time.Hour * 24
+
+// candlesRebuilder is implemented by storage engines which precompute candles.
+type candlesRebuilder interface {
+	RebuildCandles(from, to time.Time) error
+}
+
+func main() {
+	app := libapp.NewApp()
+	app.Name = "Trade Logs Candles Rebuild"
+	app.Usage = "Rebuild OHLCV candles of trade logs in a time range"
+	app.Version = "0.0.1"
+	app.Action = run
+
+	app.Flags = append(app.Flags, timeutil.NewMilliTimeRangeCliFlags()...)
+	app.Flags = append(app.Flags, storage.NewCliFlags()...)
+	app.Flags = append(app.Flags, influxdb.NewCliFlags()...)
+	app.Flags = append(app.Flags, libapp.NewPostgreSQLFlags(storage.PostgresDefaultDB)...)
+	app.Flags = append(app.Flags, blockchain.NewEthereumNodeFlags())
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(c *cli.Context) error {
+	if err := libapp.Validate(c); err != nil {
+		return err
+	}
+
+	sugar, flush, err := libapp.NewSugaredLogger(c)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	from, err := timeutil.FromTimeMillisFromContext(c)
+	if err != nil {
+		return err
+	}
+	if from.IsZero() {
+		return errors.New("from time is required")
+	}
+	to, err := timeutil.ToTimeMillisFromContext(c)
+	if err != nil {
+		return err
+	}
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.After(to) {
+		return fmt.Errorf("from time %s is after to time %s", from, to)
+	}
+
+	tokenAmountFormatter, err := blockchain.NewToKenAmountFormatterFromContext(c)
+	if err != nil {
+		return err
+	}
+	st, err := storage.NewStorageInterfaceFromContext(sugar, c, tokenAmountFormatter)
+	if err != nil {
+		return err
+	}
+	rebuilder, ok := st.(candlesRebuilder)
+	if !ok {
+		return fmt.Errorf("db engine %q does not support candles", c.String(storage.DBEngineFlag))
+	}
+
+	for start := from; start.Before(to); start = start.Add(rebuildStep) {
+		end := start.Add(rebuildStep)
+		if end.After(to) {
+			end = to
+		}
+		if err := rebuilder.RebuildCandles(start, end); err != nil {
+			return err
+		}
+		sugar.Infow("rebuilt candles", "from", start, "to", end)
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/KyberNetwork/reserve-stats/lib/etherscan"
 	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
 	"github.com/KyberNetwork/reserve-stats/lib/mathutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
 	tradelogcq "github.com/KyberNetwork/reserve-stats/tradelogs/storage/influx/cq"
@@ -48,6 +49,21 @@ const (
 
 	bigVolumeThresholdFlag = "big-volume-threshold"
 	defaultBigVolume       = 100
+
+	reorgCheckDepthFlag    = "reorg-check-depth"
+	defaultReorgCheckDepth = 100
+
+	streamFlag                = "stream"
+	streamPollIntervalFlag    = "stream-poll-interval"
+	defaultStreamPollInterval = 5 * time.Second
+
+	queueFlag                = "queue"
+	queuePlanFlag            = "queue-plan"
+	crawlerIDFlag            = "crawler-id"
+	leaseDurationFlag        = "lease-duration"
+	defaultLeaseDuration     = 10 * time.Minute
+	queuePollIntervalFlag    = "queue-poll-interval"
+	defaultQueuePollInterval = 5 * time.Second
 )
 
 func main() {
@@ -104,6 +120,50 @@ func main() {
 			EnvVar: "BIG_VOLUME_THRESHOLD",
 			Value:  defaultBigVolume,
 		},
+		cli.Uint64Flag{
+			Name:   reorgCheckDepthFlag,
+			Usage:  "The number of blocks behind last crawled block to verify against canonical chain, 0 to disable reorg detection",
+			EnvVar: "REORG_CHECK_DEPTH",
+			Value:  defaultReorgCheckDepth,
+		},
+		cli.BoolFlag{
+			Name:   streamFlag,
+			Usage:  "Subscribe to trade events instead of polling block ranges, requires a websocket or IPC ethereum node",
+			EnvVar: "STREAM",
+		},
+		cli.DurationFlag{
+			Name:   streamPollIntervalFlag,
+			Usage:  "The interval to check for newly confirmed blocks in streaming mode",
+			EnvVar: "STREAM_POLL_INTERVAL",
+			Value:  defaultStreamPollInterval,
+		},
+		cli.BoolFlag{
+			Name:   queueFlag,
+			Usage:  "Crawl block ranges leased from the crawl queue in postgres, so several crawlers can share the work",
+			EnvVar: "QUEUE",
+		},
+		cli.BoolTFlag{
+			Name:   queuePlanFlag,
+			Usage:  "Enqueue new block ranges in queue mode, only one of crawlers sharing the queue should enable it",
+			EnvVar: "QUEUE_PLAN",
+		},
+		cli.StringFlag{
+			Name:   crawlerIDFlag,
+			Usage:  "The unique name of the crawler holding leases in queue mode, suffixed by the worker index, default to host name and process ID",
+			EnvVar: "CRAWLER_ID",
+		},
+		cli.DurationFlag{
+			Name:   leaseDurationFlag,
+			Usage:  "The duration a block range is leased to the crawler in queue mode, the lease is renewed while the range is crawled",
+			EnvVar: "LEASE_DURATION",
+			Value:  defaultLeaseDuration,
+		},
+		cli.DurationFlag{
+			Name:   queuePollIntervalFlag,
+			Usage:  "The interval to check for new block ranges when the crawl queue is empty",
+			EnvVar: "QUEUE_POLL_INTERVAL",
+			Value:  defaultQueuePollInterval,
+		},
 	)
 
 	app.Flags = append(app.Flags, storage.NewCliFlags()...)
@@ -113,6 +173,7 @@ func main() {
 	app.Flags = append(app.Flags, blockchain.NewEthereumNodeFlags())
 	app.Flags = append(app.Flags, cq.NewCQFlags()...)
 	app.Flags = append(app.Flags, etherscan.NewCliFlags()...)
+	app.Flags = append(app.Flags, tradelogs.NewInternalTxCliFlags()...)
 	if err := app.Run(os.Args); err != nil {
 		log.Fatal(err)
 	}
@@ -217,6 +278,12 @@ func run(c *cli.Context) error {
 		return err
 	}
 	networkProxyAddr := contracts.ProxyContractAddress().MustGetOneFromContext(c)
+	if c.Bool(streamFlag) {
+		return runStreaming(sugar, c, storageInterface, etherscanClient, networkProxyAddr)
+	}
+	if c.Bool(queueFlag) {
+		return runQueue(sugar, c, storageInterface, etherscanClient, networkProxyAddr)
+	}
 	maxWorkers := c.Int(maxWorkersFlag)
 	maxBlocks := c.Int(maxBlocksFlag)
 	attempts := c.Int(attemptsFlag) // exit if failed to fetch logs after attempts times
@@ -238,6 +305,7 @@ func run(c *cli.Context) error {
 
 		requiredWorkers := requiredWorkers(fromBlock, toBlock, maxBlocks, maxWorkers)
 		startingBlocks := deployment.MustGetStartingBlocksFromContext(c)
+		splitPoints := tradelogs.SplitPoints(startingBlocks)
 
 		bigVolume := c.Float64(bigVolumeThresholdFlag)
 		p := workers.NewPool(sugar, requiredWorkers, storageInterface, float32(bigVolume))
@@ -246,34 +314,23 @@ func run(c *cli.Context) error {
 			"to_block", toBlock.String(),
 			"workers", requiredWorkers,
 			"max_blocks", maxBlocks,
-			"starting_blocks", startingBlocks)
+			"split_points", splitPoints)
 
 		go func(fromBlock, toBlock, maxBlocks int64) {
 			var jobOrder = p.GetLastCompleteJobOrder()
 			for i := fromBlock; i <= toBlock; i += maxBlocks {
 				end := mathutil.MinInt64(i+maxBlocks, toBlock)
-				switch {
-				case uint64(end) >= startingBlocks.V4() && uint64(i) < startingBlocks.V4():
-					jobOrder++
-					p.Run(workers.NewFetcherJob(c, jobOrder, big.NewInt(i), big.NewInt(int64(startingBlocks.V4())), attempts, etherscanClient, networkProxyAddr))
-					jobOrder++
-					p.Run(workers.NewFetcherJob(c, jobOrder, big.NewInt(int64(startingBlocks.V4())), big.NewInt(end), attempts, etherscanClient, networkProxyAddr))
-				//if job start at block v2 and end at block v3 then split job
-				case uint64(end) >= startingBlocks.V3() && uint64(i) < startingBlocks.V3():
-					jobOrder++
-					p.Run(workers.NewFetcherJob(c, jobOrder, big.NewInt(i), big.NewInt(int64(startingBlocks.V3())), attempts, etherscanClient, networkProxyAddr))
-					jobOrder++
-					p.Run(workers.NewFetcherJob(c, jobOrder, big.NewInt(int64(startingBlocks.V3())), big.NewInt(end), attempts, etherscanClient, networkProxyAddr))
-				//if job start at block v1 and end at block v2 then split job
-				case uint64(end) >= startingBlocks.V2() && uint64(i) < startingBlocks.V2():
-					jobOrder++
-					p.Run(workers.NewFetcherJob(c, jobOrder, big.NewInt(i), big.NewInt(int64(startingBlocks.V2())), attempts, etherscanClient, networkProxyAddr))
-					jobOrder++
-					p.Run(workers.NewFetcherJob(c, jobOrder, big.NewInt(int64(startingBlocks.V2())), big.NewInt(end), attempts, etherscanClient, networkProxyAddr))
-				default:
-					jobOrder++
-					p.Run(workers.NewFetcherJob(c, jobOrder, big.NewInt(i), big.NewInt(end), attempts, etherscanClient, networkProxyAddr))
+				// a job is decoded with the decoder of its from block, split it at starting blocks of newer versions
+				from := i
+				for _, splitPoint := range splitPoints {
+					if uint64(from) < splitPoint && splitPoint <= uint64(end) {
+						jobOrder++
+						p.Run(workers.NewFetcherJob(c, jobOrder, big.NewInt(from), big.NewInt(int64(splitPoint)), attempts, etherscanClient, networkProxyAddr))
+						from = int64(splitPoint)
+					}
 				}
+				jobOrder++
+				p.Run(workers.NewFetcherJob(c, jobOrder, big.NewInt(from), big.NewInt(end), attempts, etherscanClient, networkProxyAddr))
 			}
 			for p.GetLastCompleteJobOrder() < jobOrder {
 				time.Sleep(time.Second)
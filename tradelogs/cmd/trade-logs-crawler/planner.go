@@ -14,6 +14,7 @@ import (
 	"github.com/KyberNetwork/reserve-stats/lib/app"
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
 	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/reorg"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
 )
 
@@ -25,12 +26,14 @@ type crawlPlanner struct {
 
 	ethClient *ethclient.Client
 	st        storage.Interface
+	detector  *reorg.Detector // nil if reorg detection is disabled
 
 	fromBlock *big.Int
 	toBlock   *big.Int
 
 	confirmations int64 // number of block confirmations before fetching
 	delay         time.Duration
+	queue         bool // plan from the last queued block instead of the last stored one
 
 	completed bool
 }
@@ -58,18 +61,40 @@ func newCrawlerPlanner(sugar *zap.SugaredLogger, c *cli.Context, st storage.Inte
 			return nil, err
 		}
 	}
+	var detector *reorg.Detector
+	if depth := c.Uint64(reorgCheckDepthFlag); depth > 0 {
+		detector = reorg.NewDetector(sugar, ethClient, st, depth)
+	}
+
 	return &crawlPlanner{
 		sugar:         sugar,
 		ethClient:     ethClient,
 		st:            st,
+		detector:      detector,
 		fromBlock:     fromBlock,
 		toBlock:       toBlock,
 		confirmations: c.Int64(blockConfirmationsFlag),
 		delay:         c.Duration(delayFlag),
+		queue:         c.Bool(queueFlag),
 		completed:     false,
 	}, nil
 }
 
+// lastBlock returns the block to continue crawling from, 0 if nothing is crawled. In queue mode, ranges
+// might be queued but not crawled yet, so it is the last queued block if any.
+func (p *crawlPlanner) lastBlock() (int64, error) {
+	if p.queue {
+		lastQueued, err := p.st.LastQueuedBlock()
+		if err != nil {
+			return 0, err
+		}
+		if lastQueued > 0 {
+			return int64(lastQueued), nil
+		}
+	}
+	return p.st.LastBlock()
+}
+
 // next returns next from/to block to fetch trade logs.
 // This method will return errEOF if there is no next block range to fetch.
 func (p *crawlPlanner) next() (*big.Int, *big.Int, error) {
@@ -84,7 +109,7 @@ func (p *crawlPlanner) next() (*big.Int, *big.Int, error) {
 
 	if p.fromBlock == nil {
 		var lastBlock int64
-		if lastBlock, err = p.st.LastBlock(); err != nil {
+		if lastBlock, err = p.lastBlock(); err != nil {
 			return nil, nil, err
 		}
 		if lastBlock == 0 {
@@ -94,6 +119,21 @@ func (p *crawlPlanner) next() (*big.Int, *big.Int, error) {
 		p.fromBlock = big.NewInt(lastBlock)
 	}
 
+	// blocks crawled in previous runs might be orphaned, crawl again from the fork block if so
+	if p.toBlock == nil && p.detector != nil {
+		forkBlock, reorged, err := p.detector.Check(p.fromBlock.Uint64())
+		if err != nil {
+			return nil, nil, err
+		}
+		if reorged && forkBlock < p.fromBlock.Uint64() {
+			logger.Infow("re-crawling orphaned blocks",
+				"from_block", p.fromBlock,
+				"fork_block", forkBlock,
+			)
+			p.fromBlock = new(big.Int).SetUint64(forkBlock)
+		}
+	}
+
 	if p.fromBlock != nil && p.toBlock != nil && p.fromBlock.Cmp(p.toBlock) > 0 {
 		return nil, nil, errors.New("fromBlock is bigger than toBlock")
 	}
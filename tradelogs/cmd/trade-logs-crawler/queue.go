@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/nanmu42/etherscan-api"
+	"github.com/urfave/cli"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/broadcast"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
+	"github.com/KyberNetwork/reserve-stats/tradelogs"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/workers"
+)
+
+// crawlerID returns the name of the crawler holding leases of the crawl queue.
+func crawlerID(c *cli.Context) (string, error) {
+	if id := c.String(crawlerIDFlag); id != "" {
+		return id, nil
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid()), nil
+}
+
+// runQueue crawls trade logs in queue mode, block ranges are persisted in the crawl queue and leased by
+// workers of all crawlers sharing it. Ranges failed max attempts times are dead-lettered to be retried
+// with the admin API, instead of stopping the crawler.
+func runQueue(sugar *zap.SugaredLogger, c *cli.Context, st storage.Interface,
+	etherscanClient *etherscan.Client, networkProxyAddr ethereum.Address) error {
+	if c.Duration(leaseDurationFlag) <= 0 {
+		return fmt.Errorf("invalid lease duration %s, it must be positive", c.Duration(leaseDurationFlag))
+	}
+	owner, err := crawlerID(c)
+	if err != nil {
+		return err
+	}
+	bc, err := broadcast.NewClientFromContext(sugar, c)
+	if err != nil {
+		return err
+	}
+	crawler, err := workers.NewCrawlerFromContext(sugar, c, bc, etherscanClient, networkProxyAddr)
+	if err != nil {
+		return err
+	}
+
+	if c.BoolT(queuePlanFlag) {
+		if c.Int(maxBlocksFlag) <= 0 {
+			return fmt.Errorf("invalid max blocks %d, it must be positive", c.Int(maxBlocksFlag))
+		}
+		planner, err := newCrawlerPlanner(sugar, c, st)
+		if err != nil {
+			return err
+		}
+		maxBlocks := uint64(c.Int(maxBlocksFlag))
+		splitPoints := tradelogs.SplitPoints(deployment.MustGetStartingBlocksFromContext(c))
+		go func() {
+			for {
+				fromBlock, toBlock, err := planner.next()
+				if err == errEOF {
+					sugar.Info("all planned block ranges are queued")
+					return
+				} else if err != nil {
+					sugar.Fatalw("failed to plan block ranges", "error", err)
+				}
+				ranges := workers.SplitRanges(fromBlock.Uint64(), toBlock.Uint64(), maxBlocks, splitPoints)
+				if err = st.EnqueueCrawlJobs(ranges); err != nil {
+					sugar.Fatalw("failed to enqueue block ranges", "error", err)
+				}
+				sugar.Infow("queued block ranges",
+					"from_block", fromBlock.String(),
+					"to_block", toBlock.String(),
+					"ranges", len(ranges))
+			}
+		}()
+	}
+
+	sugar.Infow("crawling block ranges of the crawl queue", "crawler_id", owner)
+	workers.NewQueueWorker(sugar, st, crawler, owner,
+		c.Duration(leaseDurationFlag),
+		uint64(c.Int(attemptsFlag)),
+		float32(c.Float64(bigVolumeThresholdFlag)),
+		c.Duration(queuePollIntervalFlag),
+	).Run(c.Int(maxWorkersFlag))
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/nanmu42/etherscan-api"
+	"github.com/urfave/cli"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/broadcast"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
+	"github.com/KyberNetwork/reserve-stats/tradelogs"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/workers"
+)
+
+// runStreaming crawls trade logs in streaming mode, trades are saved as soon as their blocks are confirmed.
+func runStreaming(sugar *zap.SugaredLogger, c *cli.Context, st storage.Interface,
+	etherscanClient *etherscan.Client, networkProxyAddr ethereum.Address) error {
+	var fromBlock uint64
+
+	bc, err := broadcast.NewClientFromContext(sugar, c)
+	if err != nil {
+		return err
+	}
+	crawler, err := workers.NewCrawlerFromContext(sugar, c, bc, etherscanClient, networkProxyAddr)
+	if err != nil {
+		return err
+	}
+	// subscription requires the ethereum node to be a websocket or IPC endpoint
+	client, err := blockchain.NewEthereumClientFromFlag(c)
+	if err != nil {
+		return err
+	}
+
+	if c.String(fromBlockFlag) != "" {
+		block, err := app.ParseBigIntFlag(c, fromBlockFlag)
+		if err != nil {
+			return err
+		}
+		fromBlock = block.Uint64()
+	} else {
+		lastBlock, err := st.LastBlock()
+		if err != nil {
+			return err
+		}
+		fromBlock = uint64(lastBlock)
+		// only the latest version is streamed, it is the last split point; older blocks are
+		// not skipped silently, they must be crawled in polling or queue mode first
+		splitPoints := tradelogs.SplitPoints(deployment.MustGetStartingBlocksFromContext(c))
+		if n := len(splitPoints); n != 0 && fromBlock < splitPoints[n-1] {
+			return fmt.Errorf("last stored block %d is before starting block %d of the latest network version, "+
+				"backfill the range with the polling or queue crawler first, or set --%s explicitly",
+				fromBlock, splitPoints[n-1], fromBlockFlag)
+		}
+	}
+
+	bigVolume := float32(c.Float64(bigVolumeThresholdFlag))
+	streamer := tradelogs.NewStreamer(sugar, crawler, client,
+		uint64(c.Int64(blockConfirmationsFlag)),
+		uint64(c.Int(maxBlocksFlag)),
+		c.Duration(streamPollIntervalFlag),
+	)
+	sugar.Infow("streaming trade logs", "from_block", fromBlock)
+	return streamer.Run(context.Background(), fromBlock, func(result *common.CrawlResult, from, to uint64) error {
+		if err := st.SaveTradeLogs(result); err != nil {
+			return err
+		}
+		return st.SaveBigTrades(bigVolume, from)
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/urfave/cli"
+
+	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/export"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+)
+
+const (
+	fromBlockFlag = "from-block"
+	toBlockFlag   = "to-block"
+	formatFlag    = "format"
+	outputFlag    = "output"
+	pageSizeFlag  = "page-size"
+
+	defaultPageSize = 1000
+)
+
+func main() {
+	app := libapp.NewApp()
+	app.Name = "Trade Logs Export"
+	app.Usage = "Export trade logs of a block range to CSV, NDJSON or Parquet"
+	app.Version = "0.0.1"
+	app.Action = run
+
+	app.Flags = append(app.Flags,
+		cli.Uint64Flag{
+			Name:   fromBlockFlag,
+			Usage:  "first block to export",
+			EnvVar: "FROM_BLOCK",
+		},
+		cli.Uint64Flag{
+			Name:   toBlockFlag,
+			Usage:  "last block to export, default to the last crawled block",
+			EnvVar: "TO_BLOCK",
+		},
+		cli.StringFlag{
+			Name:   formatFlag,
+			Usage:  "output format: csv, ndjson or parquet",
+			EnvVar: "FORMAT",
+			Value:  string(export.CSV),
+		},
+		cli.StringFlag{
+			Name:   outputFlag,
+			Usage:  "output file, default to standard output",
+			EnvVar: "OUTPUT",
+		},
+		cli.Uint64Flag{
+			Name:   pageSizeFlag,
+			Usage:  "number of trade logs loaded from database at once",
+			EnvVar: "PAGE_SIZE",
+			Value:  defaultPageSize,
+		},
+	)
+	app.Flags = append(app.Flags, storage.NewCliFlags()...)
+	app.Flags = append(app.Flags, influxdb.NewCliFlags()...)
+	app.Flags = append(app.Flags, libapp.NewPostgreSQLFlags(storage.PostgresDefaultDB)...)
+	app.Flags = append(app.Flags, blockchain.NewEthereumNodeFlags())
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(c *cli.Context) (err error) {
+	if err := libapp.Validate(c); err != nil {
+		return err
+	}
+
+	sugar, flush, err := libapp.NewSugaredLogger(c)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	format, err := export.ParseFormat(c.String(formatFlag))
+	if err != nil {
+		return err
+	}
+
+	tokenAmountFormatter, err := blockchain.NewToKenAmountFormatterFromContext(c)
+	if err != nil {
+		return err
+	}
+	st, err := storage.NewStorageInterfaceFromContext(sugar, c, tokenAmountFormatter)
+	if err != nil {
+		return err
+	}
+	symbolResolver, err := blockchain.NewTokenInfoGetterFromContext(c, st)
+	if err != nil {
+		return err
+	}
+
+	fromBlock, toBlock := c.Uint64(fromBlockFlag), c.Uint64(toBlockFlag)
+	if toBlock == 0 {
+		lastBlock, err := st.LastBlock()
+		if err != nil {
+			return err
+		}
+		toBlock = uint64(lastBlock)
+		// the last block is logged so the same export can be reproduced later
+		sugar.Infow("no to block provided, using last crawled block", "to_block", toBlock)
+	}
+	if fromBlock > toBlock {
+		return fmt.Errorf("from block %d is greater than to block %d", fromBlock, toBlock)
+	}
+
+	var out io.Writer = os.Stdout
+	if output := c.String(outputFlag); output != "" {
+		f, fErr := os.Create(output)
+		if fErr != nil {
+			return fErr
+		}
+		// err is the named result, the close error is returned if nothing else failed
+		defer func() {
+			if cErr := f.Close(); cErr != nil && err == nil {
+				err = cErr
+			}
+		}()
+		out = f
+	}
+
+	w, err := export.NewWriter(format, out)
+	if err != nil {
+		return err
+	}
+	exporter := export.NewExporter(sugar, st,
+		export.WithPageSize(c.Uint64(pageSizeFlag)),
+		export.WithSymbolResolver(symbolResolver))
+	total, err := exporter.Export(common.TradeLogFilter{FromBlock: fromBlock, ToBlock: toBlock}, w)
+	if err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	sugar.Infow("export completed", "from_block", fromBlock, "to_block", toBlock, "format", format, "total", total)
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/urfave/cli"
+
+	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/lib/tokenrate"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/fiat"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+)
+
+const (
+	providerFlag   = "provider"
+	fromBlockFlag  = "from-block"
+	toBlockFlag    = "to-block"
+	batchSizeFlag  = "batch-size"
+	setDefaultFlag = "set-default"
+
+	defaultBatchSize = 1000
+)
+
+func main() {
+	app := libapp.NewApp()
+	app.Name = "Trade Logs Fiat Backfill"
+	app.Usage = "Re-price trades of a block or time range with the ETH/USD rates of a provider"
+	app.Version = "0.0.1"
+	app.Action = run
+
+	app.Flags = append(app.Flags,
+		cli.StringFlag{
+			Name:   providerFlag,
+			Usage:  "The ETH/USD rate provider to re-price trades with: coingecko or binance",
+			EnvVar: "PROVIDER",
+		},
+		cli.Uint64Flag{
+			Name:   fromBlockFlag,
+			Usage:  "Re-price trades from block",
+			EnvVar: "FROM_BLOCK",
+		},
+		cli.Uint64Flag{
+			Name:   toBlockFlag,
+			Usage:  "Re-price trades to block",
+			EnvVar: "TO_BLOCK",
+		},
+		cli.IntFlag{
+			Name:   batchSizeFlag,
+			Usage:  "The number of trades saved at once",
+			EnvVar: "BATCH_SIZE",
+			Value:  defaultBatchSize,
+		},
+		cli.BoolFlag{
+			Name:   setDefaultFlag,
+			Usage:  "Replace the rates fixed at crawl time, otherwise rates are only stored next to other providers",
+			EnvVar: "SET_DEFAULT",
+		},
+	)
+	app.Flags = append(app.Flags, timeutil.NewMilliTimeRangeCliFlags()...)
+	app.Flags = append(app.Flags, storage.NewCliFlags()...)
+	app.Flags = append(app.Flags, influxdb.NewCliFlags()...)
+	app.Flags = append(app.Flags, libapp.NewPostgreSQLFlags(storage.PostgresDefaultDB)...)
+	app.Flags = append(app.Flags, blockchain.NewEthereumNodeFlags())
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(c *cli.Context) error {
+	if err := libapp.Validate(c); err != nil {
+		return err
+	}
+
+	sugar, flush, err := libapp.NewSugaredLogger(c)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	batchSize := c.Int(batchSizeFlag)
+	if batchSize <= 0 {
+		return errors.New("batch size must be positive")
+	}
+	from, err := timeutil.FromTimeMillisFromContext(c)
+	if err != nil {
+		return err
+	}
+	to, err := timeutil.ToTimeMillisFromContext(c)
+	if err != nil {
+		return err
+	}
+	fromBlock, toBlock := c.Uint64(fromBlockFlag), c.Uint64(toBlockFlag)
+	if fromBlock == 0 && toBlock == 0 && from.IsZero() && to.IsZero() {
+		return errors.New("a block or time range is required")
+	}
+
+	provider, err := tokenrate.NewETHUSDRateProvider(sugar, c.String(providerFlag))
+	if err != nil {
+		return err
+	}
+	tokenAmountFormatter, err := blockchain.NewToKenAmountFormatterFromContext(c)
+	if err != nil {
+		return err
+	}
+	st, err := storage.NewStorageInterfaceFromContext(sugar, c, tokenAmountFormatter)
+	if err != nil {
+		return err
+	}
+	return fiat.NewBackfiller(sugar, st, provider, batchSize, c.Bool(setDefaultFlag)).Run(fromBlock, toBlock, from, to)
+}
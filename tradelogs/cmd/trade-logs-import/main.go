@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/export"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/importer"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+)
+
+const (
+	inputFlag          = "input"
+	formatFlag         = "format"
+	batchSizeFlag      = "batch-size"
+	rejectedOutputFlag = "rejected-output"
+
+	defaultBatchSize = 100
+)
+
+func main() {
+	app := libapp.NewApp()
+	app.Name = "Trade Logs Import"
+	app.Usage = "Import trade logs from NDJSON or CSV files"
+	app.Version = "0.0.1"
+	app.Action = run
+
+	app.Flags = append(app.Flags,
+		cli.StringSliceFlag{
+			Name:   inputFlag,
+			Usage:  "input files to import, can be repeated",
+			EnvVar: "INPUT",
+		},
+		cli.StringFlag{
+			Name:   formatFlag,
+			Usage:  "input format: ndjson or csv, default to detect by file extension",
+			EnvVar: "FORMAT",
+		},
+		cli.IntFlag{
+			Name:   batchSizeFlag,
+			Usage:  "number of trade logs saved in a transaction",
+			EnvVar: "BATCH_SIZE",
+			Value:  defaultBatchSize,
+		},
+		cli.StringFlag{
+			Name:   rejectedOutputFlag,
+			Usage:  "file to write rejected records as NDJSON, rejected records are logged if not provided",
+			EnvVar: "REJECTED_OUTPUT",
+		},
+	)
+	app.Flags = append(app.Flags, storage.NewCliFlags()...)
+	app.Flags = append(app.Flags, influxdb.NewCliFlags()...)
+	app.Flags = append(app.Flags, libapp.NewPostgreSQLFlags(storage.PostgresDefaultDB)...)
+	app.Flags = append(app.Flags, blockchain.NewEthereumNodeFlags())
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// formatOf returns the format of input file, detected by file extension if not provided.
+func formatOf(path, format string) (export.Format, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			format = string(export.CSV)
+		case ".ndjson", ".jsonl", ".json":
+			format = string(export.NDJSON)
+		default:
+			return "", fmt.Errorf("can not detect format of %s, please provide --%s", path, formatFlag)
+		}
+	}
+	return export.ParseFormat(format)
+}
+
+func run(c *cli.Context) (err error) {
+	if err := libapp.Validate(c); err != nil {
+		return err
+	}
+
+	sugar, flush, err := libapp.NewSugaredLogger(c)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	inputs := c.StringSlice(inputFlag)
+	if len(inputs) == 0 {
+		return fmt.Errorf("no input file provided, please provide --%s", inputFlag)
+	}
+
+	tokenAmountFormatter, err := blockchain.NewToKenAmountFormatterFromContext(c)
+	if err != nil {
+		return err
+	}
+	st, err := storage.NewStorageInterfaceFromContext(sugar, c, tokenAmountFormatter)
+	if err != nil {
+		return err
+	}
+
+	options := []importer.Option{importer.WithBatchSize(c.Int(batchSizeFlag))}
+	if output := c.String(rejectedOutputFlag); output != "" {
+		f, fErr := os.Create(output)
+		if fErr != nil {
+			return fErr
+		}
+		// err is the named result, the close error is returned if nothing else failed
+		defer func() {
+			if cErr := f.Close(); cErr != nil && err == nil {
+				err = cErr
+			}
+		}()
+		enc := json.NewEncoder(f)
+		options = append(options, importer.WithRejectionHandler(func(r importer.Rejection) {
+			if eErr := enc.Encode(r); eErr != nil {
+				sugar.Errorw("failed to write rejected record", "line", r.Line, "error", eErr)
+			}
+		}))
+	}
+	im := importer.NewImporter(sugar, st, tokenAmountFormatter, options...)
+
+	for _, input := range inputs {
+		report, err := importFile(im, input, c.String(formatFlag))
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %v", input, err)
+		}
+		sugar.Infow("imported trade logs", "input", input, "imported", report.Imported, "rejected", report.Rejected)
+	}
+	return nil
+}
+
+func importFile(im *importer.Importer, path, format string) (importer.Report, error) {
+	f, err := formatOf(path, format)
+	if err != nil {
+		return importer.Report{}, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return importer.Report{}, err
+	}
+	defer file.Close()
+
+	r, err := importer.NewReader(f, file)
+	if err != nil {
+		return importer.Report{}, err
+	}
+	return im.Import(r)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/urfave/cli"
+
+	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/mev"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+)
+
+const (
+	fromBlockFlag = "from-block"
+	toBlockFlag   = "to-block"
+	batchSizeFlag = "batch-size"
+
+	defaultBatchSize = 1000
+)
+
+func main() {
+	app := libapp.NewApp()
+	app.Name = "Trade Logs MEV Detector"
+	app.Usage = "Tag trade logs probably sandwiched by front run and back run trades"
+	app.Version = "0.0.1"
+	app.Action = run
+
+	app.Flags = append(app.Flags,
+		cli.Uint64Flag{
+			Name:   fromBlockFlag,
+			Usage:  "Detect sandwiched trades from block",
+			EnvVar: "FROM_BLOCK",
+		},
+		cli.Uint64Flag{
+			Name:   toBlockFlag,
+			Usage:  "Detect sandwiched trades to block, default to the last crawled block",
+			EnvVar: "TO_BLOCK",
+		},
+		cli.Uint64Flag{
+			Name:   batchSizeFlag,
+			Usage:  "The number of blocks processed at once",
+			EnvVar: "BATCH_SIZE",
+			Value:  defaultBatchSize,
+		},
+	)
+	app.Flags = append(app.Flags, storage.NewCliFlags()...)
+	app.Flags = append(app.Flags, influxdb.NewCliFlags()...)
+	app.Flags = append(app.Flags, libapp.NewPostgreSQLFlags(storage.PostgresDefaultDB)...)
+	app.Flags = append(app.Flags, blockchain.NewEthereumNodeFlags())
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(c *cli.Context) error {
+	if err := libapp.Validate(c); err != nil {
+		return err
+	}
+
+	sugar, flush, err := libapp.NewSugaredLogger(c)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	batchSize := c.Uint64(batchSizeFlag)
+	if batchSize == 0 {
+		return errors.New("batch size must be positive")
+	}
+	fromBlock := c.Uint64(fromBlockFlag)
+	if fromBlock == 0 {
+		return errors.New("from block is required")
+	}
+
+	tokenAmountFormatter, err := blockchain.NewToKenAmountFormatterFromContext(c)
+	if err != nil {
+		return err
+	}
+	st, err := storage.NewStorageInterfaceFromContext(sugar, c, tokenAmountFormatter)
+	if err != nil {
+		return err
+	}
+
+	toBlock := c.Uint64(toBlockFlag)
+	if toBlock == 0 {
+		lastBlock, err := st.LastBlock()
+		if err != nil {
+			return err
+		}
+		toBlock = uint64(lastBlock)
+	}
+	if fromBlock > toBlock {
+		return fmt.Errorf("from block %d is after to block %d", fromBlock, toBlock)
+	}
+
+	return mev.NewDetector(sugar, st, batchSize).Run(fromBlock, toBlock)
+}
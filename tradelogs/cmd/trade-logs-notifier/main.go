@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+
+	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/notifier"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+)
+
+const (
+	intervalFlag = "interval"
+
+	defaultInterval = time.Minute
+)
+
+func main() {
+	app := libapp.NewApp()
+	app.Name = "Trade Logs Notifier"
+	app.Usage = "Send big trades to configured webhook, Slack, Telegram and file sinks"
+	app.Version = "0.0.1"
+	app.Action = run
+
+	app.Flags = append(app.Flags,
+		cli.DurationFlag{
+			Name:   intervalFlag,
+			Usage:  "The interval between checks for big trades to send",
+			EnvVar: "INTERVAL",
+			Value:  defaultInterval,
+		},
+	)
+	app.Flags = append(app.Flags, notifier.NewCliFlags()...)
+	app.Flags = append(app.Flags, storage.NewCliFlags()...)
+	app.Flags = append(app.Flags, influxdb.NewCliFlags()...)
+	app.Flags = append(app.Flags, libapp.NewPostgreSQLFlags(storage.PostgresDefaultDB)...)
+	app.Flags = append(app.Flags, blockchain.NewEthereumNodeFlags())
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(c *cli.Context) error {
+	if err := libapp.Validate(c); err != nil {
+		return err
+	}
+
+	sugar, flush, err := libapp.NewSugaredLogger(c)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	interval := c.Duration(intervalFlag)
+	if interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+
+	tokenAmountFormatter, err := blockchain.NewToKenAmountFormatterFromContext(c)
+	if err != nil {
+		return err
+	}
+	st, err := storage.NewStorageInterfaceFromContext(sugar, c, tokenAmountFormatter)
+	if err != nil {
+		return err
+	}
+	n, err := notifier.NewNotifierFromContext(c, sugar, st)
+	if err != nil {
+		return err
+	}
+	n.Run(interval)
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli"
+
+	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+)
+
+const (
+	rebateWalletFlag = "rebate-wallet"
+	formatFlag       = "format"
+	outputFlag       = "output"
+
+	jsonFormat = "json"
+	csvFormat  = "csv"
+)
+
+func main() {
+	app := libapp.NewApp()
+	app.Name = "Trade Logs Rebate Statement"
+	app.Usage = "Report rebates paid to a rebate wallet in a time range"
+	app.Version = "0.0.1"
+	app.Action = run
+
+	app.Flags = append(app.Flags,
+		cli.StringFlag{
+			Name:   rebateWalletFlag,
+			Usage:  "rebate wallet address",
+			EnvVar: "REBATE_WALLET",
+		},
+		cli.StringFlag{
+			Name:   formatFlag,
+			Usage:  "output format: json for the full statement or csv for line items",
+			EnvVar: "FORMAT",
+			Value:  jsonFormat,
+		},
+		cli.StringFlag{
+			Name:   outputFlag,
+			Usage:  "output file, default to standard output",
+			EnvVar: "OUTPUT",
+		},
+	)
+	app.Flags = append(app.Flags, timeutil.NewMilliTimeRangeCliFlags()...)
+	app.Flags = append(app.Flags, storage.NewCliFlags()...)
+	app.Flags = append(app.Flags, influxdb.NewCliFlags()...)
+	app.Flags = append(app.Flags, libapp.NewPostgreSQLFlags(storage.PostgresDefaultDB)...)
+	app.Flags = append(app.Flags, blockchain.NewEthereumNodeFlags())
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeCSV(w io.Writer, statement common.RebateStatement) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"timestamp", "block_number", "tx_hash", "trade_index", "fee_index",
+		"reserves", "rebate_percent_bps", "eth_amount", "usd_amount",
+	}); err != nil {
+		return err
+	}
+	for _, item := range statement.Items {
+		var reserves []string
+		for _, reserve := range item.Reserves {
+			reserves = append(reserves, reserve.Hex())
+		}
+		if err := cw.Write([]string{
+			strconv.FormatUint(item.Timestamp, 10),
+			strconv.FormatUint(item.BlockNumber, 10),
+			item.TxHash.Hex(),
+			strconv.FormatUint(uint64(item.TradeIndex), 10),
+			strconv.FormatUint(uint64(item.FeeIndex), 10),
+			strings.Join(reserves, ";"),
+			strconv.FormatUint(item.PercentBps, 10),
+			strconv.FormatFloat(item.ETHAmount, 'f', -1, 64),
+			strconv.FormatFloat(item.USDAmount, 'f', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func run(c *cli.Context) (err error) {
+	if err := libapp.Validate(c); err != nil {
+		return err
+	}
+
+	sugar, flush, err := libapp.NewSugaredLogger(c)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	walletHex := c.String(rebateWalletFlag)
+	if !ethereum.IsHexAddress(walletHex) {
+		return fmt.Errorf("invalid rebate wallet: %q", walletHex)
+	}
+	format := c.String(formatFlag)
+	if format != jsonFormat && format != csvFormat {
+		return fmt.Errorf("unsupported format: %q", format)
+	}
+	from, err := timeutil.FromTimeMillisFromContext(c)
+	if err != nil {
+		return err
+	}
+	if from.IsZero() {
+		return errors.New("from time is required")
+	}
+	to, err := timeutil.ToTimeMillisFromContext(c)
+	if err != nil {
+		return err
+	}
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.After(to) {
+		return fmt.Errorf("from time %s is after to time %s", from, to)
+	}
+
+	tokenAmountFormatter, err := blockchain.NewToKenAmountFormatterFromContext(c)
+	if err != nil {
+		return err
+	}
+	st, err := storage.NewStorageInterfaceFromContext(sugar, c, tokenAmountFormatter)
+	if err != nil {
+		return err
+	}
+
+	statement, err := st.GetRebateStatement(ethereum.HexToAddress(walletHex), from, to)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if output := c.String(outputFlag); output != "" {
+		f, fErr := os.Create(output)
+		if fErr != nil {
+			return fErr
+		}
+		// err is the named result, the close error is returned if nothing else failed
+		defer func() {
+			if cErr := f.Close(); cErr != nil && err == nil {
+				err = cErr
+			}
+		}()
+		out = f
+	}
+
+	switch format {
+	case csvFormat:
+		err = writeCSV(out, statement)
+	default:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(statement)
+	}
+	if err != nil {
+		return err
+	}
+	sugar.Infow("rebate statement completed",
+		"rebate_wallet", statement.RebateWallet.Hex(),
+		"items", len(statement.Items),
+		"total_eth", statement.TotalETH,
+		"total_usd", statement.TotalUSD)
+	return nil
+}
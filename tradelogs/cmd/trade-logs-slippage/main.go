@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/urfave/cli"
+
+	libapp "github.com/KyberNetwork/reserve-stats/lib/app"
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
+	"github.com/KyberNetwork/reserve-stats/lib/influxdb"
+	"github.com/KyberNetwork/reserve-stats/reserverates/storage/postgres"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/slippage"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
+)
+
+const (
+	fromBlockFlag            = "from-block"
+	toBlockFlag              = "to-block"
+	batchSizeFlag            = "batch-size"
+	reserveRatesDatabaseFlag = "reserve-rates-database"
+
+	defaultBatchSize            = 1000
+	defaultReserveRatesDatabase = "reserve_rates"
+)
+
+func main() {
+	app := libapp.NewApp()
+	app.Name = "Trade Logs Slippage"
+	app.Usage = "Calculate slippage of splits from rates quoted by reserves at the previous block"
+	app.Version = "0.0.1"
+	app.Action = run
+
+	app.Flags = append(app.Flags,
+		cli.Uint64Flag{
+			Name:   fromBlockFlag,
+			Usage:  "Calculate slippage of splits from block",
+			EnvVar: "FROM_BLOCK",
+		},
+		cli.Uint64Flag{
+			Name:   toBlockFlag,
+			Usage:  "Calculate slippage of splits to block, default to the last crawled block",
+			EnvVar: "TO_BLOCK",
+		},
+		cli.Uint64Flag{
+			Name:   batchSizeFlag,
+			Usage:  "The number of blocks processed at once",
+			EnvVar: "BATCH_SIZE",
+			Value:  defaultBatchSize,
+		},
+		cli.StringFlag{
+			Name:   reserveRatesDatabaseFlag,
+			Usage:  "The database of reserve rates on the same PostgreSQL server",
+			EnvVar: "RESERVE_RATES_DATABASE",
+			Value:  defaultReserveRatesDatabase,
+		},
+	)
+	app.Flags = append(app.Flags, storage.NewCliFlags()...)
+	app.Flags = append(app.Flags, influxdb.NewCliFlags()...)
+	app.Flags = append(app.Flags, libapp.NewPostgreSQLFlags(storage.PostgresDefaultDB)...)
+	app.Flags = append(app.Flags, blockchain.NewEthereumNodeFlags())
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(c *cli.Context) error {
+	if err := libapp.Validate(c); err != nil {
+		return err
+	}
+
+	sugar, flush, err := libapp.NewSugaredLogger(c)
+	if err != nil {
+		return err
+	}
+	defer flush()
+
+	batchSize := c.Uint64(batchSizeFlag)
+	if batchSize == 0 {
+		return errors.New("batch size must be positive")
+	}
+	fromBlock := c.Uint64(fromBlockFlag)
+	if fromBlock == 0 {
+		return errors.New("from block is required")
+	}
+
+	tokenAmountFormatter, err := blockchain.NewToKenAmountFormatterFromContext(c)
+	if err != nil {
+		return err
+	}
+	st, err := storage.NewStorageInterfaceFromContext(sugar, c, tokenAmountFormatter)
+	if err != nil {
+		return err
+	}
+	symbolResolver, err := blockchain.NewTokenInfoGetterFromContext(c, st)
+	if err != nil {
+		return err
+	}
+
+	db, err := libapp.NewDBFromContextWithDatabase(c, c.String(reserveRatesDatabaseFlag))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cErr := db.Close(); cErr != nil {
+			sugar.Errorw("failed to close reserve rates database", "error", cErr)
+		}
+	}()
+	rateStorage, err := postgres.NewPostgresStorage(db, sugar, nil, deployment.MustGetChainFromContext(c).ID)
+	if err != nil {
+		return err
+	}
+
+	toBlock := c.Uint64(toBlockFlag)
+	if toBlock == 0 {
+		lastBlock, err := st.LastBlock()
+		if err != nil {
+			return err
+		}
+		toBlock = uint64(lastBlock)
+	}
+	if fromBlock > toBlock {
+		return fmt.Errorf("from block %d is after to block %d", fromBlock, toBlock)
+	}
+
+	return slippage.NewCalculator(sugar, st, rateStorage, symbolResolver, batchSize).Run(fromBlock, toBlock)
+}
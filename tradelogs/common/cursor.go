@@ -0,0 +1,59 @@
+package common
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+)
+
+// TradeLogCursor is the position of a trade log in the chain. Trade logs are paginated
+// in the order of block number and log index.
+type TradeLogCursor struct {
+	BlockNumber uint64
+	LogIndex    uint
+}
+
+// String encodes the cursor to an opaque string to be passed back by API clients.
+func (c TradeLogCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.BlockNumber, c.LogIndex)))
+}
+
+// ParseTradeLogCursor decodes the cursor returned by TradeLogCursor.String.
+func ParseTradeLogCursor(s string) (TradeLogCursor, error) {
+	var c TradeLogCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("invalid cursor %q: %v", s, err)
+	}
+	if _, err = fmt.Sscanf(string(data), "%d:%d", &c.BlockNumber, &c.LogIndex); err != nil {
+		return c, fmt.Errorf("invalid cursor %q: %v", s, err)
+	}
+	return c, nil
+}
+
+// CursorOf returns the cursor pointing to given trade log.
+func CursorOf(tl TradelogV4) TradeLogCursor {
+	return TradeLogCursor{BlockNumber: tl.BlockNumber, LogIndex: tl.Index}
+}
+
+// TradeLogFilter is the filter to load a page of trade logs. Empty fields are not filtered.
+type TradeLogFilter struct {
+	From time.Time
+	To   time.Time
+	// FromBlock and ToBlock are the inclusive block range, 0 means unbounded.
+	FromBlock uint64
+	ToBlock   uint64
+	// After is the cursor of the last trade log of previous page.
+	After *TradeLogCursor
+	// Limit is the maximum number of trade logs returned, 0 means no limit.
+	Limit uint64
+
+	// Tokens matches trade logs with either source or destination token in the list.
+	Tokens          []ethereum.Address
+	Reserves        []ethereum.Address
+	Wallets         []ethereum.Address
+	Users           []ethereum.Address
+	IntegrationApps []string
+}
@@ -2,6 +2,7 @@ package common
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"time"
 
@@ -70,7 +71,7 @@ type TradeLog struct {
 	TxSender ethereum.Address `json:"tx_sender"`
 
 	ETHUSDRate     float64 `json:"eth_usd_rate"`
-	ETHUSDProvider string  `json:"-"`
+	ETHUSDProvider string  `json:"eth_usd_provider,omitempty"`
 
 	UserName  string `json:"user_name"`
 	ProfileID int64  `json:"profile_id"`
@@ -86,6 +87,13 @@ type CrawlResult struct {
 	Reserves      []Reserve    `json:"reserves"` // reserve update on this
 	UpdateWallets []Reserve    `json:"update_wallets"`
 	Trades        []TradelogV4 `json:"trades"`
+	Blocks        []BlockInfo  `json:"blocks"` // hashes of processed blocks, used to detect chain reorganization
+}
+
+// BlockInfo is the hash of a block processed by the crawler.
+type BlockInfo struct {
+	Number uint64        `json:"block_number"`
+	Hash   ethereum.Hash `json:"block_hash"`
 }
 
 // TradelogV4 is object for tradelog after katalyst upgrade
@@ -115,7 +123,7 @@ type TradelogV4 struct {
 	DestAmount        *big.Int `json:"dst_amount"`
 	FiatAmount        float64  `json:"fiat_amount"`
 	ETHUSDRate        float64  `json:"eth_usd_rate"`
-	ETHUSDProvider    string   `json:"-"`
+	ETHUSDProvider    string   `json:"eth_usd_provider,omitempty"`
 
 	WalletAddress ethereum.Address `json:"wallet_addr"`
 	WalletName    string           `json:"wallet_name"`
@@ -196,14 +204,84 @@ type KyberUserInfo struct {
 
 // BigTradeLog represent trade event on KyberNetwork
 type BigTradeLog struct {
-	TradelogID        uint64        `json:"tradelog_id"`
-	Timestamp         time.Time     `json:"timestamp"`
-	TransactionHash   ethereum.Hash `json:"tx_hash"`
-	EthAmount         *big.Int      `json:"eth_amount"`
-	OriginalETHAmount *big.Int      `json:"original_eth_amount"`
-	SrcSymbol         string        `json:"src_symbol,omitempty"`
-	DestSymbol        string        `json:"dst_symbol,omitempty"`
-	FiatAmount        float64       `json:"fiat_amount"`
+	TradelogID        uint64           `json:"tradelog_id"`
+	Timestamp         time.Time        `json:"timestamp"`
+	TransactionHash   ethereum.Hash    `json:"tx_hash"`
+	EthAmount         *big.Int         `json:"eth_amount"`
+	OriginalETHAmount *big.Int         `json:"original_eth_amount"`
+	SrcSymbol         string           `json:"src_symbol,omitempty"`
+	DestSymbol        string           `json:"dst_symbol,omitempty"`
+	FiatAmount        float64          `json:"fiat_amount"`
+	SrcAddress        ethereum.Address `json:"src_addr"`
+	DestAddress       ethereum.Address `json:"dst_addr"`
+	WalletAddress     ethereum.Address `json:"wallet_addr"`
+	WalletName        string           `json:"wallet_name,omitempty"`
+}
+
+// NotificationStatus is the delivery state of a big trade to a notifier sink.
+type NotificationStatus string
+
+const (
+	// NotificationStatusDelivered means the big trade is delivered to the sink.
+	NotificationStatusDelivered NotificationStatus = "delivered"
+	// NotificationStatusFailed means the last delivery failed, it is retried until max attempts.
+	NotificationStatusFailed NotificationStatus = "failed"
+	// NotificationStatusSkipped means the big trade does not match the rule of the sink.
+	NotificationStatusSkipped NotificationStatus = "skipped"
+)
+
+// BigTradeNotification is the result of delivering a big trade to a notifier sink.
+type BigTradeNotification struct {
+	TradelogID uint64             `json:"tradelog_id"`
+	Status     NotificationStatus `json:"status"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// TradeFiatPrice is the ETH/USD rate of a trade from a rate provider.
+type TradeFiatPrice struct {
+	TradelogID uint64    `json:"tradelog_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	ETHUSDRate float64   `json:"eth_usd_rate"`
+}
+
+// AuditCheck is the number of crawled trades violating a data quality invariant, with sample tx hashes.
+type AuditCheck struct {
+	Name    string   `json:"name"`
+	Count   uint64   `json:"count"`
+	Samples []string `json:"samples"`
+}
+
+// BlockRange is a range of blocks from FromBlock to ToBlock inclusive.
+type BlockRange struct {
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+}
+
+// CrawlJobStatus is the state of a block range in the crawl queue.
+type CrawlJobStatus string
+
+const (
+	// CrawlJobStatusPending means the range is waiting to be leased by a crawler.
+	CrawlJobStatusPending CrawlJobStatus = "pending"
+	// CrawlJobStatusRunning means the range is leased by a crawler, it is pending again when the lease expires.
+	CrawlJobStatusRunning CrawlJobStatus = "running"
+	// CrawlJobStatusDone means trade logs of the range are saved.
+	CrawlJobStatusDone CrawlJobStatus = "done"
+	// CrawlJobStatusFailed means the range failed max attempts times, it is dead-lettered until retried.
+	CrawlJobStatusFailed CrawlJobStatus = "failed"
+)
+
+// CrawlJob is a block range in the crawl queue.
+type CrawlJob struct {
+	ID             uint64         `json:"id"`
+	FromBlock      uint64         `json:"from_block"`
+	ToBlock        uint64         `json:"to_block"`
+	Status         CrawlJobStatus `json:"status"`
+	Attempts       uint64         `json:"attempts"`
+	LeaseOwner     string         `json:"lease_owner,omitempty"`
+	LeaseExpiresAt *time.Time     `json:"lease_expires_at,omitempty"`
+	LastError      string         `json:"last_error,omitempty"`
+	UpdatedAt      time.Time      `json:"updated_at"`
 }
 
 // MarshalJSON implements custom JSON marshaller for TradeLog to format timestamp in unix millis instead of RFC3339.
@@ -338,6 +416,240 @@ type UserVolume struct {
 	USDAmount float64 `json:"usd_amount"`
 }
 
+// FeeGroup is the dimension Katalyst fees are aggregated by.
+type FeeGroup string
+
+const (
+	// FeeGroupReserve aggregates fees by reserve, resolved from rebate wallets.
+	FeeGroupReserve FeeGroup = "reserve"
+	// FeeGroupRebateWallet aggregates fees by rebate wallet.
+	FeeGroupRebateWallet FeeGroup = "rebate_wallet"
+	// FeeGroupPlatformWallet aggregates fees by platform wallet.
+	FeeGroupPlatformWallet FeeGroup = "platform_wallet"
+)
+
+// FeeBreakdown represent aggregated Katalyst fees, amounts are in native token and USD.
+type FeeBreakdown struct {
+	Burn           float64 `json:"burn" db:"burn"`
+	BurnUSD        float64 `json:"burn_usd" db:"burn_usd"`
+	Reward         float64 `json:"reward" db:"reward"`
+	RewardUSD      float64 `json:"reward_usd" db:"reward_usd"`
+	Rebate         float64 `json:"rebate" db:"rebate"`
+	RebateUSD      float64 `json:"rebate_usd" db:"rebate_usd"`
+	PlatformFee    float64 `json:"platform_fee" db:"platform_fee"`
+	PlatformFeeUSD float64 `json:"platform_fee_usd" db:"platform_fee_usd"`
+}
+
+// CandleIntervals are the supported intervals of OHLCV candles.
+var CandleIntervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"1h": time.Hour,
+	"1d": time.Hour * 24,
+}
+
+// Candle represent OHLCV of a token in an interval, prices are per token in ETH and USD.
+type Candle struct {
+	Timestamp   uint64  `json:"timestamp"`
+	OpenETH     float64 `json:"open_eth" db:"open_eth"`
+	HighETH     float64 `json:"high_eth" db:"high_eth"`
+	LowETH      float64 `json:"low_eth" db:"low_eth"`
+	CloseETH    float64 `json:"close_eth" db:"close_eth"`
+	OpenUSD     float64 `json:"open_usd" db:"open_usd"`
+	HighUSD     float64 `json:"high_usd" db:"high_usd"`
+	LowUSD      float64 `json:"low_usd" db:"low_usd"`
+	CloseUSD    float64 `json:"close_usd" db:"close_usd"`
+	VolumeToken float64 `json:"volume_token" db:"volume_token"`
+	VolumeETH   float64 `json:"volume_eth" db:"volume_eth"`
+	VolumeUSD   float64 `json:"volume_usd" db:"volume_usd"`
+	TradeCount  uint64  `json:"trade_count" db:"trade_count"`
+}
+
+// RebateWalletReserve is a reserve which set a rebate wallet from FromBlock to ToBlock inclusive,
+// ToBlock is 0 if the reserve still uses the wallet.
+type RebateWalletReserve struct {
+	Address   ethereum.Address `json:"reserve"`
+	ReserveID ethereum.Hash    `json:"reserve_id"`
+	FromBlock uint64           `json:"from_block"`
+	ToBlock   uint64           `json:"to_block"`
+}
+
+// RebateLineItem is the rebate paid to a rebate wallet by a trade.
+type RebateLineItem struct {
+	Timestamp   uint64             `json:"timestamp"`
+	BlockNumber uint64             `json:"block_number"`
+	TxHash      ethereum.Hash      `json:"tx_hash"`
+	TradeIndex  uint               `json:"trade_index"`
+	FeeIndex    uint               `json:"fee_index"`
+	Reserves    []ethereum.Address `json:"reserves"`
+	PercentBps  uint64             `json:"rebate_percent_bps"`
+	ETHAmount   float64            `json:"eth_amount"`
+	USDAmount   float64            `json:"usd_amount"`
+}
+
+// RebateStatement is the rebates paid to a rebate wallet in a period.
+type RebateStatement struct {
+	RebateWallet ethereum.Address      `json:"rebate_wallet"`
+	From         uint64                `json:"from"`
+	To           uint64                `json:"to"`
+	Reserves     []RebateWalletReserve `json:"reserves"`
+	Items        []RebateLineItem      `json:"items"`
+	TotalETH     float64               `json:"total_eth"`
+	TotalUSD     float64               `json:"total_usd"`
+}
+
+// Sandwich is a trade probably sandwiched by a front run and a back run trade of the attacker on the
+// same token in the same block, the extracted value is the estimated profit of the attacker from the trade.
+type Sandwich struct {
+	Timestamp      uint64           `json:"timestamp"`
+	BlockNumber    uint64           `json:"block_number"`
+	TxHash         ethereum.Hash    `json:"tx_hash"`
+	TradeIndex     uint             `json:"trade_index"`
+	Wallet         ethereum.Address `json:"wallet_addr"`
+	Token          ethereum.Address `json:"token"`
+	Attacker       ethereum.Address `json:"attacker"`
+	FrontRunTxHash ethereum.Hash    `json:"front_run_tx_hash"`
+	BackRunTxHash  ethereum.Hash    `json:"back_run_tx_hash"`
+	ExtractedETH   float64          `json:"extracted_eth"`
+	ExtractedUSD   float64          `json:"extracted_usd"`
+}
+
+// MEVWalletStats is the value extracted from sandwiched trades of an integration wallet.
+type MEVWalletStats struct {
+	Wallet           ethereum.Address `json:"wallet_addr"`
+	SandwichedTrades uint64           `json:"sandwiched_trades"`
+	ExtractedETH     float64          `json:"extracted_eth"`
+	ExtractedUSD     float64          `json:"extracted_usd"`
+}
+
+// MEVReport is the sandwiched trades in a period and the value extracted from them.
+type MEVReport struct {
+	From              uint64           `json:"from"`
+	To                uint64           `json:"to"`
+	Wallets           []MEVWalletStats `json:"wallets"`
+	Sandwiches        []Sandwich       `json:"sandwiches"`
+	TotalExtractedETH float64          `json:"total_extracted_eth"`
+	TotalExtractedUSD float64          `json:"total_extracted_usd"`
+}
+
+// SplitExecution is the rate a reserve filled a split between ETH and a token at, rates are token
+// per ETH if the token is bought and ETH per token otherwise.
+type SplitExecution struct {
+	SplitID      uint64           `json:"split_id"`
+	BlockNumber  uint64           `json:"block_number"`
+	Reserve      ethereum.Address `json:"reserve"`
+	Token        ethereum.Address `json:"token"`
+	Buy          bool             `json:"buy"`
+	ExecutedRate float64          `json:"executed_rate"`
+	ETHAmount    float64          `json:"eth_amount"`
+}
+
+// SplitSlippage is the slippage of a split from the rate quoted by the reserve at the previous block,
+// positive slippage is a worse fill than the quote.
+type SplitSlippage struct {
+	SplitID      uint64  `json:"split_id"`
+	QuotedRate   float64 `json:"quoted_rate"`
+	ExecutedRate float64 `json:"executed_rate"`
+	SlippageBps  float64 `json:"slippage_bps"`
+}
+
+// SlippageSizeBuckets are the lower bounds in ETH of split size buckets of slippage stats.
+var SlippageSizeBuckets = []float64{0, 1, 10, 100}
+
+// SlippageSizeBucketName returns the name of the i-th bucket of SlippageSizeBuckets, such as 1-10.
+func SlippageSizeBucketName(i int) string {
+	if i+1 >= len(SlippageSizeBuckets) {
+		return fmt.Sprintf("%v+", SlippageSizeBuckets[len(SlippageSizeBuckets)-1])
+	}
+	return fmt.Sprintf("%v-%v", SlippageSizeBuckets[i], SlippageSizeBuckets[i+1])
+}
+
+// SlippageStats is the slippage of splits of a reserve for a token in a size bucket.
+type SlippageStats struct {
+	Reserve          ethereum.Address `json:"reserve"`
+	Token            ethereum.Address `json:"token"`
+	Side             string           `json:"side"`
+	SizeBucket       string           `json:"size_bucket"`
+	Splits           uint64           `json:"splits"`
+	VolumeETH        float64          `json:"volume_eth"`
+	WeightedSlippage float64          `json:"weighted_slippage_bps"`
+	MedianSlippage   float64          `json:"median_slippage_bps"`
+	P95Slippage      float64          `json:"p95_slippage_bps"`
+	MaxSlippage      float64          `json:"max_slippage_bps"`
+}
+
+// MarketShareGroup is the dimension reserve market share is aggregated by.
+type MarketShareGroup string
+
+const (
+	// MarketShareGroupReserve aggregates market share by reserve.
+	MarketShareGroupReserve MarketShareGroup = "reserve"
+	// MarketShareGroupReserveType aggregates market share by reserve type.
+	MarketShareGroupReserveType MarketShareGroup = "reserve_type"
+)
+
+// ReserveMarketShare is the share of volume of a token pair a reserve captured in a period, pairs are
+// the source and destination of splits so one of them is always ETH. A split is multi-reserve if its
+// trade routed the same pair through several reserves, its weight is its part of the pair volume of
+// the trade. Reserve is the zero address if market share is aggregated by reserve type.
+type ReserveMarketShare struct {
+	Timestamp          uint64           `json:"timestamp"`
+	Src                ethereum.Address `json:"src"`
+	Dst                ethereum.Address `json:"dst"`
+	Reserve            ethereum.Address `json:"reserve"`
+	ReserveType        uint64           `json:"reserve_type"`
+	VolumeETH          float64          `json:"volume_eth"`
+	VolumeUSD          float64          `json:"volume_usd"`
+	Share              float64          `json:"share"`
+	Splits             uint64           `json:"splits"`
+	MultiReserveSplits uint64           `json:"multi_reserve_splits"`
+	AvgSplitWeight     float64          `json:"avg_split_weight"`
+}
+
+// CohortInterval is the length of periods users are grouped in cohorts by.
+type CohortInterval string
+
+const (
+	// CohortIntervalWeek groups users by the week of their first trade, weeks start on Monday.
+	CohortIntervalWeek CohortInterval = "week"
+	// CohortIntervalMonth groups users by the month of their first trade.
+	CohortIntervalMonth CohortInterval = "month"
+)
+
+// CohortGroup is the dimension cohorts are split by, users are attributed to the value of their first trade.
+type CohortGroup string
+
+const (
+	// CohortGroupNone does not split cohorts.
+	CohortGroupNone CohortGroup = ""
+	// CohortGroupIntegrationApp splits cohorts by integration app.
+	CohortGroupIntegrationApp CohortGroup = "integration_app"
+	// CohortGroupWallet splits cohorts by wallet address.
+	CohortGroupWallet CohortGroup = "wallet"
+	// CohortGroupCountry splits cohorts by country.
+	CohortGroupCountry CohortGroup = "country"
+)
+
+// CohortPeriod is the activity of a cohort in a period, period 0 is the period of the first trade.
+type CohortPeriod struct {
+	Period      uint64  `json:"period"`
+	Timestamp   uint64  `json:"timestamp"`
+	ActiveUsers uint64  `json:"active_users"`
+	Retention   float64 `json:"retention"`
+	ETHVolume   float64 `json:"eth_volume"`
+	USDVolume   float64 `json:"usd_volume"`
+}
+
+// UserCohort is the users who made their first trade in the same period, and their activity in
+// every later period they traded in.
+type UserCohort struct {
+	Timestamp uint64         `json:"timestamp"`
+	Group     string         `json:"group,omitempty"`
+	Users     uint64         `json:"users"`
+	ETHVolume float64        `json:"eth_volume"`
+	USDVolume float64        `json:"usd_volume"`
+	Periods   []CohortPeriod `json:"periods"`
+}
+
 // WalletStats represent stat for a wallet address
 type WalletStats struct {
 	ETHVolume          float64 `json:"eth_volume"`
@@ -10,7 +10,6 @@ import (
 	ethereum "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/nanmu42/etherscan-api"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
@@ -53,8 +52,6 @@ const (
 var defaultTimeout = 10 * time.Second
 var errUnknownLogTopic = errors.New("unknown log topic")
 
-type tradeLogFetcher func(*big.Int, *big.Int, time.Duration) (*common.CrawlResult, error)
-
 // NewCrawler create a new Crawler instance.
 func NewCrawler(sugar *zap.SugaredLogger,
 	client *ethclient.Client,
@@ -62,8 +59,9 @@ func NewCrawler(sugar *zap.SugaredLogger,
 	rateProvider tokenrate.ETHUSDRateProvider,
 	addresses []ethereum.Address,
 	sb deployment.VersionedStartingBlocks,
-	etherscanClient *etherscan.Client,
+	internalTxProvider InternalTxProvider,
 	volumeExcludedReserves []ethereum.Address,
+	wrappedNativeToken ethereum.Address,
 	networkProxy, kyberStorage, kyberFeeHandler, kyberNetwork ethereum.Address) (*Crawler, error) {
 	resolver, err := blockchain.NewBlockTimeResolver(sugar, client)
 	if err != nil {
@@ -85,7 +83,7 @@ func NewCrawler(sugar *zap.SugaredLogger,
 		return nil, err
 	}
 
-	return &Crawler{
+	crawler := &Crawler{
 		sugar:                   sugar,
 		ethClient:               client,
 		txTime:                  resolver,
@@ -93,13 +91,19 @@ func NewCrawler(sugar *zap.SugaredLogger,
 		rateProvider:            rateProvider,
 		addresses:               addresses,
 		startingBlocks:          sb,
-		etherscanClient:         etherscanClient,
+		internalTxProvider:      internalTxProvider,
 		volumeExludedReserves:   volumeExcludedReserves,
+		wrappedNativeToken:      wrappedNativeToken,
 		networkProxy:            networkProxy,
 		kyberStorageContract:    kyberStorageContract,
 		kyberFeeHandlerContract: kyberFeeHandlerContract,
 		kyberNetworkContract:    kyberNetworkContract,
-	}, nil
+	}
+	crawler.decoders = newVersionedDecoders(crawler)
+	if len(crawler.decoders) == 0 {
+		return nil, errors.New("no trade log decoder registered")
+	}
+	return crawler, nil
 }
 
 // Crawler gets trade logs on KyberNetwork on blockchain, adding the
@@ -113,13 +117,31 @@ type Crawler struct {
 	addresses             []ethereum.Address
 	startingBlocks        deployment.VersionedStartingBlocks
 	volumeExludedReserves []ethereum.Address
+	wrappedNativeToken    ethereum.Address
 
 	kyberStorageContract    *contracts.KyberStorage
 	kyberFeeHandlerContract *contracts.KyberFeeHandler
 	kyberNetworkContract    *contracts.KyberNetwork
 
-	etherscanClient *etherscan.Client
-	networkProxy    ethereum.Address
+	internalTxProvider InternalTxProvider
+	networkProxy       ethereum.Address
+
+	decoders []versionedDecoder // latest version first
+}
+
+// EthClient returns the ethereum client used by crawler.
+func (crawler *Crawler) EthClient() *ethclient.Client {
+	return crawler.ethClient
+}
+
+// Sugar returns the logger of crawler.
+func (crawler *Crawler) Sugar() *zap.SugaredLogger {
+	return crawler.sugar
+}
+
+// BlockTime returns the timestamp of given block.
+func (crawler *Crawler) BlockTime(block uint64) (time.Time, error) {
+	return crawler.txTime.Resolve(block)
 }
 
 func logDataToExecuteTradeParams(data []byte) (ethereum.Address, ethereum.Address, ethereum.Hash, ethereum.Hash, error) {
@@ -365,10 +387,10 @@ func (crawler *Crawler) fillKyberTradeV4(tradelog common.TradelogV4, logItem typ
 
 	tradelog.OriginalEthAmount = trade.EthWeiValue
 	defaultRatio := 2
-	if trade.Src == blockchain.WETHAddr || trade.Src == blockchain.ETHAddr || trade.Src == blockchain.PTAddr {
+	if trade.Src == crawler.wrappedNativeToken || trade.Src == blockchain.ETHAddr || trade.Src == blockchain.PTAddr {
 		defaultRatio--
 	}
-	if trade.Dest == blockchain.WETHAddr || trade.Dest == blockchain.ETHAddr || trade.Dest == blockchain.PTAddr {
+	if trade.Dest == crawler.wrappedNativeToken || trade.Dest == blockchain.ETHAddr || trade.Dest == blockchain.PTAddr {
 		defaultRatio--
 	}
 	tradelog.EthAmount = big.NewInt(1).Mul(trade.EthWeiValue, big.NewInt(int64(defaultRatio)))
@@ -431,6 +453,41 @@ func (crawler *Crawler) fetchLogsWithTopics(fromBlock, toBlock *big.Int, timeout
 
 }
 
+// blockInfosFromLogs returns the distinct blocks the given logs belong to, in the order they appear.
+func blockInfosFromLogs(logs []types.Log) []common.BlockInfo {
+	var (
+		result []common.BlockInfo
+		seen   = make(map[uint64]struct{})
+	)
+	for _, log := range logs {
+		if log.Removed {
+			continue
+		}
+		if _, ok := seen[log.BlockNumber]; ok {
+			continue
+		}
+		seen[log.BlockNumber] = struct{}{}
+		result = append(result, common.BlockInfo{Number: log.BlockNumber, Hash: log.BlockHash})
+	}
+	return result
+}
+
+// appendToBlockInfo records the hash of toBlock in crawl result, so ranges without any trade
+// are still verified against chain reorganization in later runs.
+func (crawler *Crawler) appendToBlockInfo(result *common.CrawlResult, toBlock *big.Int, timeout time.Duration) error {
+	if n := len(result.Blocks); n > 0 && result.Blocks[n-1].Number == toBlock.Uint64() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	header, err := crawler.ethClient.HeaderByNumber(ctx, toBlock)
+	if err != nil {
+		return err
+	}
+	result.Blocks = append(result.Blocks, common.BlockInfo{Number: header.Number.Uint64(), Hash: header.Hash()})
+	return nil
+}
+
 func (crawler *Crawler) updateBasicInfo(log types.Log, tradeLog common.TradelogV4, timeout time.Duration) (common.TradelogV4, error) {
 	var txSender ethereum.Address
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -510,31 +567,28 @@ func (crawler *Crawler) updateBasicInfoV4(log types.Log, tradeLog common.Tradelo
 
 // GetTradeLogs returns trade logs from KyberNetwork.
 func (crawler *Crawler) GetTradeLogs(fromBlock, toBlock *big.Int, timeout time.Duration) (*common.CrawlResult, error) {
-	var (
-		result  *common.CrawlResult
-		fetchFn tradeLogFetcher
-	)
-
-	// fetchTradeLogV2 also works for v3 trades, so to keep it simple, we only use fetchTradeLogV3 if both
-	// from, to blocks are >= starting block v3
-	switch {
-	case fromBlock.Uint64() >= crawler.startingBlocks.V4() && toBlock.Uint64() >= crawler.startingBlocks.V4():
-		fetchFn = crawler.fetchTradeLogV4
-	case fromBlock.Uint64() >= crawler.startingBlocks.V3() && toBlock.Uint64() >= crawler.startingBlocks.V3():
-		fetchFn = crawler.fetchTradeLogV3
-	case fromBlock.Uint64() >= crawler.startingBlocks.V2() && toBlock.Uint64() >= crawler.startingBlocks.V2():
-		fetchFn = crawler.fetchTradeLogV2
-	default:
-		fetchFn = crawler.fetchTradeLogV1
+	// the decoder is chosen by from block only, ranges are split at starting blocks of registered versions
+	// (see SplitPoints) so a range never spans two versions
+	decoder, err := crawler.decoderAt(fromBlock.Uint64())
+	if err != nil {
+		return nil, err
 	}
-
-	result, err := fetchFn(fromBlock, toBlock, timeout)
+	result, err := crawler.fetchTradeLogs(decoder, fromBlock, toBlock, timeout)
 	if err != nil {
 		return result, errors.Wrapf(err, "failed to fetch trade logs fromBlock: %v toBlock:%v", fromBlock, toBlock)
 	}
 	if result == nil {
 		return result, nil
 	}
+	return crawler.enrichTradeLogs(result, toBlock, timeout)
+}
+
+// enrichTradeLogs adds user, integration app and USD rate information to assembled trade logs.
+func (crawler *Crawler) enrichTradeLogs(result *common.CrawlResult, toBlock *big.Int, timeout time.Duration) (*common.CrawlResult, error) {
+	var err error
+	if err = crawler.appendToBlockInfo(result, toBlock, timeout); err != nil {
+		return result, errors.Wrapf(err, "failed to get header of block %v", toBlock)
+	}
 	for index, tradeLog := range result.Trades {
 		var uid, ip, country string
 
@@ -17,6 +17,7 @@ import (
 	"github.com/nanmu42/etherscan-api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
 	"github.com/KyberNetwork/reserve-stats/lib/contracts"
@@ -32,7 +33,10 @@ const (
 
 type mockBroadCastClient struct{}
 
-var ec = etherscan.New(etherscan.Mainnet, "")
+func newEtherscanProvider(sugar *zap.SugaredLogger) InternalTxProvider {
+	return NewEtherscanInternalTxProvider(sugar, etherscan.New(etherscan.Mainnet, ""),
+		ethereum.HexToAddress(internalNetworkAddrV1))
+}
 
 func newMockBroadCastClient() *mockBroadCastClient {
 	return &mockBroadCastClient{}
@@ -85,7 +89,7 @@ func TestCrawlerGetTradeLogs(t *testing.T) {
 		ethereum.HexToAddress("0x52166528FCC12681aF996e409Ee3a421a4e128A3"), // burner contract
 	}
 	c, err := NewCrawler(sugar, client, newMockBroadCastClient(), tokenrate.NewMock(), v3Addresses,
-		deployment.StartingBlocks[deployment.Production], ec, []ethereum.Address{}, nwProxyAddr, kyberStorageAddr, feeHandlerAddr, kyberNetwork)
+		deployment.StartingBlocks[deployment.Production], newEtherscanProvider(sugar), []ethereum.Address{}, blockchain.WETHAddr, nwProxyAddr, kyberStorageAddr, feeHandlerAddr, kyberNetwork)
 	require.NoError(t, err)
 
 	result, err := c.GetTradeLogs(big.NewInt(7025000), big.NewInt(7025100), time.Minute)
@@ -103,7 +107,7 @@ func TestCrawlerGetTradeLogs(t *testing.T) {
 	}
 
 	c, err = NewCrawler(sugar, client, newMockBroadCastClient(), tokenrate.NewMock(), v2Addresses,
-		deployment.StartingBlocks[deployment.Production], ec, []ethereum.Address{}, nwProxyAddr, kyberStorageAddr, feeHandlerAddr, kyberNetwork)
+		deployment.StartingBlocks[deployment.Production], newEtherscanProvider(sugar), []ethereum.Address{}, blockchain.WETHAddr, nwProxyAddr, kyberStorageAddr, feeHandlerAddr, kyberNetwork)
 	require.NoError(t, err)
 
 	result, err = c.GetTradeLogs(big.NewInt(6343120), big.NewInt(6343220), time.Minute)
@@ -200,7 +204,7 @@ func TestCrawlerGetTradeLogs(t *testing.T) {
 	}
 
 	c, err = NewCrawler(sugar, client, newMockBroadCastClient(), tokenrate.NewMock(), v1Addresses,
-		deployment.StartingBlocks[deployment.Production], ec, []ethereum.Address{}, nwProxyAddr, kyberStorageAddr, feeHandlerAddr, kyberNetwork)
+		deployment.StartingBlocks[deployment.Production], newEtherscanProvider(sugar), []ethereum.Address{}, blockchain.WETHAddr, nwProxyAddr, kyberStorageAddr, feeHandlerAddr, kyberNetwork)
 	require.NoError(t, err)
 
 	result, err = c.GetTradeLogs(big.NewInt(5877442), big.NewInt(5877500), time.Minute)
@@ -253,7 +257,7 @@ func newTestCrawler(t *testing.T, version string) *Crawler {
 	sugar := testutil.MustNewDevelopmentSugaredLogger()
 	client := testutil.MustNewDevelopmentwEthereumClient()
 	c, err := NewCrawler(sugar, client, newMockBroadCastClient(), tokenrate.NewMock(), addresses,
-		deployment.StartingBlocks[deployment.Production], ec, []ethereum.Address{}, nwProxyAddr, kyberStorageAddr, feeHandlerAddr, kyberNetwork)
+		deployment.StartingBlocks[deployment.Production], newEtherscanProvider(sugar), []ethereum.Address{}, blockchain.WETHAddr, nwProxyAddr, kyberStorageAddr, feeHandlerAddr, kyberNetwork)
 	require.NoError(t, err)
 	return c
 }
@@ -465,3 +469,37 @@ func TestCrawlKyberTrade(t *testing.T) {
 		fmt.Printf("%s\n", dataByte)
 	}
 }
+
+// recordingRateProvider is a tokenrate.Provider recording the tokens it is asked for.
+type recordingRateProvider struct {
+	tokens []string
+}
+
+func (p *recordingRateProvider) Rate(token, currency string, _ time.Time) (float64, error) {
+	p.tokens = append(p.tokens, token+"/"+currency)
+	return 300, nil
+}
+
+func (p *recordingRateProvider) Name() string {
+	return "recording"
+}
+
+func TestCrawlNativeTokenUSDRate(t *testing.T) {
+	provider := &recordingRateProvider{}
+	rateProvider, err := tokenrate.NewNativeTokenUSDRateProvider(provider, deployment.Chains[deployment.BSC].NativeToken)
+	require.NoError(t, err)
+	crawler := &Crawler{
+		sugar:           testutil.MustNewDevelopmentSugaredLogger(),
+		broadcastClient: newMockBroadCastClient(),
+		rateProvider:    rateProvider,
+	}
+
+	result, err := crawler.enrichTradeLogs(&common.CrawlResult{
+		Trades: []common.TradelogV4{{BlockNumber: 100, Timestamp: time.Unix(1600000000, 0)}},
+		Blocks: []common.BlockInfo{{Number: 100}},
+	}, big.NewInt(100), defaultTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"binancecoin/usd"}, provider.tokens)
+	assert.Equal(t, float64(300), result.Trades[0].ETHUSDRate)
+	assert.Equal(t, "recording", result.Trades[0].ETHUSDProvider)
+}
@@ -2,13 +2,13 @@ package tradelogs
 
 import (
 	"math/big"
-	"time"
 
 	ethereum "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
 
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
 	"github.com/KyberNetwork/reserve-stats/lib/mathutil"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 )
@@ -20,22 +20,20 @@ const (
 	internalNetworkAddrV1 = "0x964F35fAe36d75B1e72770e244F6595B68508CF5"
 )
 
-func (crawler *Crawler) fetchTradeLogV1(fromBlock, toBlock *big.Int, timeout time.Duration) (*common.CrawlResult, error) {
-	topics := [][]ethereum.Hash{
-		{
-			ethereum.HexToHash(executeTradeEvent),
-			ethereum.HexToHash(burnFeeEvent),
-			ethereum.HexToHash(feeToWalletEvent),
-			ethereum.HexToHash(etherReceivalEvent),
-		},
-	}
-
-	typeLogs, err := crawler.fetchLogsWithTopics(fromBlock, toBlock, timeout, topics)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to fetch log by topic")
-	}
+// tradeLogTopicsV1 is the topics filter of all events needed to assemble trade logs v1.
+var tradeLogTopicsV1 = [][]ethereum.Hash{
+	{
+		ethereum.HexToHash(executeTradeEvent),
+		ethereum.HexToHash(burnFeeEvent),
+		ethereum.HexToHash(feeToWalletEvent),
+		ethereum.HexToHash(etherReceivalEvent),
+	},
+}
 
-	return crawler.assembleTradeLogsV1(typeLogs)
+func init() {
+	registerDecoder("v1", func(deployment.VersionedStartingBlocks) uint64 { return 0 }, func(crawler *Crawler) TradeLogDecoder {
+		return &funcDecoder{topics: tradeLogTopicsV1, assemble: crawler.assembleTradeLogsV1}
+	})
 }
 
 func (crawler *Crawler) getTransactionReceiptV1(tradeLog common.TradelogV4, receipt *types.Receipt, logIndex uint,
@@ -67,27 +65,13 @@ func (crawler *Crawler) getTransactionReceiptV1(tradeLog common.TradelogV4, rece
 }
 
 func (crawler *Crawler) getInternalTransaction(tradeLog common.TradelogV4) (common.TradelogV4, error) {
-	blockInt := int(tradeLog.BlockNumber)
-	internalTxs, err := crawler.etherscanClient.InternalTxByAddress(internalNetworkAddrV1, &blockInt, &blockInt, 0, 0, false)
+	transfers, err := crawler.internalTxProvider.InternalTransfers(tradeLog.TransactionHash, tradeLog.BlockNumber)
 	if err != nil {
-		switch {
-		case blockchain.IsEtherscanNotransactionFound(err):
-			crawler.sugar.Warnw("internal transaction not found on etherscan", "err", err,
-				"tx_hash", tradeLog.TransactionHash, "block_number", tradeLog.BlockNumber)
-			return tradeLog, nil
-		case blockchain.IsEtherscanRateLimit(err):
-			crawler.sugar.Warnw("failed to get internal transaction", "err", err,
-				"tx_hash", tradeLog.TransactionHash, "block_number", tradeLog.BlockNumber)
-			return tradeLog, err
-		default:
-			return tradeLog, err
-		}
+		return tradeLog, err
 	}
-	for _, tx := range internalTxs {
-		txHash := ethereum.HexToHash(tx.Hash)
-		fromAddr := ethereum.HexToAddress(tx.From)
-		if txHash == tradeLog.TransactionHash && fromAddr == ethereum.HexToAddress(internalNetworkAddrV1) {
-			tradeLog.ReceiverAddress = ethereum.HexToAddress(tx.To)
+	for _, transfer := range transfers {
+		if transfer.From == ethereum.HexToAddress(internalNetworkAddrV1) {
+			tradeLog.ReceiverAddress = transfer.To
 			return tradeLog, nil
 		}
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
 	"github.com/KyberNetwork/reserve-stats/lib/mathutil"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 )
@@ -19,22 +20,20 @@ const (
 	kyberTradeEventV2 = "0x1c8399ecc5c956b9cb18c820248b10b634cca4af308755e07cd467655e8ec3c7"
 )
 
-func (crawler *Crawler) fetchTradeLogV2(fromBlock, toBlock *big.Int, timeout time.Duration) (*common.CrawlResult, error) {
-	topics := [][]ethereum.Hash{
-		{
-			ethereum.HexToHash(kyberTradeEventV2),
-			ethereum.HexToHash(burnFeeEvent),
-			ethereum.HexToHash(feeToWalletEvent),
-			ethereum.HexToHash(etherReceivalEvent),
-		},
-	}
-
-	typeLogs, err := crawler.fetchLogsWithTopics(fromBlock, toBlock, timeout, topics)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to fetch log by topic")
-	}
+// tradeLogTopicsV2 is the topics filter of all events needed to assemble trade logs v2.
+var tradeLogTopicsV2 = [][]ethereum.Hash{
+	{
+		ethereum.HexToHash(kyberTradeEventV2),
+		ethereum.HexToHash(burnFeeEvent),
+		ethereum.HexToHash(feeToWalletEvent),
+		ethereum.HexToHash(etherReceivalEvent),
+	},
+}
 
-	return crawler.assembleTradeLogsV2(typeLogs)
+func init() {
+	registerDecoder("v2", func(sb deployment.VersionedStartingBlocks) uint64 { return sb.V2() }, func(crawler *Crawler) TradeLogDecoder {
+		return &funcDecoder{topics: tradeLogTopicsV2, assemble: crawler.assembleTradeLogsV2}
+	})
 }
 
 func (crawler *Crawler) getTransactionReceipt(txHash ethereum.Hash, timeout time.Duration) (*types.Receipt, error) {
@@ -3,7 +3,6 @@ package tradelogs
 import (
 	"math/big"
 	"strings"
-	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	ethereum "github.com/ethereum/go-ethereum/common"
@@ -11,26 +10,15 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/KyberNetwork/reserve-stats/lib/contracts"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 )
 
 var (
 	networkABI, networkABIV4 abi.ABI
-)
 
-func init() {
-	var err error
-	networkABI, err = abi.JSON(strings.NewReader(contracts.NetworkProxyABI))
-	if err != nil {
-		panic(err)
-	}
-	networkABIV4, err = abi.JSON(strings.NewReader(contracts.KyberNetworkProxyV4ABI))
-	if err != nil {
-		panic(err)
-	}
-}
-func (crawler *Crawler) fetchTradeLogV3(fromBlock, toBlock *big.Int, timeout time.Duration) (*common.CrawlResult, error) {
-	topics := [][]ethereum.Hash{
+	// tradeLogTopicsV3 is the topics filter of all events needed to assemble trade logs v3.
+	tradeLogTopicsV3 = [][]ethereum.Hash{
 		{
 			ethereum.HexToHash(burnFeeEvent),
 			ethereum.HexToHash(feeToWalletEvent),
@@ -39,13 +27,22 @@ func (crawler *Crawler) fetchTradeLogV3(fromBlock, toBlock *big.Int, timeout tim
 			ethereum.HexToHash(reserveRebateWalletSetEvent),
 		},
 	}
+)
 
-	typeLogs, err := crawler.fetchLogsWithTopics(fromBlock, toBlock, timeout, topics)
+func init() {
+	var err error
+	networkABI, err = abi.JSON(strings.NewReader(contracts.NetworkProxyABI))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to fetch log by topic")
+		panic(err)
+	}
+	networkABIV4, err = abi.JSON(strings.NewReader(contracts.KyberNetworkProxyV4ABI))
+	if err != nil {
+		panic(err)
 	}
 
-	return crawler.assembleTradeLogsV3(typeLogs)
+	registerDecoder("v3", func(sb deployment.VersionedStartingBlocks) uint64 { return sb.V3() }, func(crawler *Crawler) TradeLogDecoder {
+		return &funcDecoder{topics: tradeLogTopicsV3, assemble: crawler.assembleTradeLogsV3}
+	})
 }
 
 type tradeWithHintParam struct {
@@ -2,13 +2,13 @@ package tradelogs
 
 import (
 	"math/big"
-	"time"
 
 	ethereum "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
 
 	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 )
 
@@ -23,25 +23,23 @@ const (
 	kyberTradeEventV4 = "0x30bbea603a7b36858fe5e3ec6ba5ff59dde039d02120d758eacfaed01520577d"
 )
 
-func (crawler *Crawler) fetchTradeLogV4(fromBlock, toBlock *big.Int, timeout time.Duration) (*common.CrawlResult, error) {
-	topics := [][]ethereum.Hash{
-		{
-			ethereum.HexToHash(burnFeeEvent),
-			ethereum.HexToHash(feeToWalletEvent),
-			ethereum.HexToHash(kyberTradeEvent),
-			ethereum.HexToHash(feeDistributedEvent),
-			ethereum.HexToHash(kyberTradeEventV4),
-			ethereum.HexToHash(addReserveToStorageEvent),
-			ethereum.HexToHash(reserveRebateWalletSetEvent),
-		},
-	}
-
-	typeLogs, err := crawler.fetchLogsWithTopics(fromBlock, toBlock, timeout, topics)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to fetch log by topic")
-	}
+// tradeLogTopicsV4 is the topics filter of all events needed to assemble trade logs v4.
+var tradeLogTopicsV4 = [][]ethereum.Hash{
+	{
+		ethereum.HexToHash(burnFeeEvent),
+		ethereum.HexToHash(feeToWalletEvent),
+		ethereum.HexToHash(kyberTradeEvent),
+		ethereum.HexToHash(feeDistributedEvent),
+		ethereum.HexToHash(kyberTradeEventV4),
+		ethereum.HexToHash(addReserveToStorageEvent),
+		ethereum.HexToHash(reserveRebateWalletSetEvent),
+	},
+}
 
-	return crawler.assembleTradeLogsV4(typeLogs)
+func init() {
+	registerDecoder("v4", func(sb deployment.VersionedStartingBlocks) uint64 { return sb.V4() }, func(crawler *Crawler) TradeLogDecoder {
+		return &funcDecoder{topics: tradeLogTopicsV4, assemble: crawler.assembleTradeLogsV4}
+	})
 }
 
 func (crawler *Crawler) fillAddReserveToStorage(crResult *common.CrawlResult, log types.Log) error {
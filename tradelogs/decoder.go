@@ -0,0 +1,185 @@
+package tradelogs
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// TradeLogDecoder decodes trade logs of a KyberNetwork protocol version.
+type TradeLogDecoder interface {
+	// Topics returns the topics filter of all events needed to assemble trade logs.
+	Topics() [][]ethereum.Hash
+	// Assemble builds trade logs from event logs matching Topics, ordered as they appear in the chain.
+	Assemble(logs []types.Log) (*common.CrawlResult, error)
+}
+
+// DecoderBackend is the clients of a crawler available to decoders of other packages.
+type DecoderBackend interface {
+	// Sugar returns the logger of the crawler.
+	Sugar() *zap.SugaredLogger
+	// EthClient returns the client of the crawled ethereum node.
+	EthClient() *ethclient.Client
+	// BlockTime returns the timestamp of given block.
+	BlockTime(block uint64) (time.Time, error)
+}
+
+var _ DecoderBackend = (*Crawler)(nil)
+
+// DecoderFactory creates the decoder of a protocol version using clients of given crawler.
+type DecoderFactory func(backend DecoderBackend) TradeLogDecoder
+
+// StartingBlockFn returns the block from which a protocol version is used. Versions without accessor
+// are looked up by name with VersionedStartingBlocks.Version, their starting blocks set in chain config.
+type StartingBlockFn func(sb deployment.VersionedStartingBlocks) uint64
+
+type decoderRegistration struct {
+	startingBlock StartingBlockFn
+	factory       func(crawler *Crawler) TradeLogDecoder
+}
+
+var (
+	decodersMu sync.Mutex
+	decoders   = make(map[string]decoderRegistration)
+)
+
+// RegisterDecoder makes a trade log decoder available for the given protocol version.
+// It is intended to be called from the init function of the package implementing the version,
+// and panics if the version is registered twice.
+func RegisterDecoder(version string, startingBlock StartingBlockFn, factory DecoderFactory) {
+	registerDecoder(version, startingBlock, func(crawler *Crawler) TradeLogDecoder {
+		return factory(crawler)
+	})
+}
+
+// registerDecoder registers a decoder of this package, which may use crawler internals.
+func registerDecoder(version string, startingBlock StartingBlockFn, factory func(crawler *Crawler) TradeLogDecoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	if _, ok := decoders[version]; ok {
+		panic(fmt.Sprintf("trade log decoder is already registered for version %s", version))
+	}
+	decoders[version] = decoderRegistration{
+		startingBlock: startingBlock,
+		factory:       factory,
+	}
+}
+
+// SplitPoints returns starting blocks of all registered versions in ascending order. A block range is
+// decoded with the decoder of its from block, so crawled ranges are split at these blocks.
+func SplitPoints(sb deployment.VersionedStartingBlocks) []uint64 {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	var (
+		result []uint64
+		seen   = make(map[uint64]bool)
+	)
+	for _, r := range decoders {
+		block := r.startingBlock(sb)
+		if block == 0 || seen[block] {
+			continue
+		}
+		seen[block] = true
+		result = append(result, block)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// versionedDecoder is a decoder of a protocol version bound to a crawler.
+type versionedDecoder struct {
+	TradeLogDecoder
+	version       string
+	startingBlock uint64
+}
+
+// newVersionedDecoders creates decoders of all registered versions, the latest version first.
+func newVersionedDecoders(crawler *Crawler) []versionedDecoder {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	var result []versionedDecoder
+	for version, r := range decoders {
+		result = append(result, versionedDecoder{
+			TradeLogDecoder: r.factory(crawler),
+			version:         version,
+			startingBlock:   r.startingBlock(crawler.startingBlocks),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].startingBlock != result[j].startingBlock {
+			return result[i].startingBlock > result[j].startingBlock
+		}
+		return versionLess(result[j].version, result[i].version)
+	})
+	return result
+}
+
+// versionLess reports whether version a is older than b. Versions are compared by their number, so v10
+// is newer than v4, versions without a number are compared as strings.
+func versionLess(a, b string) bool {
+	na, errA := strconv.Atoi(strings.TrimPrefix(a, "v"))
+	nb, errB := strconv.Atoi(strings.TrimPrefix(b, "v"))
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return na < nb
+}
+
+// decoderAt returns the decoder of the latest version deployed at given block.
+func (crawler *Crawler) decoderAt(block uint64) (versionedDecoder, error) {
+	for _, d := range crawler.decoders {
+		if block >= d.startingBlock {
+			return d, nil
+		}
+	}
+	return versionedDecoder{}, fmt.Errorf("no trade log decoder for block %d", block)
+}
+
+// latestDecoder returns the decoder of the latest registered version.
+func (crawler *Crawler) latestDecoder() (versionedDecoder, error) {
+	if len(crawler.decoders) == 0 {
+		return versionedDecoder{}, errors.New("no trade log decoder registered")
+	}
+	return crawler.decoders[0], nil
+}
+
+func (crawler *Crawler) fetchTradeLogs(decoder TradeLogDecoder, fromBlock, toBlock *big.Int, timeout time.Duration) (*common.CrawlResult, error) {
+	typeLogs, err := crawler.fetchLogsWithTopics(fromBlock, toBlock, timeout, decoder.Topics())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch log by topic")
+	}
+
+	result, err := decoder.Assemble(typeLogs)
+	if err != nil {
+		return result, err
+	}
+	result.Blocks = blockInfosFromLogs(typeLogs)
+	return result, nil
+}
+
+// funcDecoder is a TradeLogDecoder of fixed topics and an assemble function.
+type funcDecoder struct {
+	topics   [][]ethereum.Hash
+	assemble func([]types.Log) (*common.CrawlResult, error)
+}
+
+func (d *funcDecoder) Topics() [][]ethereum.Hash {
+	return d.topics
+}
+
+func (d *funcDecoder) Assemble(logs []types.Log) (*common.CrawlResult, error) {
+	return d.assemble(logs)
+}
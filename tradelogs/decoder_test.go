@@ -0,0 +1,61 @@
+package tradelogs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
+)
+
+func TestDecoderAt(t *testing.T) {
+	sb := deployment.StartingBlocks[deployment.Production]
+	crawler := &Crawler{startingBlocks: sb}
+	crawler.decoders = newVersionedDecoders(crawler)
+
+	var tests = []struct {
+		block   uint64
+		version string
+	}{
+		{block: 0, version: "v1"},
+		{block: sb.V2() - 1, version: "v1"},
+		{block: sb.V2(), version: "v2"},
+		{block: sb.V3() - 1, version: "v2"},
+		{block: sb.V3(), version: "v3"},
+		{block: sb.V4() - 1, version: "v3"},
+		{block: sb.V4(), version: "v4"},
+		{block: sb.V4() + 1000000, version: "v4"},
+	}
+	for _, tc := range tests {
+		decoder, err := crawler.decoderAt(tc.block)
+		require.NoError(t, err)
+		assert.Equal(t, tc.version, decoder.version, "block %d", tc.block)
+	}
+
+	latest, err := crawler.latestDecoder()
+	require.NoError(t, err)
+	assert.Equal(t, "v4", latest.version)
+	assert.Equal(t, tradeLogTopicsV4, latest.Topics())
+}
+
+func TestRegisterDecoderDuplicated(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterDecoder("v4", func(sb deployment.VersionedStartingBlocks) uint64 { return sb.V4() }, func(DecoderBackend) TradeLogDecoder {
+			return &funcDecoder{topics: tradeLogTopicsV4}
+		})
+	})
+}
+
+func TestSplitPoints(t *testing.T) {
+	sb := deployment.StartingBlocks[deployment.Production]
+	// v1 starts at genesis, it does not split ranges
+	assert.Equal(t, []uint64{sb.V2(), sb.V3(), sb.V4()}, SplitPoints(sb))
+}
+
+func TestVersionLess(t *testing.T) {
+	assert.True(t, versionLess("v4", "v10"))
+	assert.False(t, versionLess("v10", "v4"))
+	assert.False(t, versionLess("v4", "v4"))
+	assert.True(t, versionLess("katalyst", "v4"))
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+const defaultPageSize = 1000
+
+// Source is the storage of trade logs to export.
+type Source interface {
+	LoadTradeLogsPage(filter common.TradeLogFilter) ([]common.TradelogV4, error)
+}
+
+// Exporter streams trade logs from storage page by page, so memory usage does not depend
+// on the size of the exported range.
+type Exporter struct {
+	sugar          *zap.SugaredLogger
+	source         Source
+	pageSize       uint64
+	symbolResolver blockchain.TokenSymbolResolver
+}
+
+// Option configures the Exporter constructor.
+type Option func(*Exporter)
+
+// WithPageSize configures the number of trade logs loaded from storage at once.
+func WithPageSize(pageSize uint64) Option {
+	return func(e *Exporter) {
+		if pageSize > 0 {
+			e.pageSize = pageSize
+		}
+	}
+}
+
+// WithSymbolResolver configures the Exporter to fill token symbols of exported trade logs.
+func WithSymbolResolver(resolver blockchain.TokenSymbolResolver) Option {
+	return func(e *Exporter) {
+		e.symbolResolver = resolver
+	}
+}
+
+// NewExporter returns a new Exporter instance.
+func NewExporter(sugar *zap.SugaredLogger, source Source, options ...Option) *Exporter {
+	e := &Exporter{
+		sugar:    sugar,
+		source:   source,
+		pageSize: defaultPageSize,
+	}
+	for _, opt := range options {
+		opt(e)
+	}
+	return e
+}
+
+func (e *Exporter) resolveSymbol(addr ethereum.Address) (string, error) {
+	if e.symbolResolver == nil || blockchain.IsZeroAddress(addr) {
+		return "", nil
+	}
+	return e.symbolResolver.Symbol(addr)
+}
+
+// Export writes all trade logs matching filter to w, ordered by block number and log index,
+// and returns the number of exported trade logs. Limit and After of filter are ignored.
+// The caller is responsible to close w.
+func (e *Exporter) Export(filter common.TradeLogFilter, w Writer) (uint64, error) {
+	var (
+		logger = e.sugar.With("func", caller.GetCurrentFunctionName(),
+			"from_block", filter.FromBlock, "to_block", filter.ToBlock)
+		total uint64
+	)
+	filter.Limit = e.pageSize
+	filter.After = nil
+	for {
+		tradeLogs, err := e.source.LoadTradeLogsPage(filter)
+		if err != nil {
+			return total, err
+		}
+		for _, tl := range tradeLogs {
+			if tl.TokenInfo.SrcSymbol, err = e.resolveSymbol(tl.TokenInfo.SrcAddress); err != nil {
+				return total, err
+			}
+			if tl.TokenInfo.DestSymbol, err = e.resolveSymbol(tl.TokenInfo.DestAddress); err != nil {
+				return total, err
+			}
+			row := NewRow(tl)
+			if err = w.Write(&row); err != nil {
+				return total, err
+			}
+		}
+		if err = w.Flush(); err != nil {
+			return total, err
+		}
+		total += uint64(len(tradeLogs))
+		logger.Debugw("exported trade logs page", "page_size", len(tradeLogs), "total", total)
+		if uint64(len(tradeLogs)) < e.pageSize {
+			break
+		}
+		cursor := common.CursorOf(tradeLogs[len(tradeLogs)-1])
+		filter.After = &cursor
+	}
+	logger.Infow("exported trade logs", "total", total)
+	return total, nil
+}
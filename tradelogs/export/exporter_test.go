@@ -0,0 +1,139 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/testutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// mockSource returns trade logs after the cursor of filter, up to filter.Limit.
+type mockSource struct {
+	tradeLogs []common.TradelogV4
+	calls     int
+}
+
+func (s *mockSource) LoadTradeLogsPage(filter common.TradeLogFilter) ([]common.TradelogV4, error) {
+	s.calls++
+	var result []common.TradelogV4
+	for _, tl := range s.tradeLogs {
+		if filter.After != nil && (tl.BlockNumber < filter.After.BlockNumber ||
+			tl.BlockNumber == filter.After.BlockNumber && tl.Index <= filter.After.LogIndex) {
+			continue
+		}
+		if uint64(len(result)) == filter.Limit {
+			break
+		}
+		result = append(result, tl)
+	}
+	return result, nil
+}
+
+func newTestTradeLogs() []common.TradelogV4 {
+	var (
+		reserve1 = ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f")
+		reserve2 = ethereum.HexToAddress("0x21433dec9cb634a23c6a4bbcce08c83f5ac2ec18")
+	)
+	return []common.TradelogV4{
+		{BlockNumber: 10, Index: 1, EthAmount: big.NewInt(100)},
+		{BlockNumber: 10, Index: 5, EthAmount: big.NewInt(200),
+			// splits and fees are not ordered by index
+			Split: []common.TradeSplit{
+				{ReserveAddress: reserve2, SrcAmount: big.NewInt(2), Index: 2},
+				{ReserveAddress: reserve1, SrcAmount: big.NewInt(1), Index: 1},
+			},
+			Fees: []common.TradelogFee{
+				{ReserveAddr: reserve2, Burn: big.NewInt(20), Index: 2},
+				{ReserveAddr: reserve1, Burn: big.NewInt(10), Index: 1},
+			},
+		},
+		{BlockNumber: 11, Index: 0, EthAmount: big.NewInt(300)},
+	}
+}
+
+func TestNewRow(t *testing.T) {
+	row := NewRow(newTestTradeLogs()[1])
+	assert.Equal(t, int64(2), row.SplitCount)
+	assert.Equal(t, "0x63825c174ab367968ec60f061753d3bbd36a0d8f;0x21433dec9cb634a23c6a4bbcce08c83f5ac2ec18", row.SplitReserves)
+	assert.Equal(t, "1;2", row.SplitSrcAmounts)
+	assert.Equal(t, "0x63825c174ab367968ec60f061753d3bbd36a0d8f;0x21433dec9cb634a23c6a4bbcce08c83f5ac2ec18", row.FeeReserves)
+	assert.Equal(t, "30", row.Burn)
+	assert.Equal(t, "1;2", row.FeeIndexes)
+	assert.Equal(t, "10;20", row.FeeBurns)
+	assert.Equal(t, "0", row.Reward)
+	assert.Equal(t, "200", row.EthAmount)
+	assert.Equal(t, len(columns), len(row.record()))
+}
+
+func TestExporter(t *testing.T) {
+	sugar := testutil.MustNewDevelopmentSugaredLogger()
+
+	t.Run("csv", func(t *testing.T) {
+		var (
+			buf    bytes.Buffer
+			source = &mockSource{tradeLogs: newTestTradeLogs()}
+		)
+		w, err := NewWriter(CSV, &buf)
+		require.NoError(t, err)
+		total, err := NewExporter(sugar, source, WithPageSize(2)).Export(common.TradeLogFilter{}, w)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Equal(t, uint64(3), total)
+		assert.Equal(t, 2, source.calls)
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		assert.Equal(t, columns, records[0])
+		assert.Equal(t, []string{"10", "10", "11"}, []string{records[1][1], records[2][1], records[3][1]})
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewWriter(NDJSON, &buf)
+		require.NoError(t, err)
+		total, err := NewExporter(sugar, &mockSource{tradeLogs: newTestTradeLogs()}).Export(common.TradeLogFilter{}, w)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Equal(t, uint64(3), total)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 3)
+		var row Row
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), &row))
+		assert.Equal(t, "300", row.EthAmount)
+	})
+
+	t.Run("reproducible", func(t *testing.T) {
+		export := func(pageSize uint64) string {
+			var buf bytes.Buffer
+			w, err := NewWriter(CSV, &buf)
+			require.NoError(t, err)
+			_, err = NewExporter(sugar, &mockSource{tradeLogs: newTestTradeLogs()}, WithPageSize(pageSize)).
+				Export(common.TradeLogFilter{}, w)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			return buf.String()
+		}
+		assert.Equal(t, export(1), export(100))
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"csv", "ndjson", "parquet"} {
+		f, err := ParseFormat(name)
+		require.NoError(t, err)
+		assert.Equal(t, Format(name), f)
+	}
+	_, err := ParseFormat("xlsx")
+	assert.Error(t, err)
+}
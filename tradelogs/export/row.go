@@ -0,0 +1,239 @@
+package export
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+const (
+	// ListSeparator joins values of splits and fees flattened into a single column.
+	ListSeparator = ";"
+	// ValueSeparator joins multiple values of a single split or fee.
+	ValueSeparator = "|"
+)
+
+// Row is a trade log flattened into columns. Token amounts are in wei and encoded as decimal
+// strings to keep full precision, splits and fees are joined by ListSeparator in index order.
+type Row struct {
+	Timestamp         int64   `json:"timestamp" parquet:"name=timestamp, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	BlockNumber       int64   `json:"block_number" parquet:"name=block_number, type=INT64"`
+	TxHash            string  `json:"tx_hash" parquet:"name=tx_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	LogIndex          int64   `json:"log_index" parquet:"name=log_index, type=INT64"`
+	Version           int64   `json:"version" parquet:"name=version, type=INT64"`
+	UserAddress       string  `json:"user_addr" parquet:"name=user_addr, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ReceiverAddress   string  `json:"receiver_address" parquet:"name=receiver_address, type=BYTE_ARRAY, convertedtype=UTF8"`
+	TxSender          string  `json:"tx_sender" parquet:"name=tx_sender, type=BYTE_ARRAY, convertedtype=UTF8"`
+	WalletAddress     string  `json:"wallet_addr" parquet:"name=wallet_addr, type=BYTE_ARRAY, convertedtype=UTF8"`
+	IntegrationApp    string  `json:"integration_app" parquet:"name=integration_app, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Country           string  `json:"country" parquet:"name=country, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SrcToken          string  `json:"src_token" parquet:"name=src_token, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SrcSymbol         string  `json:"src_symbol" parquet:"name=src_symbol, type=BYTE_ARRAY, convertedtype=UTF8"`
+	DstToken          string  `json:"dst_token" parquet:"name=dst_token, type=BYTE_ARRAY, convertedtype=UTF8"`
+	DstSymbol         string  `json:"dst_symbol" parquet:"name=dst_symbol, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SrcAmount         string  `json:"src_amount" parquet:"name=src_amount, type=BYTE_ARRAY, convertedtype=UTF8"`
+	DstAmount         string  `json:"dst_amount" parquet:"name=dst_amount, type=BYTE_ARRAY, convertedtype=UTF8"`
+	EthAmount         string  `json:"eth_amount" parquet:"name=eth_amount, type=BYTE_ARRAY, convertedtype=UTF8"`
+	OriginalEthAmount string  `json:"original_eth_amount" parquet:"name=original_eth_amount, type=BYTE_ARRAY, convertedtype=UTF8"`
+	FiatAmount        float64 `json:"fiat_amount" parquet:"name=fiat_amount, type=DOUBLE"`
+	ETHUSDRate        float64 `json:"eth_usd_rate" parquet:"name=eth_usd_rate, type=DOUBLE"`
+	GasUsed           int64   `json:"gas_used" parquet:"name=gas_used, type=INT64"`
+	GasPrice          string  `json:"gas_price" parquet:"name=gas_price, type=BYTE_ARRAY, convertedtype=UTF8"`
+	TransactionFee    string  `json:"transaction_fee" parquet:"name=transaction_fee, type=BYTE_ARRAY, convertedtype=UTF8"`
+
+	SplitCount      int64  `json:"split_count" parquet:"name=split_count, type=INT64"`
+	SplitReserves   string `json:"split_reserves" parquet:"name=split_reserves, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SplitSrcTokens  string `json:"split_src_tokens" parquet:"name=split_src_tokens, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SplitDstTokens  string `json:"split_dst_tokens" parquet:"name=split_dst_tokens, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SplitSrcAmounts string `json:"split_src_amounts" parquet:"name=split_src_amounts, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SplitDstAmounts string `json:"split_dst_amounts" parquet:"name=split_dst_amounts, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SplitRates      string `json:"split_rates" parquet:"name=split_rates, type=BYTE_ARRAY, convertedtype=UTF8"`
+
+	FeeCount           int64  `json:"fee_count" parquet:"name=fee_count, type=INT64"`
+	FeeReserves        string `json:"fee_reserves" parquet:"name=fee_reserves, type=BYTE_ARRAY, convertedtype=UTF8"`
+	FeePlatformWallets string `json:"fee_platform_wallets" parquet:"name=fee_platform_wallets, type=BYTE_ARRAY, convertedtype=UTF8"`
+	// fee amounts are the sum of all fees of the trade
+	PlatformFee    string `json:"platform_fee" parquet:"name=platform_fee, type=BYTE_ARRAY, convertedtype=UTF8"`
+	PlatformRebate string `json:"platform_rebate" parquet:"name=platform_rebate, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Burn           string `json:"burn" parquet:"name=burn, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ReserveRebate  string `json:"reserve_rebate" parquet:"name=reserve_rebate, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Reward         string `json:"reward" parquet:"name=reward, type=BYTE_ARRAY, convertedtype=UTF8"`
+
+	// per fee values, so fees can be restored by importer
+	FeeIndexes         string `json:"fee_indexes" parquet:"name=fee_indexes, type=BYTE_ARRAY, convertedtype=UTF8"`
+	FeePlatformFees    string `json:"fee_platform_fees" parquet:"name=fee_platform_fees, type=BYTE_ARRAY, convertedtype=UTF8"`
+	FeePlatformRebates string `json:"fee_platform_rebates" parquet:"name=fee_platform_rebates, type=BYTE_ARRAY, convertedtype=UTF8"`
+	FeeBurns           string `json:"fee_burns" parquet:"name=fee_burns, type=BYTE_ARRAY, convertedtype=UTF8"`
+	FeeReserveRebates  string `json:"fee_reserve_rebates" parquet:"name=fee_reserve_rebates, type=BYTE_ARRAY, convertedtype=UTF8"`
+	FeeRewards         string `json:"fee_rewards" parquet:"name=fee_rewards, type=BYTE_ARRAY, convertedtype=UTF8"`
+	// rebate wallets and percents of each fee are joined by ValueSeparator
+	FeeRebateWallets  string `json:"fee_rebate_wallets" parquet:"name=fee_rebate_wallets, type=BYTE_ARRAY, convertedtype=UTF8"`
+	FeeRebatePercents string `json:"fee_rebate_percents" parquet:"name=fee_rebate_percents, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// columns is the header of CSV output, it must be in the same order as Row.record.
+var columns = []string{
+	"timestamp", "block_number", "tx_hash", "log_index", "version",
+	"user_addr", "receiver_address", "tx_sender", "wallet_addr", "integration_app", "country",
+	"src_token", "src_symbol", "dst_token", "dst_symbol",
+	"src_amount", "dst_amount", "eth_amount", "original_eth_amount", "fiat_amount", "eth_usd_rate",
+	"gas_used", "gas_price", "transaction_fee",
+	"split_count", "split_reserves", "split_src_tokens", "split_dst_tokens",
+	"split_src_amounts", "split_dst_amounts", "split_rates",
+	"fee_count", "fee_reserves", "fee_platform_wallets",
+	"platform_fee", "platform_rebate", "burn", "reserve_rebate", "reward",
+	"fee_indexes", "fee_platform_fees", "fee_platform_rebates", "fee_burns", "fee_reserve_rebates", "fee_rewards",
+	"fee_rebate_wallets", "fee_rebate_percents",
+}
+
+// Columns returns the header of CSV output.
+func Columns() []string {
+	return append([]string(nil), columns...)
+}
+
+func (r *Row) record() []string {
+	formatInt := func(i int64) string { return strconv.FormatInt(i, 10) }
+	formatFloat := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	return []string{
+		formatInt(r.Timestamp), formatInt(r.BlockNumber), r.TxHash, formatInt(r.LogIndex), formatInt(r.Version),
+		r.UserAddress, r.ReceiverAddress, r.TxSender, r.WalletAddress, r.IntegrationApp, r.Country,
+		r.SrcToken, r.SrcSymbol, r.DstToken, r.DstSymbol,
+		r.SrcAmount, r.DstAmount, r.EthAmount, r.OriginalEthAmount, formatFloat(r.FiatAmount), formatFloat(r.ETHUSDRate),
+		formatInt(r.GasUsed), r.GasPrice, r.TransactionFee,
+		formatInt(r.SplitCount), r.SplitReserves, r.SplitSrcTokens, r.SplitDstTokens,
+		r.SplitSrcAmounts, r.SplitDstAmounts, r.SplitRates,
+		formatInt(r.FeeCount), r.FeeReserves, r.FeePlatformWallets,
+		r.PlatformFee, r.PlatformRebate, r.Burn, r.ReserveRebate, r.Reward,
+		r.FeeIndexes, r.FeePlatformFees, r.FeePlatformRebates, r.FeeBurns, r.FeeReserveRebates, r.FeeRewards,
+		r.FeeRebateWallets, r.FeeRebatePercents,
+	}
+}
+
+func bigString(i *big.Int) string {
+	if i == nil {
+		return "0"
+	}
+	return i.String()
+}
+
+func addressString(addr ethereum.Address) string {
+	return strings.ToLower(addr.Hex())
+}
+
+func joinBigInts(values []*big.Int) string {
+	var result []string
+	for _, v := range values {
+		result = append(result, bigString(v))
+	}
+	return strings.Join(result, ListSeparator)
+}
+
+// sum returns the sum of values, nil values are ignored.
+func sum(values ...*big.Int) *big.Int {
+	result := big.NewInt(0)
+	for _, v := range values {
+		if v != nil {
+			result.Add(result, v)
+		}
+	}
+	return result
+}
+
+// NewRow flattens the trade log. Splits and fees are sorted by index so the output does not
+// depend on the order returned by storage.
+func NewRow(tl common.TradelogV4) Row {
+	row := Row{
+		Timestamp:         int64(timeutil.TimeToTimestampMs(tl.Timestamp)),
+		BlockNumber:       int64(tl.BlockNumber),
+		TxHash:            tl.TransactionHash.Hex(),
+		LogIndex:          int64(tl.Index),
+		Version:           int64(tl.Version),
+		UserAddress:       addressString(tl.User.UserAddress),
+		ReceiverAddress:   addressString(tl.ReceiverAddress),
+		TxSender:          addressString(tl.TxDetail.TxSender),
+		WalletAddress:     addressString(tl.WalletAddress),
+		IntegrationApp:    tl.IntegrationApp,
+		Country:           tl.User.Country,
+		SrcToken:          addressString(tl.TokenInfo.SrcAddress),
+		SrcSymbol:         tl.TokenInfo.SrcSymbol,
+		DstToken:          addressString(tl.TokenInfo.DestAddress),
+		DstSymbol:         tl.TokenInfo.DestSymbol,
+		SrcAmount:         bigString(tl.SrcAmount),
+		DstAmount:         bigString(tl.DestAmount),
+		EthAmount:         bigString(tl.EthAmount),
+		OriginalEthAmount: bigString(tl.OriginalEthAmount),
+		FiatAmount:        tl.FiatAmount,
+		ETHUSDRate:        tl.ETHUSDRate,
+		GasUsed:           int64(tl.TxDetail.GasUsed),
+		GasPrice:          bigString(tl.TxDetail.GasPrice),
+		TransactionFee:    bigString(tl.TxDetail.TransactionFee),
+		SplitCount:        int64(len(tl.Split)),
+		FeeCount:          int64(len(tl.Fees)),
+	}
+
+	splits := append([]common.TradeSplit(nil), tl.Split...)
+	sort.Slice(splits, func(i, j int) bool { return splits[i].Index < splits[j].Index })
+	var reserves, srcTokens, dstTokens, srcAmounts, dstAmounts, rates []string
+	for _, s := range splits {
+		reserves = append(reserves, addressString(s.ReserveAddress))
+		srcTokens = append(srcTokens, addressString(s.SrcToken))
+		dstTokens = append(dstTokens, addressString(s.DstToken))
+		srcAmounts = append(srcAmounts, bigString(s.SrcAmount))
+		dstAmounts = append(dstAmounts, bigString(s.DstAmount))
+		rates = append(rates, bigString(s.Rate))
+	}
+	row.SplitReserves = strings.Join(reserves, ListSeparator)
+	row.SplitSrcTokens = strings.Join(srcTokens, ListSeparator)
+	row.SplitDstTokens = strings.Join(dstTokens, ListSeparator)
+	row.SplitSrcAmounts = strings.Join(srcAmounts, ListSeparator)
+	row.SplitDstAmounts = strings.Join(dstAmounts, ListSeparator)
+	row.SplitRates = strings.Join(rates, ListSeparator)
+
+	fees := append([]common.TradelogFee(nil), tl.Fees...)
+	sort.Slice(fees, func(i, j int) bool { return fees[i].Index < fees[j].Index })
+	var (
+		feeReserves, platformWallets, feeIndexes, rebateWallets, rebatePercents []string
+		platformFees, walletFees, burns, rebates, rewards                       []*big.Int
+	)
+	for _, f := range fees {
+		feeReserves = append(feeReserves, addressString(f.ReserveAddr))
+		platformWallets = append(platformWallets, addressString(f.PlatformWallet))
+		feeIndexes = append(feeIndexes, strconv.FormatUint(uint64(f.Index), 10))
+		platformFees = append(platformFees, f.PlatformFee)
+		walletFees = append(walletFees, f.WalletFee)
+		burns = append(burns, f.Burn)
+		rebates = append(rebates, f.Rebate)
+		rewards = append(rewards, f.Reward)
+		var wallets, percents []string
+		for _, w := range f.RebateWallets {
+			wallets = append(wallets, addressString(w))
+		}
+		for _, p := range f.RebatePercentBpsPerWallet {
+			percents = append(percents, bigString(p))
+		}
+		rebateWallets = append(rebateWallets, strings.Join(wallets, ValueSeparator))
+		rebatePercents = append(rebatePercents, strings.Join(percents, ValueSeparator))
+	}
+	row.FeeReserves = strings.Join(feeReserves, ListSeparator)
+	row.FeePlatformWallets = strings.Join(platformWallets, ListSeparator)
+	row.FeeIndexes = strings.Join(feeIndexes, ListSeparator)
+	row.FeePlatformFees = joinBigInts(platformFees)
+	row.FeePlatformRebates = joinBigInts(walletFees)
+	row.FeeBurns = joinBigInts(burns)
+	row.FeeReserveRebates = joinBigInts(rebates)
+	row.FeeRewards = joinBigInts(rewards)
+	row.FeeRebateWallets = strings.Join(rebateWallets, ListSeparator)
+	row.FeeRebatePercents = strings.Join(rebatePercents, ListSeparator)
+	row.PlatformFee = sum(platformFees...).String()
+	row.PlatformRebate = sum(walletFees...).String()
+	row.Burn = sum(burns...).String()
+	row.ReserveRebate = sum(rebates...).String()
+	row.Reward = sum(rewards...).String()
+	return row
+}
@@ -0,0 +1,143 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Format is the output format of exported trade logs.
+type Format string
+
+const (
+	// CSV is comma separated values with a header row.
+	CSV Format = "csv"
+	// NDJSON is newline delimited JSON, one trade log per line.
+	NDJSON Format = "ndjson"
+	// Parquet is Apache Parquet columnar format.
+	Parquet Format = "parquet"
+)
+
+// parquetRowGroupSize is the size of buffered rows before written out as a row group.
+const parquetRowGroupSize = 16 * 1024 * 1024
+
+// ParseFormat returns the Format of given name.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(name); f {
+	case CSV, NDJSON, Parquet:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid export format: %q, supported formats: %s, %s, %s", name, CSV, NDJSON, Parquet)
+	}
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv"
+	case NDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Writer writes rows to the underlying output.
+type Writer interface {
+	Write(row *Row) error
+	// Flush writes buffered rows to the underlying output.
+	Flush() error
+	// Close flushes and finishes the output, it does not close the underlying io.Writer.
+	Close() error
+}
+
+// NewWriter returns a Writer of given format.
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case CSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case NDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case Parquet:
+		pw, err := writer.NewParquetWriterFromWriter(w, new(Row), 1)
+		if err != nil {
+			return nil, err
+		}
+		pw.RowGroupSize = parquetRowGroupSize
+		pw.CompressionType = parquet.CompressionCodec_SNAPPY
+		return &parquetWriter{pw: pw}, nil
+	default:
+		return nil, fmt.Errorf("invalid export format: %q", format)
+	}
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (cw *csvWriter) writeHeader() error {
+	if cw.headerWritten {
+		return nil
+	}
+	cw.headerWritten = true
+	return cw.w.Write(columns)
+}
+
+func (cw *csvWriter) Write(row *Row) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	return cw.w.Write(row.record())
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	// the header is written even if there is no row
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	return cw.Flush()
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (nw *ndjsonWriter) Write(row *Row) error {
+	return nw.enc.Encode(row)
+}
+
+func (nw *ndjsonWriter) Flush() error {
+	return nil
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
+
+// parquetWriter writes rows in row groups, the file footer is written on Close.
+type parquetWriter struct {
+	pw *writer.ParquetWriter
+}
+
+func (pw *parquetWriter) Write(row *Row) error {
+	return pw.pw.Write(*row)
+}
+
+func (pw *parquetWriter) Flush() error {
+	return nil
+}
+
+func (pw *parquetWriter) Close() error {
+	return pw.pw.WriteStop()
+}
@@ -0,0 +1,85 @@
+package fiat
+
+import (
+	"time"
+
+	"github.com/KyberNetwork/tokenrate"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// Storage is the trade logs storage that keeps ETH/USD rates of trades by provider.
+type Storage interface {
+	GetTradesToPrice(fromBlock, toBlock uint64, from, to time.Time) ([]common.TradeFiatPrice, error)
+	SaveFiatPrices(provider string, prices []common.TradeFiatPrice, setDefault bool) error
+}
+
+// Backfiller re-prices trades with the ETH/USD rates of a provider.
+type Backfiller struct {
+	sugar      *zap.SugaredLogger
+	st         Storage
+	provider   tokenrate.ETHUSDRateProvider
+	batchSize  int
+	setDefault bool // replace the rates fixed at crawl time
+}
+
+// NewBackfiller returns a new Backfiller instance.
+func NewBackfiller(sugar *zap.SugaredLogger, st Storage, provider tokenrate.ETHUSDRateProvider,
+	batchSize int, setDefault bool) *Backfiller {
+	return &Backfiller{
+		sugar:      sugar,
+		st:         st,
+		provider:   provider,
+		batchSize:  batchSize,
+		setDefault: setDefault,
+	}
+}
+
+// Run re-prices trades in the block or time range, zero bounds are not filtered. Rates are saved in
+// batches, so an interrupted run can be resumed by running again.
+func (b *Backfiller) Run(fromBlock, toBlock uint64, from, to time.Time) error {
+	var (
+		logger = b.sugar.With(
+			"func", caller.GetCurrentFunctionName(),
+			"provider", b.provider.Name(),
+			"from_block", fromBlock,
+			"to_block", toBlock,
+			"from", from,
+			"to", to,
+		)
+		// trades in the same minute share the rate to save provider requests
+		rates = make(map[time.Time]float64)
+	)
+	trades, err := b.st.GetTradesToPrice(fromBlock, toBlock, from, to)
+	if err != nil {
+		return err
+	}
+	logger.Infow("re-pricing trades", "trades", len(trades))
+	for start := 0; start < len(trades); start += b.batchSize {
+		end := start + b.batchSize
+		if end > len(trades) {
+			end = len(trades)
+		}
+		batch := trades[start:end]
+		for i := range batch {
+			minute := batch[i].Timestamp.UTC().Truncate(time.Minute)
+			rate, ok := rates[minute]
+			if !ok {
+				if rate, err = b.provider.USDRate(batch[i].Timestamp); err != nil {
+					logger.Errorw("failed to get ETH/USD rate", "tradelog_id", batch[i].TradelogID, "error", err)
+					return err
+				}
+				rates[minute] = rate
+			}
+			batch[i].ETHUSDRate = rate
+		}
+		if err = b.st.SaveFiatPrices(b.provider.Name(), batch, b.setDefault); err != nil {
+			return err
+		}
+		logger.Infow("re-priced trades", "batch_from_id", batch[0].TradelogID,
+			"batch_to_id", batch[len(batch)-1].TradelogID, "trades", len(batch))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package fiat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+type mockStorage struct {
+	trades     []common.TradeFiatPrice
+	saved      map[string][]common.TradeFiatPrice
+	setDefault bool
+}
+
+func (s *mockStorage) GetTradesToPrice(fromBlock, toBlock uint64, from, to time.Time) ([]common.TradeFiatPrice, error) {
+	result := make([]common.TradeFiatPrice, len(s.trades))
+	copy(result, s.trades)
+	return result, nil
+}
+
+func (s *mockStorage) SaveFiatPrices(provider string, prices []common.TradeFiatPrice, setDefault bool) error {
+	s.saved[provider] = append(s.saved[provider], prices...)
+	s.setDefault = setDefault
+	return nil
+}
+
+type mockProvider struct {
+	calls int
+}
+
+func (p *mockProvider) USDRate(timestamp time.Time) (float64, error) {
+	p.calls++
+	return float64(timestamp.Minute()), nil
+}
+
+func (p *mockProvider) Name() string {
+	return "mock"
+}
+
+func TestBackfiller(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+
+	base := time.Date(2020, 7, 10, 9, 30, 0, 0, time.UTC)
+	st := &mockStorage{
+		trades: []common.TradeFiatPrice{
+			{TradelogID: 1, Timestamp: base.Add(5 * time.Second)},
+			{TradelogID: 2, Timestamp: base.Add(40 * time.Second)},
+			{TradelogID: 3, Timestamp: base.Add(2 * time.Minute)},
+		},
+		saved: make(map[string][]common.TradeFiatPrice),
+	}
+	provider := &mockProvider{}
+	require.NoError(t, NewBackfiller(logger.Sugar(), st, provider, 2, true).Run(0, 0, base, base.Add(time.Hour)))
+
+	// trades in the same minute share the rate
+	assert.Equal(t, 2, provider.calls)
+	assert.True(t, st.setDefault)
+	require.Len(t, st.saved["mock"], 3)
+	for i, expected := range []float64{30, 30, 32} {
+		assert.Equal(t, uint64(i+1), st.saved["mock"][i].TradelogID)
+		assert.Equal(t, expected, st.saved["mock"][i].ETHUSDRate)
+	}
+}
@@ -11,7 +11,9 @@ import (
 
 type assetVolumeQuery struct {
 	httputil.TimeRangeQueryFreq
-	Asset string `form:"asset" binding:"required,isAddress"`
+	Asset    string `form:"asset" binding:"required,isAddress"`
+	Provider string `form:"provider"`
+	httputil.TimezoneQuery
 }
 
 func (sv *Server) getAssetVolume(c *gin.Context) {
@@ -35,9 +37,15 @@ func (sv *Server) getAssetVolume(c *gin.Context) {
 		return
 	}
 
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
 	token := common.HexToAddress(query.Asset)
 
-	result, err := sv.storage.GetAssetVolume(token, from, to, query.Freq)
+	result, err := sv.storage.GetAssetVolume(token, from, to, query.Freq, query.Provider, timezone)
 	if err != nil {
 		c.JSON(
 			http.StatusInternalServerError,
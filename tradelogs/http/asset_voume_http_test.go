@@ -24,7 +24,7 @@ const (
 	testVolAmount = 0.333
 )
 
-func (s *mockStorage) GetReserveVolume(rsvAddr ethereum.Address, token ethereum.Address, fromTime, toTime time.Time, frequency string) (map[uint64]*common.VolumeStats, error) {
+func (s *mockStorage) GetReserveVolume(rsvAddr ethereum.Address, token ethereum.Address, fromTime, toTime time.Time, frequency, provider string, timezone *time.Location) (map[uint64]*common.VolumeStats, error) {
 	return nil, nil
 }
 
@@ -32,7 +32,7 @@ func (s *mockStorage) GetMonthlyVolume(reserveAddr ethereum.Address, fromTime, t
 	return nil, nil
 }
 
-func (s *mockStorage) GetAssetVolume(token ethereum.Address, fromTime, toTime time.Time, frequency string) (map[uint64]*common.VolumeStats, error) {
+func (s *mockStorage) GetAssetVolume(token ethereum.Address, fromTime, toTime time.Time, frequency, provider string, timezone *time.Location) (map[uint64]*common.VolumeStats, error) {
 	from := timeutil.TimeToTimestampMs(fromTime)
 	to := timeutil.TimeToTimestampMs(toTime)
 	var (
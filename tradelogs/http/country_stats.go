@@ -12,7 +12,7 @@ import (
 type countryStatsQuery struct {
 	httputil.TimeRangeQuery
 	CountryCode string `form:"country" binding:"required,isValidCountryCode"`
-	Timezone    int8   `form:"timezone" binding:"isSupportedTimezone"`
+	httputil.TimezoneQuery
 }
 
 func (sv *Server) getCountryStats(c *gin.Context) {
@@ -35,12 +35,18 @@ func (sv *Server) getCountryStats(c *gin.Context) {
 		httputil.ResponseFailure(c, http.StatusBadRequest, err)
 		return
 	}
+
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
 	countryCode := query.CountryCode
 	if countryCode == common.UnknownCountry {
 		countryCode = ""
 	}
 
-	countryStats, err := sv.storage.GetCountryStats(countryCode, from, to, query.Timezone)
+	countryStats, err := sv.storage.GetCountryStats(countryCode, from, to, timezone)
 	if err != nil {
 		httputil.ResponseFailure(
 			c,
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	libhttputil "github.com/KyberNetwork/reserve-stats/lib/httputil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+type crawlJobsQuery struct {
+	Status string `form:"status"`
+}
+
+func (sv *Server) getCrawlJobs(c *gin.Context) {
+	var query crawlJobsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		libhttputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+	status := common.CrawlJobStatus(query.Status)
+	switch status {
+	case "":
+		status = common.CrawlJobStatusFailed
+	case common.CrawlJobStatusPending, common.CrawlJobStatusRunning, common.CrawlJobStatusDone, common.CrawlJobStatusFailed:
+	default:
+		libhttputil.ResponseFailure(c, http.StatusBadRequest, fmt.Errorf("invalid status: %s", query.Status))
+		return
+	}
+
+	jobs, err := sv.storage.GetCrawlJobs(status)
+	if err != nil {
+		libhttputil.ResponseFailure(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+type retryCrawlJobsRequest struct {
+	IDs []uint64 `json:"ids"`
+}
+
+type retryCrawlJobsResponse struct {
+	Retried int64 `json:"retried"`
+}
+
+func (sv *Server) retryCrawlJobs(c *gin.Context) {
+	var query retryCrawlJobsRequest
+	if err := c.ShouldBindJSON(&query); err != nil {
+		libhttputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	retried, err := sv.storage.RetryCrawlJobs(query.IDs)
+	if err != nil {
+		libhttputil.ResponseFailure(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, retryCrawlJobsResponse{Retried: retried})
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+func TestCrawlJobsRoute(t *testing.T) {
+	s, err := newTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := s.setupRouter()
+
+	expectOK := func(t *testing.T, resp *httptest.ResponseRecorder) {
+		require.Equal(t, http.StatusOK, resp.Code)
+	}
+
+	var tests = []httputil.HTTPTestCase{
+		{
+			Msg:      "Test failed jobs by default",
+			Endpoint: "/crawl-jobs",
+			Method:   http.MethodGet,
+			Assert:   expectOK,
+		},
+		{
+			Msg:      "Test pending jobs",
+			Endpoint: "/crawl-jobs?status=pending",
+			Method:   http.MethodGet,
+			Assert:   expectOK,
+		},
+		{
+			Msg:      "Test invalid status",
+			Endpoint: "/crawl-jobs?status=lost",
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test retry jobs",
+			Endpoint: "/crawl-jobs/retry",
+			Method:   http.MethodPost,
+			Body:     []byte(`{"ids": [1, 2]}`),
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+				require.JSONEq(t, `{"retried": 2}`, resp.Body.String())
+			},
+		},
+		{
+			Msg:      "Test retry all jobs",
+			Endpoint: "/crawl-jobs/retry",
+			Method:   http.MethodPost,
+			Body:     []byte(`{}`),
+			Assert:   expectOK,
+		},
+		{
+			Msg:      "Test invalid retry request",
+			Endpoint: "/crawl-jobs/retry",
+			Method:   http.MethodPost,
+			Body:     []byte(`{"ids": ["a"]}`),
+			Assert:   expectInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Msg, func(t *testing.T) { httputil.RunHTTPTestCase(t, tc, router) })
+	}
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/export"
+)
+
+// maxExportBlockRange is the maximum number of blocks of an export, about a month of blocks.
+const maxExportBlockRange = 200000
+
+type exportTradeLogsQuery struct {
+	FromBlock uint64 `form:"from_block"`
+	// ToBlock is a pointer as required binding rejects the zero value, exists only checks it is set
+	ToBlock *uint64 `form:"to_block" binding:"exists"`
+	Format  string  `form:"format"`
+
+	Tokens          []string `form:"token" binding:"dive,isAddress"`
+	Reserves        []string `form:"reserve" binding:"dive,isAddress"`
+	Wallets         []string `form:"wallet" binding:"dive,isAddress"`
+	Users           []string `form:"user" binding:"dive,isAddress"`
+	IntegrationApps []string `form:"integration_app"`
+}
+
+// flushWriter flushes the response after each page of trade logs is written, so the client
+// receives data while the export is running.
+type flushWriter struct {
+	export.Writer
+	flusher http.Flusher
+}
+
+func (fw *flushWriter) Flush() error {
+	if err := fw.Writer.Flush(); err != nil {
+		return err
+	}
+	fw.flusher.Flush()
+	return nil
+}
+
+func (sv *Server) exportTradeLogs(c *gin.Context) {
+	var query exportTradeLogsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+	toBlock := *query.ToBlock
+	if query.FromBlock > toBlock {
+		httputil.ResponseFailure(c, http.StatusBadRequest, errors.New("from_block must not be greater than to_block"))
+		return
+	}
+	if toBlock-query.FromBlock > maxExportBlockRange {
+		httputil.ResponseFailure(c, http.StatusBadRequest,
+			fmt.Errorf("block range is too broad, max is %d blocks", maxExportBlockRange))
+		return
+	}
+	if query.Format == "" {
+		query.Format = string(export.CSV)
+	}
+	format, err := export.ParseFormat(query.Format)
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	filter := common.TradeLogFilter{
+		FromBlock:       query.FromBlock,
+		ToBlock:         toBlock,
+		Tokens:          hexToAddresses(query.Tokens),
+		Reserves:        hexToAddresses(query.Reserves),
+		Wallets:         hexToAddresses(query.Wallets),
+		Users:           hexToAddresses(query.Users),
+		IntegrationApps: query.IntegrationApps,
+	}
+	w, err := export.NewWriter(format, c.Writer)
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="trade-logs-%d-%d.%s"`,
+		query.FromBlock, toBlock, format))
+	c.Status(http.StatusOK)
+
+	exporter := export.NewExporter(sv.sugar, sv.storage, export.WithSymbolResolver(sv.symbolResolver))
+	// the response status is already sent, errors can only be logged and the output is truncated
+	if _, err = exporter.Export(filter, &flushWriter{Writer: w, flusher: c.Writer}); err != nil {
+		sv.sugar.Errorw("failed to export trade logs", "from_block", query.FromBlock, "to_block", toBlock, "error", err)
+		return
+	}
+	if err = w.Close(); err != nil {
+		sv.sugar.Errorw("failed to finish trade logs export", "error", err)
+	}
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+func TestExportTradeLogsRoute(t *testing.T) {
+	s, err := newTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := s.setupRouter()
+
+	var tests = []httputil.HTTPTestCase{
+		{
+			Msg:      "Test valid csv export",
+			Endpoint: "/export/trade-logs?from_block=100&to_block=200",
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.Equal(t, "text/csv", resp.Header().Get("Content-Type"))
+				// empty export still has the header row
+				assert.True(t, strings.HasPrefix(resp.Body.String(), "timestamp,block_number,tx_hash"))
+			},
+		},
+		{
+			Msg:      "Test valid ndjson export",
+			Endpoint: "/export/trade-logs?from_block=100&to_block=200&format=ndjson",
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.Equal(t, "application/x-ndjson", resp.Header().Get("Content-Type"))
+			},
+		},
+		{
+			Msg:      "Test export from genesis block",
+			Endpoint: "/export/trade-logs?from_block=0&to_block=0",
+			Method:   http.MethodGet,
+			Assert:   httputil.AssertCode(http.StatusOK),
+		},
+		{
+			Msg:      "Test missing block range",
+			Endpoint: "/export/trade-logs?from_block=100",
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test invalid block range",
+			Endpoint: "/export/trade-logs?from_block=200&to_block=100",
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test block range too broad",
+			Endpoint: "/export/trade-logs?from_block=100&to_block=1000000",
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test invalid format",
+			Endpoint: "/export/trade-logs?from_block=100&to_block=200&format=xlsx",
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Msg, func(t *testing.T) { httputil.RunHTTPTestCase(t, tc, router) })
+	}
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+type feeBreakdownQuery struct {
+	httputil.TimeRangeQueryFreq
+	Addresses []string `form:"address" binding:"dive,isAddress"`
+	httputil.TimezoneQuery
+}
+
+// getFeeBreakdown returns the handler of Katalyst fees aggregated by given group,
+// results are keyed by address then by timestamp in milliseconds.
+func (sv *Server) getFeeBreakdown(group common.FeeGroup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query feeBreakdownQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			httputil.ResponseFailure(c, http.StatusBadRequest, err)
+			return
+		}
+
+		fromTime, toTime, err := query.Validate()
+		if err != nil {
+			httputil.ResponseFailure(c, http.StatusBadRequest, err)
+			return
+		}
+
+		timezone, err := query.Location()
+		if err != nil {
+			httputil.ResponseFailure(c, http.StatusBadRequest, err)
+			return
+		}
+
+		fees, err := sv.storage.GetFeeBreakdown(group, fromTime, toTime, query.Freq, timezone,
+			hexToAddresses(query.Addresses))
+		if err != nil {
+			sv.sugar.Errorw("failed to get fee breakdown", "group", group, "query", query, "error", err)
+			httputil.ResponseFailure(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, fees)
+	}
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+func TestFeeBreakdownRoute(t *testing.T) {
+	s, err := newTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := s.setupRouter()
+
+	expectOK := func(t *testing.T, resp *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusOK, resp.Code)
+	}
+
+	var tests []httputil.HTTPTestCase
+	for _, endpoint := range []string{
+		"/fee-breakdown/reserve",
+		"/fee-breakdown/rebate-wallet",
+		"/fee-breakdown/platform-wallet",
+	} {
+		tests = append(tests,
+			httputil.HTTPTestCase{
+				Msg:      fmt.Sprintf("Test valid request %s", endpoint),
+				Endpoint: fmt.Sprintf("%s?from=%d&to=%d&freq=h", endpoint, fromTime, toTime),
+				Method:   http.MethodGet,
+				Assert:   expectOK,
+			},
+			httputil.HTTPTestCase{
+				Msg: fmt.Sprintf("Test valid request with address filter %s", endpoint),
+				Endpoint: fmt.Sprintf("%s?from=%d&to=%d&freq=d&timezone=7&address=%s&address=%s",
+					endpoint, fromTime, toTime, reserveAddr, walletAddr),
+				Method: http.MethodGet,
+				Assert: expectOK,
+			},
+			httputil.HTTPTestCase{
+				Msg:      fmt.Sprintf("Test valid IANA timezone %s", endpoint),
+				Endpoint: fmt.Sprintf("%s?from=%d&to=%d&freq=d&timezone=Asia/Kolkata", endpoint, fromTime, toTime),
+				Method:   http.MethodGet,
+				Assert:   expectOK,
+			},
+			httputil.HTTPTestCase{
+				Msg:      fmt.Sprintf("Test invalid IANA timezone %s", endpoint),
+				Endpoint: fmt.Sprintf("%s?from=%d&to=%d&freq=d&timezone=Asia/Atlantis", endpoint, fromTime, toTime),
+				Method:   http.MethodGet,
+				Assert:   expectInvalidInput,
+			},
+			httputil.HTTPTestCase{
+				Msg:      fmt.Sprintf("Test invalid address %s", endpoint),
+				Endpoint: fmt.Sprintf("%s?from=%d&to=%d&address=%s", endpoint, fromTime, toTime, invalidAddress),
+				Method:   http.MethodGet,
+				Assert:   expectInvalidInput,
+			},
+			httputil.HTTPTestCase{
+				Msg:      fmt.Sprintf("Test invalid frequency %s", endpoint),
+				Endpoint: fmt.Sprintf("%s?from=%d&to=%d&freq=%s", endpoint, fromTime, toTime, invalidFreq),
+				Method:   http.MethodGet,
+				Assert:   expectInvalidInput,
+			},
+			httputil.HTTPTestCase{
+				Msg:      fmt.Sprintf("Test invalid timezone %s", endpoint),
+				Endpoint: fmt.Sprintf("%s?from=%d&to=%d&timezone=%d", endpoint, fromTime, toTime, invalidTimezone),
+				Method:   http.MethodGet,
+				Assert:   expectInvalidInput,
+			},
+		)
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Msg, func(t *testing.T) { httputil.RunHTTPTestCase(t, tc, router) })
+	}
+}
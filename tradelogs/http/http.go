@@ -2,6 +2,7 @@ package http
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -18,11 +19,19 @@ import (
 	_ "github.com/KyberNetwork/reserve-stats/lib/httputil/validators" // import custom validator functions
 	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
 	"github.com/KyberNetwork/reserve-stats/lib/userprofile"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
 )
 
 const (
 	hourlyBurnFeeMaxDuration = time.Hour * 24 * 180 // 180 days
+
+	// paginatedTradeLogsMaxDuration is the max time frame of trade logs query with limit
+	paginatedTradeLogsMaxDuration = time.Hour * 24 * 366
+	maxTradeLogsPageSize          = 5000
+	// nextCursorHeader is the response header of cursor to request the next page of trade logs,
+	// it is not set on the last page.
+	nextCursorHeader = "X-Next-Cursor"
 )
 
 // NewServer returns an instance of HttpApi to serve trade logs.
@@ -88,6 +97,7 @@ type Server struct {
 type burnFeeQuery struct {
 	libhttputil.TimeRangeQueryFreq
 	ReserveAddrs []string `form:"reserve" binding:"dive,isAddress"`
+	libhttputil.TimezoneQuery
 }
 
 func (sv *Server) getTokenSymbol(tokenAddress ethereum.Address) (string, error) {
@@ -98,8 +108,62 @@ func (sv *Server) getTokenSymbol(tokenAddress ethereum.Address) (string, error)
 	return symbol, nil
 }
 
+type tradeLogsQuery struct {
+	libhttputil.TimeRangeQuery
+	// Cursor is the next_cursor returned by previous page
+	Cursor string `form:"cursor"`
+	Limit  uint64 `form:"limit"`
+
+	Tokens          []string `form:"token" binding:"dive,isAddress"`
+	Reserves        []string `form:"reserve" binding:"dive,isAddress"`
+	Wallets         []string `form:"wallet" binding:"dive,isAddress"`
+	Users           []string `form:"user" binding:"dive,isAddress"`
+	IntegrationApps []string `form:"integration_app"`
+}
+
+func hexToAddresses(addresses []string) []ethereum.Address {
+	var result []ethereum.Address
+	for _, addr := range addresses {
+		result = append(result, ethereum.HexToAddress(addr))
+	}
+	return result
+}
+
+// filter validates the query and converts it to storage filter. Paginated queries are allowed
+// a longer time frame as each page is bounded by limit.
+func (q *tradeLogsQuery) filter() (common.TradeLogFilter, error) {
+	var (
+		filter  common.TradeLogFilter
+		options []libhttputil.TimeRangeQueryValidationOption
+		err     error
+	)
+	if q.Limit > maxTradeLogsPageSize {
+		return filter, fmt.Errorf("limit %d exceeds max page size %d", q.Limit, maxTradeLogsPageSize)
+	}
+	if q.Limit > 0 {
+		options = append(options, libhttputil.TimeRangeQueryWithMaxTimeFrame(paginatedTradeLogsMaxDuration))
+	}
+	if filter.From, filter.To, err = q.Validate(options...); err != nil {
+		return filter, err
+	}
+	if q.Cursor != "" {
+		cursor, err := common.ParseTradeLogCursor(q.Cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = &cursor
+	}
+	filter.Limit = q.Limit
+	filter.Tokens = hexToAddresses(q.Tokens)
+	filter.Reserves = hexToAddresses(q.Reserves)
+	filter.Wallets = hexToAddresses(q.Wallets)
+	filter.Users = hexToAddresses(q.Users)
+	filter.IntegrationApps = q.IntegrationApps
+	return filter, nil
+}
+
 func (sv *Server) getTradeLogs(c *gin.Context) {
-	var query libhttputil.TimeRangeQuery
+	var query tradeLogsQuery
 	if err := c.ShouldBindQuery(&query); err != nil {
 		libhttputil.ResponseFailure(
 			c,
@@ -109,13 +173,18 @@ func (sv *Server) getTradeLogs(c *gin.Context) {
 		return
 	}
 
-	fromTime, toTime, err := query.Validate()
+	filter, err := query.filter()
 	if err != nil {
 		libhttputil.ResponseFailure(c, http.StatusBadRequest, err)
 		return
 	}
+	fromTime, toTime := filter.From, filter.To
 
-	tradeLogs, err := sv.storage.LoadTradeLogs(fromTime, toTime)
+	if filter.Limit > 0 {
+		// fetch one more record to know if there is a next page
+		filter.Limit++
+	}
+	tradeLogs, err := sv.storage.LoadTradeLogsPage(filter)
 	if err != nil {
 		sv.sugar.Errorw(err.Error(), "fromTime", fromTime, "toTime", toTime)
 		libhttputil.ResponseFailure(
@@ -125,6 +194,10 @@ func (sv *Server) getTradeLogs(c *gin.Context) {
 		)
 		return
 	}
+	if query.Limit > 0 && uint64(len(tradeLogs)) > query.Limit {
+		tradeLogs = tradeLogs[:query.Limit]
+		c.Header(nextCursorHeader, common.CursorOf(tradeLogs[len(tradeLogs)-1]).String())
+	}
 	addrToAppName, err := sv.getAddrToAppName()
 	if err != nil {
 		libhttputil.ResponseFailure(
@@ -214,11 +287,17 @@ func (sv *Server) getBurnFee(c *gin.Context) {
 		return
 	}
 
+	timezone, err := query.Location()
+	if err != nil {
+		libhttputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
 	for _, rsvAddr := range query.ReserveAddrs {
 		rsvAddrs = append(rsvAddrs, ethereum.HexToAddress(rsvAddr))
 	}
 
-	burnFee, err := sv.storage.GetAggregatedBurnFee(fromTime, toTime, query.Freq, rsvAddrs)
+	burnFee, err := sv.storage.GetAggregatedBurnFee(fromTime, toTime, query.Freq, rsvAddrs, timezone)
 	if err != nil {
 		sv.sugar.Errorw(err.Error(), "parameter", query)
 		libhttputil.ResponseFailure(
@@ -340,6 +419,8 @@ func (sv *Server) getTradeLogsByTx(c *gin.Context) {
 type getReportRequest struct {
 	libhttputil.TimeRangeQuery
 	Limit uint64 `form:"limit"`
+	// Provider is the ETH/USD rate provider to price trades with, default to the rates fixed at crawl time
+	Provider string `form:"provider"`
 }
 
 func (sv *Server) getStats(c *gin.Context) {
@@ -353,7 +434,7 @@ func (sv *Server) getStats(c *gin.Context) {
 		libhttputil.ResponseFailure(c, http.StatusBadRequest, err)
 		return
 	}
-	stats, err := sv.storage.GetStats(from, to)
+	stats, err := sv.storage.GetStats(from, to, query.Provider)
 	if err != nil {
 		libhttputil.ResponseFailure(c, http.StatusInternalServerError, err)
 		return
@@ -376,7 +457,7 @@ func (sv *Server) getTopTokens(c *gin.Context) {
 		libhttputil.ResponseFailure(c, http.StatusBadRequest, err)
 		return
 	}
-	topTokens, err := sv.storage.GetTopTokens(from, to, query.Limit)
+	topTokens, err := sv.storage.GetTopTokens(from, to, query.Limit, query.Provider)
 	if err != nil {
 		libhttputil.ResponseFailure(c, http.StatusInternalServerError, err)
 		return
@@ -398,7 +479,7 @@ func (sv *Server) getTopIntegration(c *gin.Context) {
 		libhttputil.ResponseFailure(c, http.StatusBadRequest, err)
 		return
 	}
-	topIntegration, err := sv.storage.GetTopIntegrations(from, to, query.Limit)
+	topIntegration, err := sv.storage.GetTopIntegrations(from, to, query.Limit, query.Provider)
 	if err != nil {
 		libhttputil.ResponseFailure(c, http.StatusInternalServerError, err)
 		return
@@ -420,7 +501,7 @@ func (sv *Server) getTopReserves(c *gin.Context) {
 		libhttputil.ResponseFailure(c, http.StatusBadRequest, err)
 		return
 	}
-	topReserves, err := sv.storage.GetTopReserves(from, to, query.Limit)
+	topReserves, err := sv.storage.GetTopReserves(from, to, query.Limit, query.Provider)
 	if err != nil {
 		libhttputil.ResponseFailure(c, http.StatusInternalServerError, err)
 		return
@@ -487,11 +568,21 @@ func (sv *Server) setupRouter() *gin.Engine {
 	r := gin.Default()
 	r.GET("/trade-logs", sv.getTradeLogs)
 	r.GET("/trade-logs/:tx_hash", sv.getTradeLogsByTx)
+	r.GET("/export/trade-logs", sv.exportTradeLogs)
 	r.GET("/burn-fee", sv.getBurnFee)
 	r.GET("/asset-volume", sv.getAssetVolume)
 	r.GET("/monthly-volume", sv.getMonthlyVolume)
 	r.GET("/reserve-volume", sv.getReserveVolume)
 	r.GET("/wallet-fee", sv.getWalletFee)
+	r.GET("/fee-breakdown/reserve", sv.getFeeBreakdown(common.FeeGroupReserve))
+	r.GET("/fee-breakdown/rebate-wallet", sv.getFeeBreakdown(common.FeeGroupRebateWallet))
+	r.GET("/fee-breakdown/platform-wallet", sv.getFeeBreakdown(common.FeeGroupPlatformWallet))
+	r.GET("/rebate-statement", sv.getRebateStatement)
+	r.GET("/ohlcv", sv.getCandles)
+	r.GET("/mev-report", sv.getMEVReport)
+	r.GET("/slippage", sv.getSlippage)
+	r.GET("/reserve-market-share", sv.getReserveMarketShare)
+	r.GET("/user-cohorts", sv.getUserCohorts)
 	r.GET("/trade-summary", sv.getTradeSummary)
 	r.GET("/user-volume", sv.getUserVolume)
 	r.GET("/user-list", sv.getUserList)
@@ -513,6 +604,10 @@ func (sv *Server) setupRouter() *gin.Engine {
 	r.GET("/big-trades", sv.getBigTrades)
 	r.PUT("/big-trades", sv.updateBigTradesTwitted)
 
+	// crawl queue admin api
+	r.GET("/crawl-jobs", sv.getCrawlJobs)
+	r.POST("/crawl-jobs/retry", sv.retryCrawlJobs)
+
 	return r
 }
 
@@ -16,7 +16,7 @@ import (
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 )
 
-func (s *mockStorage) GetIntegrationVolume(fromTime, toTime time.Time) (map[uint64]*common.IntegrationVolume, error) {
+func (s *mockStorage) GetIntegrationVolume(fromTime, toTime time.Time, timezone *time.Location) (map[uint64]*common.IntegrationVolume, error) {
 	return nil, nil
 }
 
@@ -42,19 +42,125 @@ func (s *mockStorage) LoadTradeLogs(from, to time.Time) ([]common.TradelogV4, er
 	return nil, nil
 }
 
-func (s *mockStorage) GetAggregatedBurnFee(from, to time.Time, freq string, reserveAddrs []ethereum.Address) (map[ethereum.Address]map[string]float64, error) {
+func (s *mockStorage) LoadTradeLogsPage(filter common.TradeLogFilter) ([]common.TradelogV4, error) {
 	return nil, nil
 }
 
-func (s *mockStorage) GetAggregatedWalletFee(reserveAddr, walletAddr, freq string, fromTime, toTime time.Time, timezone int8) (map[uint64]float64, error) {
+func (s *mockStorage) GetReservesByAddresses(addresses []ethereum.Address) ([]common.Reserve, error) {
 	return nil, nil
 }
 
-func (s *mockStorage) GetTradeSummary(from, to time.Time, timezone int8) (map[uint64]*common.TradeSummary, error) {
+func (s *mockStorage) GetAggregatedBurnFee(from, to time.Time, freq string, reserveAddrs []ethereum.Address, timezone *time.Location) (map[ethereum.Address]map[string]float64, error) {
 	return nil, nil
 }
 
-func (s *mockStorage) GetUserVolume(userAddr ethereum.Address, fromTime, toTime time.Time, freq string) (map[uint64]common.UserVolume, error) {
+func (s *mockStorage) GetFeeBreakdown(group common.FeeGroup, from, to time.Time, freq string, timezone *time.Location,
+	addrs []ethereum.Address) (map[ethereum.Address]map[uint64]common.FeeBreakdown, error) {
+	return nil, nil
+}
+
+func (s *mockStorage) GetRebateStatement(wallet ethereum.Address, from, to time.Time) (common.RebateStatement, error) {
+	return common.RebateStatement{RebateWallet: wallet}, nil
+}
+
+func (s *mockStorage) GetCandles(token ethereum.Address, interval string, from, to time.Time) ([]common.Candle, error) {
+	return []common.Candle{}, nil
+}
+
+func (s *mockStorage) SaveSandwiches(fromBlock, toBlock uint64, sandwiches []common.Sandwich) error {
+	return nil
+}
+
+func (s *mockStorage) GetMEVReport(from, to time.Time, wallets []ethereum.Address) (common.MEVReport, error) {
+	return common.MEVReport{}, nil
+}
+
+func (s *mockStorage) GetSplitExecutions(fromBlock, toBlock uint64) ([]common.SplitExecution, error) {
+	return nil, nil
+}
+
+func (s *mockStorage) SaveSlippages(fromBlock, toBlock uint64, slippages []common.SplitSlippage) error {
+	return nil
+}
+
+func (s *mockStorage) GetSlippageStats(from, to time.Time, reserves, tokens []ethereum.Address) ([]common.SlippageStats, error) {
+	return []common.SlippageStats{}, nil
+}
+
+func (s *mockStorage) GetReserveMarketShare(group common.MarketShareGroup, from, to time.Time, freq string, timezone *time.Location,
+	tokens []ethereum.Address, reserveTypes []uint64) ([]common.ReserveMarketShare, error) {
+	return []common.ReserveMarketShare{}, nil
+}
+
+func (s *mockStorage) GetUserCohorts(interval common.CohortInterval, group common.CohortGroup, from, to time.Time, timezone *time.Location) ([]common.UserCohort, error) {
+	return []common.UserCohort{}, nil
+}
+
+func (s *mockStorage) GetBigTradesToNotify(sink string, since time.Time, maxAttempts uint64) ([]common.BigTradeLog, error) {
+	return []common.BigTradeLog{}, nil
+}
+
+func (s *mockStorage) SaveBigTradeNotifications(sink string, notifications []common.BigTradeNotification) error {
+	return nil
+}
+
+func (s *mockStorage) GetTradesToPrice(fromBlock, toBlock uint64, from, to time.Time) ([]common.TradeFiatPrice, error) {
+	return []common.TradeFiatPrice{}, nil
+}
+
+func (s *mockStorage) SaveFiatPrices(provider string, prices []common.TradeFiatPrice, setDefault bool) error {
+	return nil
+}
+
+func (s *mockStorage) AuditTradeLogs(fromBlock, toBlock, samples uint64) ([]common.AuditCheck, error) {
+	return nil, nil
+}
+
+func (s *mockStorage) GetBlockGaps(fromBlock, toBlock, maxGap uint64) ([]common.BlockRange, error) {
+	return nil, nil
+}
+
+func (s *mockStorage) EnqueueCrawlJobs(ranges []common.BlockRange) error {
+	return nil
+}
+
+func (s *mockStorage) LastQueuedBlock() (uint64, error) {
+	return 0, nil
+}
+
+func (s *mockStorage) LeaseCrawlJob(owner string, lease time.Duration, maxAttempts uint64) (*common.CrawlJob, error) {
+	return nil, nil
+}
+
+func (s *mockStorage) CompleteCrawlJob(id uint64, owner string) error {
+	return nil
+}
+
+func (s *mockStorage) RenewCrawlJob(id uint64, owner string, lease time.Duration) error {
+	return nil
+}
+
+func (s *mockStorage) FailCrawlJob(id uint64, owner, reason string, maxAttempts uint64) error {
+	return nil
+}
+
+func (s *mockStorage) GetCrawlJobs(status common.CrawlJobStatus) ([]common.CrawlJob, error) {
+	return []common.CrawlJob{}, nil
+}
+
+func (s *mockStorage) RetryCrawlJobs(ids []uint64) (int64, error) {
+	return int64(len(ids)), nil
+}
+
+func (s *mockStorage) GetAggregatedWalletFee(reserveAddr, walletAddr, freq string, fromTime, toTime time.Time, timezone *time.Location) (map[uint64]float64, error) {
+	return nil, nil
+}
+
+func (s *mockStorage) GetTradeSummary(from, to time.Time, timezone *time.Location) (map[uint64]*common.TradeSummary, error) {
+	return nil, nil
+}
+
+func (s *mockStorage) GetUserVolume(userAddr ethereum.Address, fromTime, toTime time.Time, freq string, timezone *time.Location) (map[uint64]common.UserVolume, error) {
 	return nil, nil
 }
 
@@ -62,11 +168,11 @@ func (s *mockStorage) GetUserList(fromTime, toTime time.Time) ([]common.UserInfo
 	return nil, nil
 }
 
-func (s *mockStorage) GetWalletStats(fromTime, toTime time.Time, walletAddr string, timezone int8) (map[uint64]common.WalletStats, error) {
+func (s *mockStorage) GetWalletStats(fromTime, toTime time.Time, walletAddr string, timezone *time.Location) (map[uint64]common.WalletStats, error) {
 	return nil, nil
 }
 
-func (s *mockStorage) GetTokenHeatmap(token ethereum.Address, from, to time.Time, timezone int8) (map[string]common.Heatmap, error) {
+func (s *mockStorage) GetTokenHeatmap(token ethereum.Address, from, to time.Time, timezone *time.Location) (map[string]common.Heatmap, error) {
 	return nil, nil
 }
 
@@ -74,19 +180,19 @@ func (s *mockStorage) LoadTradeLogsByTxHash(txHash ethereum.Hash) ([]common.Trad
 	return nil, nil
 }
 
-func (s *mockStorage) GetStats(from, to time.Time) (common.StatsResponse, error) {
+func (s *mockStorage) GetStats(from, to time.Time, provider string) (common.StatsResponse, error) {
 	return common.StatsResponse{}, nil
 }
 
-func (s *mockStorage) GetTopTokens(from, to time.Time, limit uint64) (common.TopTokens, error) {
+func (s *mockStorage) GetTopTokens(from, to time.Time, limit uint64, provider string) (common.TopTokens, error) {
 	return common.TopTokens{}, nil
 }
 
-func (s *mockStorage) GetTopIntegrations(from, to time.Time, limit uint64) (common.TopIntegrations, error) {
+func (s *mockStorage) GetTopIntegrations(from, to time.Time, limit uint64, provider string) (common.TopIntegrations, error) {
 	return common.TopIntegrations{}, nil
 }
 
-func (s *mockStorage) GetTopReserves(from, to time.Time, limit uint64) (common.TopReserves, error) {
+func (s *mockStorage) GetTopReserves(from, to time.Time, limit uint64, provider string) (common.TopReserves, error) {
 	return common.TopReserves{}, nil
 }
 
@@ -102,6 +208,14 @@ func (s *mockStorage) UpdateBigTradesTwitted(trades []uint64) error {
 	return nil
 }
 
+func (s *mockStorage) GetBlockHashes(fromBlock uint64) ([]common.BlockInfo, error) {
+	return nil, nil
+}
+
+func (s *mockStorage) DeleteTradeLogsFromBlock(fromBlock uint64) error {
+	return nil
+}
+
 func newTestServer() (*Server, error) {
 	sugar := testutil.MustNewDevelopmentSugaredLogger()
 	return NewServer(
@@ -151,6 +265,31 @@ func TestTradeLogsRoute(t *testing.T) {
 				assert.Contains(t, result.Error, "max time frame exceed")
 			},
 		},
+		{
+			Msg:      "Test paginated request with long time range",
+			Endpoint: fmt.Sprintf("/trade-logs?from=0&to=%d&limit=100", time.Hour/time.Millisecond*25),
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.Empty(t, resp.Header().Get(nextCursorHeader))
+			},
+		},
+		{
+			Msg:      "Test invalid cursor",
+			Endpoint: "/trade-logs?limit=100&cursor=invalid",
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+		{
+			Msg:      "Test invalid token filter",
+			Endpoint: "/trade-logs?token=not-an-address",
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
 	}
 	for _, tc := range tests {
 		tc := tc
@@ -13,8 +13,13 @@ const (
 	defaultTimeFrame = time.Hour * 24 * 3       // 3 days
 )
 
+type integrationVolumeQuery struct {
+	httputil.TimeRangeQuery
+	httputil.TimezoneQuery
+}
+
 func (sv *Server) getIntegrationVolume(c *gin.Context) {
-	var query httputil.TimeRangeQuery
+	var query integrationVolumeQuery
 	if err := c.ShouldBindQuery(&query); err != nil {
 		httputil.ResponseFailure(
 			c,
@@ -36,7 +41,13 @@ func (sv *Server) getIntegrationVolume(c *gin.Context) {
 		return
 	}
 
-	integrationVolume, err := sv.storage.GetIntegrationVolume(fromTime, toTime)
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	integrationVolume, err := sv.storage.GetIntegrationVolume(fromTime, toTime, timezone)
 	if err != nil {
 		httputil.ResponseFailure(
 			c,
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+type marketShareQuery struct {
+	httputil.TimeRangeQueryFreq
+	Group        string   `form:"group"`
+	Tokens       []string `form:"token" binding:"dive,isAddress"`
+	ReserveTypes []uint64 `form:"reserve_type"`
+	httputil.TimezoneQuery
+}
+
+func (sv *Server) getReserveMarketShare(c *gin.Context) {
+	var query marketShareQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	group := common.MarketShareGroup(query.Group)
+	switch group {
+	case "":
+		group = common.MarketShareGroupReserve
+	case common.MarketShareGroupReserve, common.MarketShareGroupReserveType:
+	default:
+		httputil.ResponseFailure(c, http.StatusBadRequest, fmt.Errorf("invalid group: %s", query.Group))
+		return
+	}
+
+	fromTime, toTime, err := query.Validate()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	shares, err := sv.storage.GetReserveMarketShare(group, fromTime, toTime, query.Freq, timezone,
+		hexToAddresses(query.Tokens), query.ReserveTypes)
+	if err != nil {
+		sv.sugar.Errorw("failed to get reserve market share", "query", query, "error", err)
+		httputil.ResponseFailure(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+func TestReserveMarketShareRoute(t *testing.T) {
+	const kncAddr = "0xdd974d5c2e2928dea5f71b9825b8b646686bd200"
+	s, err := newTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := s.setupRouter()
+
+	expectOK := func(t *testing.T, resp *httptest.ResponseRecorder) {
+		require.Equal(t, http.StatusOK, resp.Code)
+	}
+
+	var tests = []httputil.HTTPTestCase{
+		{
+			Msg:      "Test valid request",
+			Endpoint: fmt.Sprintf("/reserve-market-share?from=%d&to=%d&freq=h", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert:   expectOK,
+		},
+		{
+			Msg: "Test valid request by reserve type",
+			Endpoint: fmt.Sprintf("/reserve-market-share?from=%d&to=%d&freq=d&timezone=7&group=reserve_type&token=%s&reserve_type=1&reserve_type=2",
+				fromTime, toTime, kncAddr),
+			Method: http.MethodGet,
+			Assert: expectOK,
+		},
+		{
+			Msg:      "Test invalid group",
+			Endpoint: fmt.Sprintf("/reserve-market-share?from=%d&to=%d&group=token", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test invalid token",
+			Endpoint: fmt.Sprintf("/reserve-market-share?from=%d&to=%d&token=%s", fromTime, toTime, invalidAddress),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test invalid reserve type",
+			Endpoint: fmt.Sprintf("/reserve-market-share?from=%d&to=%d&reserve_type=fpr", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test invalid frequency",
+			Endpoint: fmt.Sprintf("/reserve-market-share?from=%d&to=%d&freq=%s", fromTime, toTime, invalidFreq),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Msg, func(t *testing.T) { httputil.RunHTTPTestCase(t, tc, router) })
+	}
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+const (
+	maxMEVReportTimeFrame     = time.Hour * 24 * 31
+	defaultMEVReportTimeFrame = time.Hour * 24
+)
+
+type mevReportQuery struct {
+	httputil.TimeRangeQuery
+	Wallets []string `form:"wallet" binding:"dive,isAddress"`
+}
+
+func (sv *Server) getMEVReport(c *gin.Context) {
+	var query mevReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	fromTime, toTime, err := query.Validate(
+		httputil.TimeRangeQueryWithMaxTimeFrame(maxMEVReportTimeFrame),
+		httputil.TimeRangeQueryWithDefaultTimeFrame(defaultMEVReportTimeFrame),
+	)
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := sv.storage.GetMEVReport(fromTime, toTime, hexToAddresses(query.Wallets))
+	if err != nil {
+		sv.sugar.Errorw("failed to get mev report", "query", query, "error", err)
+		httputil.ResponseFailure(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+func TestMEVReportRoute(t *testing.T) {
+	s, err := newTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := s.setupRouter()
+
+	var tests = []httputil.HTTPTestCase{
+		{
+			Msg:      "Test valid request",
+			Endpoint: fmt.Sprintf("/mev-report?wallet=%s&from=%d&to=%d", walletAddr, fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			Msg:      "Test all wallets",
+			Endpoint: fmt.Sprintf("/mev-report?from=%d&to=%d", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			Msg:      "Test invalid wallet",
+			Endpoint: fmt.Sprintf("/mev-report?wallet=%s", invalidAddress),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg: "Test time frame exceeded",
+			Endpoint: fmt.Sprintf("/mev-report?from=%d&to=%d",
+				fromTime, fromTime+uint64(32*24*60*60*1000)),
+			Method: http.MethodGet,
+			Assert: expectInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Msg, func(t *testing.T) { httputil.RunHTTPTestCase(t, tc, router) })
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+const defaultCandleInterval = "1h"
+
+// candleTimeFrames are the max and default time frame of each candle interval.
+var candleTimeFrames = map[string]struct {
+	max, def time.Duration
+}{
+	"1m": {max: time.Hour * 24 * 7, def: time.Hour * 24},
+	"1h": {max: time.Hour * 24 * 180, def: time.Hour * 24 * 7},
+	"1d": {max: time.Hour * 24 * 365 * 3, def: time.Hour * 24 * 90},
+}
+
+type candlesQuery struct {
+	httputil.TimeRangeQuery
+	Token    string `form:"token" binding:"required,isAddress"`
+	Interval string `form:"interval"`
+}
+
+func (sv *Server) getCandles(c *gin.Context) {
+	var query candlesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if query.Interval == "" {
+		query.Interval = defaultCandleInterval
+	}
+	timeFrame, ok := candleTimeFrames[query.Interval]
+	if _, supported := common.CandleIntervals[query.Interval]; !ok || !supported {
+		httputil.ResponseFailure(c, http.StatusBadRequest, fmt.Errorf("invalid interval: %s", query.Interval))
+		return
+	}
+
+	fromTime, toTime, err := query.Validate(
+		httputil.TimeRangeQueryWithMaxTimeFrame(timeFrame.max),
+		httputil.TimeRangeQueryWithDefaultTimeFrame(timeFrame.def),
+	)
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	candles, err := sv.storage.GetCandles(ethereum.HexToAddress(query.Token), query.Interval, fromTime, toTime)
+	if err != nil {
+		sv.sugar.Errorw("failed to get candles", "query", query, "error", err)
+		httputil.ResponseFailure(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, candles)
+}
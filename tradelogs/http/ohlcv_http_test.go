@@ -0,0 +1,69 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+func TestCandlesRoute(t *testing.T) {
+	const kncAddr = "0xdd974d5c2e2928dea5f71b9825b8b646686bd200"
+	s, err := newTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := s.setupRouter()
+
+	var tests = []httputil.HTTPTestCase{
+		{
+			Msg:      "Test valid request",
+			Endpoint: fmt.Sprintf("/ohlcv?token=%s&interval=1m&from=%d&to=%d", kncAddr, fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			Msg:      "Test default interval",
+			Endpoint: fmt.Sprintf("/ohlcv?token=%s", kncAddr),
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			Msg:      "Test missing token",
+			Endpoint: fmt.Sprintf("/ohlcv?interval=1h&from=%d&to=%d", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test invalid token",
+			Endpoint: fmt.Sprintf("/ohlcv?token=%s", invalidAddress),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test invalid interval",
+			Endpoint: fmt.Sprintf("/ohlcv?token=%s&interval=5m", kncAddr),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg: "Test time frame exceeded",
+			Endpoint: fmt.Sprintf("/ohlcv?token=%s&interval=1m&from=%d&to=%d",
+				kncAddr, fromTime, fromTime+uint64(8*24*60*60*1000)),
+			Method: http.MethodGet,
+			Assert: expectInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Msg, func(t *testing.T) { httputil.RunHTTPTestCase(t, tc, router) })
+	}
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+const (
+	// maxRebateStatementTimeFrame allows a statement to cover a few reward epochs
+	maxRebateStatementTimeFrame     = time.Hour * 24 * 92
+	defaultRebateStatementTimeFrame = time.Hour * 24 * 7
+)
+
+type rebateStatementQuery struct {
+	httputil.TimeRangeQuery
+	RebateWallet string `form:"rebate_wallet" binding:"required,isAddress"`
+}
+
+func (sv *Server) getRebateStatement(c *gin.Context) {
+	var query rebateStatementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	fromTime, toTime, err := query.Validate(
+		httputil.TimeRangeQueryWithMaxTimeFrame(maxRebateStatementTimeFrame),
+		httputil.TimeRangeQueryWithDefaultTimeFrame(defaultRebateStatementTimeFrame),
+	)
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	statement, err := sv.storage.GetRebateStatement(ethereum.HexToAddress(query.RebateWallet), fromTime, toTime)
+	if err != nil {
+		sv.sugar.Errorw("failed to get rebate statement", "query", query, "error", err)
+		httputil.ResponseFailure(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
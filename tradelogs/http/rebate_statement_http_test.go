@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func TestRebateStatementRoute(t *testing.T) {
+	s, err := newTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := s.setupRouter()
+
+	var tests = []httputil.HTTPTestCase{
+		{
+			Msg:      "Test valid request",
+			Endpoint: fmt.Sprintf("/rebate-statement?rebate_wallet=%s&from=%d&to=%d", walletAddr, fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+				var statement common.RebateStatement
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &statement))
+				assert.Equal(t, ethereum.HexToAddress(walletAddr), statement.RebateWallet)
+			},
+		},
+		{
+			Msg:      "Test missing rebate wallet",
+			Endpoint: fmt.Sprintf("/rebate-statement?from=%d&to=%d", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test invalid rebate wallet",
+			Endpoint: fmt.Sprintf("/rebate-statement?rebate_wallet=%s", invalidAddress),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg: "Test time frame exceeded",
+			Endpoint: fmt.Sprintf("/rebate-statement?rebate_wallet=%s&from=%d&to=%d",
+				walletAddr, fromTime, fromTime+uint64(100*24*60*60*1000)),
+			Method: http.MethodGet,
+			Assert: expectInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Msg, func(t *testing.T) { httputil.RunHTTPTestCase(t, tc, router) })
+	}
+}
@@ -11,8 +11,10 @@ import (
 
 type reserveVolumeQuery struct {
 	httputil.TimeRangeQueryFreq
-	Asset   string `form:"asset" binding:"required,isAddress"`
-	Reserve string `form:"reserve" binding:"isAddress"`
+	Asset    string `form:"asset" binding:"required,isAddress"`
+	Reserve  string `form:"reserve" binding:"isAddress"`
+	Provider string `form:"provider"`
+	httputil.TimezoneQuery
 }
 
 func (sv *Server) getReserveVolume(c *gin.Context) {
@@ -29,10 +31,16 @@ func (sv *Server) getReserveVolume(c *gin.Context) {
 		return
 	}
 
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
 	token := ethereum.HexToAddress(query.Asset)
 
 	result, err := sv.storage.GetReserveVolume(ethereum.HexToAddress(query.Reserve), token,
-		from, to, query.Freq)
+		from, to, query.Freq, query.Provider, timezone)
 	if err != nil {
 		httputil.ResponseFailure(c, http.StatusInternalServerError, err)
 		return
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+const (
+	maxSlippageTimeFrame     = time.Hour * 24 * 90
+	defaultSlippageTimeFrame = time.Hour * 24 * 7
+)
+
+type slippageQuery struct {
+	httputil.TimeRangeQuery
+	Reserves []string `form:"reserve" binding:"dive,isAddress"`
+	Tokens   []string `form:"token" binding:"dive,isAddress"`
+}
+
+func (sv *Server) getSlippage(c *gin.Context) {
+	var query slippageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	fromTime, toTime, err := query.Validate(
+		httputil.TimeRangeQueryWithMaxTimeFrame(maxSlippageTimeFrame),
+		httputil.TimeRangeQueryWithDefaultTimeFrame(defaultSlippageTimeFrame),
+	)
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	stats, err := sv.storage.GetSlippageStats(fromTime, toTime,
+		hexToAddresses(query.Reserves), hexToAddresses(query.Tokens))
+	if err != nil {
+		sv.sugar.Errorw("failed to get slippage stats", "query", query, "error", err)
+		httputil.ResponseFailure(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+func TestSlippageRoute(t *testing.T) {
+	const kncAddr = "0xdd974d5c2e2928dea5f71b9825b8b646686bd200"
+	s, err := newTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := s.setupRouter()
+
+	var tests = []httputil.HTTPTestCase{
+		{
+			Msg: "Test valid request",
+			Endpoint: fmt.Sprintf("/slippage?reserve=%s&token=%s&from=%d&to=%d",
+				reserveAddr, kncAddr, fromTime, toTime),
+			Method: http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			Msg:      "Test all reserves and tokens",
+			Endpoint: fmt.Sprintf("/slippage?from=%d&to=%d", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			Msg:      "Test invalid reserve",
+			Endpoint: fmt.Sprintf("/slippage?reserve=%s", invalidAddress),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test invalid token",
+			Endpoint: fmt.Sprintf("/slippage?token=%s", invalidAddress),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg: "Test time frame exceeded",
+			Endpoint: fmt.Sprintf("/slippage?from=%d&to=%d",
+				fromTime, fromTime+uint64(91*24*60*60*1000)),
+			Method: http.MethodGet,
+			Assert: expectInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Msg, func(t *testing.T) { httputil.RunHTTPTestCase(t, tc, router) })
+	}
+}
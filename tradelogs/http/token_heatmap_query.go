@@ -10,8 +10,8 @@ import (
 
 type tokenHeatmapQuery struct {
 	httputil.TimeRangeQuery
-	Asset    string `form:"asset" binding:"required,isAddress"`
-	Timezone int8   `form:"timezone" binding:"isSupportedTimezone"`
+	Asset string `form:"asset" binding:"required,isAddress"`
+	httputil.TimezoneQuery
 }
 
 func (sv *Server) getTokenHeatMap(c *gin.Context) {
@@ -36,9 +36,15 @@ func (sv *Server) getTokenHeatMap(c *gin.Context) {
 		return
 	}
 
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
 	asset := common.HexToAddress(query.Asset)
 
-	heatmap, err := sv.storage.GetTokenHeatmap(asset, from, to, query.Timezone)
+	heatmap, err := sv.storage.GetTokenHeatmap(asset, from, to, timezone)
 	if err != nil {
 		httputil.ResponseFailure(c, http.StatusInternalServerError, err)
 		return
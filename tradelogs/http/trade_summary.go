@@ -9,7 +9,7 @@ import (
 
 type tradeSummaryQuery struct {
 	httputil.TimeRangeQuery
-	Timezone int8 `form:"timezone" binding:"isSupportedTimezone"`
+	httputil.TimezoneQuery
 }
 
 func (sv *Server) getTradeSummary(c *gin.Context) {
@@ -37,7 +37,13 @@ func (sv *Server) getTradeSummary(c *gin.Context) {
 		return
 	}
 
-	tradeSummary, err := sv.storage.GetTradeSummary(fromTime, toTime, query.Timezone)
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	tradeSummary, err := sv.storage.GetTradeSummary(fromTime, toTime, timezone)
 	if err != nil {
 		httputil.ResponseFailure(
 			c,
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+const defaultCohortInterval = common.CohortIntervalWeek
+
+// cohortTimeFrames are the max and default time frame of first trades of each cohort interval.
+var cohortTimeFrames = map[common.CohortInterval]struct {
+	max, def time.Duration
+}{
+	common.CohortIntervalWeek:  {max: time.Hour * 24 * 7 * 26, def: time.Hour * 24 * 7 * 8},
+	common.CohortIntervalMonth: {max: time.Hour * 24 * 365 * 3, def: time.Hour * 24 * 365},
+}
+
+type userCohortQuery struct {
+	httputil.TimeRangeQuery
+	Interval string `form:"interval"`
+	Group    string `form:"group"`
+	httputil.TimezoneQuery
+}
+
+func (sv *Server) getUserCohorts(c *gin.Context) {
+	var query userCohortQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	interval := common.CohortInterval(query.Interval)
+	if interval == "" {
+		interval = defaultCohortInterval
+	}
+	timeFrame, ok := cohortTimeFrames[interval]
+	if !ok {
+		httputil.ResponseFailure(c, http.StatusBadRequest, fmt.Errorf("invalid interval: %s", query.Interval))
+		return
+	}
+	group := common.CohortGroup(query.Group)
+	switch group {
+	case common.CohortGroupNone, common.CohortGroupIntegrationApp, common.CohortGroupWallet, common.CohortGroupCountry:
+	default:
+		httputil.ResponseFailure(c, http.StatusBadRequest, fmt.Errorf("invalid group: %s", query.Group))
+		return
+	}
+
+	fromTime, toTime, err := query.Validate(
+		httputil.TimeRangeQueryWithMaxTimeFrame(timeFrame.max),
+		httputil.TimeRangeQueryWithDefaultTimeFrame(timeFrame.def),
+	)
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	cohorts, err := sv.storage.GetUserCohorts(interval, group, fromTime, toTime, timezone)
+	if err != nil {
+		sv.sugar.Errorw("failed to get user cohorts", "query", query, "error", err)
+		httputil.ResponseFailure(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cohorts)
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+func TestUserCohortsRoute(t *testing.T) {
+	s, err := newTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := s.setupRouter()
+
+	expectOK := func(t *testing.T, resp *httptest.ResponseRecorder) {
+		require.Equal(t, http.StatusOK, resp.Code)
+	}
+
+	var tests = []httputil.HTTPTestCase{
+		{
+			Msg:      "Test default interval",
+			Endpoint: fmt.Sprintf("/user-cohorts?from=%d&to=%d", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert:   expectOK,
+		},
+		{
+			Msg:      "Test monthly cohorts by country",
+			Endpoint: fmt.Sprintf("/user-cohorts?from=%d&to=%d&interval=month&group=country", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert:   expectOK,
+		},
+		{
+			Msg:      "Test invalid interval",
+			Endpoint: fmt.Sprintf("/user-cohorts?from=%d&to=%d&interval=day", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg:      "Test invalid group",
+			Endpoint: fmt.Sprintf("/user-cohorts?from=%d&to=%d&group=token", fromTime, toTime),
+			Method:   http.MethodGet,
+			Assert:   expectInvalidInput,
+		},
+		{
+			Msg: "Test time frame exceeded",
+			Endpoint: fmt.Sprintf("/user-cohorts?interval=week&from=%d&to=%d",
+				fromTime, fromTime+uint64(7*27*24*60*60*1000)),
+			Method: http.MethodGet,
+			Assert: expectInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Msg, func(t *testing.T) { httputil.RunHTTPTestCase(t, tc, router) })
+	}
+}
@@ -11,6 +11,7 @@ import (
 type userVolumeQuery struct {
 	httputil.TimeRangeQueryFreq
 	UserAddress string `form:"userAddr" binding:"required,isAddress"`
+	httputil.TimezoneQuery
 }
 
 func (sv *Server) getUserVolume(c *gin.Context) {
@@ -26,7 +27,13 @@ func (sv *Server) getUserVolume(c *gin.Context) {
 		return
 	}
 
-	volume, err := sv.storage.GetUserVolume(ethereum.HexToAddress(query.UserAddress), fromTime, toTime, query.Freq)
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
+	volume, err := sv.storage.GetUserVolume(ethereum.HexToAddress(query.UserAddress), fromTime, toTime, query.Freq, timezone)
 	if err != nil {
 		httputil.ResponseFailure(c, http.StatusBadRequest, err)
 		return
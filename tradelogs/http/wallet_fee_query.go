@@ -13,7 +13,7 @@ type walletFeeQuery struct {
 	httputil.TimeRangeQueryFreq
 	ReserveAddr string `form:"reserve" binding:"required,isAddress"`
 	WalletAddr  string `form:"walletAddr" binding:"required,isAddress"`
-	Timezone    int8   `form:"timezone" binding:"isSupportedTimezone"`
+	httputil.TimezoneQuery
 }
 
 func (sv *Server) getWalletFee(c *gin.Context) {
@@ -35,11 +35,17 @@ func (sv *Server) getWalletFee(c *gin.Context) {
 		return
 	}
 
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+
 	// normalize Ethereum addresses
 	walletAddr := common.HexToAddress(query.WalletAddr).Hex()
 	reserveAddr := common.HexToAddress(query.ReserveAddr).Hex()
 
-	walletFee, err := sv.storage.GetAggregatedWalletFee(reserveAddr, walletAddr, query.Freq, fromTime, toTime, query.Timezone)
+	walletFee, err := sv.storage.GetAggregatedWalletFee(reserveAddr, walletAddr, query.Freq, fromTime, toTime, timezone)
 	if err != nil {
 		sv.sugar.Errorw("reserve addr", query.ReserveAddr, "Wallet addr", query.WalletAddr,
 			"from time", fromTime, "to time", toTime, "frequency", query.Freq)
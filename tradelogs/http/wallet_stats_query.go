@@ -12,7 +12,7 @@ import (
 type walletStatsQuery struct {
 	httputil.TimeRangeQuery
 	WalletAddr string `form:"walletAddr,isAddress"`
-	Timezone   int8   `form:"timezone" binding:"isSupportedTimezone"`
+	httputil.TimezoneQuery
 }
 
 func (sv *Server) getWalletStats(c *gin.Context) {
@@ -30,7 +30,12 @@ func (sv *Server) getWalletStats(c *gin.Context) {
 	walletAddr := ethereum.HexToAddress(query.WalletAddr)
 	from := timeutil.TimestampMsToTime(query.From)
 	to := timeutil.TimestampMsToTime(query.To)
-	walletStats, err := sv.storage.GetWalletStats(from, to, walletAddr.Hex(), query.Timezone)
+	timezone, err := query.Location()
+	if err != nil {
+		httputil.ResponseFailure(c, http.StatusBadRequest, err)
+		return
+	}
+	walletStats, err := sv.storage.GetWalletStats(from, to, walletAddr.Hex(), timezone)
 	if err != nil {
 		httputil.ResponseFailure(
 			c,
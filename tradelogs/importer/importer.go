@@ -0,0 +1,335 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+const (
+	defaultBatchSize = 100
+	// katalystVersion is the trade log version of Katalyst network, whose splits reference reserve IDs.
+	katalystVersion = 4
+)
+
+// Storage is the storage to import trade logs into.
+type Storage interface {
+	SaveTradeLogs(result *common.CrawlResult) error
+	GetReservesByAddresses(addresses []ethereum.Address) ([]common.Reserve, error)
+}
+
+// DecimalsResolver returns decimals of a token.
+type DecimalsResolver interface {
+	GetDecimals(token ethereum.Address) (int64, error)
+}
+
+// Rejection is a record which is not imported.
+type Rejection struct {
+	Line   int    `json:"line"`
+	TxHash string `json:"tx_hash,omitempty"`
+	Index  uint   `json:"index"`
+	Reason string `json:"reason"`
+}
+
+// Report is the result of an import.
+type Report struct {
+	Imported uint64 `json:"imported"`
+	Rejected uint64 `json:"rejected"`
+}
+
+// Importer validates trade logs read from files and saves them in batches. Saving is idempotent,
+// importing the same records again updates the existing trade logs.
+type Importer struct {
+	sugar     *zap.SugaredLogger
+	storage   Storage
+	decimals  DecimalsResolver
+	batchSize int
+	onReject  func(Rejection)
+
+	// reserves caches Katalyst reserves by address, ordered by block number
+	reserves map[ethereum.Address][]common.Reserve
+}
+
+// Option configures the Importer constructor.
+type Option func(*Importer)
+
+// WithBatchSize configures the number of trade logs saved in a transaction.
+func WithBatchSize(batchSize int) Option {
+	return func(im *Importer) {
+		if batchSize > 0 {
+			im.batchSize = batchSize
+		}
+	}
+}
+
+// WithRejectionHandler configures the handler called for every rejected record.
+func WithRejectionHandler(fn func(Rejection)) Option {
+	return func(im *Importer) {
+		im.onReject = fn
+	}
+}
+
+// NewImporter returns a new Importer instance.
+func NewImporter(sugar *zap.SugaredLogger, storage Storage, decimals DecimalsResolver, options ...Option) *Importer {
+	im := &Importer{
+		sugar:     sugar,
+		storage:   storage,
+		decimals:  decimals,
+		batchSize: defaultBatchSize,
+		reserves:  make(map[ethereum.Address][]common.Reserve),
+	}
+	for _, opt := range options {
+		opt(im)
+	}
+	if im.onReject == nil {
+		im.onReject = func(r Rejection) {
+			sugar.Warnw("rejected trade log", "line", r.Line, "tx_hash", r.TxHash, "index", r.Index, "reason", r.Reason)
+		}
+	}
+	return im
+}
+
+// Import reads all records of r and saves valid ones. It stops at the first error of reading
+// input or saving to storage, records of previous batches are kept and can be imported again.
+func (im *Importer) Import(r Reader) (Report, error) {
+	var (
+		logger = im.sugar.With("func", caller.GetCurrentFunctionName())
+		report Report
+		batch  []Record
+	)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		if rec.Err == nil {
+			rec.Err = im.validate(rec.TradeLog)
+		}
+		if rec.Err != nil {
+			im.reject(&report, rec)
+			continue
+		}
+		batch = append(batch, rec)
+		if len(batch) == im.batchSize {
+			if err = im.save(&report, batch); err != nil {
+				return report, err
+			}
+			batch = nil
+		}
+	}
+	if err := im.save(&report, batch); err != nil {
+		return report, err
+	}
+	logger.Infow("import completed", "imported", report.Imported, "rejected", report.Rejected)
+	return report, nil
+}
+
+func (im *Importer) reject(report *Report, rec Record) {
+	report.Rejected++
+	rejection := Rejection{Line: rec.Line, Index: rec.TradeLog.Index, Reason: rec.Err.Error()}
+	if rec.TradeLog.TransactionHash != (ethereum.Hash{}) {
+		rejection.TxHash = rec.TradeLog.TransactionHash.Hex()
+	}
+	im.onReject(rejection)
+}
+
+// validate checks the trade log has all fields needed by storage.
+func (im *Importer) validate(tl common.TradelogV4) error {
+	switch {
+	case tl.TransactionHash == ethereum.Hash{}:
+		return fmt.Errorf("missing tx_hash")
+	case tl.BlockNumber == 0:
+		return fmt.Errorf("missing block_number")
+	case tl.Timestamp.IsZero() || tl.Timestamp.Unix() <= 0:
+		return fmt.Errorf("missing timestamp")
+	case tl.Version < 1 || tl.Version > katalystVersion:
+		return fmt.Errorf("unsupported version: %d", tl.Version)
+	case blockchain.IsZeroAddress(tl.TokenInfo.SrcAddress) || blockchain.IsZeroAddress(tl.TokenInfo.DestAddress):
+		return fmt.Errorf("missing src or dst token")
+	}
+	for _, amount := range []*big.Int{tl.EthAmount, tl.OriginalEthAmount, tl.SrcAmount, tl.DestAmount} {
+		if amount != nil && amount.Sign() < 0 {
+			return fmt.Errorf("negative amount: %s", amount)
+		}
+	}
+	for _, token := range []ethereum.Address{tl.TokenInfo.SrcAddress, tl.TokenInfo.DestAddress} {
+		if _, err := im.decimals.GetDecimals(token); err != nil {
+			return fmt.Errorf("unknown decimals of token %s: %v", token.Hex(), err)
+		}
+	}
+	if tl.Version == katalystVersion && len(tl.Split) == 0 {
+		return fmt.Errorf("missing splits of katalyst trade")
+	}
+	return nil
+}
+
+// loadReserves fetches reserves of given addresses which are not cached.
+func (im *Importer) loadReserves(batch []Record) error {
+	var (
+		missing []ethereum.Address
+		seen    = make(map[ethereum.Address]struct{})
+	)
+	for _, rec := range batch {
+		if rec.TradeLog.Version != katalystVersion {
+			continue
+		}
+		for _, s := range rec.TradeLog.Split {
+			if _, ok := im.reserves[s.ReserveAddress]; ok {
+				continue
+			}
+			if _, ok := seen[s.ReserveAddress]; ok {
+				continue
+			}
+			seen[s.ReserveAddress] = struct{}{}
+			missing = append(missing, s.ReserveAddress)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	reserves, err := im.storage.GetReservesByAddresses(missing)
+	if err != nil {
+		return err
+	}
+	for _, addr := range missing {
+		// unknown reserves are cached as well to not query them again
+		im.reserves[addr] = nil
+	}
+	for _, r := range reserves {
+		im.reserves[r.Address] = append(im.reserves[r.Address], r)
+	}
+	return nil
+}
+
+// reserveID returns ID of the reserve at given block.
+func (im *Importer) reserveID(addr ethereum.Address, block uint64) ([32]byte, bool) {
+	var (
+		id    [32]byte
+		found bool
+	)
+	for _, r := range im.reserves[addr] {
+		if r.BlockNumber > block {
+			break
+		}
+		id, found = r.ReserveID, true
+	}
+	return id, found
+}
+
+func zeroIfNil(v *big.Int) *big.Int {
+	if v == nil {
+		return big.NewInt(0)
+	}
+	return v
+}
+
+// prepare fills fields of the trade log as set by crawler, which are not returned by the API.
+func (im *Importer) prepare(tl common.TradelogV4) (common.TradelogV4, error) {
+	tl.EthAmount = zeroIfNil(tl.EthAmount)
+	tl.OriginalEthAmount = zeroIfNil(tl.OriginalEthAmount)
+	tl.SrcAmount = zeroIfNil(tl.SrcAmount)
+	tl.DestAmount = zeroIfNil(tl.DestAmount)
+	tl.TxDetail.GasPrice = zeroIfNil(tl.TxDetail.GasPrice)
+	tl.TxDetail.TransactionFee = zeroIfNil(tl.TxDetail.TransactionFee)
+	for i := range tl.Fees {
+		tl.Fees[i].WalletFee = zeroIfNil(tl.Fees[i].WalletFee)
+		tl.Fees[i].PlatformFee = zeroIfNil(tl.Fees[i].PlatformFee)
+		tl.Fees[i].Burn = zeroIfNil(tl.Fees[i].Burn)
+		tl.Fees[i].Rebate = zeroIfNil(tl.Fees[i].Rebate)
+		tl.Fees[i].Reward = zeroIfNil(tl.Fees[i].Reward)
+	}
+	if tl.WalletName == "" {
+		tl.WalletName = tradelogs.WalletAddrToName(tl.WalletAddress)
+	}
+
+	splits := append([]common.TradeSplit(nil), tl.Split...)
+	sort.Slice(splits, func(i, j int) bool { return splits[i].Index < splits[j].Index })
+	if tl.Version != katalystVersion {
+		for _, s := range splits {
+			if s.SrcToken == tl.TokenInfo.SrcAddress && s.SrcToken != blockchain.ETHAddr {
+				tl.SrcReserveAddress = s.ReserveAddress
+			}
+			if s.DstToken == tl.TokenInfo.DestAddress && s.DstToken != blockchain.ETHAddr {
+				tl.DstReserveAddress = s.ReserveAddress
+			}
+		}
+		return tl, nil
+	}
+
+	tl.T2EReserves, tl.E2TReserves = nil, nil
+	tl.T2ESrcAmount, tl.E2TSrcAmount = nil, nil
+	tl.T2ERates, tl.E2TRates = nil, nil
+	for _, s := range splits {
+		id, ok := im.reserveID(s.ReserveAddress, tl.BlockNumber)
+		if !ok {
+			return tl, fmt.Errorf("unknown reserve %s at block %d", s.ReserveAddress.Hex(), tl.BlockNumber)
+		}
+		switch {
+		case s.DstToken == blockchain.ETHAddr:
+			tl.T2EReserves = append(tl.T2EReserves, id)
+			tl.T2ESrcAmount = append(tl.T2ESrcAmount, zeroIfNil(s.SrcAmount))
+			tl.T2ERates = append(tl.T2ERates, zeroIfNil(s.Rate))
+		case s.SrcToken == blockchain.ETHAddr:
+			tl.E2TReserves = append(tl.E2TReserves, id)
+			tl.E2TSrcAmount = append(tl.E2TSrcAmount, zeroIfNil(s.SrcAmount))
+			tl.E2TRates = append(tl.E2TRates, zeroIfNil(s.Rate))
+		default:
+			return tl, fmt.Errorf("split %d is neither token to ether nor ether to token", s.Index)
+		}
+	}
+	// crawler keeps destination amount of Katalyst trades as sum of src amount * rate of the last leg,
+	// storage converts it to token amount.
+	srcAmounts, rates := tl.T2ESrcAmount, tl.T2ERates
+	if len(tl.E2TSrcAmount) != 0 {
+		srcAmounts, rates = tl.E2TSrcAmount, tl.E2TRates
+	}
+	tl.DestAmount = big.NewInt(0)
+	for i, amount := range srcAmounts {
+		tl.DestAmount.Add(tl.DestAmount, new(big.Int).Mul(amount, rates[i]))
+	}
+	return tl, nil
+}
+
+// save resolves reserves of the batch and saves trade logs in a transaction.
+func (im *Importer) save(report *Report, batch []Record) error {
+	var (
+		logger = im.sugar.With("func", caller.GetCurrentFunctionName())
+		trades []common.TradelogV4
+	)
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := im.loadReserves(batch); err != nil {
+		return err
+	}
+	for _, rec := range batch {
+		tl, err := im.prepare(rec.TradeLog)
+		if err != nil {
+			rec.Err = err
+			im.reject(report, rec)
+			continue
+		}
+		trades = append(trades, tl)
+	}
+	if len(trades) == 0 {
+		return nil
+	}
+	if err := im.storage.SaveTradeLogs(&common.CrawlResult{Trades: trades}); err != nil {
+		return fmt.Errorf("failed to save trade logs of lines %d-%d: %v", batch[0].Line, batch[len(batch)-1].Line, err)
+	}
+	report.Imported += uint64(len(trades))
+	logger.Debugw("saved trade logs", "from_line", batch[0].Line, "to_line", batch[len(batch)-1].Line, "trades", len(trades))
+	return nil
+}
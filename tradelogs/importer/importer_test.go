@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/testutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/export"
+)
+
+var (
+	testKNC       = ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200")
+	testReserve   = ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f")
+	testUnknown   = ethereum.HexToAddress("0x21433dec9cb634a23c6a4bbcce08c83f5ac2ec18")
+	testReserveID = [32]byte{0xaa, 0x01}
+)
+
+type mockStorage struct {
+	saved    []common.TradelogV4
+	reserves []common.Reserve
+	lookups  int
+}
+
+func (s *mockStorage) SaveTradeLogs(result *common.CrawlResult) error {
+	s.saved = append(s.saved, result.Trades...)
+	return nil
+}
+
+func (s *mockStorage) GetReservesByAddresses(addresses []ethereum.Address) ([]common.Reserve, error) {
+	s.lookups++
+	var result []common.Reserve
+	for _, r := range s.reserves {
+		for _, addr := range addresses {
+			if r.Address == addr {
+				result = append(result, r)
+			}
+		}
+	}
+	return result, nil
+}
+
+type mockDecimals struct{}
+
+func (mockDecimals) GetDecimals(token ethereum.Address) (int64, error) {
+	if token == blockchain.ETHAddr || token == testKNC {
+		return 18, nil
+	}
+	return 0, fmt.Errorf("unknown token")
+}
+
+func newTestTradeLog(block uint64, index uint) common.TradelogV4 {
+	return common.TradelogV4{
+		Timestamp:         time.Unix(1589209417, 0).UTC(),
+		BlockNumber:       block,
+		TransactionHash:   ethereum.BigToHash(big.NewInt(int64(block))),
+		Index:             index,
+		Version:           4,
+		TokenInfo:         common.TradeTokenInfo{SrcAddress: testKNC, DestAddress: blockchain.ETHAddr},
+		EthAmount:         big.NewInt(100),
+		OriginalEthAmount: big.NewInt(100),
+		SrcAmount:         big.NewInt(1000),
+		DestAmount:        big.NewInt(100),
+		Split: []common.TradeSplit{{
+			ReserveAddress: testReserve,
+			SrcToken:       testKNC,
+			DstToken:       blockchain.ETHAddr,
+			SrcAmount:      big.NewInt(1000),
+			Rate:           big.NewInt(2),
+			Index:          1,
+		}},
+		Fees: []common.TradelogFee{{
+			ReserveAddr:               testReserve,
+			Burn:                      big.NewInt(5),
+			RebateWallets:             []ethereum.Address{testReserve},
+			RebatePercentBpsPerWallet: []*big.Int{big.NewInt(10000)},
+			Index:                     index - 1,
+		}},
+	}
+}
+
+func exportCSV(t *testing.T, tradeLogs ...common.TradelogV4) string {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(export.CSV, &buf)
+	require.NoError(t, err)
+	for _, tl := range tradeLogs {
+		row := export.NewRow(tl)
+		require.NoError(t, w.Write(&row))
+	}
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestImportCSV(t *testing.T) {
+	var (
+		sugar      = testutil.MustNewDevelopmentSugaredLogger()
+		valid      = newTestTradeLog(100, 3)
+		badReserve = newTestTradeLog(101, 3)
+		oldReserve = newTestTradeLog(10, 3)
+		badToken   = newTestTradeLog(102, 3)
+		rejections []Rejection
+		st         = &mockStorage{reserves: []common.Reserve{{Address: testReserve, ReserveID: testReserveID, BlockNumber: 50}}}
+	)
+	badReserve.Split[0].ReserveAddress = testUnknown
+	badToken.TokenInfo.SrcAddress = testUnknown
+
+	input := exportCSV(t, valid, badReserve, oldReserve, badToken) + "1,2,not-enough-fields\n"
+	r, err := NewReader(export.CSV, strings.NewReader(input))
+	require.NoError(t, err)
+	im := NewImporter(sugar, st, mockDecimals{},
+		WithBatchSize(2),
+		WithRejectionHandler(func(r Rejection) { rejections = append(rejections, r) }))
+	report, err := im.Import(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, Report{Imported: 1, Rejected: 4}, report)
+	var lines []int
+	for _, r := range rejections {
+		lines = append(lines, r.Line)
+	}
+	assert.ElementsMatch(t, []int{3, 4, 5, 6}, lines)
+
+	require.Len(t, st.saved, 1)
+	saved := st.saved[0]
+	assert.Equal(t, valid.TransactionHash, saved.TransactionHash)
+	assert.Equal(t, [][32]byte{testReserveID}, saved.T2EReserves)
+	assert.Equal(t, []*big.Int{big.NewInt(1000)}, saved.T2ESrcAmount)
+	// destination amount is src amount * rate as set by crawler
+	assert.Equal(t, big.NewInt(2000), saved.DestAmount)
+	require.Len(t, saved.Fees, 1)
+	assert.Equal(t, uint(2), saved.Fees[0].Index)
+	assert.Equal(t, big.NewInt(5), saved.Fees[0].Burn)
+	assert.Equal(t, []ethereum.Address{testReserve}, saved.Fees[0].RebateWallets)
+}
+
+func TestImportNDJSON(t *testing.T) {
+	var (
+		sugar = testutil.MustNewDevelopmentSugaredLogger()
+		st    = &mockStorage{reserves: []common.Reserve{{Address: testReserve, ReserveID: testReserveID, BlockNumber: 50}}}
+		buf   bytes.Buffer
+	)
+	for i := uint64(0); i < 3; i++ {
+		tl := newTestTradeLog(100+i, 1)
+		data, err := tl.MarshalJSON()
+		require.NoError(t, err)
+		buf.Write(data)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n{invalid json\n")
+
+	r, err := NewReader(export.NDJSON, &buf)
+	require.NoError(t, err)
+	report, err := NewImporter(sugar, st, mockDecimals{}).Import(r)
+	require.NoError(t, err)
+	assert.Equal(t, Report{Imported: 3, Rejected: 1}, report)
+	require.Len(t, st.saved, 3)
+	assert.Equal(t, uint64(101), st.saved[1].BlockNumber)
+	assert.Equal(t, time.Unix(1589209417, 0).UTC(), st.saved[1].Timestamp.UTC())
+	// reserves are looked up once
+	assert.Equal(t, 1, st.lookups)
+}
+
+func TestImportCSVMissingColumn(t *testing.T) {
+	r, err := NewReader(export.CSV, strings.NewReader("timestamp,block_number\n1,2\n"))
+	require.NoError(t, err)
+	_, err = NewImporter(testutil.MustNewDevelopmentSugaredLogger(), &mockStorage{}, mockDecimals{}).Import(r)
+	assert.Error(t, err)
+}
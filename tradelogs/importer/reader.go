@@ -0,0 +1,390 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/export"
+)
+
+// Record is a trade log read from input. Err is set when the record can not be parsed,
+// the record is rejected without stopping the import. Line is the line number of NDJSON input
+// or the row number of CSV input, counting the header.
+type Record struct {
+	Line     int
+	TradeLog common.TradelogV4
+	Err      error
+}
+
+// Reader reads trade logs from input, it returns io.EOF when there is no more record.
+type Reader interface {
+	Next() (Record, error)
+}
+
+// NewReader returns a Reader of given format. NDJSON input is a trade log per line as returned
+// by trade logs API, CSV input is in the format of trade logs export.
+func NewReader(format export.Format, r io.Reader) (Reader, error) {
+	switch format {
+	case export.NDJSON:
+		return &ndjsonReader{r: bufio.NewReader(r)}, nil
+	case export.CSV:
+		cr := csv.NewReader(r)
+		// field count is validated against the header
+		cr.FieldsPerRecord = -1
+		return &csvReader{r: cr}, nil
+	default:
+		return nil, fmt.Errorf("unsupported import format: %q", format)
+	}
+}
+
+type ndjsonReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (nr *ndjsonReader) Next() (Record, error) {
+	for {
+		data, err := nr.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return Record{}, err
+		}
+		if len(data) == 0 && err == io.EOF {
+			return Record{}, io.EOF
+		}
+		nr.line++
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		rec := Record{Line: nr.line}
+		if uErr := json.Unmarshal(data, &rec.TradeLog); uErr != nil {
+			rec.Err = fmt.Errorf("invalid json: %v", uErr)
+		}
+		return rec, nil
+	}
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+	line    int
+}
+
+// requiredColumns are columns needed to import a trade log, other columns are optional.
+var requiredColumns = []string{
+	"timestamp", "block_number", "tx_hash", "log_index", "version",
+	"user_addr", "src_token", "dst_token", "src_amount", "dst_amount", "eth_amount", "original_eth_amount",
+}
+
+func (cr *csvReader) readHeader() error {
+	header, err := cr.r.Read()
+	if err == io.EOF {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read csv header: %v", err)
+	}
+	cr.line++
+	cr.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		cr.columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := cr.columns[name]; !ok {
+			return fmt.Errorf("missing required csv column: %s", name)
+		}
+	}
+	return nil
+}
+
+func (cr *csvReader) Next() (Record, error) {
+	if cr.columns == nil {
+		if err := cr.readHeader(); err != nil {
+			return Record{}, err
+		}
+	}
+	values, err := cr.r.Read()
+	if err == io.EOF {
+		return Record{}, io.EOF
+	}
+	cr.line++
+	rec := Record{Line: cr.line}
+	var pErr *csv.ParseError
+	switch {
+	case errors.As(err, &pErr):
+		rec.Err = err
+		return rec, nil
+	case err != nil:
+		return rec, err
+	case len(values) != len(cr.columns):
+		rec.Err = fmt.Errorf("wrong number of fields: %d, expected: %d", len(values), len(cr.columns))
+		return rec, nil
+	}
+	rec.TradeLog, rec.Err = (&csvRow{columns: cr.columns, values: values}).tradeLog()
+	return rec, nil
+}
+
+// csvRow parses values of a row, the first parse error is kept and returned by tradeLog.
+type csvRow struct {
+	columns map[string]int
+	values  []string
+	err     error
+}
+
+func (r *csvRow) get(name string) string {
+	i, ok := r.columns[name]
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(r.values[i])
+}
+
+func (r *csvRow) setErr(name string, err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("invalid %s: %v", name, err)
+	}
+}
+
+func (r *csvRow) uint64(name string) uint64 {
+	s := r.get(name)
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		r.setErr(name, err)
+	}
+	return v
+}
+
+func (r *csvRow) float64(name string) float64 {
+	s := r.get(name)
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		r.setErr(name, err)
+	}
+	return v
+}
+
+func parseBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return big.NewInt(0), nil
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("%q is not an integer", s)
+	}
+	return v, nil
+}
+
+func parseAddress(s string) (ethereum.Address, error) {
+	if s == "" {
+		return ethereum.Address{}, nil
+	}
+	if !ethereum.IsHexAddress(s) {
+		return ethereum.Address{}, fmt.Errorf("%q is not an address", s)
+	}
+	return ethereum.HexToAddress(s), nil
+}
+
+func (r *csvRow) bigInt(name string) *big.Int {
+	v, err := parseBigInt(r.get(name))
+	if err != nil {
+		r.setErr(name, err)
+	}
+	return v
+}
+
+func (r *csvRow) address(name string) ethereum.Address {
+	v, err := parseAddress(r.get(name))
+	if err != nil {
+		r.setErr(name, err)
+	}
+	return v
+}
+
+// list returns values of a flattened column, which must have n values.
+func (r *csvRow) list(name string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	if _, ok := r.columns[name]; !ok {
+		r.setErr(name, errors.New("missing column"))
+		return make([]string, n)
+	}
+	values := strings.Split(r.get(name), export.ListSeparator)
+	if len(values) != n {
+		r.setErr(name, fmt.Errorf("%d values, expected: %d", len(values), n))
+		return make([]string, n)
+	}
+	return values
+}
+
+func (r *csvRow) bigIntList(name string, n int) []*big.Int {
+	var result []*big.Int
+	for _, s := range r.list(name, n) {
+		v, err := parseBigInt(s)
+		if err != nil {
+			r.setErr(name, err)
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+func (r *csvRow) addressList(name string, n int) []ethereum.Address {
+	var result []ethereum.Address
+	for _, s := range r.list(name, n) {
+		v, err := parseAddress(s)
+		if err != nil {
+			r.setErr(name, err)
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+func (r *csvRow) splits() []common.TradeSplit {
+	var (
+		n          = int(r.uint64("split_count"))
+		reserves   = r.addressList("split_reserves", n)
+		srcTokens  = r.addressList("split_src_tokens", n)
+		dstTokens  = r.addressList("split_dst_tokens", n)
+		srcAmounts = r.bigIntList("split_src_amounts", n)
+		dstAmounts = r.bigIntList("split_dst_amounts", n)
+		rates      = r.bigIntList("split_rates", n)
+		result     []common.TradeSplit
+	)
+	if r.err != nil {
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		result = append(result, common.TradeSplit{
+			ReserveAddress: reserves[i],
+			SrcToken:       srcTokens[i],
+			DstToken:       dstTokens[i],
+			SrcAmount:      srcAmounts[i],
+			DstAmount:      dstAmounts[i],
+			Rate:           rates[i],
+			// splits are exported in index order and indexed from 1
+			Index: uint(i + 1),
+		})
+	}
+	return result
+}
+
+func (r *csvRow) fees() []common.TradelogFee {
+	var (
+		n               = int(r.uint64("fee_count"))
+		reserves        = r.addressList("fee_reserves", n)
+		platformWallets = r.addressList("fee_platform_wallets", n)
+		indexes         = r.list("fee_indexes", n)
+		platformFees    = r.bigIntList("fee_platform_fees", n)
+		walletFees      = r.bigIntList("fee_platform_rebates", n)
+		burns           = r.bigIntList("fee_burns", n)
+		rebates         = r.bigIntList("fee_reserve_rebates", n)
+		rewards         = r.bigIntList("fee_rewards", n)
+		rebateWallets   = r.list("fee_rebate_wallets", n)
+		rebatePercents  = r.list("fee_rebate_percents", n)
+		result          []common.TradelogFee
+	)
+	if r.err != nil {
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		index, err := strconv.ParseUint(indexes[i], 10, 32)
+		if err != nil {
+			r.setErr("fee_indexes", err)
+			return nil
+		}
+		fee := common.TradelogFee{
+			ReserveAddr:    reserves[i],
+			PlatformWallet: platformWallets[i],
+			PlatformFee:    platformFees[i],
+			WalletFee:      walletFees[i],
+			Burn:           burns[i],
+			Rebate:         rebates[i],
+			Reward:         rewards[i],
+			Index:          uint(index),
+		}
+		if rebateWallets[i] != "" {
+			for _, s := range strings.Split(rebateWallets[i], export.ValueSeparator) {
+				wallet, err := parseAddress(s)
+				if err != nil {
+					r.setErr("fee_rebate_wallets", err)
+					return nil
+				}
+				fee.RebateWallets = append(fee.RebateWallets, wallet)
+			}
+		}
+		if rebatePercents[i] != "" {
+			for _, s := range strings.Split(rebatePercents[i], export.ValueSeparator) {
+				percent, err := parseBigInt(s)
+				if err != nil {
+					r.setErr("fee_rebate_percents", err)
+					return nil
+				}
+				fee.RebatePercentBpsPerWallet = append(fee.RebatePercentBpsPerWallet, percent)
+			}
+		}
+		result = append(result, fee)
+	}
+	return result
+}
+
+func (r *csvRow) tradeLog() (common.TradelogV4, error) {
+	var txHash ethereum.Hash
+	if s := r.get("tx_hash"); len(s) != 2+2*ethereum.HashLength {
+		r.setErr("tx_hash", fmt.Errorf("%q is not a transaction hash", s))
+	} else {
+		txHash = ethereum.HexToHash(s)
+	}
+	tl := common.TradelogV4{
+		Timestamp:       timeutil.TimestampMsToTime(r.uint64("timestamp")),
+		BlockNumber:     r.uint64("block_number"),
+		TransactionHash: txHash,
+		Index:           uint(r.uint64("log_index")),
+		Version:         uint(r.uint64("version")),
+		TokenInfo: common.TradeTokenInfo{
+			SrcAddress:  r.address("src_token"),
+			DestAddress: r.address("dst_token"),
+		},
+		EthAmount:         r.bigInt("eth_amount"),
+		OriginalEthAmount: r.bigInt("original_eth_amount"),
+		SrcAmount:         r.bigInt("src_amount"),
+		DestAmount:        r.bigInt("dst_amount"),
+		FiatAmount:        r.float64("fiat_amount"),
+		ETHUSDRate:        r.float64("eth_usd_rate"),
+		WalletAddress:     r.address("wallet_addr"),
+		IntegrationApp:    r.get("integration_app"),
+		User: common.KyberUserInfo{
+			UserAddress: r.address("user_addr"),
+			Country:     r.get("country"),
+		},
+		ReceiverAddress: r.address("receiver_address"),
+		TxDetail: common.TxDetail{
+			GasUsed:        r.uint64("gas_used"),
+			GasPrice:       r.bigInt("gas_price"),
+			TransactionFee: r.bigInt("transaction_fee"),
+			TxSender:       r.address("tx_sender"),
+		},
+	}
+	tl.Split = r.splits()
+	tl.Fees = r.fees()
+	return tl, r.err
+}
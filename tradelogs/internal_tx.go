@@ -0,0 +1,302 @@
+package tradelogs
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/nanmu42/etherscan-api"
+	"github.com/urfave/cli"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+)
+
+const (
+	internalTxBackendFlag = "internal-tx-backend"
+	traceNodeFlag         = "trace-node"
+
+	// EtherscanInternalTxBackend gets internal transactions from etherscan API.
+	EtherscanInternalTxBackend = "etherscan"
+	// DebugTraceInternalTxBackend traces transactions with debug_traceTransaction and the built-in callTracer (geth).
+	DebugTraceInternalTxBackend = "debug"
+	// ParityTraceInternalTxBackend traces transactions with trace_transaction (openethereum, erigon).
+	ParityTraceInternalTxBackend = "trace"
+
+	debugTraceTransactionMethod  = "debug_traceTransaction"
+	parityTraceTransactionMethod = "trace_transaction"
+)
+
+// InternalTransfer is a value transfer made by a contract while executing a transaction.
+type InternalTransfer struct {
+	From  ethereum.Address
+	To    ethereum.Address
+	Value *big.Int
+}
+
+// InternalTxProvider returns the internal value transfers of a transaction.
+type InternalTxProvider interface {
+	InternalTransfers(txHash ethereum.Hash, blockNumber uint64) ([]InternalTransfer, error)
+}
+
+// NewInternalTxCliFlags returns cli flags to configure the internal transactions backend.
+func NewInternalTxCliFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name: internalTxBackendFlag,
+			Usage: fmt.Sprintf("backend to get internal transactions of trades, one of: %s, %s, %s",
+				EtherscanInternalTxBackend, DebugTraceInternalTxBackend, ParityTraceInternalTxBackend),
+			EnvVar: "INTERNAL_TX_BACKEND",
+			Value:  EtherscanInternalTxBackend,
+		},
+		cli.StringFlag{
+			Name:   traceNodeFlag,
+			Usage:  "archive node URL to trace transactions, default to ethereum node",
+			EnvVar: "TRACE_NODE",
+		},
+	}
+}
+
+// NewInternalTxProviderFromContext returns the internal transactions backend configured by cli flags.
+func NewInternalTxProviderFromContext(sugar *zap.SugaredLogger, c *cli.Context, etherscanClient *etherscan.Client) (InternalTxProvider, error) {
+	backend := c.String(internalTxBackendFlag)
+	switch backend {
+	case "", EtherscanInternalTxBackend:
+		if etherscanClient == nil {
+			return nil, fmt.Errorf("etherscan client is required by internal transactions backend %s", EtherscanInternalTxBackend)
+		}
+		return NewEtherscanInternalTxProvider(sugar, etherscanClient, ethereum.HexToAddress(internalNetworkAddrV1)), nil
+	case DebugTraceInternalTxBackend, ParityTraceInternalTxBackend:
+		nodeURL := c.String(traceNodeFlag)
+		if nodeURL == "" {
+			nodeURL = blockchain.NodeURLFromFlag(c)
+		}
+		client, err := rpc.DialHTTPWithClient(nodeURL, &http.Client{})
+		if err != nil {
+			return nil, err
+		}
+		method := debugTraceTransactionMethod
+		if backend == ParityTraceInternalTxBackend {
+			method = parityTraceTransactionMethod
+		}
+		return NewTraceInternalTxProvider(sugar, client, method), nil
+	default:
+		return nil, fmt.Errorf("invalid internal transactions backend: %s", backend)
+	}
+}
+
+// EtherscanInternalTxProvider gets internal transactions of an address from etherscan API.
+type EtherscanInternalTxProvider struct {
+	sugar   *zap.SugaredLogger
+	client  *etherscan.Client
+	address ethereum.Address
+}
+
+// NewEtherscanInternalTxProvider returns an EtherscanInternalTxProvider, etherscan only lists internal
+// transactions by address so transfers are limited to those of given address.
+func NewEtherscanInternalTxProvider(sugar *zap.SugaredLogger, client *etherscan.Client, address ethereum.Address) *EtherscanInternalTxProvider {
+	return &EtherscanInternalTxProvider{sugar: sugar, client: client, address: address}
+}
+
+// InternalTransfers returns internal transfers of given transaction made by the provider address.
+func (p *EtherscanInternalTxProvider) InternalTransfers(txHash ethereum.Hash, blockNumber uint64) ([]InternalTransfer, error) {
+	blockInt := int(blockNumber)
+	internalTxs, err := p.client.InternalTxByAddress(p.address.Hex(), &blockInt, &blockInt, 0, 0, false)
+	if err != nil {
+		switch {
+		case blockchain.IsEtherscanNotransactionFound(err):
+			p.sugar.Warnw("internal transaction not found on etherscan", "err", err,
+				"tx_hash", txHash, "block_number", blockNumber)
+			return nil, nil
+		case blockchain.IsEtherscanRateLimit(err):
+			p.sugar.Warnw("failed to get internal transaction", "err", err,
+				"tx_hash", txHash, "block_number", blockNumber)
+			return nil, err
+		default:
+			return nil, err
+		}
+	}
+
+	var transfers []InternalTransfer
+	for _, tx := range internalTxs {
+		if ethereum.HexToHash(tx.Hash) != txHash {
+			continue
+		}
+		transfer := InternalTransfer{
+			From:  ethereum.HexToAddress(tx.From),
+			To:    ethereum.HexToAddress(tx.To),
+			Value: big.NewInt(0),
+		}
+		if tx.Value != nil {
+			transfer.Value = tx.Value.Int()
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
+// TraceInternalTxProvider gets internal transfers by tracing transactions on an archive node, it does not
+// depend on any third party API.
+type TraceInternalTxProvider struct {
+	sugar   *zap.SugaredLogger
+	client  *rpc.Client
+	method  string
+	timeout time.Duration
+}
+
+// NewTraceInternalTxProvider returns a TraceInternalTxProvider using given trace method, either
+// debug_traceTransaction or trace_transaction.
+func NewTraceInternalTxProvider(sugar *zap.SugaredLogger, client *rpc.Client, method string) *TraceInternalTxProvider {
+	return &TraceInternalTxProvider{sugar: sugar, client: client, method: method, timeout: time.Minute}
+}
+
+// callFrame is a call of the callTracer result, nested calls are in Calls.
+type callFrame struct {
+	Type  string           `json:"type"`
+	From  ethereum.Address `json:"from"`
+	To    ethereum.Address `json:"to"`
+	Value *hexutil.Big     `json:"value"`
+	Error string           `json:"error"`
+	Calls []callFrame      `json:"calls"`
+}
+
+// parityTrace is an entry of the trace_transaction result, the position of the call in the call tree is
+// given by TraceAddress.
+type parityTrace struct {
+	Type   string `json:"type"`
+	Action struct {
+		CallType      string           `json:"callType"`
+		From          ethereum.Address `json:"from"`
+		To            ethereum.Address `json:"to"`
+		Value         *hexutil.Big     `json:"value"`
+		Address       ethereum.Address `json:"address"`
+		RefundAddress ethereum.Address `json:"refundAddress"`
+		Balance       *hexutil.Big     `json:"balance"`
+	} `json:"action"`
+	Result *struct {
+		Address ethereum.Address `json:"address"`
+	} `json:"result"`
+	Error        string `json:"error"`
+	TraceAddress []int  `json:"traceAddress"`
+}
+
+// InternalTransfers returns the value transfers of internal calls of given transaction. Calls reverted
+// and calls not moving value (delegatecall, staticcall) are skipped.
+func (p *TraceInternalTxProvider) InternalTransfers(txHash ethereum.Hash, blockNumber uint64) ([]InternalTransfer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	logger := p.sugar.With("tx_hash", txHash, "block_number", blockNumber, "method", p.method)
+
+	switch p.method {
+	case debugTraceTransactionMethod:
+		var root callFrame
+		if err := p.client.CallContext(ctx, &root, p.method, txHash, map[string]string{"tracer": "callTracer"}); err != nil {
+			return nil, err
+		}
+		if root.Error != "" {
+			logger.Debugw("transaction is reverted", "error", root.Error)
+			return nil, nil
+		}
+		var transfers []InternalTransfer
+		for _, call := range root.Calls {
+			transfers = appendCallTransfers(transfers, call)
+		}
+		return transfers, nil
+	case parityTraceTransactionMethod:
+		var traces []parityTrace
+		if err := p.client.CallContext(ctx, &traces, p.method, txHash); err != nil {
+			return nil, err
+		}
+		return parityTransfers(traces), nil
+	default:
+		return nil, fmt.Errorf("unsupported trace method: %s", p.method)
+	}
+}
+
+// appendCallTransfers appends transfers of the call and its nested calls to transfers.
+func appendCallTransfers(transfers []InternalTransfer, call callFrame) []InternalTransfer {
+	if call.Error != "" {
+		return transfers // the call and its nested calls are reverted
+	}
+	switch strings.ToUpper(call.Type) {
+	case "CALL", "CREATE", "CREATE2", "SELFDESTRUCT":
+		if call.Value != nil && call.Value.ToInt().Sign() > 0 {
+			transfers = append(transfers, InternalTransfer{
+				From:  call.From,
+				To:    call.To,
+				Value: call.Value.ToInt(),
+			})
+		}
+	}
+	for _, nested := range call.Calls {
+		transfers = appendCallTransfers(transfers, nested)
+	}
+	return transfers
+}
+
+// parityTransfers returns transfers of internal calls in traces, skipping the top level call.
+func parityTransfers(traces []parityTrace) []InternalTransfer {
+	var reverted [][]int
+	for _, trace := range traces {
+		if trace.Error != "" {
+			reverted = append(reverted, trace.TraceAddress)
+		}
+	}
+	isReverted := func(traceAddress []int) bool {
+		for _, r := range reverted {
+			if len(r) <= len(traceAddress) && intsEqual(r, traceAddress[:len(r)]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var transfers []InternalTransfer
+	for _, trace := range traces {
+		if len(trace.TraceAddress) == 0 || isReverted(trace.TraceAddress) {
+			continue
+		}
+		var transfer InternalTransfer
+		switch trace.Type {
+		case "call":
+			if trace.Action.CallType != "call" || trace.Action.Value == nil {
+				continue
+			}
+			transfer = InternalTransfer{From: trace.Action.From, To: trace.Action.To, Value: trace.Action.Value.ToInt()}
+		case "create":
+			if trace.Result == nil || trace.Action.Value == nil {
+				continue
+			}
+			transfer = InternalTransfer{From: trace.Action.From, To: trace.Result.Address, Value: trace.Action.Value.ToInt()}
+		case "suicide":
+			if trace.Action.Balance == nil {
+				continue
+			}
+			transfer = InternalTransfer{From: trace.Action.Address, To: trace.Action.RefundAddress, Value: trace.Action.Balance.ToInt()}
+		default:
+			continue
+		}
+		if transfer.Value.Sign() > 0 {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers
+}
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
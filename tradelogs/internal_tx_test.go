@@ -0,0 +1,80 @@
+package tradelogs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/testutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// newTraceTestServer returns a JSON-RPC server replying trace requests with fixtures of testdata/trace,
+// named after the trace method.
+func newTraceTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			JSONRPC string            `json:"jsonrpc"`
+			ID      json.RawMessage   `json:"id"`
+			Method  string            `json:"method"`
+			Params  []json.RawMessage `json:"params"`
+		}
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&req)) {
+			return
+		}
+		result, err := ioutil.ReadFile(filepath.Join("testdata", "trace", req.Method+".json"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			ID      json.RawMessage `json:"id"`
+			Result  json.RawMessage `json:"result"`
+		}{JSONRPC: "2.0", ID: req.ID, Result: result}))
+	}))
+}
+
+func TestTraceInternalTxProvider(t *testing.T) {
+	var (
+		sugar    = testutil.MustNewDevelopmentSugaredLogger()
+		txHash   = ethereum.HexToHash("0x1f4c0ec4c2cd6e9c1b0c78bca6d91a0a5d0c8ab3e2a8c5e2c4a7a3b1e8f2d6c9")
+		network  = ethereum.HexToAddress(internalNetworkAddrV1)
+		reserve  = ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f")
+		receiver = ethereum.HexToAddress("0x2c1ba59d6f58433fb1eaee7d20b26ed83bda51a3")
+		value, _ = big.NewInt(0).SetString("1500000000000000000", 10)
+		// transfers of reverted calls, delegatecall and staticcall are excluded
+		expected = []InternalTransfer{
+			{From: reserve, To: network, Value: value},
+			{From: network, To: receiver, Value: value},
+		}
+	)
+
+	server := newTraceTestServer(t)
+	defer server.Close()
+	client, err := rpc.DialHTTP(server.URL)
+	require.NoError(t, err)
+
+	for _, method := range []string{debugTraceTransactionMethod, parityTraceTransactionMethod} {
+		t.Run(method, func(t *testing.T) {
+			p := NewTraceInternalTxProvider(sugar, client, method)
+			transfers, err := p.InternalTransfers(txHash, 5000000)
+			require.NoError(t, err)
+			assert.Equal(t, expected, transfers)
+
+			crawler := &Crawler{sugar: sugar, internalTxProvider: p}
+			tradeLog, err := crawler.getInternalTransaction(common.TradelogV4{TransactionHash: txHash, BlockNumber: 5000000})
+			require.NoError(t, err)
+			assert.Equal(t, receiver, tradeLog.ReceiverAddress)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package mev
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// Storage is the trade logs storage that keeps sandwiched trades.
+type Storage interface {
+	LoadTradeLogsPage(filter common.TradeLogFilter) ([]common.TradelogV4, error)
+	SaveSandwiches(fromBlock, toBlock uint64, sandwiches []common.Sandwich) error
+}
+
+// Detector tags sandwiched trades of crawled trade logs.
+type Detector struct {
+	sugar     *zap.SugaredLogger
+	st        Storage
+	batchSize uint64 // number of blocks processed at once
+}
+
+// NewDetector returns a new Detector instance.
+func NewDetector(sugar *zap.SugaredLogger, st Storage, batchSize uint64) *Detector {
+	return &Detector{
+		sugar:     sugar,
+		st:        st,
+		batchSize: batchSize,
+	}
+}
+
+// Run detects sandwiched trades of blocks in range [fromBlock, toBlock] in batches. Sandwiches of each
+// batch replace the previously detected ones, so running again over the same blocks is safe.
+func (d *Detector) Run(fromBlock, toBlock uint64) error {
+	logger := d.sugar.With(
+		"func", caller.GetCurrentFunctionName(),
+		"from_block", fromBlock,
+		"to_block", toBlock,
+	)
+	for start := fromBlock; start <= toBlock; start += d.batchSize {
+		end := start + d.batchSize - 1
+		if end > toBlock {
+			end = toBlock
+		}
+		trades, err := d.st.LoadTradeLogsPage(common.TradeLogFilter{FromBlock: start, ToBlock: end})
+		if err != nil {
+			return err
+		}
+		sandwiches := FindSandwiches(trades)
+		if err = d.st.SaveSandwiches(start, end, sandwiches); err != nil {
+			return err
+		}
+		logger.Infow("detected sandwiched trades",
+			"batch_from", start,
+			"batch_to", end,
+			"trades", len(trades),
+			"sandwiches", len(sandwiches))
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package mev
+
+import (
+	"math"
+	"math/big"
+	"sort"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// leg is the splits of a trade between ETH and a token in one direction, amounts are in wei of
+// the token and ETH, so prices of legs of the same token are comparable without token decimals.
+type leg struct {
+	token       ethereum.Address
+	buy         bool // the token is bought with ETH
+	tokenAmount float64
+	ethAmount   float64
+}
+
+func (l leg) price() float64 {
+	return l.ethAmount / l.tokenAmount
+}
+
+func weiToFloat(amount *big.Int) float64 {
+	if amount == nil {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(amount).Float64()
+	return f
+}
+
+// legsOf aggregates splits of the trade by token and direction.
+func legsOf(trade common.TradelogV4) []leg {
+	var (
+		result  []leg
+		indexes = make(map[leg]int)
+	)
+	for _, s := range trade.Split {
+		var l leg
+		switch {
+		case s.SrcToken == blockchain.ETHAddr && s.DstToken != blockchain.ETHAddr:
+			l = leg{token: s.DstToken, buy: true}
+			l.tokenAmount, l.ethAmount = weiToFloat(s.DstAmount), weiToFloat(s.SrcAmount)
+		case s.DstToken == blockchain.ETHAddr && s.SrcToken != blockchain.ETHAddr:
+			l = leg{token: s.SrcToken}
+			l.tokenAmount, l.ethAmount = weiToFloat(s.SrcAmount), weiToFloat(s.DstAmount)
+		default:
+			continue
+		}
+		key := leg{token: l.token, buy: l.buy}
+		i, ok := indexes[key]
+		if !ok {
+			indexes[key] = len(result)
+			result = append(result, l)
+			continue
+		}
+		result[i].tokenAmount += l.tokenAmount
+		result[i].ethAmount += l.ethAmount
+	}
+	var valid []leg
+	for _, l := range result {
+		if l.tokenAmount > 0 && l.ethAmount > 0 {
+			valid = append(valid, l)
+		}
+	}
+	return valid
+}
+
+func findLeg(legs []leg, token ethereum.Address, buy bool) (leg, bool) {
+	for _, l := range legs {
+		if l.token == token && l.buy == buy {
+			return l, true
+		}
+	}
+	return leg{}, false
+}
+
+// profit returns the estimated profit in wei of the attacker from the token amount traded in both
+// the front run and the back run.
+func profit(front, back leg) float64 {
+	matched := math.Min(front.tokenAmount, back.tokenAmount)
+	if front.buy {
+		return matched * (back.price() - front.price())
+	}
+	return matched * (front.price() - back.price())
+}
+
+type sandwichKey struct {
+	front, back int
+	token       ethereum.Address
+	buy         bool
+}
+
+type victim struct {
+	index int
+	leg   leg
+}
+
+// sandwichOf looks for the nearest front run before and back run after the trade at index i on the
+// given leg. A front run is a trade of another sender in the same direction at a better price than the
+// victim, a back run is a trade of the same sender as the front run in the opposite direction,
+// which makes a profit.
+func sandwichOf(trades []common.TradelogV4, legs [][]leg, i int, vl leg) (int, int, bool) {
+	var v = trades[i]
+	for f := i - 1; f >= 0; f-- {
+		attacker := trades[f].TxDetail.TxSender
+		if blockchain.IsZeroAddress(attacker) || attacker == v.TxDetail.TxSender {
+			continue
+		}
+		fl, ok := findLeg(legs[f], vl.token, vl.buy)
+		if !ok {
+			continue
+		}
+		if (vl.buy && vl.price() <= fl.price()) || (!vl.buy && vl.price() >= fl.price()) {
+			continue
+		}
+		for b := i + 1; b < len(trades); b++ {
+			if trades[b].TxDetail.TxSender != attacker || trades[b].TransactionHash == trades[f].TransactionHash {
+				continue
+			}
+			bl, ok := findLeg(legs[b], vl.token, !vl.buy)
+			if !ok || profit(fl, bl) <= 0 {
+				continue
+			}
+			return f, b, true
+		}
+	}
+	return 0, 0, false
+}
+
+// findSandwichesInBlock finds sandwiched trades of a block, trades are ordered by log index. The
+// profit of a sandwich is shared by its victims by their traded token amount.
+func findSandwichesInBlock(trades []common.TradelogV4) []common.Sandwich {
+	var (
+		legs    = make([][]leg, len(trades))
+		keys    []sandwichKey
+		victims = make(map[sandwichKey][]victim)
+		result  []common.Sandwich
+	)
+	for i, trade := range trades {
+		legs[i] = legsOf(trade)
+	}
+	for i := range trades {
+		for _, vl := range legs[i] {
+			front, back, ok := sandwichOf(trades, legs, i, vl)
+			if !ok {
+				continue
+			}
+			key := sandwichKey{front: front, back: back, token: vl.token, buy: vl.buy}
+			if _, exist := victims[key]; !exist {
+				keys = append(keys, key)
+			}
+			victims[key] = append(victims[key], victim{index: i, leg: vl})
+		}
+	}
+	for _, key := range keys {
+		var (
+			front, _    = findLeg(legs[key.front], key.token, key.buy)
+			back, _     = findLeg(legs[key.back], key.token, !key.buy)
+			extracted   = profit(front, back)
+			totalAmount float64
+		)
+		for _, v := range victims[key] {
+			totalAmount += v.leg.tokenAmount
+		}
+		for _, v := range victims[key] {
+			trade := trades[v.index]
+			extractedETH := extracted * v.leg.tokenAmount / totalAmount / math.Pow10(18)
+			result = append(result, common.Sandwich{
+				Timestamp:      timeutil.TimeToTimestampMs(trade.Timestamp),
+				BlockNumber:    trade.BlockNumber,
+				TxHash:         trade.TransactionHash,
+				TradeIndex:     trade.Index,
+				Wallet:         trade.WalletAddress,
+				Token:          key.token,
+				Attacker:       trades[key.front].TxDetail.TxSender,
+				FrontRunTxHash: trades[key.front].TransactionHash,
+				BackRunTxHash:  trades[key.back].TransactionHash,
+				ExtractedETH:   extractedETH,
+				ExtractedUSD:   extractedETH * trade.ETHUSDRate,
+			})
+		}
+	}
+	return result
+}
+
+// FindSandwiches returns trades probably sandwiched by a front run and a back run trade of one sender
+// on the same token in the same block, ordered by block number and log index.
+func FindSandwiches(trades []common.TradelogV4) []common.Sandwich {
+	var (
+		blocks  []uint64
+		byBlock = make(map[uint64][]common.TradelogV4)
+		result  []common.Sandwich
+	)
+	for _, trade := range trades {
+		if _, ok := byBlock[trade.BlockNumber]; !ok {
+			blocks = append(blocks, trade.BlockNumber)
+		}
+		byBlock[trade.BlockNumber] = append(byBlock[trade.BlockNumber], trade)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+	for _, block := range blocks {
+		blockTrades := byBlock[block]
+		sort.SliceStable(blockTrades, func(i, j int) bool { return blockTrades[i].Index < blockTrades[j].Index })
+		sandwiches := findSandwichesInBlock(blockTrades)
+		sort.SliceStable(sandwiches, func(i, j int) bool { return sandwiches[i].TradeIndex < sandwiches[j].TradeIndex })
+		result = append(result, sandwiches...)
+	}
+	return result
+}
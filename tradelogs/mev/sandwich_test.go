@@ -0,0 +1,168 @@
+package mev
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+var (
+	knc      = ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200")
+	attacker = ethereum.HexToAddress("0x0000000000007f150bd6f54c40a34d7c3d5e9f56")
+	user     = ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b")
+	wallet   = ethereum.HexToAddress("0xf1aa99c69715f423086008eb9d06dc1e35cc504d")
+)
+
+func ethToWei(amount float64) *big.Int {
+	result, _ := new(big.Float).Mul(big.NewFloat(amount), big.NewFloat(1e18)).Int(nil)
+	return result
+}
+
+// newTrade returns a trade of a single split, buying KNC with ETH if buy is true or selling KNC otherwise.
+func newTrade(block uint64, index uint, txHash byte, sender ethereum.Address, buy bool, kncAmount, ethAmount float64) common.TradelogV4 {
+	split := common.TradeSplit{
+		SrcToken:  knc,
+		DstToken:  blockchain.ETHAddr,
+		SrcAmount: ethToWei(kncAmount),
+		DstAmount: ethToWei(ethAmount),
+	}
+	if buy {
+		split = common.TradeSplit{
+			SrcToken:  blockchain.ETHAddr,
+			DstToken:  knc,
+			SrcAmount: ethToWei(ethAmount),
+			DstAmount: ethToWei(kncAmount),
+		}
+	}
+	return common.TradelogV4{
+		Timestamp:       time.Date(2020, 7, 10, 9, 30, 0, 0, time.UTC),
+		BlockNumber:     block,
+		TransactionHash: ethereum.Hash{txHash},
+		Index:           index,
+		WalletAddress:   wallet,
+		ETHUSDRate:      200,
+		TxDetail:        common.TxDetail{TxSender: sender},
+		Split:           []common.TradeSplit{split},
+	}
+}
+
+func TestFindSandwiches(t *testing.T) {
+	var tests = []struct {
+		name     string
+		trades   []common.TradelogV4
+		expected []common.Sandwich
+	}{
+		{
+			name: "buy sandwich",
+			trades: []common.TradelogV4{
+				newTrade(100, 3, 0x03, attacker, false, 1000, 10.5),
+				newTrade(100, 1, 0x01, attacker, true, 1000, 10),
+				newTrade(100, 2, 0x02, user, true, 450, 5),
+			},
+			expected: []common.Sandwich{
+				{
+					Timestamp:      1594373400000,
+					BlockNumber:    100,
+					TxHash:         ethereum.Hash{0x02},
+					TradeIndex:     2,
+					Wallet:         wallet,
+					Token:          knc,
+					Attacker:       attacker,
+					FrontRunTxHash: ethereum.Hash{0x01},
+					BackRunTxHash:  ethereum.Hash{0x03},
+					ExtractedETH:   0.5,
+					ExtractedUSD:   100,
+				},
+			},
+		},
+		{
+			name: "sell sandwich shared by victims",
+			trades: []common.TradelogV4{
+				newTrade(100, 1, 0x01, attacker, false, 1000, 10),
+				newTrade(100, 2, 0x02, user, false, 300, 2.7),
+				newTrade(100, 3, 0x03, wallet, false, 100, 0.9),
+				newTrade(100, 4, 0x04, attacker, true, 1000, 9),
+			},
+			expected: []common.Sandwich{
+				{
+					Timestamp:      1594373400000,
+					BlockNumber:    100,
+					TxHash:         ethereum.Hash{0x02},
+					TradeIndex:     2,
+					Wallet:         wallet,
+					Token:          knc,
+					Attacker:       attacker,
+					FrontRunTxHash: ethereum.Hash{0x01},
+					BackRunTxHash:  ethereum.Hash{0x04},
+					ExtractedETH:   0.75,
+					ExtractedUSD:   150,
+				},
+				{
+					Timestamp:      1594373400000,
+					BlockNumber:    100,
+					TxHash:         ethereum.Hash{0x03},
+					TradeIndex:     3,
+					Wallet:         wallet,
+					Token:          knc,
+					Attacker:       attacker,
+					FrontRunTxHash: ethereum.Hash{0x01},
+					BackRunTxHash:  ethereum.Hash{0x04},
+					ExtractedETH:   0.25,
+					ExtractedUSD:   50,
+				},
+			},
+		},
+		{
+			name: "back run by another sender",
+			trades: []common.TradelogV4{
+				newTrade(100, 1, 0x01, attacker, true, 1000, 10),
+				newTrade(100, 2, 0x02, user, true, 450, 5),
+				newTrade(100, 3, 0x03, wallet, false, 1000, 10.5),
+			},
+		},
+		{
+			name: "victim price is not worse than front run",
+			trades: []common.TradelogV4{
+				newTrade(100, 1, 0x01, attacker, true, 1000, 10),
+				newTrade(100, 2, 0x02, user, true, 500, 5),
+				newTrade(100, 3, 0x03, attacker, false, 1000, 10.5),
+			},
+		},
+		{
+			name: "back run makes no profit",
+			trades: []common.TradelogV4{
+				newTrade(100, 1, 0x01, attacker, true, 1000, 10),
+				newTrade(100, 2, 0x02, user, true, 450, 5),
+				newTrade(100, 3, 0x03, attacker, false, 1000, 9.5),
+			},
+		},
+		{
+			name: "trades in different blocks",
+			trades: []common.TradelogV4{
+				newTrade(100, 1, 0x01, attacker, true, 1000, 10),
+				newTrade(100, 2, 0x02, user, true, 450, 5),
+				newTrade(101, 1, 0x03, attacker, false, 1000, 10.5),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := FindSandwiches(tc.trades)
+			require.Len(t, result, len(tc.expected))
+			for i, expected := range tc.expected {
+				assert.InDelta(t, expected.ExtractedETH, result[i].ExtractedETH, 1e-9)
+				assert.InDelta(t, expected.ExtractedUSD, result[i].ExtractedUSD, 1e-6)
+				result[i].ExtractedETH, result[i].ExtractedUSD = expected.ExtractedETH, expected.ExtractedUSD
+				assert.Equal(t, expected, result[i])
+			}
+		})
+	}
+}
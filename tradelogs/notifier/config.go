@@ -0,0 +1,146 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/urfave/cli"
+	"go.uber.org/zap"
+)
+
+const (
+	configFileFlag  = "notifier-config-file"
+	maxAttemptsFlag = "notifier-max-attempts"
+	lookbackFlag    = "notifier-lookback"
+
+	defaultMaxAttempts = 5
+	defaultLookback    = time.Hour
+	defaultHTTPTimeout = 10 * time.Second
+)
+
+// SinkType is the kind of destination of a sink.
+type SinkType string
+
+const (
+	// SinkTypeWebhook posts the payload as JSON to an url, optionally signed with HMAC-SHA256.
+	SinkTypeWebhook SinkType = "webhook"
+	// SinkTypeSlack posts a message to a Slack compatible incoming webhook.
+	SinkTypeSlack SinkType = "slack"
+	// SinkTypeTelegram sends a message to a chat of a Telegram compatible bot API.
+	SinkTypeTelegram SinkType = "telegram"
+	// SinkTypeFile appends the payload as a JSON line to a local file.
+	SinkTypeFile SinkType = "file"
+)
+
+// SinkConfig is the configuration of a sink in the notifier config file.
+type SinkConfig struct {
+	Name     string   `json:"name"`
+	Type     SinkType `json:"type"`
+	URL      string   `json:"url"`
+	Secret   string   `json:"secret"`
+	BotToken string   `json:"bot_token"`
+	ChatID   string   `json:"chat_id"`
+	Path     string   `json:"path"`
+	Rule     Rule     `json:"rule"`
+}
+
+// NewSinks returns sinks of the configs. Names of sinks must be unique as the delivery state is kept
+// by sink name.
+func NewSinks(client *http.Client, configs []SinkConfig) ([]NamedSink, error) {
+	var (
+		sinks []NamedSink
+		names = make(map[string]struct{})
+	)
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return nil, errors.New("sink name is required")
+		}
+		if _, ok := names[cfg.Name]; ok {
+			return nil, fmt.Errorf("duplicated sink name: %s", cfg.Name)
+		}
+		names[cfg.Name] = struct{}{}
+
+		var sink Sink
+		switch cfg.Type {
+		case SinkTypeWebhook:
+			if cfg.URL == "" {
+				return nil, fmt.Errorf("url is required for webhook sink %s", cfg.Name)
+			}
+			sink = NewWebhookSink(client, cfg.URL, cfg.Secret)
+		case SinkTypeSlack:
+			if cfg.URL == "" {
+				return nil, fmt.Errorf("url is required for slack sink %s", cfg.Name)
+			}
+			sink = NewSlackSink(client, cfg.URL)
+		case SinkTypeTelegram:
+			if cfg.BotToken == "" || cfg.ChatID == "" {
+				return nil, fmt.Errorf("bot token and chat id are required for telegram sink %s", cfg.Name)
+			}
+			sink = NewTelegramSink(client, cfg.URL, cfg.BotToken, cfg.ChatID)
+		case SinkTypeFile:
+			if cfg.Path == "" {
+				return nil, fmt.Errorf("path is required for file sink %s", cfg.Name)
+			}
+			sink = NewFileSink(cfg.Path)
+		default:
+			return nil, fmt.Errorf("sink type not supported: %s", cfg.Type)
+		}
+		sinks = append(sinks, NamedSink{Name: cfg.Name, Rule: cfg.Rule, Sink: sink})
+	}
+	return sinks, nil
+}
+
+// NewCliFlags returns cli flags to configure the notifier.
+func NewCliFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:   configFileFlag,
+			Usage:  "path to the JSON file of sinks big trades are sent to",
+			EnvVar: "NOTIFIER_CONFIG_FILE",
+		},
+		cli.Uint64Flag{
+			Name:   maxAttemptsFlag,
+			Usage:  "the number of times delivery of a big trade to a sink is attempted",
+			EnvVar: "NOTIFIER_MAX_ATTEMPTS",
+			Value:  defaultMaxAttempts,
+		},
+		cli.DurationFlag{
+			Name:   lookbackFlag,
+			Usage:  "only big trades in this duration are sent",
+			EnvVar: "NOTIFIER_LOOKBACK",
+			Value:  defaultLookback,
+		},
+	}
+}
+
+// NewNotifierFromContext returns the notifier configured by cli flags.
+func NewNotifierFromContext(c *cli.Context, sugar *zap.SugaredLogger, st Storage) (*Notifier, error) {
+	var configs []SinkConfig
+	configFile := c.String(configFileFlag)
+	if configFile == "" {
+		return nil, errors.New("notifier config file is required")
+	}
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &configs); err != nil {
+		return nil, err
+	}
+	sinks, err := NewSinks(&http.Client{Timeout: defaultHTTPTimeout}, configs)
+	if err != nil {
+		return nil, err
+	}
+	if len(sinks) == 0 {
+		return nil, errors.New("no sink is configured")
+	}
+	maxAttempts := c.Uint64(maxAttemptsFlag)
+	if maxAttempts == 0 {
+		return nil, errors.New("max attempts must be positive")
+	}
+	return NewNotifier(sugar, st, sinks, maxAttempts, c.Duration(lookbackFlag)), nil
+}
@@ -0,0 +1,146 @@
+package notifier
+
+import (
+	"math/big"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// Storage is the trade logs storage that keeps delivery state of big trades per sink.
+type Storage interface {
+	GetBigTradesToNotify(sink string, since time.Time, maxAttempts uint64) ([]common.BigTradeLog, error)
+	SaveBigTradeNotifications(sink string, notifications []common.BigTradeNotification) error
+}
+
+// Sink delivers big trade notifications to a destination.
+type Sink interface {
+	Send(p Payload) error
+}
+
+// NamedSink is a sink configured with a unique name and the rule big trades have to match to be sent.
+type NamedSink struct {
+	Name string
+	Rule Rule
+	Sink Sink
+}
+
+// Payload is the big trade sent to sinks.
+type Payload struct {
+	TradelogID    uint64           `json:"tradelog_id"`
+	Timestamp     uint64           `json:"timestamp"`
+	TxHash        ethereum.Hash    `json:"tx_hash"`
+	SrcSymbol     string           `json:"src_symbol"`
+	DstSymbol     string           `json:"dst_symbol"`
+	SrcAddress    ethereum.Address `json:"src_addr"`
+	DstAddress    ethereum.Address `json:"dst_addr"`
+	ETHAmount     float64          `json:"eth_amount"`
+	USDAmount     float64          `json:"usd_amount"`
+	WalletAddress ethereum.Address `json:"wallet_addr"`
+	WalletName    string           `json:"wallet_name,omitempty"`
+}
+
+// NewPayload returns the payload of the big trade.
+func NewPayload(trade common.BigTradeLog) Payload {
+	return Payload{
+		TradelogID:    trade.TradelogID,
+		Timestamp:     timeutil.TimeToTimestampMs(trade.Timestamp),
+		TxHash:        trade.TransactionHash,
+		SrcSymbol:     trade.SrcSymbol,
+		DstSymbol:     trade.DestSymbol,
+		SrcAddress:    trade.SrcAddress,
+		DstAddress:    trade.DestAddress,
+		ETHAmount:     weiToETH(trade.OriginalETHAmount),
+		USDAmount:     trade.FiatAmount,
+		WalletAddress: trade.WalletAddress,
+		WalletName:    trade.WalletName,
+	}
+}
+
+func weiToETH(amount *big.Int) float64 {
+	if amount == nil {
+		return 0
+	}
+	result, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), big.NewFloat(1e18)).Float64()
+	return result
+}
+
+// Notifier sends big trades to all configured sinks and records the delivery state of every sink, so
+// a trade is delivered once per sink and failed deliveries are retried until max attempts.
+type Notifier struct {
+	sugar       *zap.SugaredLogger
+	st          Storage
+	sinks       []NamedSink
+	maxAttempts uint64
+	lookback    time.Duration // only big trades in this duration are sent
+}
+
+// NewNotifier returns a new Notifier instance.
+func NewNotifier(sugar *zap.SugaredLogger, st Storage, sinks []NamedSink, maxAttempts uint64,
+	lookback time.Duration) *Notifier {
+	return &Notifier{
+		sugar:       sugar,
+		st:          st,
+		sinks:       sinks,
+		maxAttempts: maxAttempts,
+		lookback:    lookback,
+	}
+}
+
+// Notify sends pending big trades to every sink once. Trades not matching the rule of a sink are
+// recorded as skipped so they are not checked again.
+func (n *Notifier) Notify() error {
+	since := time.Now().Add(-n.lookback)
+	for _, s := range n.sinks {
+		logger := n.sugar.With("func", caller.GetCurrentFunctionName(), "sink", s.Name)
+		trades, err := n.st.GetBigTradesToNotify(s.Name, since, n.maxAttempts)
+		if err != nil {
+			return err
+		}
+		var notifications []common.BigTradeNotification
+		for _, trade := range trades {
+			p := NewPayload(trade)
+			if !s.Rule.Match(p) {
+				notifications = append(notifications, common.BigTradeNotification{
+					TradelogID: trade.TradelogID,
+					Status:     common.NotificationStatusSkipped,
+				})
+				continue
+			}
+			if err = s.Sink.Send(p); err != nil {
+				logger.Warnw("failed to send big trade", "tradelog_id", trade.TradelogID, "error", err)
+				notifications = append(notifications, common.BigTradeNotification{
+					TradelogID: trade.TradelogID,
+					Status:     common.NotificationStatusFailed,
+					Error:      err.Error(),
+				})
+				continue
+			}
+			notifications = append(notifications, common.BigTradeNotification{
+				TradelogID: trade.TradelogID,
+				Status:     common.NotificationStatusDelivered,
+			})
+		}
+		if err = n.st.SaveBigTradeNotifications(s.Name, notifications); err != nil {
+			return err
+		}
+		logger.Infow("notified big trades", "trades", len(trades))
+	}
+	return nil
+}
+
+// Run sends pending big trades to sinks every interval, it never returns.
+func (n *Notifier) Run(interval time.Duration) {
+	logger := n.sugar.With("func", caller.GetCurrentFunctionName())
+	for {
+		if err := n.Notify(); err != nil {
+			logger.Errorw("failed to notify big trades", "error", err)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+type notification struct {
+	status   common.NotificationStatus
+	attempts uint64
+}
+
+// mockStorage keeps delivery state of big trades per sink the same way as the postgres storage.
+type mockStorage struct {
+	trades        []common.BigTradeLog
+	notifications map[string]map[uint64]*notification
+}
+
+func (s *mockStorage) GetBigTradesToNotify(sink string, since time.Time, maxAttempts uint64) ([]common.BigTradeLog, error) {
+	var result []common.BigTradeLog
+	for _, trade := range s.trades {
+		if trade.Timestamp.Before(since) {
+			continue
+		}
+		n, ok := s.notifications[sink][trade.TradelogID]
+		if !ok || (n.status == common.NotificationStatusFailed && n.attempts < maxAttempts) {
+			result = append(result, trade)
+		}
+	}
+	return result, nil
+}
+
+func (s *mockStorage) SaveBigTradeNotifications(sink string, notifications []common.BigTradeNotification) error {
+	if _, ok := s.notifications[sink]; !ok {
+		s.notifications[sink] = make(map[uint64]*notification)
+	}
+	for _, n := range notifications {
+		saved, ok := s.notifications[sink][n.TradelogID]
+		if !ok {
+			saved = &notification{}
+			s.notifications[sink][n.TradelogID] = saved
+		}
+		saved.status = n.Status
+		if n.Status != common.NotificationStatusSkipped {
+			saved.attempts++
+		}
+	}
+	return nil
+}
+
+type mockSink struct {
+	fail bool
+	sent []uint64
+}
+
+func (s *mockSink) Send(p Payload) error {
+	if s.fail {
+		return errors.New("sink is down")
+	}
+	s.sent = append(s.sent, p.TradelogID)
+	return nil
+}
+
+func TestRuleMatch(t *testing.T) {
+	var (
+		knc    = ethereum.HexToAddress("0xdd974D5C2e2928deA5F71b9825b8b646686BD200")
+		wallet = ethereum.HexToAddress("0xf1AA99C69715F423086008eB9D06Dc1E35Cc504d")
+		p      = Payload{
+			ETHAmount:     150,
+			USDAmount:     36000,
+			SrcAddress:    knc,
+			DstAddress:    blockchain.ETHAddr,
+			WalletAddress: wallet,
+		}
+	)
+	assert.True(t, Rule{}.Match(p))
+	assert.True(t, Rule{MinETHAmount: 100, MinUSDAmount: 30000}.Match(p))
+	assert.False(t, Rule{MinETHAmount: 200}.Match(p))
+	assert.False(t, Rule{MinUSDAmount: 50000}.Match(p))
+	assert.True(t, Rule{Tokens: []ethereum.Address{blockchain.ETHAddr}}.Match(p))
+	assert.False(t, Rule{Tokens: []ethereum.Address{blockchain.ETHAddr}}.Match(Payload{SrcAddress: knc}))
+	assert.True(t, Rule{Wallets: []ethereum.Address{wallet}}.Match(p))
+	assert.False(t, Rule{Wallets: []ethereum.Address{knc}}.Match(p))
+}
+
+func TestNotifier(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	sugar := logger.Sugar()
+
+	now := time.Now()
+	st := &mockStorage{
+		trades: []common.BigTradeLog{
+			{TradelogID: 1, Timestamp: now.Add(-2 * time.Hour), OriginalETHAmount: blockchain.EthToWei(1000)},
+			{TradelogID: 2, Timestamp: now.Add(-time.Minute), OriginalETHAmount: blockchain.EthToWei(150), FiatAmount: 36000},
+			{TradelogID: 3, Timestamp: now, OriginalETHAmount: blockchain.EthToWei(600)},
+		},
+		notifications: make(map[string]map[uint64]*notification),
+	}
+	var (
+		all   = &mockSink{}
+		whale = &mockSink{}
+		down  = &mockSink{fail: true}
+	)
+	n := NewNotifier(sugar, st, []NamedSink{
+		{Name: "all", Sink: all},
+		{Name: "whale", Rule: Rule{MinETHAmount: 500}, Sink: whale},
+		{Name: "down", Sink: down},
+	}, 2, time.Hour)
+
+	require.NoError(t, n.Notify())
+	// trades older than lookback are not sent
+	assert.Equal(t, []uint64{2, 3}, all.sent)
+	assert.Equal(t, []uint64{3}, whale.sent)
+	assert.Equal(t, common.NotificationStatusSkipped, st.notifications["whale"][2].status)
+	assert.Equal(t, common.NotificationStatusFailed, st.notifications["down"][2].status)
+
+	// delivered and skipped trades are not sent again, failed ones are retried until max attempts
+	down.fail = false
+	require.NoError(t, n.Notify())
+	assert.Equal(t, []uint64{2, 3}, all.sent)
+	assert.Equal(t, []uint64{3}, whale.sent)
+	assert.Equal(t, []uint64{2, 3}, down.sent)
+	assert.Equal(t, uint64(2), st.notifications["down"][3].attempts)
+	assert.Equal(t, common.NotificationStatusDelivered, st.notifications["down"][3].status)
+
+	st.trades = append(st.trades, common.BigTradeLog{TradelogID: 4, Timestamp: now})
+	down.fail = true
+	require.NoError(t, n.Notify())
+	require.NoError(t, n.Notify())
+	require.NoError(t, n.Notify())
+	assert.Equal(t, uint64(2), st.notifications["down"][4].attempts)
+	assert.Equal(t, []uint64{2, 3, 4}, all.sent)
+}
@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	ethereum "github.com/ethereum/go-ethereum/common"
+)
+
+// Rule is the condition big trades have to match to be sent to a sink. Zero thresholds and empty
+// lists are not checked.
+type Rule struct {
+	MinETHAmount float64            `json:"min_eth_amount"`
+	MinUSDAmount float64            `json:"min_usd_amount"`
+	Tokens       []ethereum.Address `json:"tokens"`  // either source or destination token is in the list
+	Wallets      []ethereum.Address `json:"wallets"` // the wallet of the trade is in the list
+}
+
+// Match returns true if the big trade matches the rule.
+func (r Rule) Match(p Payload) bool {
+	if p.ETHAmount < r.MinETHAmount || p.USDAmount < r.MinUSDAmount {
+		return false
+	}
+	if len(r.Tokens) != 0 && !containsAddress(r.Tokens, p.SrcAddress) && !containsAddress(r.Tokens, p.DstAddress) {
+		return false
+	}
+	if len(r.Wallets) != 0 && !containsAddress(r.Wallets, p.WalletAddress) {
+		return false
+	}
+	return true
+}
+
+func containsAddress(addresses []ethereum.Address, address ethereum.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+// defaultTelegramURL is the base url of the Telegram bot API.
+const defaultTelegramURL = "https://api.telegram.org"
+
+// message returns the human readable text of the big trade sent to chat sinks.
+func message(p Payload) string {
+	wallet := p.WalletName
+	if wallet == "" {
+		wallet = p.WalletAddress.Hex()
+	}
+	return fmt.Sprintf("Big trade: %.2f ETH ($%.2f) %s → %s via %s, tx %s",
+		p.ETHAmount, p.USDAmount, p.SrcSymbol, p.DstSymbol, wallet, p.TxHash.Hex())
+}
+
+// WebhookSink posts the payload as JSON to an url, see httputil.PostSignedJSON for the signature.
+type WebhookSink struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+// NewWebhookSink returns a new WebhookSink instance.
+func NewWebhookSink(client *http.Client, url, secret string) *WebhookSink {
+	return &WebhookSink{client: client, url: url, secret: secret}
+}
+
+// Send posts the payload to the webhook.
+func (s *WebhookSink) Send(p Payload) error {
+	_, err := httputil.PostSignedJSON(s.client, s.url, s.secret, p)
+	return err
+}
+
+// SlackSink posts the big trade message to a Slack compatible incoming webhook.
+type SlackSink struct {
+	client *http.Client
+	url    string
+}
+
+// NewSlackSink returns a new SlackSink instance.
+func NewSlackSink(client *http.Client, url string) *SlackSink {
+	return &SlackSink{client: client, url: url}
+}
+
+// Send posts the big trade message to the Slack webhook.
+func (s *SlackSink) Send(p Payload) error {
+	_, err := httputil.PostJSON(s.client, s.url, struct {
+		Text string `json:"text"`
+	}{Text: message(p)}, nil)
+	return err
+}
+
+// TelegramSink sends the big trade message to a chat with the sendMessage method of a Telegram
+// compatible bot API.
+type TelegramSink struct {
+	client   *http.Client
+	baseURL  string
+	botToken string
+	chatID   string
+}
+
+// NewTelegramSink returns a new TelegramSink instance, baseURL defaults to the Telegram bot API.
+func NewTelegramSink(client *http.Client, baseURL, botToken, chatID string) *TelegramSink {
+	if baseURL == "" {
+		baseURL = defaultTelegramURL
+	}
+	return &TelegramSink{
+		client:   client,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		botToken: botToken,
+		chatID:   chatID,
+	}
+}
+
+// Send sends the big trade message to the chat.
+func (s *TelegramSink) Send(p Payload) error {
+	var rsp struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	url := fmt.Sprintf("%s/bot%s/sendMessage", s.baseURL, s.botToken)
+	body, err := httputil.PostJSON(s.client, url, struct {
+		ChatID string `json:"chat_id"`
+		Text   string `json:"text"`
+	}{ChatID: s.chatID, Text: message(p)}, nil)
+	if err != nil {
+		// errors of the http client contain the url, do not leak the bot token to logs and storage
+		return errors.New(strings.Replace(err.Error(), s.botToken, "<bot_token>", -1))
+	}
+	if err = json.Unmarshal(body, &rsp); err != nil {
+		return err
+	}
+	if !rsp.OK {
+		return fmt.Errorf("failed to send telegram message: %s", rsp.Description)
+	}
+	return nil
+}
+
+// FileSink appends the payload as a JSON line to a local file.
+type FileSink struct {
+	path string
+}
+
+// NewFileSink returns a new FileSink instance.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Send appends the payload to the file.
+func (s *FileSink) Send(p Payload) (err error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cErr := f.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
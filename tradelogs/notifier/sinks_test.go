@@ -0,0 +1,185 @@
+package notifier
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/httputil"
+)
+
+var testPayload = Payload{
+	TradelogID:    1,
+	Timestamp:     1594373400000,
+	TxHash:        ethereum.HexToHash("0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff01"),
+	SrcSymbol:     "KNC",
+	DstSymbol:     "ETH",
+	SrcAddress:    ethereum.HexToAddress("0xdd974D5C2e2928deA5F71b9825b8b646686BD200"),
+	DstAddress:    ethereum.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE"),
+	ETHAmount:     150,
+	USDAmount:     36000,
+	WalletAddress: ethereum.HexToAddress("0xf1AA99C69715F423086008eB9D06Dc1E35Cc504d"),
+	WalletName:    "Kyber Swap",
+}
+
+func TestWebhookSink(t *testing.T) {
+	const secret = "secret"
+	var (
+		body      []byte
+		signature string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err = ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		signature = r.Header.Get(httputil.WebhookSignatureHeader)
+	}))
+	defer ts.Close()
+
+	require.NoError(t, NewWebhookSink(ts.Client(), ts.URL, secret).Send(testPayload))
+	var received Payload
+	require.NoError(t, json.Unmarshal(body, &received))
+	assert.Equal(t, testPayload, received)
+	// the signature is verifiable by the receiver with the shared secret
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+	assert.Len(t, strings.TrimPrefix(signature, "sha256="), 64)
+
+	require.NoError(t, NewWebhookSink(ts.Client(), ts.URL, "").Send(testPayload))
+	assert.Empty(t, signature)
+}
+
+func TestWebhookSinkFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	assert.Error(t, NewWebhookSink(ts.Client(), ts.URL, "").Send(testPayload))
+}
+
+func TestSlackSink(t *testing.T) {
+	var received struct {
+		Text string `json:"text"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	require.NoError(t, NewSlackSink(ts.Client(), ts.URL).Send(testPayload))
+	assert.Equal(t, message(testPayload), received.Text)
+	assert.Contains(t, received.Text, "150.00 ETH")
+	assert.Contains(t, received.Text, "Kyber Swap")
+	assert.Contains(t, received.Text, testPayload.TxHash.Hex())
+}
+
+func TestTelegramSink(t *testing.T) {
+	const (
+		botToken = "123:token"
+		chatID   = "-100123"
+	)
+	var (
+		path     string
+		received struct {
+			ChatID string `json:"chat_id"`
+			Text   string `json:"text"`
+		}
+		ok = true
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		if ok {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+	}))
+	defer ts.Close()
+
+	sink := NewTelegramSink(ts.Client(), ts.URL+"/", botToken, chatID)
+	require.NoError(t, sink.Send(testPayload))
+	assert.Equal(t, "/bot"+botToken+"/sendMessage", path)
+	assert.Equal(t, chatID, received.ChatID)
+	assert.Equal(t, message(testPayload), received.Text)
+
+	ok = false
+	err := sink.Send(testPayload)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "chat not found")
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notifier")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	path := filepath.Join(dir, "big_trades.json")
+	sink := NewFileSink(path)
+	second := testPayload
+	second.TradelogID = 2
+	require.NoError(t, sink.Send(testPayload))
+	require.NoError(t, sink.Send(second))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, f.Close())
+	}()
+	var lines []Payload
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var p Payload
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &p))
+		lines = append(lines, p)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []Payload{testPayload, second}, lines)
+}
+
+func TestNewSinks(t *testing.T) {
+	sinks, err := NewSinks(http.DefaultClient, []SinkConfig{
+		{Name: "partner", Type: SinkTypeWebhook, URL: "http://localhost/hook", Secret: "secret"},
+		{Name: "ops", Type: SinkTypeSlack, URL: "http://localhost/slack",
+			Rule: Rule{MinETHAmount: 500}},
+		{Name: "community", Type: SinkTypeTelegram, BotToken: "123:token", ChatID: "1"},
+		{Name: "archive", Type: SinkTypeFile, Path: "/tmp/big_trades.json"},
+	})
+	require.NoError(t, err)
+	require.Len(t, sinks, 4)
+	assert.IsType(t, &WebhookSink{}, sinks[0].Sink)
+	assert.InDelta(t, 500, sinks[1].Rule.MinETHAmount, 1e-9)
+	assert.Equal(t, defaultTelegramURL, sinks[2].Sink.(*TelegramSink).baseURL)
+	assert.IsType(t, &FileSink{}, sinks[3].Sink)
+
+	for _, configs := range [][]SinkConfig{
+		{{Name: "a", Type: SinkTypeFile, Path: "a"}, {Name: "a", Type: SinkTypeFile, Path: "b"}},
+		{{Type: SinkTypeFile, Path: "a"}},
+		{{Name: "a", Type: SinkTypeWebhook}},
+		{{Name: "a", Type: SinkTypeTelegram, BotToken: "123:token"}},
+		{{Name: "a", Type: "twitter"}},
+	} {
+		_, err = NewSinks(http.DefaultClient, configs)
+		assert.Error(t, err)
+	}
+}
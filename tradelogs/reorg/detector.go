@@ -0,0 +1,107 @@
+package reorg
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	ether "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// HeaderReader reads block headers of the canonical chain.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Storage is the trade logs storage that keeps hashes of crawled blocks.
+type Storage interface {
+	GetBlockHashes(fromBlock uint64) ([]common.BlockInfo, error)
+	DeleteTradeLogsFromBlock(fromBlock uint64) error
+}
+
+// Detector compares hashes of crawled blocks with the canonical chain
+// and rolls back trade logs of orphaned blocks.
+type Detector struct {
+	sugar  *zap.SugaredLogger
+	client HeaderReader
+	st     Storage
+	depth  uint64 // number of blocks behind last crawled block to verify
+}
+
+// NewDetector returns a new Detector instance.
+func NewDetector(sugar *zap.SugaredLogger, client HeaderReader, st Storage, depth uint64) *Detector {
+	return &Detector{
+		sugar:  sugar,
+		client: client,
+		st:     st,
+		depth:  depth,
+	}
+}
+
+func (d *Detector) canonicalHeader(number uint64) (*types.Header, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	header, err := d.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err == ether.NotFound {
+		return nil, nil
+	}
+	return header, err
+}
+
+// Check verifies recorded hashes of blocks in range [lastBlock - depth, lastBlock] against the canonical chain.
+// When a mismatch is found, all trade logs from the block after the last verified one are deleted
+// and that block is returned, so the caller can crawl it again.
+func (d *Detector) Check(lastBlock uint64) (uint64, bool, error) {
+	var (
+		fromBlock uint64
+		forkBlock uint64
+	)
+	if lastBlock > d.depth {
+		fromBlock = lastBlock - d.depth
+	}
+	logger := d.sugar.With(
+		"func", caller.GetCurrentFunctionName(),
+		"from_block", fromBlock,
+		"last_block", lastBlock,
+	)
+
+	blocks, err := d.st.GetBlockHashes(fromBlock)
+	if err != nil {
+		return 0, false, err
+	}
+
+	forkBlock = fromBlock
+	for _, b := range blocks {
+		header, err := d.canonicalHeader(b.Number)
+		if err != nil {
+			return 0, false, err
+		}
+		if header != nil && header.Hash() == b.Hash {
+			forkBlock = b.Number + 1
+			continue
+		}
+
+		logger.Warnw("chain reorganization detected",
+			"block_number", b.Number,
+			"recorded_hash", b.Hash.Hex(),
+			"fork_block", forkBlock)
+		if forkBlock == fromBlock {
+			logger.Warnw("no verified block in check range, reorganization might be deeper than check depth",
+				"depth", d.depth)
+		}
+		if err = d.st.DeleteTradeLogsFromBlock(forkBlock); err != nil {
+			return 0, false, err
+		}
+		return forkBlock, true, nil
+	}
+
+	logger.Debugw("no chain reorganization detected", "verified_blocks", len(blocks))
+	return 0, false, nil
+}
@@ -0,0 +1,108 @@
+package reorg
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/testutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+type mockStorage struct {
+	blocks      []common.BlockInfo
+	deletedFrom *uint64
+}
+
+func (s *mockStorage) GetBlockHashes(fromBlock uint64) ([]common.BlockInfo, error) {
+	var result []common.BlockInfo
+	for _, b := range s.blocks {
+		if b.Number >= fromBlock {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func (s *mockStorage) DeleteTradeLogsFromBlock(fromBlock uint64) error {
+	s.deletedFrom = &fromBlock
+	return nil
+}
+
+// newForkedBackends returns two simulated chains sharing the first commonBlocks blocks,
+// the second chain includes a transaction right after that so all following blocks are different.
+func newForkedBackends(t *testing.T, commonBlocks, totalBlocks int) (*backends.SimulatedBackend, *backends.SimulatedBackend) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	alloc := core.GenesisAlloc{addr: {Balance: big.NewInt(1e18)}}
+
+	original := backends.NewSimulatedBackend(alloc, 8000000)
+	forked := backends.NewSimulatedBackend(alloc, 8000000)
+	for i := 0; i < commonBlocks; i++ {
+		original.Commit()
+		forked.Commit()
+	}
+
+	tx := types.NewTransaction(0, ethereum.HexToAddress("0x1"), big.NewInt(1), 21000, big.NewInt(1), nil)
+	signedTx, err := types.SignTx(tx, types.HomesteadSigner{}, key)
+	require.NoError(t, err)
+	require.NoError(t, forked.SendTransaction(context.Background(), signedTx))
+	for i := commonBlocks; i < totalBlocks; i++ {
+		original.Commit()
+		forked.Commit()
+	}
+	return original, forked
+}
+
+func recordedBlocks(t *testing.T, client HeaderReader, numbers ...uint64) []common.BlockInfo {
+	var result []common.BlockInfo
+	for _, number := range numbers {
+		header, err := client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(number))
+		require.NoError(t, err)
+		result = append(result, common.BlockInfo{Number: number, Hash: header.Hash()})
+	}
+	return result
+}
+
+func TestDetectorCheck(t *testing.T) {
+	sugar := testutil.MustNewDevelopmentSugaredLogger()
+	// blocks 1-3 are the same on both chains, blocks 4-6 are different
+	original, forked := newForkedBackends(t, 3, 6)
+
+	t.Run("no reorganization", func(t *testing.T) {
+		st := &mockStorage{blocks: recordedBlocks(t, original, 2, 4, 6)}
+		forkBlock, reorged, err := NewDetector(sugar, original, st, 10).Check(6)
+		require.NoError(t, err)
+		assert.False(t, reorged)
+		assert.Zero(t, forkBlock)
+		assert.Nil(t, st.deletedFrom)
+	})
+
+	t.Run("reorganization", func(t *testing.T) {
+		st := &mockStorage{blocks: recordedBlocks(t, original, 2, 4, 6)}
+		forkBlock, reorged, err := NewDetector(sugar, forked, st, 10).Check(6)
+		require.NoError(t, err)
+		assert.True(t, reorged)
+		// block 3 is not recorded, rolling back from block after the last verified one
+		assert.Equal(t, uint64(3), forkBlock)
+		require.NotNil(t, st.deletedFrom)
+		assert.Equal(t, uint64(3), *st.deletedFrom)
+	})
+
+	t.Run("reorganization deeper than check depth", func(t *testing.T) {
+		st := &mockStorage{blocks: recordedBlocks(t, original, 2, 4, 5, 6)}
+		forkBlock, reorged, err := NewDetector(sugar, forked, st, 1).Check(6)
+		require.NoError(t, err)
+		assert.True(t, reorged)
+		assert.Equal(t, uint64(5), forkBlock)
+	})
+}
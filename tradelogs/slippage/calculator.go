@@ -0,0 +1,120 @@
+package slippage
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	rateCommon "github.com/KyberNetwork/reserve-stats/reserverates/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// Storage is the trade logs storage that keeps slippage of splits.
+type Storage interface {
+	GetSplitExecutions(fromBlock, toBlock uint64) ([]common.SplitExecution, error)
+	SaveSlippages(fromBlock, toBlock uint64, slippages []common.SplitSlippage) error
+}
+
+// RateStorage is the reserve rates storage that returns rates quoted by reserves.
+type RateStorage interface {
+	GetRatesAtBlocks(queries []rateCommon.RateQuery) (map[rateCommon.RateQuery]rateCommon.ReserveRateEntry, error)
+}
+
+// Calculator computes slippage of executed splits from the rates quoted by reserves at the previous block.
+type Calculator struct {
+	sugar          *zap.SugaredLogger
+	st             Storage
+	rateStorage    RateStorage
+	symbolResolver blockchain.TokenSymbolResolver
+	batchSize      uint64 // number of blocks processed at once
+}
+
+// NewCalculator returns a new Calculator instance.
+func NewCalculator(sugar *zap.SugaredLogger, st Storage, rateStorage RateStorage,
+	symbolResolver blockchain.TokenSymbolResolver, batchSize uint64) *Calculator {
+	return &Calculator{
+		sugar:          sugar,
+		st:             st,
+		rateStorage:    rateStorage,
+		symbolResolver: symbolResolver,
+		batchSize:      batchSize,
+	}
+}
+
+// Run computes slippage of splits of blocks in range [fromBlock, toBlock] in batches. Slippage of each
+// batch replaces the previously computed one, so running again over the same blocks is safe.
+func (calc *Calculator) Run(fromBlock, toBlock uint64) error {
+	logger := calc.sugar.With(
+		"func", caller.GetCurrentFunctionName(),
+		"from_block", fromBlock,
+		"to_block", toBlock,
+	)
+	for start := fromBlock; start <= toBlock; start += calc.batchSize {
+		end := start + calc.batchSize - 1
+		if end > toBlock {
+			end = toBlock
+		}
+		executions, err := calc.st.GetSplitExecutions(start, end)
+		if err != nil {
+			return err
+		}
+		slippages, err := calc.calculate(executions)
+		if err != nil {
+			return err
+		}
+		if err = calc.st.SaveSlippages(start, end, slippages); err != nil {
+			return err
+		}
+		logger.Infow("calculated slippage of splits",
+			"batch_from", start,
+			"batch_to", end,
+			"splits", len(executions),
+			"slippages", len(slippages))
+	}
+	return nil
+}
+
+// calculate returns slippage of executions which have a rate quoted by the reserve at the previous block.
+func (calc *Calculator) calculate(executions []common.SplitExecution) ([]common.SplitSlippage, error) {
+	var (
+		queries   = make([]rateCommon.RateQuery, len(executions))
+		slippages []common.SplitSlippage
+	)
+	for i, e := range executions {
+		symbol, err := calc.symbolResolver.Symbol(e.Token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve symbol of token %s: %s", e.Token.Hex(), err)
+		}
+		queries[i] = rateCommon.RateQuery{
+			Reserve: e.Reserve.Hex(),
+			Pair:    fmt.Sprintf("ETH-%s", symbol),
+			Block:   e.BlockNumber - 1,
+		}
+	}
+	rates, err := calc.rateStorage.GetRatesAtBlocks(queries)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range executions {
+		rate, ok := rates[queries[i]]
+		if !ok {
+			continue
+		}
+		quoted := rate.SellReserveRate
+		if e.Buy {
+			quoted = rate.BuyReserveRate
+		}
+		if quoted == 0 {
+			continue
+		}
+		slippages = append(slippages, common.SplitSlippage{
+			SplitID:      e.SplitID,
+			QuotedRate:   quoted,
+			ExecutedRate: e.ExecutedRate,
+			SlippageBps:  (quoted - e.ExecutedRate) / quoted * 10000,
+		})
+	}
+	return slippages, nil
+}
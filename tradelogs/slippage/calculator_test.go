@@ -0,0 +1,102 @@
+package slippage
+
+import (
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	rateCommon "github.com/KyberNetwork/reserve-stats/reserverates/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+var (
+	reserve = ethereum.HexToAddress("0x63825c174ab367968EC60f061753D3bbD36A0D8F")
+	knc     = ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200")
+)
+
+type mockStorage struct {
+	executions []common.SplitExecution
+	saved      map[uint64][]common.SplitSlippage
+}
+
+func (s *mockStorage) GetSplitExecutions(fromBlock, toBlock uint64) ([]common.SplitExecution, error) {
+	var result []common.SplitExecution
+	for _, e := range s.executions {
+		if e.BlockNumber >= fromBlock && e.BlockNumber <= toBlock {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (s *mockStorage) SaveSlippages(fromBlock, toBlock uint64, slippages []common.SplitSlippage) error {
+	s.saved[fromBlock] = slippages
+	return nil
+}
+
+type mockRateStorage struct {
+	rates map[rateCommon.RateQuery]rateCommon.ReserveRateEntry
+}
+
+func (s *mockRateStorage) GetRatesAtBlocks(queries []rateCommon.RateQuery) (map[rateCommon.RateQuery]rateCommon.ReserveRateEntry, error) {
+	result := make(map[rateCommon.RateQuery]rateCommon.ReserveRateEntry)
+	for _, q := range queries {
+		if rate, ok := s.rates[q]; ok {
+			result[q] = rate
+		}
+	}
+	return result, nil
+}
+
+type mockSymbolResolver struct{}
+
+func (r mockSymbolResolver) Symbol(address ethereum.Address) (string, error) {
+	return "KNC", nil
+}
+
+func TestCalculator(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	require.NoError(t, err)
+	sugar := logger.Sugar()
+
+	st := &mockStorage{
+		executions: []common.SplitExecution{
+			{SplitID: 1, BlockNumber: 100, Reserve: reserve, Token: knc, Buy: true, ExecutedRate: 396, ETHAmount: 1},
+			{SplitID: 2, BlockNumber: 100, Reserve: reserve, Token: knc, Buy: false, ExecutedRate: 0.0025, ETHAmount: 2},
+			// no rate recorded at the previous block
+			{SplitID: 3, BlockNumber: 150, Reserve: reserve, Token: knc, Buy: true, ExecutedRate: 400, ETHAmount: 1},
+			{SplitID: 4, BlockNumber: 200, Reserve: reserve, Token: knc, Buy: true, ExecutedRate: 404, ETHAmount: 1},
+		},
+		saved: make(map[uint64][]common.SplitSlippage),
+	}
+	rateStorage := &mockRateStorage{rates: map[rateCommon.RateQuery]rateCommon.ReserveRateEntry{
+		{Reserve: reserve.Hex(), Pair: "ETH-KNC", Block: 99}: {
+			BuyReserveRate:  400,
+			SellReserveRate: 0.0025,
+		},
+		{Reserve: reserve.Hex(), Pair: "ETH-KNC", Block: 199}: {
+			BuyReserveRate:  400,
+			SellReserveRate: 0.0025,
+		},
+	}}
+
+	calc := NewCalculator(sugar, st, rateStorage, mockSymbolResolver{}, 100)
+	require.NoError(t, calc.Run(100, 250))
+
+	require.Len(t, st.saved, 2)
+	require.Len(t, st.saved[100], 2)
+	assert.Equal(t, uint64(1), st.saved[100][0].SplitID)
+	assert.Equal(t, float64(400), st.saved[100][0].QuotedRate)
+	assert.InDelta(t, 100, st.saved[100][0].SlippageBps, 1e-9)
+	assert.Equal(t, uint64(2), st.saved[100][1].SplitID)
+	assert.Equal(t, 0.0025, st.saved[100][1].QuotedRate)
+	assert.InDelta(t, 0, st.saved[100][1].SlippageBps, 1e-9)
+
+	require.Len(t, st.saved[200], 1)
+	assert.Equal(t, uint64(4), st.saved[200][0].SplitID)
+	// a better fill than the quote is a negative slippage
+	assert.InDelta(t, -100, st.saved[200][0].SlippageBps, 1e-9)
+}
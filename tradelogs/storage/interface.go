@@ -32,30 +32,60 @@ const (
 type Interface interface {
 	LoadTradeLogsByTxHash(tx ethereum.Hash) ([]common.TradelogV4, error)
 	LoadTradeLogs(from, to time.Time) ([]common.TradelogV4, error)
-	GetAggregatedBurnFee(from, to time.Time, freq string, reserveAddrs []ethereum.Address) (map[ethereum.Address]map[string]float64, error)
-	GetAssetVolume(token ethereum.Address, fromTime, toTime time.Time, frequency string) (map[uint64]*common.VolumeStats, error)
-	GetReserveVolume(rsvAddr ethereum.Address, token ethereum.Address, fromTime, toTime time.Time, frequency string) (map[uint64]*common.VolumeStats, error)
+	LoadTradeLogsPage(filter common.TradeLogFilter) ([]common.TradelogV4, error)
+	GetAggregatedBurnFee(from, to time.Time, freq string, reserveAddrs []ethereum.Address, timezone *time.Location) (map[ethereum.Address]map[string]float64, error)
+	GetFeeBreakdown(group common.FeeGroup, from, to time.Time, freq string, timezone *time.Location,
+		addrs []ethereum.Address) (map[ethereum.Address]map[uint64]common.FeeBreakdown, error)
+	GetRebateStatement(wallet ethereum.Address, from, to time.Time) (common.RebateStatement, error)
+	GetCandles(token ethereum.Address, interval string, from, to time.Time) ([]common.Candle, error)
+	SaveSandwiches(fromBlock, toBlock uint64, sandwiches []common.Sandwich) error
+	GetMEVReport(from, to time.Time, wallets []ethereum.Address) (common.MEVReport, error)
+	GetSplitExecutions(fromBlock, toBlock uint64) ([]common.SplitExecution, error)
+	SaveSlippages(fromBlock, toBlock uint64, slippages []common.SplitSlippage) error
+	GetSlippageStats(from, to time.Time, reserves, tokens []ethereum.Address) ([]common.SlippageStats, error)
+	GetReserveMarketShare(group common.MarketShareGroup, from, to time.Time, freq string, timezone *time.Location,
+		tokens []ethereum.Address, reserveTypes []uint64) ([]common.ReserveMarketShare, error)
+	GetUserCohorts(interval common.CohortInterval, group common.CohortGroup, from, to time.Time, timezone *time.Location) ([]common.UserCohort, error)
+	GetAssetVolume(token ethereum.Address, fromTime, toTime time.Time, frequency, provider string, timezone *time.Location) (map[uint64]*common.VolumeStats, error)
+	GetReserveVolume(rsvAddr ethereum.Address, token ethereum.Address, fromTime, toTime time.Time, frequency, provider string, timezone *time.Location) (map[uint64]*common.VolumeStats, error)
 	GetMonthlyVolume(rsvAddr ethereum.Address, from, to time.Time) (map[uint64]*common.VolumeStats, error)
 	GetAggregatedWalletFee(reserveAddr, walletAddr, freq string,
-		fromTime, toTime time.Time, timezone int8) (map[uint64]float64, error)
-	GetTradeSummary(from, to time.Time, timezone int8) (map[uint64]*common.TradeSummary, error)
-	GetUserVolume(userAddr ethereum.Address, from, to time.Time, freq string) (map[uint64]common.UserVolume, error)
+		fromTime, toTime time.Time, timezone *time.Location) (map[uint64]float64, error)
+	GetTradeSummary(from, to time.Time, timezone *time.Location) (map[uint64]*common.TradeSummary, error)
+	GetUserVolume(userAddr ethereum.Address, from, to time.Time, freq string, timezone *time.Location) (map[uint64]common.UserVolume, error)
 	GetUserList(from, to time.Time) ([]common.UserInfo, error)
-	GetWalletStats(fromTime, toTime time.Time, walletAddr string, timezone int8) (map[uint64]common.WalletStats, error)
-	GetCountryStats(countryCode string, from, to time.Time, timezone int8) (map[uint64]*common.CountryStats, error)
-	GetTokenHeatmap(asset ethereum.Address, from, to time.Time, timezone int8) (map[string]common.Heatmap, error)
-	GetIntegrationVolume(fromTime, toTime time.Time) (map[uint64]*common.IntegrationVolume, error)
+	GetWalletStats(fromTime, toTime time.Time, walletAddr string, timezone *time.Location) (map[uint64]common.WalletStats, error)
+	GetCountryStats(countryCode string, from, to time.Time, timezone *time.Location) (map[uint64]*common.CountryStats, error)
+	GetTokenHeatmap(asset ethereum.Address, from, to time.Time, timezone *time.Location) (map[string]common.Heatmap, error)
+	GetIntegrationVolume(fromTime, toTime time.Time, timezone *time.Location) (map[uint64]*common.IntegrationVolume, error)
 	LastBlock() (int64, error)
 	SaveTradeLogs(log *common.CrawlResult) error
+	GetReservesByAddresses(addresses []ethereum.Address) ([]common.Reserve, error)
+	GetBlockHashes(fromBlock uint64) ([]common.BlockInfo, error)
+	DeleteTradeLogsFromBlock(fromBlock uint64) error
 	GetTokenSymbol(address string) (string, error)
 	UpdateTokens(tokenAddresses, symbols []string) error
-	GetStats(from, to time.Time) (common.StatsResponse, error)
-	GetTopTokens(from, to time.Time, limit uint64) (common.TopTokens, error)
-	GetTopIntegrations(from, to time.Time, limit uint64) (common.TopIntegrations, error)
-	GetTopReserves(from, to time.Time, limit uint64) (common.TopReserves, error)
+	GetStats(from, to time.Time, provider string) (common.StatsResponse, error)
+	GetTopTokens(from, to time.Time, limit uint64, provider string) (common.TopTokens, error)
+	GetTopIntegrations(from, to time.Time, limit uint64, provider string) (common.TopIntegrations, error)
+	GetTopReserves(from, to time.Time, limit uint64, provider string) (common.TopReserves, error)
 	GetNotTwittedTrades(from, to time.Time) ([]common.BigTradeLog, error)
 	SaveBigTrades(bigVolume float32, fromBlock uint64) error
 	UpdateBigTradesTwitted(trades []uint64) error
+	GetBigTradesToNotify(sink string, since time.Time, maxAttempts uint64) ([]common.BigTradeLog, error)
+	SaveBigTradeNotifications(sink string, notifications []common.BigTradeNotification) error
+	GetTradesToPrice(fromBlock, toBlock uint64, from, to time.Time) ([]common.TradeFiatPrice, error)
+	SaveFiatPrices(provider string, prices []common.TradeFiatPrice, setDefault bool) error
+	AuditTradeLogs(fromBlock, toBlock, samples uint64) ([]common.AuditCheck, error)
+	GetBlockGaps(fromBlock, toBlock, maxGap uint64) ([]common.BlockRange, error)
+	EnqueueCrawlJobs(ranges []common.BlockRange) error
+	LastQueuedBlock() (uint64, error)
+	LeaseCrawlJob(owner string, lease time.Duration, maxAttempts uint64) (*common.CrawlJob, error)
+	CompleteCrawlJob(id uint64, owner string) error
+	RenewCrawlJob(id uint64, owner string, lease time.Duration) error
+	FailCrawlJob(id uint64, owner, reason string, maxAttempts uint64) error
+	GetCrawlJobs(status common.CrawlJobStatus) ([]common.CrawlJob, error)
+	RetryCrawlJobs(ids []uint64) (int64, error)
 }
 
 // NewCliFlags return dbEngine flag option
@@ -72,19 +102,13 @@ func NewCliFlags() []cli.Flag {
 
 // KNCAddressFromContext return knc address by deployment mode
 func KNCAddressFromContext(c *cli.Context) ethereum.Address {
-	deploymentMode := deployment.MustGetDeploymentFromContext(c)
-	switch deploymentMode {
-	case deployment.Ropsten:
-		return ethereum.HexToAddress("0x4e470dc7321e84ca96fcaedd0c8abcebbaeb68c6")
-	default:
-		return ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200")
-	}
+	return deployment.MustGetChainFromContext(c).KNCToken
 }
 
 // NewStorageInterfaceFromContext return new storage interface
 func NewStorageInterfaceFromContext(sugar *zap.SugaredLogger, c *cli.Context, tokenAmountFormatter blockchain.TokenAmountFormatterInterface) (Interface, error) {
 	dbEngine := c.String(DBEngineFlag)
-	kncAddr := KNCAddressFromContext(c)
+	chain := deployment.MustGetChainFromContext(c)
 	switch dbEngine {
 	// case InfluxDBEngine:
 	// 	influxClient, err := influxdb.NewClientFromContext(c)
@@ -108,7 +132,7 @@ func NewStorageInterfaceFromContext(sugar *zap.SugaredLogger, c *cli.Context, to
 		if err != nil {
 			return nil, err
 		}
-		postgresStorage, err := postgres.NewTradeLogDB(sugar, db, tokenAmountFormatter, kncAddr)
+		postgresStorage, err := postgres.NewTradeLogDB(sugar, db, tokenAmountFormatter, chain)
 		if err != nil {
 			sugar.Errorw("failed to initiate postgres storage", "error", err)
 			return nil, err
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+// splitAmountTolerance is the relative difference allowed between the source amount of a trade and the
+// sum of its splits, as amounts are stored in single precision.
+const splitAmountTolerance = 1e-4
+
+// auditChecks are the invariants of crawled trades by check name, conditions are on the tradelogs table
+// aliased as a, with src and dst as the tokens of the trade.
+var auditChecks = []struct {
+	name      string
+	condition string
+}{
+	{name: "zero_fiat_amount", condition: "COALESCE(a.eth_amount * a.eth_usd_rate, 0) = 0"},
+	{name: "empty_src_symbol", condition: "COALESCE(src.symbol, '') = ''"},
+	{name: "empty_dst_symbol", condition: "COALESCE(dst.symbol, '') = ''"},
+	{name: "split_amount_mismatch", condition: fmt.Sprintf(`ABS(a.src_amount - COALESCE(
+		(SELECT SUM(s.src_amount) FROM split AS s WHERE s.trade_id = a.id AND s.src = src.address), 0))
+		> %g * a.src_amount`, splitAmountTolerance)},
+}
+
+// AuditTradeLogs returns the number of trades in the block range violating each data quality check,
+// with tx hashes of at most samples latest of them. Zero block bounds are not filtered.
+func (tldb *TradeLogDB) AuditTradeLogs(fromBlock, toBlock, samples uint64) ([]common.AuditCheck, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"from_block", fromBlock, "to_block", toBlock)
+		args       = []interface{}{tldb.chainID, samples}
+		conditions = []string{"a.chain_id = $1"}
+		result     []common.AuditCheck
+	)
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if fromBlock != 0 {
+		addCondition("a.block_number >= $%d", fromBlock)
+	}
+	if toBlock != 0 {
+		addCondition("a.block_number <= $%d", toBlock)
+	}
+	for _, check := range auditChecks {
+		var record struct {
+			Count   uint64         `db:"count"`
+			Samples pq.StringArray `db:"samples"`
+		}
+		query := fmt.Sprintf(`SELECT COUNT(*) AS count,
+	COALESCE((ARRAY_AGG(a.tx_hash ORDER BY a.block_number DESC, a.id DESC))[1:$2], '{}') AS samples
+FROM "%[1]s" AS a
+INNER JOIN token AS src ON a.src_address_id = src.id
+INNER JOIN token AS dst ON a.dst_address_id = dst.id
+WHERE %[2]s AND (%[3]s);`,
+			schema.TradeLogsTableName, strings.Join(conditions, " AND "), check.condition)
+		logger.Debugw("prepare statement", "check", check.name, "stmt", query)
+		if err := tldb.db.Get(&record, query, args...); err != nil {
+			logger.Errorw("failed to audit trade logs", "check", check.name, "error", err)
+			return nil, err
+		}
+		result = append(result, common.AuditCheck{
+			Name:    check.name,
+			Count:   record.Count,
+			Samples: record.Samples,
+		})
+	}
+	return result, nil
+}
+
+// GetBlockGaps returns the ranges between crawled blocks in [fromBlock, toBlock] that are longer than
+// maxGap blocks, including the range from fromBlock to the first crawled block. A block is crawled if it
+// has trades or its hash is recorded, the crawler records hash of the last block of every crawled range
+// of at most maxGap blocks, in both polling and streaming modes, so longer gaps are ranges the crawler
+// never saved.
+func (tldb *TradeLogDB) GetBlockGaps(fromBlock, toBlock, maxGap uint64) ([]common.BlockRange, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"from_block", fromBlock, "to_block", toBlock, "max_gap", maxGap)
+		records []struct {
+			FromBlock uint64 `db:"from_block"`
+			ToBlock   uint64 `db:"to_block"`
+		}
+		result = []common.BlockRange{}
+	)
+	query := fmt.Sprintf(`WITH crawled AS (
+	SELECT block_number FROM "%[1]s" WHERE chain_id = $1 AND block_number >= $2 AND block_number <= $3
+	UNION
+	SELECT block_number FROM "%[2]s" WHERE chain_id = $1 AND block_number >= $2 AND block_number <= $3
+)
+SELECT prev + 1 AS from_block, block_number - 1 AS to_block FROM (
+	-- the first crawled block is compared to the block before fromBlock
+	SELECT block_number, LAG(block_number::BIGINT, 1, $2::BIGINT - 1) OVER (ORDER BY block_number) AS prev FROM crawled
+) AS b WHERE block_number - prev > $4
+ORDER BY from_block;`, schema.BlockHashTableName, schema.TradeLogsTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if err := tldb.db.Select(&records, query, tldb.chainID, fromBlock, toBlock, maxGap); err != nil {
+		logger.Errorw("failed to get block gaps", "error", err)
+		return nil, err
+	}
+	for _, r := range records {
+		result = append(result, common.BlockRange{FromBlock: r.FromBlock, ToBlock: r.ToBlock})
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func TestAuditTradeLogs(t *testing.T) {
+	const dbName = "test_audit_trade_logs"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		knc       = ethereum.HexToAddress("0xdd974D5C2e2928deA5F71b9825b8b646686BD200")
+		unknown   = ethereum.HexToAddress("0x4f3AfEC4E5a3F2A6a1A411DEF7D7dFe50eE057bF")
+		timestamp = time.Date(2020, 7, 10, 9, 30, 0, 0, time.UTC)
+		trades    []common.TradelogV4
+	)
+	newTrade := func(i int, blockNumber uint64, src ethereum.Address, rate float64) common.TradelogV4 {
+		return common.TradelogV4{
+			Timestamp:       timestamp.Add(time.Duration(i) * time.Minute),
+			BlockNumber:     blockNumber,
+			TransactionHash: ethereum.BigToHash(big.NewInt(int64(i + 1))),
+			Version:         3,
+			User: common.KyberUserInfo{
+				UserAddress: ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b"),
+			},
+			TokenInfo: common.TradeTokenInfo{
+				SrcAddress:  src,
+				DestAddress: blockchain.ETHAddr,
+			},
+			SrcReserveAddress: ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f"),
+			EthAmount:         ethToWei(1),
+			OriginalEthAmount: ethToWei(1),
+			SrcAmount:         ethToWei(100),
+			DestAmount:        ethToWei(1),
+			ETHUSDRate:        rate,
+			TxDetail: common.TxDetail{
+				GasPrice:       big.NewInt(0),
+				TransactionFee: big.NewInt(0),
+			},
+		}
+	}
+	trades = append(trades,
+		newTrade(0, 100, knc, 200),
+		newTrade(1, 150, knc, 0),
+		newTrade(2, 160, unknown, 200),
+		newTrade(3, 170, knc, 200),
+	)
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{
+		Trades: trades,
+		Blocks: []common.BlockInfo{
+			{Number: 200, Hash: ethereum.BigToHash(big.NewInt(200))},
+			{Number: 300, Hash: ethereum.BigToHash(big.NewInt(300))},
+			{Number: 600, Hash: ethereum.BigToHash(big.NewInt(600))},
+		},
+	}))
+	require.NoError(t, testStorage.UpdateTokens([]string{knc.Hex(), blockchain.ETHAddr.Hex()}, []string{"KNC", "ETH"}))
+	_, err = testStorage.db.Exec(`UPDATE split SET src_amount = src_amount / 2
+		WHERE trade_id = (SELECT id FROM tradelogs WHERE block_number = 170)`)
+	require.NoError(t, err)
+
+	checks, err := testStorage.AuditTradeLogs(0, 0, 10)
+	require.NoError(t, err)
+	counts := make(map[string]uint64)
+	samples := make(map[string][]string)
+	for _, check := range checks {
+		counts[check.Name] = check.Count
+		samples[check.Name] = check.Samples
+	}
+	assert.Equal(t, map[string]uint64{
+		"zero_fiat_amount":      1,
+		"empty_src_symbol":      1,
+		"empty_dst_symbol":      0,
+		"split_amount_mismatch": 1,
+	}, counts)
+	assert.Equal(t, []string{trades[1].TransactionHash.Hex()}, samples["zero_fiat_amount"])
+	assert.Equal(t, []string{trades[2].TransactionHash.Hex()}, samples["empty_src_symbol"])
+	assert.Empty(t, samples["empty_dst_symbol"])
+	assert.Equal(t, []string{trades[3].TransactionHash.Hex()}, samples["split_amount_mismatch"])
+
+	// block range is filtered
+	checks, err = testStorage.AuditTradeLogs(155, 0, 10)
+	require.NoError(t, err)
+	for _, check := range checks {
+		assert.Equalf(t, uint64(map[string]int{"empty_src_symbol": 1, "split_amount_mismatch": 1}[check.Name]),
+			check.Count, "check %s", check.Name)
+	}
+
+	gaps, err := testStorage.GetBlockGaps(100, 1000, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []common.BlockRange{{FromBlock: 301, ToBlock: 599}}, gaps)
+	gaps, err = testStorage.GetBlockGaps(100, 500, 100)
+	require.NoError(t, err)
+	assert.Empty(t, gaps)
+
+	// blocks before the first crawled block are a gap
+	gaps, err = testStorage.GetBlockGaps(0, 500, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []common.BlockRange{{FromBlock: 0, ToBlock: 99}}, gaps)
+	gaps, err = testStorage.GetBlockGaps(1, 500, 100)
+	require.NoError(t, err)
+	assert.Empty(t, gaps)
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+const (
+	// getBigTradesToNotifyTemplate selects big trades not delivered to the sink yet, trades whose
+	// delivery failed are selected again until max attempts.
+	getBigTradesToNotifyTemplate = `
+SELECT bt.tradelog_id,
+a.timestamp AS timestamp,
+a.block_number,
+eth_amount,
+original_eth_amount,
+eth_usd_rate*original_eth_amount as fiat_amount,
+e.symbol AS src_symbol,
+f.symbol AS dst_symbol,
+e.address AS src_address,
+f.address AS dst_address,
+tx_hash,
+g.address AS wallet_address,
+COALESCE(g.name, '') as wallet_name
+FROM big_tradelogs AS bt
+INNER JOIN tradelogs as a ON a.id = bt.tradelog_id
+INNER JOIN token AS e ON a.src_address_id = e.id
+INNER JOIN token AS f ON a.dst_address_id = f.id
+INNER JOIN wallet AS g on g.id = a.wallet_address_id
+LEFT JOIN "%[1]s" AS n ON n.tradelog_id = bt.tradelog_id AND n.sink = $1
+WHERE a.chain_id = $2 AND a.timestamp >= $3
+AND (n.id IS NULL OR (n.status = $4 AND n.attempts < $5))
+ORDER BY a.timestamp, bt.tradelog_id;
+`
+
+	saveBigTradeNotificationsTemplate = `INSERT INTO "%[1]s" (chain_id, tradelog_id, sink, status, attempts, last_error)
+VALUES (
+	$1,
+	UNNEST($2::INTEGER[]),
+	$3,
+	UNNEST($4::TEXT[]),
+	UNNEST($5::INTEGER[]),
+	UNNEST($6::TEXT[])
+) ON CONFLICT (tradelog_id, sink) DO UPDATE SET
+	status = EXCLUDED.status,
+	attempts = "%[1]s".attempts + EXCLUDED.attempts,
+	last_error = EXCLUDED.last_error,
+	updated_at = now();`
+)
+
+// GetBigTradesToNotify returns big trades since the given time which are not delivered to the sink,
+// including the ones whose delivery failed less than maxAttempts times.
+func (tldb *TradeLogDB) GetBigTradesToNotify(sink string, since time.Time, maxAttempts uint64) ([]common.BigTradeLog, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"sink", sink, "since", since, "max_attempts", maxAttempts)
+		queryResult []bigTradeLogDBData
+		result      = []common.BigTradeLog{}
+	)
+	query := fmt.Sprintf(getBigTradesToNotifyTemplate, schema.BigTradeNotificationTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if err := tldb.db.Select(&queryResult, query, sink, tldb.chainID, since,
+		common.NotificationStatusFailed, maxAttempts); err != nil {
+		logger.Errorw("failed to get big trades to notify", "error", err)
+		return nil, err
+	}
+	for _, r := range queryResult {
+		bigTradeLog, err := tldb.bigTradeLogFromDBData(r)
+		if err != nil {
+			logger.Errorw("failed to parse big trade", "error", err)
+			return nil, err
+		}
+		result = append(result, bigTradeLog)
+	}
+	return result, nil
+}
+
+// SaveBigTradeNotifications records results of delivering big trades to the sink, every delivered or
+// failed result counts as an attempt.
+func (tldb *TradeLogDB) SaveBigTradeNotifications(sink string, notifications []common.BigTradeNotification) error {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"sink", sink, "notifications", len(notifications))
+		tradelogIDs, attempts []int64
+		statuses, errs        []string
+	)
+	if len(notifications) == 0 {
+		return nil
+	}
+	for _, n := range notifications {
+		var attempt int64 = 1
+		if n.Status == common.NotificationStatusSkipped {
+			attempt = 0
+		}
+		tradelogIDs = append(tradelogIDs, int64(n.TradelogID))
+		statuses = append(statuses, string(n.Status))
+		attempts = append(attempts, attempt)
+		errs = append(errs, n.Error)
+	}
+	query := fmt.Sprintf(saveBigTradeNotificationsTemplate, schema.BigTradeNotificationTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if _, err := tldb.db.Exec(query, tldb.chainID, pq.Array(tradelogIDs), sink,
+		pq.StringArray(statuses), pq.Array(attempts), pq.StringArray(errs)); err != nil {
+		logger.Errorw("failed to save big trade notifications", "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func TestBigTradeNotifications(t *testing.T) {
+	const (
+		dbName = "test_big_trade_notifications"
+		sink   = "webhook"
+	)
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		knc    = ethereum.HexToAddress("0xdd974D5C2e2928deA5F71b9825b8b646686BD200")
+		wallet = ethereum.HexToAddress("0xf1AA99C69715F423086008eB9D06Dc1E35Cc504d")
+		now    = time.Now().UTC().Truncate(time.Second)
+		trades []common.TradelogV4
+	)
+	for i, ethAmount := range []float64{500, 10} {
+		trades = append(trades, common.TradelogV4{
+			Timestamp:       now,
+			BlockNumber:     uint64(100 + i),
+			TransactionHash: ethereum.BigToHash(big.NewInt(int64(i + 1))),
+			Version:         3,
+			User: common.KyberUserInfo{
+				UserAddress: ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b"),
+			},
+			TokenInfo: common.TradeTokenInfo{
+				SrcAddress:  knc,
+				DestAddress: blockchain.ETHAddr,
+			},
+			SrcReserveAddress: ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f"),
+			EthAmount:         ethToWei(ethAmount),
+			OriginalEthAmount: ethToWei(ethAmount),
+			SrcAmount:         ethToWei(ethAmount * 100),
+			DestAmount:        ethToWei(ethAmount),
+			WalletAddress:     wallet,
+			ETHUSDRate:        200,
+			TxDetail: common.TxDetail{
+				GasPrice:       big.NewInt(0),
+				TransactionFee: big.NewInt(0),
+			},
+		})
+	}
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: trades}))
+	require.NoError(t, testStorage.SaveBigTrades(100, 0))
+
+	since := now.Add(-time.Hour)
+	bigTrades, err := testStorage.GetBigTradesToNotify(sink, since, 2)
+	require.NoError(t, err)
+	require.Len(t, bigTrades, 1)
+	trade := bigTrades[0]
+	assert.Equal(t, trades[0].TransactionHash, trade.TransactionHash)
+	assert.Equal(t, knc, trade.SrcAddress)
+	assert.Equal(t, blockchain.ETHAddr, trade.DestAddress)
+	assert.Equal(t, wallet, trade.WalletAddress)
+	assert.InDelta(t, 100000, trade.FiatAmount, 1e-3)
+
+	// failed deliveries are retried until max attempts
+	failed := []common.BigTradeNotification{{
+		TradelogID: trade.TradelogID,
+		Status:     common.NotificationStatusFailed,
+		Error:      "connection refused",
+	}}
+	require.NoError(t, testStorage.SaveBigTradeNotifications(sink, failed))
+	bigTrades, err = testStorage.GetBigTradesToNotify(sink, since, 2)
+	require.NoError(t, err)
+	assert.Len(t, bigTrades, 1)
+	require.NoError(t, testStorage.SaveBigTradeNotifications(sink, failed))
+	bigTrades, err = testStorage.GetBigTradesToNotify(sink, since, 2)
+	require.NoError(t, err)
+	assert.Empty(t, bigTrades)
+
+	// delivery state is kept per sink
+	bigTrades, err = testStorage.GetBigTradesToNotify("slack", since, 2)
+	require.NoError(t, err)
+	assert.Len(t, bigTrades, 1)
+	require.NoError(t, testStorage.SaveBigTradeNotifications("slack", []common.BigTradeNotification{{
+		TradelogID: trade.TradelogID,
+		Status:     common.NotificationStatusDelivered,
+	}}))
+	bigTrades, err = testStorage.GetBigTradesToNotify("slack", since, 2)
+	require.NoError(t, err)
+	assert.Empty(t, bigTrades)
+
+	bigTrades, err = testStorage.GetBigTradesToNotify("telegram", now.Add(time.Minute), 2)
+	require.NoError(t, err)
+	assert.Empty(t, bigTrades)
+}
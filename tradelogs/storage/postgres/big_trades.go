@@ -21,24 +21,28 @@ original_eth_amount,
 eth_usd_rate*original_eth_amount as fiat_amount, 
 e.symbol AS src_symbol, 
 f.symbol AS dst_symbol, 
+e.address AS src_address, 
+f.address AS dst_address, 
 tx_hash, 
-g.name as wallet_name
+g.address AS wallet_address, 
+COALESCE(g.name, '') as wallet_name
 FROM big_tradelogs AS bt
 INNER JOIN tradelogs as a ON a.id = bt.tradelog_id
 INNER JOIN token AS e ON a.src_address_id = e.id
 INNER JOIN token AS f ON a.dst_address_id = f.id
 INNER JOIN wallet AS g on g.id = a.wallet_address_id
-WHERE bt.twitted is false AND a.timestamp >= $1 AND a.timestamp <= $2;
+WHERE bt.twitted is false AND a.timestamp >= $1 AND a.timestamp <= $2 AND a.chain_id = $3;
 `
 
 	insertionBigTradelogsTemplate = `
-INSERT INTO big_tradelogs (tradelog_id) (
-	SELECT tradelog_id.id FROM tradelogs AS tradelog_id 
+INSERT INTO big_tradelogs (tradelog_id, chain_id) (
+	SELECT tradelog_id.id, tradelog_id.chain_id FROM tradelogs AS tradelog_id 
 	INNER JOIN token AS src_token ON src_token.id = tradelog_id.src_address_id
 	INNER JOIN token AS dst_token ON dst_token.id = tradelog_id.dst_address_id
 	WHERE original_eth_amount > $1 
 	AND tradelog_id.block_number >= $2 
-	AND src_token.symbol != 'WETH' AND dst_token.symbol != 'WETH'
+	AND src_token.address != $3 AND dst_token.address != $3
+	AND tradelog_id.chain_id = $4
 	AND tradelog_id.timestamp >= now() - interval '1' hour
 )
 ON CONFLICT (tradelog_id) DO NOTHING;
@@ -50,6 +54,9 @@ type bigTradeLogDBData struct {
 	TradelogID        uint64    `db:"tradelog_id"`
 	SrcSymbol         string    `db:"src_symbol"`
 	DstSymbol         string    `db:"dst_symbol"`
+	SrcAddress        string    `db:"src_address"`
+	DstAddress        string    `db:"dst_address"`
+	WalletAddress     string    `db:"wallet_address"`
 	WalletName        string    `db:"wallet_name"`
 	Timestamp         time.Time `db:"timestamp"`
 	TransactionHash   string    `db:"tx_hash"`
@@ -66,7 +73,7 @@ func (tldb *TradeLogDB) GetNotTwittedTrades(from, to time.Time) ([]common.BigTra
 		queryResult = []bigTradeLogDBData{}
 		result      = []common.BigTradeLog{}
 	)
-	err := tldb.db.Select(&queryResult, getBigTradesQuery, from, to)
+	err := tldb.db.Select(&queryResult, getBigTradesQuery, from, to, tldb.chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -77,34 +84,43 @@ func (tldb *TradeLogDB) GetNotTwittedTrades(from, to time.Time) ([]common.BigTra
 	}
 
 	for _, r := range queryResult {
-		var (
-			ethAmountInWei, originalEthAmountInWei *big.Int
-		)
-		if ethAmountInWei, err = tldb.tokenAmountFormatter.ToWei(blockchain.ETHAddr, r.EthAmount); err != nil {
-			logger.Debugw("failed to parse eth amount", "error", err)
+		bigTradeLog, err := tldb.bigTradeLogFromDBData(r)
+		if err != nil {
+			logger.Debugw("failed to parse big trade", "error", err)
 			return nil, err
 		}
-
-		if originalEthAmountInWei, err = tldb.tokenAmountFormatter.ToWei(blockchain.ETHAddr, r.OriginalETHAmount); err != nil {
-			logger.Debugw("failed to parse original eth amount", "error", err)
-			return nil, err
-		}
-
-		bigTradeLog := common.BigTradeLog{
-			TradelogID:        r.TradelogID,
-			Timestamp:         r.Timestamp,
-			TransactionHash:   ethereum.HexToHash(r.TransactionHash),
-			EthAmount:         ethAmountInWei,
-			OriginalETHAmount: originalEthAmountInWei,
-			SrcSymbol:         r.SrcSymbol,
-			DestSymbol:        r.DstSymbol,
-			FiatAmount:        r.FiatAmount,
-		}
 		result = append(result, bigTradeLog)
 	}
 	return result, nil
 }
 
+func (tldb *TradeLogDB) bigTradeLogFromDBData(r bigTradeLogDBData) (common.BigTradeLog, error) {
+	var (
+		ethAmountInWei, originalEthAmountInWei *big.Int
+		err                                    error
+	)
+	if ethAmountInWei, err = tldb.tokenAmountFormatter.ToWei(blockchain.ETHAddr, r.EthAmount); err != nil {
+		return common.BigTradeLog{}, fmt.Errorf("failed to parse eth amount: %s", err)
+	}
+	if originalEthAmountInWei, err = tldb.tokenAmountFormatter.ToWei(blockchain.ETHAddr, r.OriginalETHAmount); err != nil {
+		return common.BigTradeLog{}, fmt.Errorf("failed to parse original eth amount: %s", err)
+	}
+	return common.BigTradeLog{
+		TradelogID:        r.TradelogID,
+		Timestamp:         r.Timestamp,
+		TransactionHash:   ethereum.HexToHash(r.TransactionHash),
+		EthAmount:         ethAmountInWei,
+		OriginalETHAmount: originalEthAmountInWei,
+		SrcSymbol:         r.SrcSymbol,
+		DestSymbol:        r.DstSymbol,
+		FiatAmount:        r.FiatAmount,
+		SrcAddress:        ethereum.HexToAddress(r.SrcAddress),
+		DestAddress:       ethereum.HexToAddress(r.DstAddress),
+		WalletAddress:     ethereum.HexToAddress(r.WalletAddress),
+		WalletName:        r.WalletName,
+	}, nil
+}
+
 // SaveBigTrades save trades into db
 func (tldb *TradeLogDB) SaveBigTrades(bigVolume float32, fromBlock uint64) error {
 	var (
@@ -112,7 +128,7 @@ func (tldb *TradeLogDB) SaveBigTrades(bigVolume float32, fromBlock uint64) error
 		bigTrades = []uint64{}
 	)
 	logger.Infow("query save big trades", "query", insertionBigTradelogsTemplate)
-	if _, err := tldb.db.Exec(insertionBigTradelogsTemplate, bigVolume, fromBlock); err != nil {
+	if _, err := tldb.db.Exec(insertionBigTradelogsTemplate, bigVolume, fromBlock, tldb.wrappedNativeToken.Hex(), tldb.chainID); err != nil {
 		return fmt.Errorf("cannot update big trades: %s", err.Error())
 	}
 	logger.Infow("number of big trades", "number", len(bigTrades))
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"fmt"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/pgsql"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+const insertionBlockHashTemplate = `INSERT INTO "%[1]s"(
+	block_number,
+	hash,
+	chain_id
+) VALUES (
+	UNNEST($1::INTEGER[]),
+	UNNEST($2::TEXT[]),
+	$3
+) ON CONFLICT (chain_id, block_number) DO UPDATE SET hash = EXCLUDED.hash;`
+
+func (tldb *TradeLogDB) saveBlockHashes(tx *sqlx.Tx, blocks []common.BlockInfo) error {
+	var (
+		logger       = tldb.sugar.With("func", caller.GetCurrentFunctionName())
+		blockNumbers []uint64
+		hashes       []string
+		seen         = make(map[uint64]struct{})
+	)
+	for _, b := range blocks {
+		if _, ok := seen[b.Number]; ok {
+			continue
+		}
+		seen[b.Number] = struct{}{}
+		blockNumbers = append(blockNumbers, b.Number)
+		hashes = append(hashes, b.Hash.Hex())
+	}
+	query := fmt.Sprintf(insertionBlockHashTemplate, schema.BlockHashTableName)
+	logger.Debugw("saving block hashes", "query", query, "blocks", len(blockNumbers))
+	_, err := tx.Exec(query, pq.Array(blockNumbers), pq.StringArray(hashes), tldb.chainID)
+	return err
+}
+
+// GetBlockHashes returns hashes of crawled blocks from given block, ordered by block number.
+func (tldb *TradeLogDB) GetBlockHashes(fromBlock uint64) ([]common.BlockInfo, error) {
+	var (
+		logger  = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "from_block", fromBlock)
+		records []struct {
+			BlockNumber uint64 `db:"block_number"`
+			Hash        string `db:"hash"`
+		}
+		result []common.BlockInfo
+	)
+	query := fmt.Sprintf(`SELECT block_number, hash FROM "%s" WHERE block_number >= $1 AND chain_id = $2 ORDER BY block_number`,
+		schema.BlockHashTableName)
+	logger.Debugw("getting block hashes", "query", query)
+	if err := tldb.db.Select(&records, query, fromBlock, tldb.chainID); err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		result = append(result, common.BlockInfo{
+			Number: r.BlockNumber,
+			Hash:   ethereum.HexToHash(r.Hash),
+		})
+	}
+	return result, nil
+}
+
+// DeleteTradeLogsFromBlock removes all trades, together with their fees and splits, and all recorded block hashes
+// from given block. It is used to roll back data of orphaned blocks after a chain reorganization.
+func (tldb *TradeLogDB) DeleteTradeLogsFromBlock(fromBlock uint64) (err error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "from_block", fromBlock)
+		// the order matters as rebates reference fee, split_slippage references split, and fee, split,
+		// big_tradelogs, big_trade_notification, tradelog_fiat_price and sandwich reference tradelogs
+		queries = []string{
+			`DELETE FROM "rebates" WHERE fee_id IN (SELECT fee.id FROM "fee"
+				JOIN "` + schema.TradeLogsTableName + `" ON fee.trade_id = tradelogs.id
+				WHERE tradelogs.block_number >= $1 AND tradelogs.chain_id = $2);`,
+			`DELETE FROM "fee" WHERE trade_id IN (SELECT id FROM "` + schema.TradeLogsTableName + `" WHERE block_number >= $1 AND chain_id = $2);`,
+			`DELETE FROM "` + schema.SplitSlippageTableName + `" WHERE split_id IN (SELECT split.id FROM "split"
+				JOIN "` + schema.TradeLogsTableName + `" ON split.trade_id = tradelogs.id
+				WHERE tradelogs.block_number >= $1 AND tradelogs.chain_id = $2);`,
+			`DELETE FROM "split" WHERE trade_id IN (SELECT id FROM "` + schema.TradeLogsTableName + `" WHERE block_number >= $1 AND chain_id = $2);`,
+			`DELETE FROM "` + schema.BigTradeLogsTableName + `" WHERE tradelog_id IN (SELECT id FROM "` + schema.TradeLogsTableName + `" WHERE block_number >= $1 AND chain_id = $2);`,
+			`DELETE FROM "` + schema.BigTradeNotificationTableName + `" WHERE tradelog_id IN (SELECT id FROM "` + schema.TradeLogsTableName + `" WHERE block_number >= $1 AND chain_id = $2);`,
+			`DELETE FROM "` + schema.FiatPriceTableName + `" WHERE tradelog_id IN (SELECT id FROM "` + schema.TradeLogsTableName + `" WHERE block_number >= $1 AND chain_id = $2);`,
+			`DELETE FROM "` + schema.SandwichTableName + `" WHERE trade_id IN (SELECT id FROM "` + schema.TradeLogsTableName + `" WHERE block_number >= $1 AND chain_id = $2);`,
+			`DELETE FROM "` + schema.TradeLogsTableName + `" WHERE block_number >= $1 AND chain_id = $2;`,
+			// reserve updates from orphaned blocks, only if no remaining split references them
+			`DELETE FROM "` + schema.ReserveTableName + `" WHERE block_number >= $1 AND chain_id = $2
+				AND NOT EXISTS (SELECT NULL FROM "split" WHERE split.reserve_id = reserve.id);`,
+			`DELETE FROM "` + schema.BlockHashTableName + `" WHERE block_number >= $1 AND chain_id = $2;`,
+		}
+	)
+	logger.Infow("deleting trade logs of orphaned blocks")
+	tx, err := tldb.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer pgsql.CommitOrRollback(tx, logger, &err)
+	candles, err := tldb.candleBucketsOfTrades(tx, "t.block_number >= $2", fromBlock)
+	if err != nil {
+		logger.Errorw("failed to get candles of orphaned trade logs", "error", err)
+		return err
+	}
+	for _, query := range queries {
+		if _, err = tx.Exec(query, fromBlock, tldb.chainID); err != nil {
+			logger.Errorw("failed to delete trade logs", "query", query, "error", err)
+			return err
+		}
+	}
+	err = tldb.refreshCandles(tx, candles)
+	return err
+}
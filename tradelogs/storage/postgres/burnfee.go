@@ -15,7 +15,8 @@ import (
 )
 
 // GetAggregatedBurnFee Get aggregated Burn fee by hour or day
-func (tldb *TradeLogDB) GetAggregatedBurnFee(from, to time.Time, freq string, reserveAddrs []ethereum.Address) (map[ethereum.Address]map[string]float64, error) {
+func (tldb *TradeLogDB) GetAggregatedBurnFee(from, to time.Time, freq string, reserveAddrs []ethereum.Address,
+	timezone *time.Location) (map[ethereum.Address]map[string]float64, error) {
 	var (
 		timeField string
 		err       error
@@ -25,13 +26,13 @@ func (tldb *TradeLogDB) GetAggregatedBurnFee(from, to time.Time, freq string, re
 
 	switch strings.ToLower(freq) {
 	case "h":
-		timeField = schema.BuildDateTruncField("hour", 0)
-		from = schema.RoundTime(from, "hour", 0)
-		to = schema.RoundTime(to, "hour", 0).Add(time.Hour)
+		timeField = schema.BuildDateTruncField("hour", timezone)
+		from = schema.RoundTime(from, "hour", timezone)
+		to = schema.RoundTime(to, "hour", timezone).Add(time.Hour)
 	case "d":
-		timeField = schema.BuildDateTruncField("day", 0)
-		from = schema.RoundTime(from, "day", 0)
-		to = schema.RoundTime(to, "day", 0).Add(time.Hour * 24)
+		timeField = schema.BuildDateTruncField("day", timezone)
+		from = schema.RoundTime(from, "day", timezone)
+		to = schema.RoundTime(to, "day", timezone).AddDate(0, 0, 1)
 
 	default:
 		return nil, fmt.Errorf("frequency not supported: %v", freq)
@@ -39,6 +40,7 @@ func (tldb *TradeLogDB) GetAggregatedBurnFee(from, to time.Time, freq string, re
 	args := []interface{}{
 		from,
 		to,
+		tldb.chainID,
 	}
 
 	hexAddrs := make([]string, 0)
@@ -48,7 +50,7 @@ func (tldb *TradeLogDB) GetAggregatedBurnFee(from, to time.Time, freq string, re
 
 	addrCondition := ""
 	if len(hexAddrs) != 0 {
-		addrCondition = " AND c.address = ANY($3)"
+		addrCondition = " AND c.address = ANY($4)"
 		args = append(args, pq.Array(hexAddrs))
 	}
 
@@ -58,7 +60,7 @@ func (tldb *TradeLogDB) GetAggregatedBurnFee(from, to time.Time, freq string, re
 			SELECT %[1]s as time, burn AS amount, reserve_address AS address
 			FROM "fee" b
 			JOIN tradelogs on tradelogs.id = fee.trade_id
-			WHERE tradelogs.timestamp >= $1 AND tradelogs.timestamp < $2 %[2]s
+			WHERE tradelogs.timestamp >= $1 AND tradelogs.timestamp < $2 AND tradelogs.chain_id = $3 %[2]s
 		) a GROUP BY time,address
 	`, timeField, addrCondition)
 
@@ -42,7 +42,7 @@ func TestTradeLogDB_GetAggregatedBurnFee(t *testing.T) {
 		to   = timeutil.TimestampMsToTime(uint64(toTime))
 	)
 
-	burnFee, err := tldb.GetAggregatedBurnFee(from, to, freq, rsvAddrs)
+	burnFee, err := tldb.GetAggregatedBurnFee(from, to, freq, rsvAddrs, time.UTC)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(burnFee))
 
@@ -60,7 +60,7 @@ func TestTradeLogDB_GetAggregatedBurnFee(t *testing.T) {
 	}
 	assert.Equal(t, expectedAmount, amount)
 	//test with empty addr
-	_, err = tldb.GetAggregatedBurnFee(from, to, freq, []ethereum.Address{})
+	_, err = tldb.GetAggregatedBurnFee(from, to, freq, []ethereum.Address{}, time.UTC)
 	assert.NoError(t, err)
 
 }
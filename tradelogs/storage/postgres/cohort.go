@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+// userCohortsTemplate puts users whose first trade is in the time range in the cohort of the period of
+// that trade, then aggregates all their trades from the cohort period on by period.
+const userCohortsTemplate = `WITH cohorts AS (
+	SELECT DISTINCT ON (a.user_address_id) a.user_address_id,
+		%[1]s AS cohort, %[2]s AS grp
+	FROM "%[3]s" AS a
+	LEFT JOIN "%[4]s" AS w ON w.id = a.wallet_address_id
+	WHERE a.chain_id = $1 AND a.is_first_trade AND a.timestamp >= $2 AND a.timestamp < $3
+	ORDER BY a.user_address_id, a.timestamp
+), sizes AS (
+	SELECT cohort, grp, COUNT(*) AS users FROM cohorts GROUP BY cohort, grp
+)
+SELECT c.cohort, c.grp, %[1]s AS period, s.users,
+	COUNT(DISTINCT a.user_address_id) AS active_users,
+	SUM(a.eth_amount) AS eth_volume,
+	SUM(a.eth_amount * a.eth_usd_rate) AS usd_volume
+FROM cohorts AS c
+JOIN sizes AS s ON s.cohort = c.cohort AND s.grp = c.grp
+JOIN "%[3]s" AS a ON a.user_address_id = c.user_address_id AND a.chain_id = $1 AND a.timestamp >= c.cohort
+GROUP BY c.cohort, c.grp, period, s.users
+ORDER BY c.cohort, c.grp, period;`
+
+// cohortRange rounds the time range to whole periods of the interval in given timezone.
+func cohortRange(interval common.CohortInterval, from, to time.Time, timezone *time.Location) (time.Time, time.Time, error) {
+	from, to = from.In(timezone), to.In(timezone)
+	switch interval {
+	case common.CohortIntervalWeek:
+		weekStart := func(t time.Time) time.Time {
+			return timeutil.Midnight(t).AddDate(0, 0, -(int(t.Weekday())+6)%7)
+		}
+		return weekStart(from), weekStart(to).AddDate(0, 0, 7), nil
+	case common.CohortIntervalMonth:
+		monthStart := func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, timezone)
+		}
+		return monthStart(from), monthStart(to).AddDate(0, 1, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("cohort interval not supported: %v", interval)
+	}
+}
+
+// cohortPeriod returns the number of periods of the interval from cohort to period, weeks are rounded
+// as they are not always 7*24 hours with daylight saving time.
+func cohortPeriod(interval common.CohortInterval, cohort, period time.Time, timezone *time.Location) uint64 {
+	cohort, period = cohort.In(timezone), period.In(timezone)
+	if interval == common.CohortIntervalWeek {
+		return uint64(period.Sub(cohort).Hours()/24/7 + 0.5)
+	}
+	return uint64((period.Year()-cohort.Year())*12 + int(period.Month()) - int(cohort.Month()))
+}
+
+// GetUserCohorts returns cohorts of users who made their first trade in the time range by week or
+// month, optionally split by integration app, wallet or country of the first trade. Retention of a
+// period is the fraction of users of the cohort who traded in that period. Weeks and months start in
+// given timezone.
+func (tldb *TradeLogDB) GetUserCohorts(interval common.CohortInterval, group common.CohortGroup,
+	from, to time.Time, timezone *time.Location) ([]common.UserCohort, error) {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"interval", interval, "group", group, "from", from, "to", to, "timezone", timezone)
+		groupField string
+		records    []struct {
+			Cohort      time.Time `db:"cohort"`
+			Group       string    `db:"grp"`
+			Period      time.Time `db:"period"`
+			Users       uint64    `db:"users"`
+			ActiveUsers uint64    `db:"active_users"`
+			ETHVolume   float64   `db:"eth_volume"`
+			USDVolume   float64   `db:"usd_volume"`
+		}
+		result = []common.UserCohort{}
+	)
+
+	from, to, err := cohortRange(interval, from, to, timezone)
+	if err != nil {
+		return nil, err
+	}
+	switch group {
+	case common.CohortGroupNone:
+		groupField = "''"
+	case common.CohortGroupIntegrationApp:
+		groupField = "COALESCE(a.integration_app, '')"
+	case common.CohortGroupWallet:
+		groupField = "COALESCE(w.address, '')"
+	case common.CohortGroupCountry:
+		groupField = "COALESCE(a.country, '')"
+	default:
+		return nil, fmt.Errorf("cohort group not supported: %v", group)
+	}
+
+	query := fmt.Sprintf(userCohortsTemplate, schema.DateTruncInTimezone(string(interval), "a.timestamp", timezone),
+		groupField, schema.TradeLogsTableName, schema.WalletTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if err = tldb.db.Select(&records, query, tldb.chainID, from, to); err != nil {
+		logger.Errorw("failed to get user cohorts", "error", err)
+		return nil, err
+	}
+	for _, r := range records {
+		timestamp := timeutil.TimeToTimestampMs(r.Cohort)
+		if len(result) == 0 || result[len(result)-1].Timestamp != timestamp || result[len(result)-1].Group != r.Group {
+			result = append(result, common.UserCohort{
+				Timestamp: timestamp,
+				Group:     r.Group,
+				Users:     r.Users,
+			})
+		}
+		cohort := &result[len(result)-1]
+		cohort.ETHVolume += r.ETHVolume
+		cohort.USDVolume += r.USDVolume
+		cohort.Periods = append(cohort.Periods, common.CohortPeriod{
+			Period:      cohortPeriod(interval, r.Cohort, r.Period, timezone),
+			Timestamp:   timeutil.TimeToTimestampMs(r.Period),
+			ActiveUsers: r.ActiveUsers,
+			Retention:   float64(r.ActiveUsers) / float64(r.Users),
+			ETHVolume:   r.ETHVolume,
+			USDVolume:   r.USDVolume,
+		})
+	}
+	return result, nil
+}
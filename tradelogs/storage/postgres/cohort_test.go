@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func TestGetUserCohorts(t *testing.T) {
+	const dbName = "test_user_cohorts"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		userA   = ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b")
+		userB   = ethereum.HexToAddress("0x0000000000007f150bd6f54c40a34d7c3d5e9f56")
+		userC   = ethereum.HexToAddress("0xa1ac6f6f7e8ad1e4e34c7c13a6e2fe94bbd73ea2")
+		walletA = ethereum.HexToAddress("0xf1aa99c69715f423086008eb9d06dc1e35cc504d")
+		walletB = ethereum.HexToAddress("0xdecaf9cd2367cdbb726e904cd6397edfcae6068d")
+		week    = time.Date(2020, 7, 6, 0, 0, 0, 0, time.UTC) // Monday
+		trades  []common.TradelogV4
+	)
+	for i, trade := range []struct {
+		user      ethereum.Address
+		wallet    ethereum.Address
+		country   string
+		timestamp time.Time
+	}{
+		{userA, walletA, "VN", week.Add(10 * time.Hour)},
+		{userB, walletB, "US", week.AddDate(0, 0, 2)},
+		{userA, walletB, "US", week.AddDate(0, 0, 8)},
+		{userC, walletA, "VN", week.AddDate(0, 0, 9)},
+		{userA, walletA, "VN", week.AddDate(0, 0, 15)},
+		{userB, walletB, "US", week.AddDate(0, 0, 16)},
+	} {
+		trades = append(trades, common.TradelogV4{
+			Timestamp:       trade.timestamp,
+			BlockNumber:     uint64(100 + i),
+			TransactionHash: ethereum.HexToHash(fmt.Sprintf("0x%064x", i+1)),
+			Version:         3,
+			User:            common.KyberUserInfo{UserAddress: trade.user, Country: trade.country},
+			TokenInfo: common.TradeTokenInfo{
+				SrcAddress:  ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200"),
+				DestAddress: blockchain.ETHAddr,
+			},
+			SrcReserveAddress: ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f"),
+			EthAmount:         ethToWei(1),
+			OriginalEthAmount: ethToWei(1),
+			SrcAmount:         ethToWei(100),
+			DestAmount:        ethToWei(1),
+			WalletAddress:     trade.wallet,
+			ETHUSDRate:        200,
+			TxDetail: common.TxDetail{
+				GasPrice:       big.NewInt(0),
+				TransactionFee: big.NewInt(0),
+			},
+		})
+	}
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: trades}))
+
+	// the range is rounded to whole weeks
+	cohorts, err := testStorage.GetUserCohorts(common.CohortIntervalWeek, common.CohortGroupNone,
+		week.AddDate(0, 0, 3), week.AddDate(0, 0, 8), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, cohorts, 2)
+	assert.Equal(t, timeutil.TimeToTimestampMs(week), cohorts[0].Timestamp)
+	assert.Equal(t, uint64(2), cohorts[0].Users)
+	assert.InDelta(t, 5, cohorts[0].ETHVolume, 1e-6)
+	assert.InDelta(t, 1000, cohorts[0].USDVolume, 1e-3)
+	require.Len(t, cohorts[0].Periods, 3)
+	for i, expected := range []struct {
+		activeUsers uint64
+		retention   float64
+	}{{2, 1}, {1, 0.5}, {2, 1}} {
+		period := cohorts[0].Periods[i]
+		assert.Equal(t, uint64(i), period.Period)
+		assert.Equal(t, timeutil.TimeToTimestampMs(week.AddDate(0, 0, 7*i)), period.Timestamp)
+		assert.Equal(t, expected.activeUsers, period.ActiveUsers)
+		assert.InDelta(t, expected.retention, period.Retention, 1e-9)
+	}
+	assert.Equal(t, timeutil.TimeToTimestampMs(week.AddDate(0, 0, 7)), cohorts[1].Timestamp)
+	assert.Equal(t, uint64(1), cohorts[1].Users)
+	require.Len(t, cohorts[1].Periods, 1)
+
+	// users are attributed to the country of their first trade
+	cohorts, err = testStorage.GetUserCohorts(common.CohortIntervalWeek, common.CohortGroupCountry, week, week, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, cohorts, 2)
+	assert.Equal(t, "US", cohorts[0].Group)
+	assert.Equal(t, uint64(1), cohorts[0].Users)
+	assert.Len(t, cohorts[0].Periods, 2)
+	assert.Equal(t, "VN", cohorts[1].Group)
+	assert.Len(t, cohorts[1].Periods, 3)
+
+	cohorts, err = testStorage.GetUserCohorts(common.CohortIntervalMonth, common.CohortGroupWallet, week, week, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, cohorts, 2)
+	users := make(map[string]uint64)
+	for _, cohort := range cohorts {
+		assert.Equal(t, timeutil.TimeToTimestampMs(time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)), cohort.Timestamp)
+		require.Len(t, cohort.Periods, 1)
+		assert.Equal(t, uint64(0), cohort.Periods[0].Period)
+		users[cohort.Group] = cohort.Users
+	}
+	assert.Equal(t, map[string]uint64{walletA.Hex(): 2, walletB.Hex(): 1}, users)
+
+	// weeks start at local midnight of the timezone
+	timezone, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	cohorts, err = testStorage.GetUserCohorts(common.CohortIntervalWeek, common.CohortGroupNone,
+		week, week.AddDate(0, 0, 1), timezone)
+	require.NoError(t, err)
+	require.Len(t, cohorts, 1)
+	assert.Equal(t, timeutil.TimeToTimestampMs(week.Add(7*time.Hour)), cohorts[0].Timestamp)
+	assert.Equal(t, uint64(2), cohorts[0].Users)
+
+	_, err = testStorage.GetUserCohorts(common.CohortInterval("day"), common.CohortGroupNone, week, week, time.UTC)
+	assert.Error(t, err)
+}
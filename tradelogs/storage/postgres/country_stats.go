@@ -11,7 +11,7 @@ import (
 )
 
 // GetCountryStats return country stats aggregated data
-func (tldb *TradeLogDB) GetCountryStats(countryCode string, from, to time.Time, timezone int8) (map[uint64]*common.CountryStats, error) {
+func (tldb *TradeLogDB) GetCountryStats(countryCode string, from, to time.Time, timezone *time.Location) (map[uint64]*common.CountryStats, error) {
 	var (
 		err            error
 		tradelogsQuery string
@@ -21,7 +21,7 @@ func (tldb *TradeLogDB) GetCountryStats(countryCode string, from, to time.Time,
 	logger := tldb.sugar.With("from", from, "to", to,
 		"func", caller.GetCurrentFunctionName())
 	from = schema.RoundTime(from, "day", timezone)
-	to = schema.RoundTime(to, "day", timezone).Add(time.Hour * 24)
+	to = schema.RoundTime(to, "day", timezone).AddDate(0, 0, 1)
 	// get unique_address, kyced, total_burn_fee, count_new_trades
 	tradelogsQuery = fmt.Sprintf(`SELECT %[1]s AS time, 
 		COUNT(DISTINCT(user_address_id)) AS unique_address,
@@ -30,7 +30,7 @@ func (tldb *TradeLogDB) GetCountryStats(countryCode string, from, to time.Time,
 		COUNT(CASE WHEN is_first_trade THEN 1 END) AS count_new_trades
 		FROM tradelogs
 		LEFT JOIN fee ON fee.trade_id = tradelogs.id
-		WHERE timestamp >= $1 AND timestamp < $2 AND country = $3
+		WHERE timestamp >= $1 AND timestamp < $2 AND country = $3 AND tradelogs.chain_id = $4
 		GROUP BY time
 	`, timeField)
 	logger.Debugw("prepare statement", "stmt", tradelogsQuery)
@@ -41,7 +41,7 @@ func (tldb *TradeLogDB) GetCountryStats(countryCode string, from, to time.Time,
 		CountNewTrades uint64 `db:"count_new_trades"`
 		Kyced          uint64 `db:"kyced"`
 	}
-	if err = tldb.db.Select(&records, tradelogsQuery, from, to, countryCode); err != nil {
+	if err = tldb.db.Select(&records, tradelogsQuery, from, to, countryCode, tldb.chainID); err != nil {
 		return nil, err
 	}
 
@@ -66,7 +66,7 @@ func (tldb *TradeLogDB) GetCountryStats(countryCode string, from, to time.Time,
 		SUM(eth_amount*eth_usd_rate) as total_usd_volume, 
 		AVG(eth_amount*eth_usd_rate) usd_per_trade, count(1) as total_trade 
 	FROM tradelogs
-	WHERE timestamp >= $1 AND timestamp < $2 AND country = $3
+	WHERE timestamp >= $1 AND timestamp < $2 AND country = $3 AND chain_id = $4
 	GROUP BY time
 	`, timeField)
 	logger.Debugw("prepare statement", "stmt", tradelogsQuery)
@@ -78,7 +78,7 @@ func (tldb *TradeLogDB) GetCountryStats(countryCode string, from, to time.Time,
 		UsdPerTrade    float64   `db:"usd_per_trade"`
 		TotalTrade     uint64    `db:"total_trade"`
 	}
-	err = tldb.db.Select(&volumeRecords, tradelogsQuery, from, to, countryCode)
+	err = tldb.db.Select(&volumeRecords, tradelogsQuery, from, to, countryCode, tldb.chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -25,7 +25,7 @@ func TestTradeLogDB_GetCountryStats(t *testing.T) {
 	var (
 		fromTime = timeutil.TimestampMsToTime(1539216000000)
 		toTime   = timeutil.TimestampMsToTime(1539254666000)
-		timeZone = int8(-8)
+		timeZone = time.FixedZone("Etc/GMT+8", -8*60*60)
 	)
 
 	timeUnix, err := time.Parse(time.RFC3339, timeStamp)
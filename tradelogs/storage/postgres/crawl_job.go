@@ -0,0 +1,260 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/pgsql"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+const (
+	crawlJobFields = `id, from_block, to_block, status, attempts, lease_owner, lease_expires_at, last_error, updated_at`
+
+	// enqueueCrawlJobsTemplate adds block ranges to the queue, a range already queued is crawled again unless
+	// it is running, as it is only enqueued again to re-crawl orphaned or missing blocks.
+	enqueueCrawlJobsTemplate = `INSERT INTO "%[1]s" (chain_id, from_block, to_block)
+VALUES (
+	$1,
+	UNNEST($2::INTEGER[]),
+	UNNEST($3::INTEGER[])
+) ON CONFLICT (chain_id, from_block, to_block) DO UPDATE SET
+	status = 'pending',
+	attempts = 0,
+	last_error = '',
+	updated_at = now()
+WHERE "%[1]s".status <> 'running';`
+
+	// deadLetterExpiredCrawlJobsTemplate fails the running jobs whose lease expired after max attempts, as
+	// their crawler probably crashed on the range.
+	deadLetterExpiredCrawlJobsTemplate = `UPDATE "%[1]s" SET
+	status = 'failed',
+	lease_owner = '',
+	lease_expires_at = NULL,
+	last_error = 'lease expired',
+	updated_at = now()
+WHERE chain_id = $1 AND status = 'running' AND lease_expires_at < now() AND attempts >= $2;`
+
+	leaseCrawlJobTemplate = `UPDATE "%[1]s" SET
+	status = 'running',
+	attempts = attempts + 1,
+	lease_owner = $2,
+	lease_expires_at = now() + $3::FLOAT * INTERVAL '1 second',
+	updated_at = now()
+WHERE id = (
+	SELECT id FROM "%[1]s"
+	WHERE chain_id = $1 AND (status = 'pending' OR (status = 'running' AND lease_expires_at < now()))
+	ORDER BY from_block
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+) RETURNING %[2]s;`
+
+	completeCrawlJobTemplate = `UPDATE "%[1]s" SET
+	status = 'done',
+	lease_owner = '',
+	lease_expires_at = NULL,
+	last_error = '',
+	updated_at = now()
+WHERE id = $1 AND chain_id = $2 AND status = 'running' AND lease_owner = $3;`
+
+	renewCrawlJobTemplate = `UPDATE "%[1]s" SET
+	lease_expires_at = now() + $4::FLOAT * INTERVAL '1 second',
+	updated_at = now()
+WHERE id = $1 AND chain_id = $2 AND status = 'running' AND lease_owner = $3;`
+
+	failCrawlJobTemplate = `UPDATE "%[1]s" SET
+	status = CASE WHEN attempts >= $4 THEN 'failed' ELSE 'pending' END,
+	lease_owner = '',
+	lease_expires_at = NULL,
+	last_error = $5,
+	updated_at = now()
+WHERE id = $1 AND chain_id = $2 AND status = 'running' AND lease_owner = $3;`
+
+	retryCrawlJobsTemplate = `UPDATE "%[1]s" SET
+	status = 'pending',
+	attempts = 0,
+	updated_at = now()
+WHERE chain_id = $1 AND status = 'failed' AND (CARDINALITY($2::INTEGER[]) = 0 OR id = ANY($2::INTEGER[]));`
+)
+
+type crawlJobDBData struct {
+	ID             uint64     `db:"id"`
+	FromBlock      uint64     `db:"from_block"`
+	ToBlock        uint64     `db:"to_block"`
+	Status         string     `db:"status"`
+	Attempts       uint64     `db:"attempts"`
+	LeaseOwner     string     `db:"lease_owner"`
+	LeaseExpiresAt *time.Time `db:"lease_expires_at"`
+	LastError      string     `db:"last_error"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+func (r crawlJobDBData) toCrawlJob() common.CrawlJob {
+	return common.CrawlJob{
+		ID:             r.ID,
+		FromBlock:      r.FromBlock,
+		ToBlock:        r.ToBlock,
+		Status:         common.CrawlJobStatus(r.Status),
+		Attempts:       r.Attempts,
+		LeaseOwner:     r.LeaseOwner,
+		LeaseExpiresAt: r.LeaseExpiresAt,
+		LastError:      r.LastError,
+		UpdatedAt:      r.UpdatedAt,
+	}
+}
+
+// EnqueueCrawlJobs adds block ranges to the crawl queue.
+func (tldb *TradeLogDB) EnqueueCrawlJobs(ranges []common.BlockRange) error {
+	var (
+		logger     = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "ranges", len(ranges))
+		fromBlocks []int64
+		toBlocks   []int64
+	)
+	if len(ranges) == 0 {
+		return nil
+	}
+	for _, r := range ranges {
+		fromBlocks = append(fromBlocks, int64(r.FromBlock))
+		toBlocks = append(toBlocks, int64(r.ToBlock))
+	}
+	query := fmt.Sprintf(enqueueCrawlJobsTemplate, schema.CrawlJobTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if _, err := tldb.db.Exec(query, tldb.chainID, pq.Array(fromBlocks), pq.Array(toBlocks)); err != nil {
+		logger.Errorw("failed to enqueue crawl jobs", "error", err)
+		return err
+	}
+	return nil
+}
+
+// LastQueuedBlock returns the last block of ranges in the crawl queue, 0 if the queue is empty.
+func (tldb *TradeLogDB) LastQueuedBlock() (uint64, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName())
+		result sql.NullInt64
+	)
+	query := fmt.Sprintf(`SELECT MAX(to_block) FROM "%s" WHERE chain_id = $1;`, schema.CrawlJobTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if err := tldb.db.Get(&result, query, tldb.chainID); err != nil {
+		logger.Errorw("failed to get last queued block", "error", err)
+		return 0, err
+	}
+	return uint64(result.Int64), nil
+}
+
+// LeaseCrawlJob leases the pending job of the lowest blocks to owner for the lease duration, or a running
+// job whose lease expired. Expired jobs leased maxAttempts times are dead-lettered instead. It returns nil
+// if there is no job to lease. Jobs are locked while being leased, so crawlers can share the queue.
+func (tldb *TradeLogDB) LeaseCrawlJob(owner string, lease time.Duration, maxAttempts uint64) (job *common.CrawlJob, err error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "owner", owner, "lease", lease)
+		record crawlJobDBData
+	)
+	tx, err := tldb.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer pgsql.CommitOrRollback(tx, logger, &err)
+
+	query := fmt.Sprintf(deadLetterExpiredCrawlJobsTemplate, schema.CrawlJobTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if _, err = tx.Exec(query, tldb.chainID, maxAttempts); err != nil {
+		logger.Errorw("failed to dead-letter expired crawl jobs", "error", err)
+		return nil, err
+	}
+	query = fmt.Sprintf(leaseCrawlJobTemplate, schema.CrawlJobTableName, crawlJobFields)
+	logger.Debugw("prepare statement", "stmt", query)
+	if err = tx.Get(&record, query, tldb.chainID, owner, lease.Seconds()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logger.Errorw("failed to lease crawl job", "error", err)
+		return nil, err
+	}
+	result := record.toCrawlJob()
+	return &result, nil
+}
+
+// updateLeasedCrawlJob runs the query updating the job leased by owner, query args are id, chain ID, owner
+// followed by args.
+func (tldb *TradeLogDB) updateLeasedCrawlJob(query string, id uint64, owner string, args ...interface{}) error {
+	var logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "id", id, "owner", owner)
+	logger.Debugw("prepare statement", "stmt", query)
+	res, err := tldb.db.Exec(query, append([]interface{}{id, tldb.chainID, owner}, args...)...)
+	if err != nil {
+		logger.Errorw("failed to update leased crawl job", "error", err)
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("crawl job %d is not leased by %s", id, owner)
+	}
+	return nil
+}
+
+// CompleteCrawlJob marks the job leased by owner as done. It fails if the lease expired and the job was
+// leased by another crawler.
+func (tldb *TradeLogDB) CompleteCrawlJob(id uint64, owner string) error {
+	return tldb.updateLeasedCrawlJob(fmt.Sprintf(completeCrawlJobTemplate, schema.CrawlJobTableName), id, owner)
+}
+
+// RenewCrawlJob extends the lease of the job leased by owner to the lease duration from now. It fails if
+// the lease expired and the job was leased by another crawler.
+func (tldb *TradeLogDB) RenewCrawlJob(id uint64, owner string, lease time.Duration) error {
+	return tldb.updateLeasedCrawlJob(fmt.Sprintf(renewCrawlJobTemplate, schema.CrawlJobTableName), id, owner,
+		lease.Seconds())
+}
+
+// FailCrawlJob records the failure of the job leased by owner. The job is pending again, or dead-lettered
+// if it was leased maxAttempts times.
+func (tldb *TradeLogDB) FailCrawlJob(id uint64, owner, reason string, maxAttempts uint64) error {
+	return tldb.updateLeasedCrawlJob(fmt.Sprintf(failCrawlJobTemplate, schema.CrawlJobTableName), id, owner,
+		maxAttempts, reason)
+}
+
+// GetCrawlJobs returns jobs of the crawl queue in given status, ordered by block.
+func (tldb *TradeLogDB) GetCrawlJobs(status common.CrawlJobStatus) ([]common.CrawlJob, error) {
+	var (
+		logger  = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "status", status)
+		records []crawlJobDBData
+		result  = []common.CrawlJob{}
+	)
+	query := fmt.Sprintf(`SELECT %[2]s FROM "%[1]s" WHERE chain_id = $1 AND status = $2 ORDER BY from_block;`,
+		schema.CrawlJobTableName, crawlJobFields)
+	logger.Debugw("prepare statement", "stmt", query)
+	if err := tldb.db.Select(&records, query, tldb.chainID, string(status)); err != nil {
+		logger.Errorw("failed to get crawl jobs", "error", err)
+		return nil, err
+	}
+	for _, r := range records {
+		result = append(result, r.toCrawlJob())
+	}
+	return result, nil
+}
+
+// RetryCrawlJobs moves dead-lettered jobs of given ids back to pending with attempts reset, all of them
+// if ids is empty. It returns the number of retried jobs.
+func (tldb *TradeLogDB) RetryCrawlJobs(ids []uint64) (int64, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "ids", ids)
+		jobIDs = []int64{}
+	)
+	for _, id := range ids {
+		jobIDs = append(jobIDs, int64(id))
+	}
+	query := fmt.Sprintf(retryCrawlJobsTemplate, schema.CrawlJobTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	res, err := tldb.db.Exec(query, tldb.chainID, pq.Array(jobIDs))
+	if err != nil {
+		logger.Errorw("failed to retry crawl jobs", "error", err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
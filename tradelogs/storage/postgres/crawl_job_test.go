@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func TestCrawlJobs(t *testing.T) {
+	const dbName = "test_crawl_jobs"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	lastBlock, err := testStorage.LastQueuedBlock()
+	require.NoError(t, err)
+	assert.Zero(t, lastBlock)
+
+	require.NoError(t, testStorage.EnqueueCrawlJobs([]common.BlockRange{
+		{FromBlock: 201, ToBlock: 300},
+		{FromBlock: 101, ToBlock: 200},
+	}))
+	lastBlock, err = testStorage.LastQueuedBlock()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(300), lastBlock)
+
+	// jobs are leased from the lowest blocks, a leased job is not leased again
+	first, err := testStorage.LeaseCrawlJob("a", time.Minute, 1)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.Equal(t, uint64(101), first.FromBlock)
+	assert.Equal(t, common.CrawlJobStatusRunning, first.Status)
+	assert.Equal(t, uint64(1), first.Attempts)
+	second, err := testStorage.LeaseCrawlJob("b", time.Minute, 1)
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	assert.Equal(t, uint64(201), second.FromBlock)
+	job, err := testStorage.LeaseCrawlJob("c", time.Minute, 1)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+
+	// only the owner renews the lease
+	require.NoError(t, testStorage.RenewCrawlJob(second.ID, "b", time.Hour))
+	assert.Error(t, testStorage.RenewCrawlJob(second.ID, "a", time.Hour))
+
+	require.NoError(t, testStorage.FailCrawlJob(first.ID, "a", "node timeout", 1))
+	assert.Error(t, testStorage.CompleteCrawlJob(second.ID, "a"))
+	require.NoError(t, testStorage.CompleteCrawlJob(second.ID, "b"))
+
+	failed, err := testStorage.GetCrawlJobs(common.CrawlJobStatusFailed)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, first.ID, failed[0].ID)
+	assert.Equal(t, "node timeout", failed[0].LastError)
+	assert.Empty(t, failed[0].LeaseOwner)
+
+	retried, err := testStorage.RetryCrawlJobs(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), retried)
+	failed, err = testStorage.GetCrawlJobs(common.CrawlJobStatusFailed)
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+
+	// a job of which lease expired after max attempts is dead-lettered
+	job, err = testStorage.LeaseCrawlJob("c", -time.Second, 1)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, first.ID, job.ID)
+	job, err = testStorage.LeaseCrawlJob("d", time.Minute, 1)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	failed, err = testStorage.GetCrawlJobs(common.CrawlJobStatusFailed)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "lease expired", failed[0].LastError)
+
+	// a done job is crawled again when enqueued again
+	require.NoError(t, testStorage.EnqueueCrawlJobs([]common.BlockRange{{FromBlock: 201, ToBlock: 300}}))
+	pending, err := testStorage.GetCrawlJobs(common.CrawlJobStatusPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.ID, pending[0].ID)
+	assert.Zero(t, pending[0].Attempts)
+}
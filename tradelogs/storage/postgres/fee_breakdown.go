@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+const (
+	// katalystFeesQuery selects fees of Katalyst trades in the time range, every fee has a list of
+	// rebate wallets with percentages in bps, sharesQuery expands it to a row per rebate wallet.
+	katalystFeesQuery = `
+	fees AS (
+		SELECT %[1]s AS time, fee.reserve_address, fee.wallet_address, fee.platform_fee, fee.burn, fee.rebate, fee.reward,
+			fee.rebate_wallets, fee.rebate_percents, tradelogs.eth_usd_rate
+		FROM "fee"
+		JOIN "tradelogs" ON tradelogs.id = fee.trade_id
+		WHERE tradelogs.timestamp >= $1 AND tradelogs.timestamp < $2 AND tradelogs.chain_id = $3
+		AND tradelogs.version = 4
+	)`
+	// rebateWalletsFrom expands rebate wallets and percentages of fee rows of the given alias to
+	// w(wallet, i) and p(percent, i), the rows must be filtered by w.i = p.i.
+	rebateWalletsFrom = `
+		jsonb_array_elements_text(CASE WHEN jsonb_typeof(%[1]s.rebate_wallets) = 'array'
+			THEN %[1]s.rebate_wallets ELSE '[]'::jsonb END) WITH ORDINALITY AS w(wallet, i),
+		jsonb_array_elements_text(CASE WHEN jsonb_typeof(%[1]s.rebate_percents) = 'array'
+			THEN %[1]s.rebate_percents ELSE '[]'::jsonb END) WITH ORDINALITY AS p(percent, i)`
+	sharesQuery = `,
+	shares AS (
+		SELECT fees.*, w.wallet AS rebate_wallet, p.percent::FLOAT / 10000 AS share
+		FROM fees, %s
+		WHERE p.i = w.i
+	)`
+)
+
+// GetFeeBreakdown returns Katalyst fees split into burn, reward, rebate and platform fee, grouped
+// by hour or day and by the given group. Burn, reward and rebate of a trade are attributed to rebate
+// wallets by the rebate percentage of each wallet and to reserves by the reserve of the fee, platform
+// fee is only attributed to platform wallets.
+func (tldb *TradeLogDB) GetFeeBreakdown(group common.FeeGroup, from, to time.Time, freq string, timezone *time.Location,
+	addrs []ethereum.Address) (map[ethereum.Address]map[uint64]common.FeeBreakdown, error) {
+	var (
+		timeField string
+		err       error
+	)
+	logger := tldb.sugar.With("from", from, "to", to, "group", group,
+		"func", caller.GetCurrentFunctionName())
+
+	switch strings.ToLower(freq) {
+	case "h":
+		timeField = schema.BuildDateTruncField("hour", timezone)
+		from = schema.RoundTime(from, "hour", timezone)
+		to = schema.RoundTime(to, "hour", timezone).Add(time.Hour)
+	case "d":
+		timeField = schema.BuildDateTruncField("day", timezone)
+		from = schema.RoundTime(from, "day", timezone)
+		to = schema.RoundTime(to, "day", timezone).AddDate(0, 0, 1)
+	default:
+		return nil, fmt.Errorf("frequency not supported: %v", freq)
+	}
+
+	var (
+		ctes        = fmt.Sprintf(katalystFeesQuery, timeField)
+		source      string
+		addrField   string
+		share       = "share"
+		platformFee = "0::FLOAT"
+	)
+	switch group {
+	case common.FeeGroupPlatformWallet:
+		source, addrField, share, platformFee = "fees", "wallet_address", "1", "platform_fee"
+	case common.FeeGroupRebateWallet:
+		ctes += fmt.Sprintf(sharesQuery, fmt.Sprintf(rebateWalletsFrom, "fees"))
+		source, addrField = "shares", "rebate_wallet"
+	case common.FeeGroupReserve:
+		source, addrField, share = "fees", "reserve_address", "1"
+	default:
+		return nil, fmt.Errorf("fee group not supported: %v", group)
+	}
+
+	args := []interface{}{
+		from,
+		to,
+		tldb.chainID,
+	}
+	addrCondition := ""
+	if len(addrs) != 0 {
+		addrCondition = fmt.Sprintf("WHERE %s = ANY($4)", addrField)
+		args = append(args, addressesToHex(addrs))
+	}
+
+	query := fmt.Sprintf(`WITH %[1]s
+	SELECT time, %[3]s AS address,
+		SUM(burn * %[5]s) AS burn, SUM(burn * %[5]s * eth_usd_rate) AS burn_usd,
+		SUM(reward * %[5]s) AS reward, SUM(reward * %[5]s * eth_usd_rate) AS reward_usd,
+		SUM(rebate * %[5]s) AS rebate, SUM(rebate * %[5]s * eth_usd_rate) AS rebate_usd,
+		SUM(%[6]s) AS platform_fee, SUM(%[6]s * eth_usd_rate) AS platform_fee_usd
+	FROM %[2]s
+	%[4]s
+	GROUP BY time, %[3]s
+	`, ctes, source, addrField, addrCondition, share, platformFee)
+
+	var records []struct {
+		Time    time.Time `db:"time"`
+		Address string    `db:"address"`
+		common.FeeBreakdown
+	}
+
+	logger.Debugw("prepare statement", "stmt", query)
+	if err = tldb.db.Select(&records, query, args...); err != nil {
+		return nil, err
+	}
+
+	result := make(map[ethereum.Address]map[uint64]common.FeeBreakdown)
+	for _, record := range records {
+		addr := ethereum.HexToAddress(record.Address)
+		if _, ok := result[addr]; !ok {
+			result[addr] = make(map[uint64]common.FeeBreakdown)
+		}
+		result[addr][timeutil.TimeToTimestampMs(record.Time)] = record.FeeBreakdown
+	}
+	return result, nil
+}
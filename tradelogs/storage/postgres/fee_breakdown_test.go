@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func ethToWei(amount float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(amount), big.NewFloat(1e18)).Int(nil)
+	return wei
+}
+
+func TestGetFeeBreakdown(t *testing.T) {
+	const dbName = "test_fee_breakdown"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		timestamp      = time.Date(2020, 7, 10, 9, 30, 0, 0, time.UTC)
+		hourMs         = timeutil.TimeToTimestampMs(time.Date(2020, 7, 10, 9, 0, 0, 0, time.UTC))
+		reserveAddr    = ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f")
+		rebateWallet   = ethereum.HexToAddress("0x4f32bbe8dfc9efd54345fc936f9fefa1ec8e8e83")
+		unknownWallet  = ethereum.HexToAddress("0xa1ac6f6f7e8ad1e4e34c7c13a6e2fe94bbd73ea2")
+		platformWallet = ethereum.HexToAddress("0xdecaf9cd2367cdbb726e904cd6397edfcae6068d")
+		otherReserve   = ethereum.HexToAddress("0x21433dec9cb634a23c6a4bbcce08c83f5ac2ec18")
+	)
+	require.NoError(t, testStorage.saveReserve([]common.Reserve{
+		{Address: reserveAddr, ReserveID: [32]byte{0xaa}, RebateWallet: rebateWallet, BlockNumber: 100},
+		// another reserve sharing the rebate wallet does not take fees of the first one
+		{Address: otherReserve, ReserveID: [32]byte{0xbb}, RebateWallet: rebateWallet, BlockNumber: 150},
+	}))
+	tradeLog := common.TradelogV4{
+		Timestamp:       timestamp,
+		BlockNumber:     200,
+		TransactionHash: ethereum.HexToHash("0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff01"),
+		Version:         4,
+		User: common.KyberUserInfo{
+			UserAddress: ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b"),
+		},
+		TokenInfo: common.TradeTokenInfo{
+			SrcAddress:  ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200"),
+			DestAddress: blockchain.ETHAddr,
+		},
+		EthAmount:         ethToWei(10),
+		OriginalEthAmount: ethToWei(10),
+		SrcAmount:         ethToWei(1000),
+		DestAmount:        big.NewInt(0),
+		ETHUSDRate:        200,
+		TxDetail: common.TxDetail{
+			GasPrice:       big.NewInt(0),
+			TransactionFee: big.NewInt(0),
+		},
+		Fees: []common.TradelogFee{
+			{
+				ReserveAddr:               reserveAddr,
+				PlatformWallet:            platformWallet,
+				WalletFee:                 big.NewInt(0),
+				PlatformFee:               ethToWei(0.5),
+				Burn:                      ethToWei(1),
+				Reward:                    ethToWei(2),
+				Rebate:                    ethToWei(1),
+				RebateWallets:             []ethereum.Address{rebateWallet, unknownWallet},
+				RebatePercentBpsPerWallet: []*big.Int{big.NewInt(7500), big.NewInt(2500)},
+				Index:                     3,
+			},
+			// fee without rebate wallet is still attributed to its reserve
+			{
+				ReserveAddr: otherReserve,
+				WalletFee:   big.NewInt(0),
+				PlatformFee: big.NewInt(0),
+				Burn:        ethToWei(0.5),
+				Reward:      big.NewInt(0),
+				Rebate:      big.NewInt(0),
+				Index:       4,
+			},
+		},
+	}
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: []common.TradelogV4{tradeLog}}))
+
+	platformFees, err := testStorage.GetFeeBreakdown(common.FeeGroupPlatformWallet, timestamp, timestamp, "h", time.UTC, nil)
+	require.NoError(t, err)
+	require.Contains(t, platformFees, platformWallet)
+	platformFee := platformFees[platformWallet][hourMs]
+	assert.InDelta(t, 0.5, platformFee.PlatformFee, 1e-6)
+	assert.InDelta(t, 100, platformFee.PlatformFeeUSD, 1e-4)
+	assert.InDelta(t, 1, platformFee.Burn, 1e-6)
+	assert.InDelta(t, 2, platformFee.Reward, 1e-6)
+	assert.InDelta(t, 1, platformFee.Rebate, 1e-6)
+
+	rebateFees, err := testStorage.GetFeeBreakdown(common.FeeGroupRebateWallet, timestamp, timestamp, "h", time.UTC,
+		[]ethereum.Address{rebateWallet})
+	require.NoError(t, err)
+	require.Len(t, rebateFees, 1)
+	rebateFee := rebateFees[rebateWallet][hourMs]
+	assert.InDelta(t, 0.75, rebateFee.Rebate, 1e-6)
+	assert.InDelta(t, 150, rebateFee.RebateUSD, 1e-4)
+	assert.InDelta(t, 1.5, rebateFee.Reward, 1e-6)
+	assert.Zero(t, rebateFee.PlatformFee)
+
+	reserveFees, err := testStorage.GetFeeBreakdown(common.FeeGroupReserve, timestamp, timestamp, "d", time.UTC, nil)
+	require.NoError(t, err)
+	dayMs := timeutil.TimeToTimestampMs(timeutil.Midnight(timestamp))
+	require.Len(t, reserveFees, 2)
+	assert.InDelta(t, 1, reserveFees[reserveAddr][dayMs].Burn, 1e-6)
+	assert.InDelta(t, 2, reserveFees[reserveAddr][dayMs].Reward, 1e-6)
+	assert.InDelta(t, 1, reserveFees[reserveAddr][dayMs].Rebate, 1e-6)
+	assert.InDelta(t, 0.5, reserveFees[otherReserve][dayMs].Burn, 1e-6)
+
+	_, err = testStorage.GetFeeBreakdown(common.FeeGroup("token"), timestamp, timestamp, "h", time.UTC, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/pgsql"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+const (
+	saveFiatPricesTemplate = `INSERT INTO "%[1]s" (tradelog_id, chain_id, provider, eth_usd_rate)
+VALUES (
+	UNNEST($1::INTEGER[]),
+	$2,
+	$3,
+	UNNEST($4::FLOAT[])
+) ON CONFLICT (tradelog_id, provider) DO UPDATE SET
+	eth_usd_rate = EXCLUDED.eth_usd_rate,
+	updated_at = now();`
+
+	// updateDefaultFiatPricesTemplate replaces the rates fixed at crawl time with the rates of the provider.
+	updateDefaultFiatPricesTemplate = `UPDATE "%[1]s" AS tradelogs
+SET eth_usd_rate = p.eth_usd_rate, eth_usd_provider = $3
+FROM (SELECT UNNEST($1::INTEGER[]) AS id, UNNEST($4::FLOAT[]) AS eth_usd_rate) AS p
+WHERE tradelogs.id = p.id AND tradelogs.chain_id = $2;`
+)
+
+// ethUSDRateField returns the ETH/USD rate expression of trades of the tradelogs table with given alias. If
+// provider is empty, the rate fixed at crawl time is used. Otherwise provider is appended to query args and
+// the rate is the one backfilled from the provider or the crawl time rate of trades crawled with that
+// provider, trades without a rate from the provider are not priced.
+func ethUSDRateField(alias, provider string, args *[]interface{}) string {
+	if provider == "" {
+		return alias + ".eth_usd_rate"
+	}
+	*args = append(*args, provider)
+	return fmt.Sprintf(`COALESCE(
+		(SELECT fp.eth_usd_rate FROM "%[1]s" AS fp WHERE fp.tradelog_id = %[2]s.id AND fp.provider = $%[3]d),
+		CASE WHEN %[2]s.eth_usd_provider = $%[3]d THEN %[2]s.eth_usd_rate END)`,
+		schema.FiatPriceTableName, alias, len(*args))
+}
+
+// GetTradesToPrice returns id and timestamp of trades in the block or time range, ordered by id. Zero
+// time and block bounds are not filtered.
+func (tldb *TradeLogDB) GetTradesToPrice(fromBlock, toBlock uint64, from, to time.Time) ([]common.TradeFiatPrice, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"from_block", fromBlock, "to_block", toBlock, "from", from, "to", to)
+		args       = []interface{}{tldb.chainID}
+		conditions = []string{"chain_id = $1"}
+		records    []struct {
+			ID        uint64    `db:"id"`
+			Timestamp time.Time `db:"timestamp"`
+		}
+		result = []common.TradeFiatPrice{}
+	)
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if fromBlock != 0 {
+		addCondition("block_number >= $%d", fromBlock)
+	}
+	if toBlock != 0 {
+		addCondition("block_number <= $%d", toBlock)
+	}
+	if !from.IsZero() {
+		addCondition("timestamp >= $%d", from)
+	}
+	if !to.IsZero() {
+		addCondition("timestamp <= $%d", to)
+	}
+	query := fmt.Sprintf(`SELECT id, timestamp FROM "%s" WHERE %s ORDER BY id;`,
+		schema.TradeLogsTableName, strings.Join(conditions, " AND "))
+	logger.Debugw("prepare statement", "stmt", query)
+	if err := tldb.db.Select(&records, query, args...); err != nil {
+		logger.Errorw("failed to get trades to price", "error", err)
+		return nil, err
+	}
+	for _, r := range records {
+		result = append(result, common.TradeFiatPrice{
+			TradelogID: r.ID,
+			Timestamp:  r.Timestamp,
+		})
+	}
+	return result, nil
+}
+
+// SaveFiatPrices stores ETH/USD rates of trades from the provider next to the rates of other providers.
+// If setDefault is true, the rates also replace the ones fixed at crawl time, together with the provider name,
+// and the USD volumes of candles of these trades are refreshed.
+func (tldb *TradeLogDB) SaveFiatPrices(provider string, prices []common.TradeFiatPrice, setDefault bool) (err error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"provider", provider, "prices", len(prices), "set_default", setDefault)
+		ids   []int64
+		rates []float64
+	)
+	if len(prices) == 0 {
+		return nil
+	}
+	for _, p := range prices {
+		ids = append(ids, int64(p.TradelogID))
+		rates = append(rates, p.ETHUSDRate)
+	}
+
+	tx, err := tldb.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer pgsql.CommitOrRollback(tx, logger, &err)
+
+	query := fmt.Sprintf(saveFiatPricesTemplate, schema.FiatPriceTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if _, err = tx.Exec(query, pq.Array(ids), tldb.chainID, provider, pq.Array(rates)); err != nil {
+		logger.Errorw("failed to save fiat prices", "error", err)
+		return err
+	}
+	if !setDefault {
+		return nil
+	}
+	query = fmt.Sprintf(updateDefaultFiatPricesTemplate, schema.TradeLogsTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if _, err = tx.Exec(query, pq.Array(ids), tldb.chainID, provider, pq.Array(rates)); err != nil {
+		logger.Errorw("failed to update default fiat prices", "error", err)
+		return err
+	}
+	candles, err := tldb.candleBucketsOfTrades(tx, "t.id = ANY($2)", pq.Array(ids))
+	if err != nil {
+		logger.Errorw("failed to get candles of priced trade logs", "error", err)
+		return err
+	}
+	err = tldb.refreshCandles(tx, candles)
+	return err
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func TestFiatPrices(t *testing.T) {
+	const dbName = "test_fiat_prices"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		wallet    = ethereum.HexToAddress("0xf1AA99C69715F423086008eB9D06Dc1E35Cc504d")
+		timestamp = time.Date(2020, 7, 10, 9, 30, 0, 0, time.UTC)
+		trades    []common.TradelogV4
+	)
+	for i := 0; i < 2; i++ {
+		trades = append(trades, common.TradelogV4{
+			Timestamp:       timestamp.Add(time.Duration(i) * time.Hour),
+			BlockNumber:     uint64(100 + i),
+			TransactionHash: ethereum.BigToHash(big.NewInt(int64(i + 1))),
+			Version:         3,
+			User: common.KyberUserInfo{
+				UserAddress: ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b"),
+			},
+			TokenInfo: common.TradeTokenInfo{
+				SrcAddress:  ethereum.HexToAddress("0xdd974D5C2e2928deA5F71b9825b8b646686BD200"),
+				DestAddress: blockchain.ETHAddr,
+			},
+			SrcReserveAddress: ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f"),
+			EthAmount:         ethToWei(1),
+			OriginalEthAmount: ethToWei(1),
+			SrcAmount:         ethToWei(100),
+			DestAmount:        ethToWei(1),
+			WalletAddress:     wallet,
+			ETHUSDRate:        200,
+			ETHUSDProvider:    "coingecko",
+			TxDetail: common.TxDetail{
+				GasPrice:       big.NewInt(0),
+				TransactionFee: big.NewInt(0),
+			},
+		})
+	}
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: trades}))
+
+	prices, err := testStorage.GetTradesToPrice(101, 0, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, prices, 1)
+	assert.True(t, trades[1].Timestamp.Equal(prices[0].Timestamp))
+	prices, err = testStorage.GetTradesToPrice(0, 0, timestamp, timestamp.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, prices, 2)
+
+	for i := range prices {
+		prices[i].ETHUSDRate = 250
+	}
+	require.NoError(t, testStorage.SaveFiatPrices("binance", prices[:1], false))
+
+	usdVolume := func(provider string) float64 {
+		volume, err := testStorage.GetAssetVolume(blockchain.ETHAddr, timestamp, timestamp, "d", provider, time.UTC)
+		require.NoError(t, err)
+		require.Len(t, volume, 1)
+		return volume[timeutil.TimeToTimestampMs(timeutil.Midnight(timestamp))].USDAmount
+	}
+	// prices of providers are kept side by side, trades without a rate from the provider are not priced
+	assert.InDelta(t, 400, usdVolume(""), 1e-6)
+	assert.InDelta(t, 400, usdVolume("coingecko"), 1e-6)
+	assert.InDelta(t, 250, usdVolume("binance"), 1e-6)
+
+	require.NoError(t, testStorage.SaveFiatPrices("binance", prices, true))
+	assert.InDelta(t, 500, usdVolume(""), 1e-6)
+	assert.InDelta(t, 500, usdVolume("binance"), 1e-6)
+	// the crawl time rate is replaced, so coingecko has no rate for these trades anymore
+	assert.InDelta(t, 0, usdVolume("coingecko"), 1e-6)
+	// USD volume of candles follows the new default rate
+	hour := timestamp.Truncate(time.Hour)
+	candles, err := testStorage.GetCandles(trades[0].TokenInfo.SrcAddress, "1h", hour, hour)
+	require.NoError(t, err)
+	require.Len(t, candles, 1)
+	assert.InDelta(t, 250, candles[0].VolumeUSD, 1e-4)
+
+	saved, err := testStorage.LoadTradeLogsPage(common.TradeLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, saved, 2)
+	assert.Equal(t, "binance", saved[0].ETHUSDProvider)
+	assert.Equal(t, float64(250), saved[0].ETHUSDRate)
+}
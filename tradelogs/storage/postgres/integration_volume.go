@@ -12,7 +12,7 @@ import (
 )
 
 // GetIntegrationVolume returns integration_volume and non_integration_volume groups by day
-func (tldb *TradeLogDB) GetIntegrationVolume(fromTime, toTime time.Time) (map[uint64]*common.IntegrationVolume, error) {
+func (tldb *TradeLogDB) GetIntegrationVolume(fromTime, toTime time.Time, timezone *time.Location) (map[uint64]*common.IntegrationVolume, error) {
 	logger := tldb.sugar.With("from", fromTime, "to", toTime,
 		"func", caller.GetCurrentFunctionName())
 	integrationQuery := fmt.Sprintf(
@@ -21,18 +21,18 @@ func (tldb *TradeLogDB) GetIntegrationVolume(fromTime, toTime time.Time) (map[ui
 			SUM(eth_amount * (CASE WHEN integration_app != '%[1]s' then 1 else 0 end)) as non_integration_volume,
 			%[2]s AS time
 		FROM "tradelogs" 
-		WHERE timestamp >= $1 and timestamp < $2
+		WHERE timestamp >= $1 and timestamp < $2 AND chain_id = $3
 		GROUP BY time`,
-		appname.KyberSwapAppName, schema.BuildDateTruncField("day", 0))
+		appname.KyberSwapAppName, schema.BuildDateTruncField("day", timezone))
 	logger.Debugw("prepare statement", "stmt", integrationQuery)
-	fromTime = schema.RoundTime(fromTime, "day", 0)
-	toTime = schema.RoundTime(toTime, "day", 0).Add(time.Hour * 24)
+	fromTime = schema.RoundTime(fromTime, "day", timezone)
+	toTime = schema.RoundTime(toTime, "day", timezone).AddDate(0, 0, 1)
 	var records []struct {
 		Timestamp            time.Time `db:"time"`
 		IntegrationVolume    float64   `db:"integration_volume"`
 		NonIntegrationVolume float64   `db:"non_integration_volume"`
 	}
-	err := tldb.db.Select(&records, integrationQuery, fromTime, toTime)
+	err := tldb.db.Select(&records, integrationQuery, fromTime, toTime, tldb.chainID)
 	if err != nil {
 		return nil, err
 	}
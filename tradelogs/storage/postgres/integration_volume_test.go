@@ -28,7 +28,7 @@ func TestTradeLogDB_GetIntegrationVolume(t *testing.T) {
 	}()
 	require.NoError(t, loadTestData(tldb.db, testDataFile))
 
-	integrationVol, err := tldb.GetIntegrationVolume(fromTime, toTime)
+	integrationVol, err := tldb.GetIntegrationVolume(fromTime, toTime, time.UTC)
 	require.NoError(t, err)
 	timeUnix, err := time.Parse(time.RFC3339, timeStamp)
 	assert.NoError(t, err)
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+// marketShareTemplate computes the volume of every split and its weight in the pair volume of its
+// trade, then the share of each group in the pair volume of every period. Filters on reserve types
+// are applied after shares are computed so shares stay relative to the volume of all reserves.
+const marketShareTemplate = `WITH splits AS (
+	SELECT %[1]s AS time, split.src, split.dst, %[2]s AS reserve, COALESCE(reserve.reserve_type, 0) AS reserve_type,
+		split.eth_amount, split.eth_amount * tradelogs.eth_usd_rate AS usd_amount,
+		COUNT(*) OVER (PARTITION BY split.trade_id, split.src, split.dst) AS pair_splits,
+		COALESCE(split.eth_amount / NULLIF(SUM(split.eth_amount)
+			OVER (PARTITION BY split.trade_id, split.src, split.dst), 0), 0) AS weight
+	FROM "split"
+	JOIN "%[3]s" AS tradelogs ON tradelogs.id = split.trade_id
+	JOIN "%[4]s" AS reserve ON reserve.id = split.reserve_id
+	WHERE tradelogs.timestamp >= $1 AND tradelogs.timestamp < $2 AND tradelogs.chain_id = $3 %[5]s
+), shares AS (
+	SELECT time, src, dst, reserve, reserve_type,
+		SUM(eth_amount) AS volume_eth,
+		SUM(usd_amount) AS volume_usd,
+		COALESCE(SUM(eth_amount) / NULLIF(SUM(SUM(eth_amount)) OVER (PARTITION BY time, src, dst), 0), 0) AS share,
+		COUNT(*) AS splits,
+		COUNT(*) FILTER (WHERE pair_splits > 1) AS multi_reserve_splits,
+		COALESCE(AVG(weight) FILTER (WHERE pair_splits > 1), 0) AS avg_split_weight
+	FROM splits
+	GROUP BY time, src, dst, reserve, reserve_type
+)
+SELECT * FROM shares
+%[6]s
+ORDER BY time, src, dst, volume_eth DESC;`
+
+// GetReserveMarketShare returns the share of volume of each token pair captured by reserves or reserve
+// types, grouped by hour or day. Empty tokens or reserve types are not filtered.
+func (tldb *TradeLogDB) GetReserveMarketShare(group common.MarketShareGroup, from, to time.Time, freq string,
+	timezone *time.Location, tokens []ethereum.Address, reserveTypes []uint64) ([]common.ReserveMarketShare, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"from", from, "to", to, "group", group, "freq", freq)
+		timeField      string
+		reserveField   string
+		tokenCondition string
+		typeCondition  string
+		records        []struct {
+			Time               time.Time `db:"time"`
+			Src                string    `db:"src"`
+			Dst                string    `db:"dst"`
+			Reserve            string    `db:"reserve"`
+			ReserveType        uint64    `db:"reserve_type"`
+			VolumeETH          float64   `db:"volume_eth"`
+			VolumeUSD          float64   `db:"volume_usd"`
+			Share              float64   `db:"share"`
+			Splits             uint64    `db:"splits"`
+			MultiReserveSplits uint64    `db:"multi_reserve_splits"`
+			AvgSplitWeight     float64   `db:"avg_split_weight"`
+		}
+		result = []common.ReserveMarketShare{}
+	)
+
+	switch strings.ToLower(freq) {
+	case "h":
+		timeField = schema.BuildDateTruncField("hour", timezone)
+		from = schema.RoundTime(from, "hour", timezone)
+		to = schema.RoundTime(to, "hour", timezone).Add(time.Hour)
+	case "d":
+		timeField = schema.BuildDateTruncField("day", timezone)
+		from = schema.RoundTime(from, "day", timezone)
+		to = schema.RoundTime(to, "day", timezone).AddDate(0, 0, 1)
+	default:
+		return nil, fmt.Errorf("frequency not supported: %v", freq)
+	}
+
+	switch group {
+	case common.MarketShareGroupReserve:
+		reserveField = "reserve.address"
+	case common.MarketShareGroupReserveType:
+		reserveField = "''"
+	default:
+		return nil, fmt.Errorf("market share group not supported: %v", group)
+	}
+
+	args := []interface{}{from, to, tldb.chainID}
+	if len(tokens) != 0 {
+		args = append(args, addressesToHex(tokens))
+		tokenCondition = fmt.Sprintf("AND (split.src = ANY($%[1]d) OR split.dst = ANY($%[1]d))", len(args))
+	}
+	if len(reserveTypes) != 0 {
+		types := make([]int64, len(reserveTypes))
+		for i, t := range reserveTypes {
+			types[i] = int64(t)
+		}
+		args = append(args, pq.Array(types))
+		typeCondition = fmt.Sprintf("WHERE reserve_type = ANY($%d)", len(args))
+	}
+
+	query := fmt.Sprintf(marketShareTemplate, timeField, reserveField, schema.TradeLogsTableName,
+		schema.ReserveTableName, tokenCondition, typeCondition)
+	logger.Debugw("prepare statement", "stmt", query)
+	if err := tldb.db.Select(&records, query, args...); err != nil {
+		logger.Errorw("failed to get reserve market share", "error", err)
+		return nil, err
+	}
+	for _, r := range records {
+		result = append(result, common.ReserveMarketShare{
+			Timestamp:          timeutil.TimeToTimestampMs(r.Time),
+			Src:                ethereum.HexToAddress(r.Src),
+			Dst:                ethereum.HexToAddress(r.Dst),
+			Reserve:            ethereum.HexToAddress(r.Reserve),
+			ReserveType:        r.ReserveType,
+			VolumeETH:          r.VolumeETH,
+			VolumeUSD:          r.VolumeUSD,
+			Share:              r.Share,
+			Splits:             r.Splits,
+			MultiReserveSplits: r.MultiReserveSplits,
+			AvgSplitWeight:     r.AvgSplitWeight,
+		})
+	}
+	return result, nil
+}
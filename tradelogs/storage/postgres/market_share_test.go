@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func TestGetReserveMarketShare(t *testing.T) {
+	const dbName = "test_reserve_market_share"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		knc       = ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200")
+		reserveA  = ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f")
+		reserveB  = ethereum.HexToAddress("0x21433dec9cb634a23c6a4bbcce08c83f5ac2ec18")
+		timestamp = time.Date(2020, 7, 10, 9, 30, 0, 0, time.UTC)
+		hourMs    = timeutil.TimeToTimestampMs(time.Date(2020, 7, 10, 9, 0, 0, 0, time.UTC))
+	)
+	require.NoError(t, testStorage.saveReserve([]common.Reserve{
+		{Address: reserveA, ReserveID: [32]byte{0xaa}, ReserveType: 1, BlockNumber: 100},
+		{Address: reserveB, ReserveID: [32]byte{0xbb}, ReserveType: 2, BlockNumber: 100},
+	}))
+	newTrade := func(block uint64, txHash string, reserveIDs [][32]byte, kncAmounts []float64) common.TradelogV4 {
+		trade := common.TradelogV4{
+			Timestamp:       timestamp,
+			BlockNumber:     block,
+			TransactionHash: ethereum.HexToHash(txHash),
+			Version:         4,
+			User: common.KyberUserInfo{
+				UserAddress: ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b"),
+			},
+			TokenInfo: common.TradeTokenInfo{
+				SrcAddress:  knc,
+				DestAddress: blockchain.ETHAddr,
+			},
+			EthAmount:         ethToWei(10),
+			OriginalEthAmount: ethToWei(10),
+			SrcAmount:         ethToWei(1000),
+			DestAmount:        ethToWei(10),
+			ETHUSDRate:        200,
+			TxDetail: common.TxDetail{
+				GasPrice:       big.NewInt(0),
+				TransactionFee: big.NewInt(0),
+			},
+			T2EReserves: reserveIDs,
+		}
+		for _, amount := range kncAmounts {
+			trade.T2ESrcAmount = append(trade.T2ESrcAmount, ethToWei(amount))
+			trade.T2ERates = append(trade.T2ERates, ethToWei(0.01))
+		}
+		return trade
+	}
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: []common.TradelogV4{
+		newTrade(200, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff01",
+			[][32]byte{{0xaa}, {0xbb}}, []float64{600, 400}),
+		newTrade(201, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff02",
+			[][32]byte{{0xaa}}, []float64{1000}),
+	}}))
+
+	shares, err := testStorage.GetReserveMarketShare(common.MarketShareGroupReserve, timestamp, timestamp, "h", time.UTC, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+	for _, share := range shares {
+		assert.Equal(t, hourMs, share.Timestamp)
+		assert.Equal(t, knc, share.Src)
+		assert.Equal(t, blockchain.ETHAddr, share.Dst)
+	}
+	assert.Equal(t, reserveA, shares[0].Reserve)
+	assert.Equal(t, uint64(1), shares[0].ReserveType)
+	assert.InDelta(t, 16, shares[0].VolumeETH, 1e-4)
+	assert.InDelta(t, 3200, shares[0].VolumeUSD, 1e-1)
+	assert.InDelta(t, 0.8, shares[0].Share, 1e-6)
+	assert.Equal(t, uint64(2), shares[0].Splits)
+	assert.Equal(t, uint64(1), shares[0].MultiReserveSplits)
+	assert.InDelta(t, 0.6, shares[0].AvgSplitWeight, 1e-6)
+	assert.Equal(t, reserveB, shares[1].Reserve)
+	assert.InDelta(t, 0.2, shares[1].Share, 1e-6)
+	assert.Equal(t, uint64(1), shares[1].MultiReserveSplits)
+	assert.InDelta(t, 0.4, shares[1].AvgSplitWeight, 1e-6)
+
+	// shares of filtered reserve types are still relative to the volume of all reserves
+	shares, err = testStorage.GetReserveMarketShare(common.MarketShareGroupReserveType, timestamp, timestamp, "d", time.UTC,
+		[]ethereum.Address{knc}, []uint64{2})
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	assert.Equal(t, ethereum.Address{}, shares[0].Reserve)
+	assert.Equal(t, uint64(2), shares[0].ReserveType)
+	assert.InDelta(t, 0.2, shares[0].Share, 1e-6)
+
+	shares, err = testStorage.GetReserveMarketShare(common.MarketShareGroupReserve, timestamp, timestamp, "h", time.UTC,
+		[]ethereum.Address{reserveA}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, shares)
+
+	_, err = testStorage.GetReserveMarketShare(common.MarketShareGroup("token"), timestamp, timestamp, "h", time.UTC, nil, nil)
+	assert.Error(t, err)
+}
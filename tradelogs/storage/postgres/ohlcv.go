@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/pgsql"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+const (
+	// lockCandlesQuery locks candles until the end of the transaction, in order of the given keys.
+	lockCandlesQuery = `SELECT pg_advisory_xact_lock(hashtext(key)) FROM UNNEST($1::TEXT[]) AS key;`
+
+	deleteCandlesTemplate = `DELETE FROM "%[1]s" AS o
+	USING UNNEST($2::TEXT[], $3::TEXT[], $4::BIGINT[]) AS b(token, period, start_ms)
+	WHERE o.chain_id = $1 AND o.token = b.token AND o.period = b.period
+	AND o.bucket = to_timestamp(b.start_ms / 1000.0);`
+
+	// insertCandlesTemplate aggregates splits between ETH and the token in each bucket, a split is a
+	// fill at price eth_amount / token amount. Open and close are the first and last fills ordered by
+	// block number, log index of the trade and index of the split.
+	insertCandlesTemplate = `WITH buckets AS (
+		SELECT token, period, to_timestamp(start_ms / 1000.0) AS bucket, to_timestamp(end_ms / 1000.0) AS bucket_end
+		FROM UNNEST($3::TEXT[], $4::TEXT[], $5::BIGINT[], $6::BIGINT[]) AS b(token, period, start_ms, end_ms)
+	), fills AS (
+		SELECT b.token, b.period, b.bucket, t.id AS trade_id, t.block_number, t.index AS trade_index,
+			s.index AS split_index, t.eth_usd_rate, s.eth_amount AS volume_eth,
+			CASE WHEN s.src = $2 THEN s.dst_amount ELSE s.src_amount END AS volume_token
+		FROM buckets AS b
+		JOIN "%[2]s" AS t ON t.chain_id = $1 AND t.timestamp >= b.bucket AND t.timestamp < b.bucket_end
+		JOIN "split" AS s ON s.trade_id = t.id
+		WHERE (s.src = $2 AND s.dst = b.token) OR (s.dst = $2 AND s.src = b.token)
+	), prices AS (
+		SELECT *, volume_eth / volume_token AS price_eth, volume_eth / volume_token * eth_usd_rate AS price_usd
+		FROM fills
+		WHERE volume_eth > 0 AND volume_token > 0
+	)
+	INSERT INTO "%[1]s" (chain_id, token, period, bucket,
+		open_eth, high_eth, low_eth, close_eth, open_usd, high_usd, low_usd, close_usd,
+		volume_token, volume_eth, volume_usd, trade_count)
+	SELECT $1, token, period, bucket,
+		(ARRAY_AGG(price_eth ORDER BY block_number, trade_index, split_index))[1],
+		MAX(price_eth),
+		MIN(price_eth),
+		(ARRAY_AGG(price_eth ORDER BY block_number DESC, trade_index DESC, split_index DESC))[1],
+		(ARRAY_AGG(price_usd ORDER BY block_number, trade_index, split_index))[1],
+		MAX(price_usd),
+		MIN(price_usd),
+		(ARRAY_AGG(price_usd ORDER BY block_number DESC, trade_index DESC, split_index DESC))[1],
+		SUM(volume_token),
+		SUM(volume_eth),
+		SUM(volume_eth * eth_usd_rate),
+		COUNT(DISTINCT trade_id)
+	FROM prices
+	GROUP BY token, period, bucket
+	ON CONFLICT (chain_id, token, period, bucket) DO UPDATE SET
+		open_eth = EXCLUDED.open_eth, high_eth = EXCLUDED.high_eth,
+		low_eth = EXCLUDED.low_eth, close_eth = EXCLUDED.close_eth,
+		open_usd = EXCLUDED.open_usd, high_usd = EXCLUDED.high_usd,
+		low_usd = EXCLUDED.low_usd, close_usd = EXCLUDED.close_usd,
+		volume_token = EXCLUDED.volume_token, volume_eth = EXCLUDED.volume_eth,
+		volume_usd = EXCLUDED.volume_usd, trade_count = EXCLUDED.trade_count;`
+)
+
+// candleBucket is a candle of a token.
+type candleBucket struct {
+	token  string
+	period string
+	bucket time.Time
+}
+
+// candleBuckets is the set of candles affected by saved or deleted trade logs.
+type candleBuckets map[candleBucket]struct{}
+
+// add adds candles of all intervals containing timestamp of the token, ETH has no candle.
+func (cb candleBuckets) add(token ethereum.Address, timestamp time.Time) {
+	if blockchain.IsZeroAddress(token) || token == blockchain.ETHAddr {
+		return
+	}
+	for period, interval := range common.CandleIntervals {
+		cb[candleBucket{
+			token:  token.Hex(),
+			period: period,
+			bucket: timestamp.UTC().Truncate(interval),
+		}] = struct{}{}
+	}
+}
+
+// sorted returns the candles ordered by token, period and bucket.
+func (cb candleBuckets) sorted() []candleBucket {
+	var result []candleBucket
+	for b := range cb {
+		result = append(result, b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].token != result[j].token {
+			return result[i].token < result[j].token
+		}
+		if result[i].period != result[j].period {
+			return result[i].period < result[j].period
+		}
+		return result[i].bucket.Before(result[j].bucket)
+	})
+	return result
+}
+
+// refreshCandles recomputes the candles from all splits of their buckets, so saving the same trade
+// logs again does not change the result. Candles without any split left are removed.
+// Trade logs are saved concurrently, so candles are locked until the transaction ends: a transaction
+// refreshing the same candles waits for it to commit and sees its splits. Candles are locked in the
+// same order by all transactions to not deadlock.
+func (tldb *TradeLogDB) refreshCandles(tx *sqlx.Tx, buckets candleBuckets) error {
+	var (
+		logger          = tldb.sugar.With("func", caller.GetCurrentFunctionName())
+		keys            []string
+		tokens, periods []string
+		starts, ends    []int64
+	)
+	if len(buckets) == 0 {
+		return nil
+	}
+	for _, b := range buckets.sorted() {
+		keys = append(keys, fmt.Sprintf("%s|%d|%s|%s|%d", schema.OHLCVTableName, tldb.chainID, b.token, b.period,
+			timeutil.TimeToTimestampMs(b.bucket)))
+		tokens = append(tokens, b.token)
+		periods = append(periods, b.period)
+		starts = append(starts, int64(timeutil.TimeToTimestampMs(b.bucket)))
+		ends = append(ends, int64(timeutil.TimeToTimestampMs(b.bucket.Add(common.CandleIntervals[b.period]))))
+	}
+	if _, err := tx.Exec(lockCandlesQuery, pq.StringArray(keys)); err != nil {
+		logger.Errorw("failed to lock candles", "error", err)
+		return err
+	}
+	deleteQuery := fmt.Sprintf(deleteCandlesTemplate, schema.OHLCVTableName)
+	if _, err := tx.Exec(deleteQuery, tldb.chainID, pq.StringArray(tokens), pq.StringArray(periods),
+		pq.Array(starts)); err != nil {
+		logger.Errorw("failed to delete candles", "error", err)
+		return err
+	}
+	insertQuery := fmt.Sprintf(insertCandlesTemplate, schema.OHLCVTableName, schema.TradeLogsTableName)
+	if _, err := tx.Exec(insertQuery, tldb.chainID, blockchain.ETHAddr.Hex(), pq.StringArray(tokens),
+		pq.StringArray(periods), pq.Array(starts), pq.Array(ends)); err != nil {
+		logger.Errorw("failed to insert candles", "error", err)
+		return err
+	}
+	logger.Debugw("refreshed candles", "candles", len(buckets))
+	return nil
+}
+
+// candleBucketsOfTrades returns candles of splits of trade logs matching the condition, which
+// takes chain ID as $1.
+func (tldb *TradeLogDB) candleBucketsOfTrades(tx *sqlx.Tx, condition string, args ...interface{}) (candleBuckets, error) {
+	var (
+		records []struct {
+			Timestamp time.Time `db:"timestamp"`
+			Src       string    `db:"src"`
+			Dst       string    `db:"dst"`
+		}
+		buckets = make(candleBuckets)
+	)
+	query := fmt.Sprintf(`SELECT DISTINCT t.timestamp, s.src, s.dst
+	FROM "%s" AS t JOIN "split" AS s ON s.trade_id = t.id
+	WHERE t.chain_id = $1 AND %s;`, schema.TradeLogsTableName, condition)
+	if err := tx.Select(&records, query, append([]interface{}{tldb.chainID}, args...)...); err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		buckets.add(ethereum.HexToAddress(r.Src), r.Timestamp)
+		buckets.add(ethereum.HexToAddress(r.Dst), r.Timestamp)
+	}
+	return buckets, nil
+}
+
+// RebuildCandles recomputes candles of all trade logs in the time range, it is used to build
+// candles of trade logs saved before candles are available.
+func (tldb *TradeLogDB) RebuildCandles(from, to time.Time) (err error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "from", from, "to", to)
+	)
+	tx, err := tldb.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer pgsql.CommitOrRollback(tx, logger, &err)
+	buckets, err := tldb.candleBucketsOfTrades(tx, "t.timestamp >= $2 AND t.timestamp < $3", from, to)
+	if err != nil {
+		logger.Errorw("failed to get candles of trade logs", "error", err)
+		return err
+	}
+	if err = tldb.refreshCandles(tx, buckets); err != nil {
+		return err
+	}
+	logger.Infow("rebuilt candles", "candles", len(buckets))
+	return nil
+}
+
+// GetCandles returns candles of the token at the interval between from and to, ordered by time.
+func (tldb *TradeLogDB) GetCandles(token ethereum.Address, interval string, from, to time.Time) ([]common.Candle, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"token", token.Hex(), "interval", interval, "from", from, "to", to)
+		records []struct {
+			Bucket time.Time `db:"bucket"`
+			common.Candle
+		}
+		result = []common.Candle{}
+	)
+	duration, ok := common.CandleIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("interval not supported: %v", interval)
+	}
+	query := fmt.Sprintf(`SELECT bucket, open_eth, high_eth, low_eth, close_eth,
+		open_usd, high_usd, low_usd, close_usd, volume_token, volume_eth, volume_usd, trade_count
+	FROM "%s"
+	WHERE chain_id = $1 AND token = $2 AND period = $3 AND bucket >= $4 AND bucket <= $5
+	ORDER BY bucket;`, schema.OHLCVTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if err := tldb.db.Select(&records, query, tldb.chainID, token.Hex(), interval,
+		from.UTC().Truncate(duration), to); err != nil {
+		logger.Errorw("failed to get candles", "error", err)
+		return nil, err
+	}
+	for _, r := range records {
+		candle := r.Candle
+		candle.Timestamp = timeutil.TimeToTimestampMs(r.Bucket)
+		result = append(result, candle)
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// newCandleTestTrade returns a trade of srcAmount of token to ethAmount ETH.
+func newCandleTestTrade(token ethereum.Address, block uint64, txHash string, timestamp time.Time, srcAmount, ethAmount float64) common.TradelogV4 {
+	return common.TradelogV4{
+		Timestamp:       timestamp,
+		BlockNumber:     block,
+		TransactionHash: ethereum.HexToHash(txHash),
+		Version:         3,
+		User: common.KyberUserInfo{
+			UserAddress: ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b"),
+		},
+		TokenInfo: common.TradeTokenInfo{
+			SrcAddress:  token,
+			DestAddress: blockchain.ETHAddr,
+		},
+		SrcReserveAddress: ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f"),
+		EthAmount:         ethToWei(ethAmount),
+		OriginalEthAmount: ethToWei(ethAmount),
+		SrcAmount:         ethToWei(srcAmount),
+		DestAmount:        ethToWei(ethAmount),
+		ETHUSDRate:        200,
+		TxDetail: common.TxDetail{
+			GasPrice:       big.NewInt(0),
+			TransactionFee: big.NewInt(0),
+		},
+	}
+}
+
+func TestCandles(t *testing.T) {
+	const dbName = "test_candles"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		knc    = ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200")
+		minute = time.Date(2020, 7, 10, 9, 30, 0, 0, time.UTC)
+	)
+	first := newCandleTestTrade(knc, 100, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff01",
+		minute.Add(10*time.Second), 1000, 2)
+	second := newCandleTestTrade(knc, 101, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff02",
+		minute.Add(40*time.Second), 500, 1.5)
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: []common.TradelogV4{second}}))
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: []common.TradelogV4{first}}))
+	// saving the same trade logs again does not change candles
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: []common.TradelogV4{first}}))
+
+	for interval := range common.CandleIntervals {
+		candles, err := testStorage.GetCandles(knc, interval, minute, minute.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, candles, 1, interval)
+		candle := candles[0]
+		assert.Equal(t, timeutil.TimeToTimestampMs(minute.Truncate(common.CandleIntervals[interval])), candle.Timestamp)
+		assert.InDelta(t, 0.002, candle.OpenETH, 1e-9)
+		assert.InDelta(t, 0.003, candle.HighETH, 1e-9)
+		assert.InDelta(t, 0.002, candle.LowETH, 1e-9)
+		assert.InDelta(t, 0.003, candle.CloseETH, 1e-9)
+		assert.InDelta(t, 0.6, candle.CloseUSD, 1e-6)
+		assert.InDelta(t, 1500, candle.VolumeToken, 1e-6)
+		assert.InDelta(t, 3.5, candle.VolumeETH, 1e-6)
+		assert.InDelta(t, 700, candle.VolumeUSD, 1e-4)
+		assert.Equal(t, uint64(2), candle.TradeCount)
+	}
+
+	require.NoError(t, testStorage.DeleteTradeLogsFromBlock(101))
+	candles, err := testStorage.GetCandles(knc, "1m", minute, minute)
+	require.NoError(t, err)
+	require.Len(t, candles, 1)
+	assert.InDelta(t, 0.002, candles[0].CloseETH, 1e-9)
+	assert.Equal(t, uint64(1), candles[0].TradeCount)
+
+	require.NoError(t, testStorage.DeleteTradeLogsFromBlock(100))
+	candles, err = testStorage.GetCandles(knc, "1m", minute, minute)
+	require.NoError(t, err)
+	assert.Empty(t, candles)
+
+	_, err = testStorage.GetCandles(knc, "5m", minute, minute)
+	assert.Error(t, err)
+}
+
+func TestCandlesConcurrentSaves(t *testing.T) {
+	const dbName = "test_candles_concurrent"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		knc  = ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200")
+		hour = time.Date(2020, 7, 10, 9, 0, 0, 0, time.UTC)
+		wg   sync.WaitGroup
+		errs = make([]error, 2)
+	)
+	// block ranges in the same hour saved concurrently, as workers do
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			trade := newCandleTestTrade(knc, uint64(100+i),
+				fmt.Sprintf("0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff%02x", i),
+				hour.Add(time.Duration(i)*10*time.Minute), 1000, 2)
+			// different users, saves are not serialized by locks of users
+			trade.User.UserAddress = ethereum.BigToAddress(big.NewInt(int64(i + 1)))
+			errs[i] = testStorage.SaveTradeLogs(&common.CrawlResult{Trades: []common.TradelogV4{trade}})
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	candles, err := testStorage.GetCandles(knc, "1h", hour, hour)
+	require.NoError(t, err)
+	require.Len(t, candles, 1)
+	assert.Equal(t, uint64(2), candles[0].TradeCount)
+	assert.InDelta(t, 4, candles[0].VolumeETH, 1e-6)
+}
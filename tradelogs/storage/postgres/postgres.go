@@ -14,6 +14,7 @@ import (
 
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
 	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
 )
@@ -24,13 +25,18 @@ type TradeLogDB struct {
 	db                   *sqlx.DB
 	tokenAmountFormatter blockchain.TokenAmountFormatterInterface
 
+	// chain ID is stored on every row so trade logs of multiple chains can share a database,
+	// all queries are scoped to this chain.
+	chainID uint64
 	// used for calculate burn amount
 	// as different environment have different knc address
 	kncAddr ethereum.Address
+	// trades between native token and its wrapped token are not accounted in volume
+	wrappedNativeToken ethereum.Address
 }
 
 //NewTradeLogDB create a new instance of TradeLogDB
-func NewTradeLogDB(sugar *zap.SugaredLogger, db *sqlx.DB, tokenAmountFormatter blockchain.TokenAmountFormatterInterface, kncAddr ethereum.Address) (*TradeLogDB, error) {
+func NewTradeLogDB(sugar *zap.SugaredLogger, db *sqlx.DB, tokenAmountFormatter blockchain.TokenAmountFormatterInterface, chain deployment.Chain) (*TradeLogDB, error) {
 	var logger = sugar.With("func", caller.GetCurrentFunctionName())
 	var err error
 	logger.Debug("initializing database schema")
@@ -43,7 +49,9 @@ func NewTradeLogDB(sugar *zap.SugaredLogger, db *sqlx.DB, tokenAmountFormatter b
 		sugar:                sugar,
 		db:                   db,
 		tokenAmountFormatter: tokenAmountFormatter,
-		kncAddr:              kncAddr,
+		chainID:              chain.ID,
+		kncAddr:              chain.KNCToken,
+		wrappedNativeToken:   chain.WrappedNativeToken,
 	}, err
 }
 
@@ -53,10 +61,10 @@ func (tldb *TradeLogDB) LastBlock() (int64, error) {
 		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName())
 		result sql.NullInt64
 	)
-	stmt := fmt.Sprintf(`SELECT MAX("block_number") FROM "%v"`, schema.TradeLogsTableName)
+	stmt := fmt.Sprintf(`SELECT MAX("block_number") FROM "%v" WHERE chain_id = $1`, schema.TradeLogsTableName)
 	logger = logger.With("query", stmt)
 	logger.Debug("Start query")
-	err := tldb.db.Get(&result, stmt)
+	err := tldb.db.Get(&result, stmt, tldb.chainID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, nil
@@ -74,6 +82,7 @@ type tradeLogDBData struct {
 	EthAmount         float64         `db:"eth_amount"`
 	OriginalEthAmount float64         `db:"original_eth_amount"`
 	EthUsdRate        float64         `db:"eth_usd_rate"`
+	EthUsdProvider    string          `db:"eth_usd_provider"`
 	UserAddress       pq.StringArray  `db:"user_address"`
 	SrcAddress        pq.StringArray  `db:"src_address"`
 	DstAddress        pq.StringArray  `db:"dst_address"`
@@ -269,6 +278,7 @@ func (tldb *TradeLogDB) tradeLogFromDBData(r tradeLogDBData) (common.TradelogV4,
 		WalletAddress:   ethereum.HexToAddress(r.WalletAddress[0]),
 		ReceiverAddress: ethereum.HexToAddress(r.ReceiverAddr),
 		ETHUSDRate:      r.EthUsdRate,
+		ETHUSDProvider:  r.EthUsdProvider,
 		TxDetail: common.TxDetail{
 			GasUsed:        r.GasUsed,
 			GasPrice:       gasPriceInWei,
@@ -289,7 +299,7 @@ func (tldb *TradeLogDB) LoadTradeLogsByTxHash(tx ethereum.Hash) ([]common.Tradel
 		queryResult []tradeLogDBData
 		result      = make([]common.TradelogV4, 0)
 	)
-	err := tldb.db.Select(&queryResult, selectTradeLogsWithTxHashQuery, tx.Hex())
+	err := tldb.db.Select(&queryResult, selectTradeLogsWithTxHashQuery, tx.Hex(), tldb.chainID)
 	if err != nil {
 		logger.Errorw("failed to get tradelog from database", "error", err)
 		return nil, err
@@ -318,7 +328,7 @@ func (tldb *TradeLogDB) LoadTradeLogs(from, to time.Time) ([]common.TradelogV4,
 		queryResult []tradeLogDBData
 		result      = make([]common.TradelogV4, 0)
 	)
-	err := tldb.db.Select(&queryResult, selectTradeLogsQuery, from, to)
+	err := tldb.db.Select(&queryResult, selectTradeLogsQuery, from, to, tldb.chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -340,36 +350,43 @@ func (tldb *TradeLogDB) LoadTradeLogs(from, to time.Time) ([]common.TradelogV4,
 }
 
 const insertionAddressTemplate = `INSERT INTO %[1]s(
-	address
+	address,
+	chain_id
 ) VALUES(
-	unnest($1::TEXT[])
+	unnest($1::TEXT[]),
+	$2
 )
-ON CONFLICT ON CONSTRAINT %[1]s_address_key DO NOTHING`
+ON CONFLICT (chain_id, address) DO NOTHING`
 
 const insertionWalletTemplate string = `
 INSERT INTO wallet(
 	address,
-	name
+	name,
+	chain_id
 ) VALUES (
 	:wallet_address,
-	:wallet_name
+	:wallet_name,
+	:chain_id
 )
-ON CONFLICT (address) 
+ON CONFLICT (chain_id, address) 
 DO NOTHING;`
 
 const insertionUserTemplate string = `
 INSERT INTO users(
 	address,
-	timestamp
+	timestamp,
+	chain_id
 ) VALUES (
 	:user_address,
-	:timestamp
+	:timestamp,
+	:chain_id
 )
-ON CONFLICT (address) 
+ON CONFLICT (chain_id, address) 
 DO NOTHING;`
 
 const selectTradeLogsQuery = `
 SELECT a.id, a.timestamp AS timestamp, a.block_number, a.eth_amount, original_eth_amount, eth_usd_rate, 
+COALESCE(a.eth_usd_provider, '') AS eth_usd_provider,
 ARRAY_AGG(d.address) AS user_address,
 ARRAY_AGG(e.address) AS src_address, 
 ARRAY_AGG(f.address) AS dst_address,
@@ -408,7 +425,7 @@ INNER JOIN wallet as w on a.wallet_address_id = w.id
 LEFT JOIN fee ON fee.trade_id = a.id
 LEFT JOIN split ON split.trade_id = a.id
 INNER JOIN reserve sr ON sr.id = split.reserve_id
-WHERE a.timestamp >= $1 and a.timestamp <= $2
+WHERE a.timestamp >= $1 and a.timestamp <= $2 AND a.chain_id = $3
 GROUP BY a.id;
 `
 
@@ -419,6 +436,7 @@ a.block_number,
 a.eth_amount, 
 original_eth_amount, 
 eth_usd_rate, 
+COALESCE(a.eth_usd_provider, '') AS eth_usd_provider,
 ARRAY_AGG(d.address) AS user_address,
 ARRAY_AGG(e.address) AS src_address, 
 ARRAY_AGG(f.address) AS dst_address,
@@ -464,6 +482,6 @@ INNER JOIN wallet AS w ON a.wallet_address_id = w.id
 LEFT JOIN fee ON fee.trade_id = a.id
 LEFT JOIN split ON split.trade_id = a.id
 INNER JOIN reserve sr ON sr.id = split.reserve_id
-WHERE a.tx_hash=$1
+WHERE a.tx_hash=$1 AND a.chain_id = $2
 GROUP BY a.id;
 `
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
 	ethereum "github.com/ethereum/go-ethereum/common"
 	"github.com/jmoiron/sqlx"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
 	"github.com/KyberNetwork/reserve-stats/lib/testutil"
 	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
@@ -40,7 +42,7 @@ func newTestTradeLogPostgresql(dbName string) (*TradeLogDB, error) {
 		return nil, err
 	}
 	tokenAmountFormatter := blockchain.NewMockTokenAmountFormatter()
-	storage, err := NewTradeLogDB(sugar, db, tokenAmountFormatter, blockchain.KNCAddr)
+	storage, err := NewTradeLogDB(sugar, db, tokenAmountFormatter, deployment.Chains[deployment.Production])
 	if err != nil {
 		return nil, err
 	}
@@ -202,7 +204,7 @@ func TestSaveTradeLogs_Overwrite(t *testing.T) {
 	require.Equal(t, len(tls), 1)
 	assert.Equal(t, tradelog2.EthAmount, tls[0].EthAmount)
 
-	tradeSummary, err := testStorage.GetTradeSummary(timestamp, timestamp, 0)
+	tradeSummary, err := testStorage.GetTradeSummary(timestamp, timestamp, time.UTC)
 	require.NoError(t, err)
 	assert.Equal(t, uint64(1), tradeSummary[timestampMs].NewUniqueAddresses)
 }
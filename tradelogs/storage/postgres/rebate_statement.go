@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// getRebateWalletReserves returns block ranges of reserves which set the rebate wallet. A range
+// starts at the block the wallet is set, by adding the reserve or by RebateWalletSet event, and
+// ends before the block the reserve sets another wallet.
+func (tldb *TradeLogDB) getRebateWalletReserves(wallet ethereum.Address) ([]common.RebateWalletReserve, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "rebate_wallet", wallet.Hex())
+		rows   []struct {
+			Address      string `db:"address"`
+			ReserveID    string `db:"reserve_id"`
+			RebateWallet string `db:"rebate_wallet"`
+			BlockNumber  uint64 `db:"block_number"`
+		}
+		result []common.RebateWalletReserve
+	)
+	query := `SELECT address, reserve_id, COALESCE(rebate_wallet, '') AS rebate_wallet,
+	COALESCE(block_number, 0) AS block_number
+	FROM reserve
+	WHERE chain_id = $1 AND reserve_id IN (
+		SELECT reserve_id FROM reserve
+		WHERE chain_id = $1 AND rebate_wallet = $2 AND COALESCE(reserve_id, '') != ''
+	)
+	ORDER BY reserve_id, block_number;`
+	logger.Debugw("getting reserves of rebate wallet", "query", query)
+	if err := tldb.db.Select(&rows, query, tldb.chainID, wallet.Hex()); err != nil {
+		logger.Errorw("failed to get reserves of rebate wallet", "error", err)
+		return nil, err
+	}
+
+	var current *common.RebateWalletReserve
+	closeRange := func(block uint64) {
+		if current != nil {
+			current.ToBlock = block - 1
+			result = append(result, *current)
+			current = nil
+		}
+	}
+	for i, r := range rows {
+		var (
+			addr      = ethereum.HexToAddress(r.Address)
+			reserveID = ethereum.HexToHash(r.ReserveID)
+		)
+		if i > 0 && rows[i-1].ReserveID != r.ReserveID && current != nil {
+			// the previous reserve still uses the wallet
+			result = append(result, *current)
+			current = nil
+		}
+		if ethereum.HexToAddress(r.RebateWallet) != wallet {
+			closeRange(r.BlockNumber)
+			continue
+		}
+		if current != nil && current.Address == addr {
+			continue
+		}
+		closeRange(r.BlockNumber)
+		current = &common.RebateWalletReserve{
+			Address:   addr,
+			ReserveID: reserveID,
+			FromBlock: r.BlockNumber,
+		}
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+	return result, nil
+}
+
+// GetRebateStatement returns rebates paid to the rebate wallet by Katalyst trades in the time range,
+// ordered by block number and log index. Reserves of each line item are the reserves which set the
+// wallet at the block of the trade.
+func (tldb *TradeLogDB) GetRebateStatement(wallet ethereum.Address, from, to time.Time) (common.RebateStatement, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"rebate_wallet", wallet.Hex(), "from", from, "to", to)
+		statement = common.RebateStatement{
+			RebateWallet: wallet,
+			From:         timeutil.TimeToTimestampMs(from),
+			To:           timeutil.TimeToTimestampMs(to),
+			Reserves:     []common.RebateWalletReserve{},
+			Items:        []common.RebateLineItem{},
+		}
+		records []struct {
+			Timestamp   time.Time `db:"timestamp"`
+			BlockNumber uint64    `db:"block_number"`
+			TxHash      string    `db:"tx_hash"`
+			TradeIndex  uint      `db:"trade_index"`
+			FeeIndex    uint      `db:"fee_index"`
+			PercentBps  uint64    `db:"percent"`
+			ETHAmount   float64   `db:"eth_amount"`
+			USDAmount   float64   `db:"usd_amount"`
+		}
+	)
+
+	reserves, err := tldb.getRebateWalletReserves(wallet)
+	if err != nil {
+		return statement, err
+	}
+	if len(reserves) != 0 {
+		statement.Reserves = reserves
+	}
+
+	query := fmt.Sprintf(`SELECT tradelogs.timestamp, tradelogs.block_number, tradelogs.tx_hash,
+		tradelogs.index AS trade_index, fee.index AS fee_index, p.percent::BIGINT AS percent,
+		fee.rebate * p.percent::FLOAT / 10000 AS eth_amount,
+		fee.rebate * p.percent::FLOAT / 10000 * tradelogs.eth_usd_rate AS usd_amount
+	FROM "fee"
+	JOIN "tradelogs" ON tradelogs.id = fee.trade_id, %s
+	WHERE tradelogs.timestamp >= $1 AND tradelogs.timestamp <= $2 AND tradelogs.chain_id = $3
+	AND tradelogs.version = 4 AND w.wallet = $4 AND p.i = w.i
+	ORDER BY tradelogs.block_number, tradelogs.index, fee.index, w.i;`, fmt.Sprintf(rebateWalletsFrom, "fee"))
+	logger.Debugw("prepare statement", "stmt", query)
+	if err = tldb.db.Select(&records, query, from, to, tldb.chainID, wallet.Hex()); err != nil {
+		logger.Errorw("failed to get rebates of wallet", "error", err)
+		return statement, err
+	}
+
+	for _, r := range records {
+		item := common.RebateLineItem{
+			Timestamp:   timeutil.TimeToTimestampMs(r.Timestamp),
+			BlockNumber: r.BlockNumber,
+			TxHash:      ethereum.HexToHash(r.TxHash),
+			TradeIndex:  r.TradeIndex,
+			FeeIndex:    r.FeeIndex,
+			Reserves:    []ethereum.Address{},
+			PercentBps:  r.PercentBps,
+			ETHAmount:   r.ETHAmount,
+			USDAmount:   r.USDAmount,
+		}
+		for _, reserve := range reserves {
+			if reserve.FromBlock <= r.BlockNumber && (reserve.ToBlock == 0 || r.BlockNumber <= reserve.ToBlock) {
+				item.Reserves = append(item.Reserves, reserve.Address)
+			}
+		}
+		statement.Items = append(statement.Items, item)
+		statement.TotalETH += r.ETHAmount
+		statement.TotalUSD += r.USDAmount
+	}
+	return statement, nil
+}
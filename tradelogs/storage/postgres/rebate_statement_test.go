@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func TestGetRebateStatement(t *testing.T) {
+	const dbName = "test_rebate_statement"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		wallet      = ethereum.HexToAddress("0x4f32bbe8dfc9efd54345fc936f9fefa1ec8e8e83")
+		otherWallet = ethereum.HexToAddress("0xa1ac6f6f7e8ad1e4e34c7c13a6e2fe94bbd73ea2")
+		reserveA    = ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f")
+		reserveB    = ethereum.HexToAddress("0x21433dec9cb634a23c6a4bbcce08c83f5ac2ec18")
+		from        = time.Date(2020, 7, 10, 0, 0, 0, 0, time.UTC)
+	)
+	require.NoError(t, testStorage.saveReserve([]common.Reserve{
+		{Address: reserveA, ReserveID: [32]byte{0xaa}, RebateWallet: wallet, BlockNumber: 100},
+		{Address: reserveA, ReserveID: [32]byte{0xaa}, RebateWallet: otherWallet, BlockNumber: 150},
+		{Address: reserveB, ReserveID: [32]byte{0xbb}, RebateWallet: wallet, BlockNumber: 120},
+	}))
+
+	newTrade := func(block uint64, txHash string, rebate float64) common.TradelogV4 {
+		return common.TradelogV4{
+			Timestamp:       from.Add(time.Duration(block) * time.Minute),
+			BlockNumber:     block,
+			TransactionHash: ethereum.HexToHash(txHash),
+			Version:         4,
+			User: common.KyberUserInfo{
+				UserAddress: ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b"),
+			},
+			TokenInfo: common.TradeTokenInfo{
+				SrcAddress:  ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200"),
+				DestAddress: blockchain.ETHAddr,
+			},
+			EthAmount:         ethToWei(10),
+			OriginalEthAmount: ethToWei(10),
+			SrcAmount:         ethToWei(1000),
+			DestAmount:        big.NewInt(0),
+			ETHUSDRate:        200,
+			TxDetail: common.TxDetail{
+				GasPrice:       big.NewInt(0),
+				TransactionFee: big.NewInt(0),
+			},
+			Fees: []common.TradelogFee{
+				{
+					WalletFee:                 big.NewInt(0),
+					PlatformFee:               big.NewInt(0),
+					Burn:                      big.NewInt(0),
+					Reward:                    big.NewInt(0),
+					Rebate:                    ethToWei(rebate),
+					RebateWallets:             []ethereum.Address{wallet, otherWallet},
+					RebatePercentBpsPerWallet: []*big.Int{big.NewInt(5000), big.NewInt(5000)},
+					Index:                     2,
+				},
+			},
+		}
+	}
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: []common.TradelogV4{
+		newTrade(130, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff01", 1),
+		newTrade(200, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff02", 2),
+	}}))
+
+	statement, err := testStorage.GetRebateStatement(wallet, from, from.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []common.RebateWalletReserve{
+		{Address: reserveA, ReserveID: ethereum.Hash{0xaa}, FromBlock: 100, ToBlock: 149},
+		{Address: reserveB, ReserveID: ethereum.Hash{0xbb}, FromBlock: 120},
+	}, statement.Reserves)
+
+	require.Len(t, statement.Items, 2)
+	assert.Equal(t, uint64(130), statement.Items[0].BlockNumber)
+	assert.ElementsMatch(t, []ethereum.Address{reserveA, reserveB}, statement.Items[0].Reserves)
+	assert.Equal(t, uint64(5000), statement.Items[0].PercentBps)
+	assert.InDelta(t, 0.5, statement.Items[0].ETHAmount, 1e-6)
+	assert.Equal(t, []ethereum.Address{reserveB}, statement.Items[1].Reserves)
+	assert.InDelta(t, 1, statement.Items[1].ETHAmount, 1e-6)
+	assert.InDelta(t, 1.5, statement.TotalETH, 1e-6)
+	assert.InDelta(t, 300, statement.TotalUSD, 1e-4)
+}
@@ -46,6 +46,7 @@ type record struct {
 	TransactionFee    float64              `db:"transaction_fee"`
 	Version           uint                 `db:"version"`
 	Fee               []common.TradelogFee `db:"fee"`
+	ChainID           uint64               `db:"chain_id"`
 }
 
 func (tldb *TradeLogDB) calculateDstAmountV4(log common.TradelogV4) (float64, error) {
@@ -204,5 +205,6 @@ func (tldb *TradeLogDB) recordFromTradeLog(log common.TradelogV4) (*record, erro
 		GasUsed:           log.TxDetail.GasUsed,
 		Version:           log.Version,
 		Fee:               log.Fees,
+		ChainID:           tldb.chainID,
 	}, nil
 }
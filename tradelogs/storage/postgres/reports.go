@@ -8,30 +8,32 @@ import (
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 )
 
-// GetStats return tradelogs stats in a time range
-func (tldb *TradeLogDB) GetStats(from, to time.Time) (common.StatsResponse, error) {
+// GetStats return tradelogs stats in a time range, usd amounts are priced with the ETH/USD rates of
+// provider, or the rates fixed at crawl time if provider is empty
+func (tldb *TradeLogDB) GetStats(from, to time.Time, provider string) (common.StatsResponse, error) {
 	var (
 		logger = tldb.sugar.With(
 			"from", from,
 			"to", to,
+			"provider", provider,
 			"func", caller.GetCurrentFunctionName(),
 		)
-		query = `
+		queryTemplate = `
 		SELECT 
 		COALESCE(SUM(
-			CASE WHEN split.src != '0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2' AND split.dst != '0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2'
+			CASE WHEN split.src != $3 AND split.dst != $3
 			THEN split.eth_amount
 			END), 0) AS eth_volume,
-		COALESCE(SUM(split.eth_amount*eth_usd_rate), 0) AS usd_volume,
+		COALESCE(SUM(split.eth_amount*%[1]s), 0) AS usd_volume,
 		COALESCE(SUM(platform_fee+burn+rebate+reward), 0) as collected_fee,
 		COUNT(DISTINCT(tx_hash, tradelogs.index)) as total_trades,
 		COUNT(CASE WHEN is_first_trade THEN 1 END) AS new_users,
 		COUNT(distinct(user_address_id)) AS unique_addresses,
-		COALESCE(AVG(split.eth_amount*eth_usd_rate), 0) as average_trade_size
+		COALESCE(AVG(split.eth_amount*%[1]s), 0) as average_trade_size
 		FROM tradelogs
 		LEFT JOIN fee ON fee.trade_id = tradelogs.id
 		LEFT JOIN split ON split.trade_id = tradelogs.id
-	  WHERE timestamp >= $1 AND timestamp <= $2
+	  WHERE timestamp >= $1 AND timestamp <= $2 AND tradelogs.chain_id = $4
 	`
 		statsRecord struct {
 			ETHVolume        float64 `db:"eth_volume"`
@@ -43,8 +45,10 @@ func (tldb *TradeLogDB) GetStats(from, to time.Time) (common.StatsResponse, erro
 			AverageTradeSize float64 `db:"average_trade_size"`
 		}
 	)
+	args := []interface{}{from, to, tldb.wrappedNativeToken.Hex(), tldb.chainID}
+	query := fmt.Sprintf(queryTemplate, ethUSDRateField("tradelogs", provider, &args))
 	logger.Infow("query to get tradelogs stats", "query", query)
-	if err := tldb.db.Get(&statsRecord, query, from, to); err != nil {
+	if err := tldb.db.Get(&statsRecord, query, args...); err != nil {
 		return common.StatsResponse{}, err
 	}
 	return common.StatsResponse{
@@ -59,15 +63,16 @@ func (tldb *TradeLogDB) GetStats(from, to time.Time) (common.StatsResponse, erro
 }
 
 // GetTopTokens return top tokens by volume in a time range
-func (tldb *TradeLogDB) GetTopTokens(from, to time.Time, limit uint64) (common.TopTokens, error) {
+func (tldb *TradeLogDB) GetTopTokens(from, to time.Time, limit uint64, provider string) (common.TopTokens, error) {
 	var (
 		logger = tldb.sugar.With(
 			"func", caller.GetCurrentFunctionName(),
 			"from", from,
 			"to", to,
 			"limit", limit,
+			"provider", provider,
 		)
-		query = `
+		queryTemplate = `
 	  SELECT
 		address as token_address,
 		symbol as token_symbol,
@@ -75,25 +80,25 @@ func (tldb *TradeLogDB) GetTopTokens(from, to time.Time, limit uint64) (common.T
 	  FROM
 	  (
 	  SELECT
-	    sum(tradelogs.original_eth_amount*tradelogs.eth_usd_rate) usd_amount,
+	    COALESCE(sum(tradelogs.original_eth_amount*%[1]s), 0) usd_amount,
 		token.address,
 		token.symbol,
 		token.id
 	  FROM tradelogs
 	    left join token on tradelogs.src_address_id = token.id
 	  WHERE
-		timestamp >= $1 AND timestamp <= $2
+		timestamp >= $1 AND timestamp <= $2 AND tradelogs.chain_id = $3
 	  GROUP BY token.id
 	  UNION ALL
 	  SELECT
-	    sum(tradelogs.original_eth_amount*tradelogs.eth_usd_rate) usd_amount,
+	    COALESCE(sum(tradelogs.original_eth_amount*%[1]s), 0) usd_amount,
 		token.address,
 		token.symbol,
 		token.id
 	  FROM tradelogs
 	    left join token on tradelogs.dst_address_id = token.id
 	  WHERE
-		timestamp >= $1 AND timestamp <= $2
+		timestamp >= $1 AND timestamp <= $2 AND tradelogs.chain_id = $3
 	  GROUP BY token.id
 	  ) a GROUP BY a.address, a.symbol ORDER BY usd_amount DESC
 		`
@@ -103,11 +108,13 @@ func (tldb *TradeLogDB) GetTopTokens(from, to time.Time, limit uint64) (common.T
 			USDAmount    float64 `db:"usd_amount"`
 		}
 	)
+	args := []interface{}{from, to, tldb.chainID}
+	query := fmt.Sprintf(queryTemplate, ethUSDRateField("tradelogs", provider, &args))
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
 	logger.Infow("query to get top tokens", "query", query)
-	if err := tldb.db.Select(&topTokens, query, from, to); err != nil {
+	if err := tldb.db.Select(&topTokens, query, args...); err != nil {
 		return common.TopTokens{}, err
 	}
 	var result = make(common.TopTokens)
@@ -123,23 +130,24 @@ func (tldb *TradeLogDB) GetTopTokens(from, to time.Time, limit uint64) (common.T
 }
 
 // GetTopIntegrations return top integrations by volume
-func (tldb *TradeLogDB) GetTopIntegrations(from, to time.Time, limit uint64) (common.TopIntegrations, error) {
+func (tldb *TradeLogDB) GetTopIntegrations(from, to time.Time, limit uint64, provider string) (common.TopIntegrations, error) {
 	var (
 		logger = tldb.sugar.With(
 			"func", caller.GetCurrentFunctionName(),
 			"from", from,
 			"to", to,
 			"limit", limit,
+			"provider", provider,
 		)
-		query = `
+		queryTemplate = `
 		SELECT
 	  wallet.address,
 	  wallet.name AS wallet_name,
-	  sum(tradelogs.eth_amount*tradelogs.eth_usd_rate) usd_amount
+	  COALESCE(sum(tradelogs.eth_amount*%[1]s), 0) usd_amount
 	FROM tradelogs
 	  left join wallet on tradelogs.wallet_address_id = wallet.id
 	WHERE
-		timestamp >= $1 AND timestamp <= $2
+		timestamp >= $1 AND timestamp <= $2 AND tradelogs.chain_id = $3
 	GROUP BY wallet.address, wallet.name ORDER BY usd_amount DESC
 		`
 		topIntegrations []struct {
@@ -148,11 +156,13 @@ func (tldb *TradeLogDB) GetTopIntegrations(from, to time.Time, limit uint64) (co
 			USDAmount float64 `db:"usd_amount"`
 		}
 	)
+	args := []interface{}{from, to, tldb.chainID}
+	query := fmt.Sprintf(queryTemplate, ethUSDRateField("tradelogs", provider, &args))
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
 	logger.Infow("get top integrations", "query", query)
-	if err := tldb.db.Select(&topIntegrations, query, from, to); err != nil {
+	if err := tldb.db.Select(&topIntegrations, query, args...); err != nil {
 		return common.TopIntegrations{}, err
 	}
 
@@ -169,29 +179,30 @@ func (tldb *TradeLogDB) GetTopIntegrations(from, to time.Time, limit uint64) (co
 }
 
 // GetTopReserves return top reserves by volume
-func (tldb *TradeLogDB) GetTopReserves(from, to time.Time, limit uint64) (common.TopReserves, error) {
+func (tldb *TradeLogDB) GetTopReserves(from, to time.Time, limit uint64, provider string) (common.TopReserves, error) {
 	var (
 		logger = tldb.sugar.With(
 			"func", caller.GetCurrentFunctionName(),
 			"from", from,
 			"to", to,
 			"limit", limit,
+			"provider", provider,
 		)
-		query = `
+		queryTemplate = `
 	  SELECT 
 		  reserve.address as reserve_address, 
-		  SUM(
+		  COALESCE(SUM(
 			CASE 
 		  		WHEN split.src = '0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE'
-				THEN split.src_amount*tradelogs.eth_usd_rate
-				ELSE split.dst_amount*tradelogs.eth_usd_rate
+				THEN split.src_amount*%[1]s
+				ELSE split.dst_amount*%[1]s
 			END
-		  ) AS usd_amount,
+		  ), 0) AS usd_amount,
 		  reserve.name
 	  FROM split
 	  JOIN tradelogs on tradelogs.id = split.trade_id
 	  JOIN reserve on split.reserve_id = reserve.id
-	  WHERE tradelogs.timestamp >= $1 AND tradelogs.timestamp <= $2
+	  WHERE tradelogs.timestamp >= $1 AND tradelogs.timestamp <= $2 AND tradelogs.chain_id = $3
 	  GROUP BY reserve.address ORDER BY usd_amount DESC
 		`
 		topReserves []struct {
@@ -200,11 +211,13 @@ func (tldb *TradeLogDB) GetTopReserves(from, to time.Time, limit uint64) (common
 			Name           string  `db:"name"`
 		}
 	)
+	args := []interface{}{from, to, tldb.chainID}
+	query := fmt.Sprintf(queryTemplate, ethUSDRateField("tradelogs", provider, &args))
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
 	logger.Infow("get top reserves", "query", query)
-	if err := tldb.db.Select(&topReserves, query, from, to); err != nil {
+	if err := tldb.db.Select(&topReserves, query, args...); err != nil {
 		return common.TopReserves{}, err
 	}
 	var result = make(common.TopReserves)
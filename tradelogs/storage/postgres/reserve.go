@@ -1,9 +1,12 @@
 package postgres
 
 import (
-	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	ethereum "github.com/ethereum/go-ethereum/common"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 )
 
 // use for version before v4
@@ -14,14 +17,51 @@ func (tldb *TradeLogDB) saveReserveAddress(tx *sqlx.Tx, reserveAddressArray []st
 			"reserves", reserveAddressArray,
 		)
 	)
-	query := `INSERT INTO reserve(address) 
-	VALUES (UNNEST($1::TEXT[])) 
-	ON CONFLICT ON CONSTRAINT reserve_pk DO NOTHING;`
+	query := `INSERT INTO reserve(address, chain_id) 
+	VALUES (UNNEST($1::TEXT[]), $2) 
+	ON CONFLICT (chain_id, address, reserve_id, block_number) DO NOTHING;`
 	logger.Debugw("updating rsv...", "query", query)
 
-	_, err := tx.Exec(query, pq.StringArray(reserveAddressArray))
+	_, err := tx.Exec(query, pq.StringArray(reserveAddressArray), tldb.chainID)
 	if err != nil {
 		logger.Errorw("failed to update reserve", "error", err)
 	}
 	return err
 }
+
+// GetReservesByAddresses returns Katalyst reserves of given addresses, ordered by block number.
+func (tldb *TradeLogDB) GetReservesByAddresses(addresses []ethereum.Address) ([]common.Reserve, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName())
+		rows   []struct {
+			Address      string `db:"address"`
+			ReserveID    string `db:"reserve_id"`
+			ReserveType  uint64 `db:"reserve_type"`
+			RebateWallet string `db:"rebate_wallet"`
+			BlockNumber  uint64 `db:"block_number"`
+		}
+		result []common.Reserve
+	)
+	query := `SELECT address, reserve_id, COALESCE(reserve_type, 0) AS reserve_type,
+	COALESCE(rebate_wallet, '') AS rebate_wallet, COALESCE(block_number, 0) AS block_number
+	FROM reserve
+	WHERE chain_id = $1 AND address = ANY($2) AND COALESCE(reserve_id, '') != ''
+	ORDER BY block_number;`
+	logger.Debugw("getting reserves", "query", query, "addresses", addresses)
+	if err := tldb.db.Select(&rows, query, tldb.chainID, addressesToHex(addresses)); err != nil {
+		logger.Errorw("failed to get reserves", "error", err)
+		return nil, err
+	}
+	for _, r := range rows {
+		var reserveID [32]byte
+		copy(reserveID[:], ethereum.HexToHash(r.ReserveID).Bytes())
+		result = append(result, common.Reserve{
+			Address:      ethereum.HexToAddress(r.Address),
+			ReserveID:    reserveID,
+			ReserveType:  r.ReserveType,
+			RebateWallet: ethereum.HexToAddress(r.RebateWallet),
+			BlockNumber:  r.BlockNumber,
+		})
+	}
+	return result, nil
+}
@@ -65,3 +65,32 @@ func TestAddUpdateReserve(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestGetReservesByAddresses(t *testing.T) {
+	const dbName = "test_get_reserves"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		reserveAddr = ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f")
+		otherAddr   = ethereum.HexToAddress("0x21433dec9cb634a23c6a4bbcce08c83f5ac2ec18")
+		reserveID   = [32]byte{0xaa, 0x01}
+		newID       = [32]byte{0xaa, 0x02}
+	)
+	require.NoError(t, testStorage.saveReserve([]common.Reserve{
+		{Address: reserveAddr, ReserveID: newID, BlockNumber: 200},
+		{Address: reserveAddr, ReserveID: reserveID, BlockNumber: 100},
+		{Address: otherAddr, ReserveID: [32]byte{0xbb}, BlockNumber: 100},
+	}))
+
+	reserves, err := testStorage.GetReservesByAddresses([]ethereum.Address{reserveAddr})
+	require.NoError(t, err)
+	require.Len(t, reserves, 2)
+	require.Equal(t, reserveID, reserves[0].ReserveID)
+	require.Equal(t, uint64(100), reserves[0].BlockNumber)
+	require.Equal(t, newID, reserves[1].ReserveID)
+	require.Equal(t, reserveAddr, reserves[1].Address)
+}
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/pgsql"
+	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+const (
+	deleteSandwichesTemplate = `DELETE FROM "%[1]s" WHERE chain_id = $1 AND trade_id IN (
+		SELECT id FROM "%[2]s" WHERE chain_id = $1 AND block_number >= $2 AND block_number <= $3
+	);`
+
+	// insertSandwichesTemplate finds the sandwiched trade by its transaction hash and log index.
+	insertSandwichesTemplate = `INSERT INTO "%[1]s" (chain_id, trade_id, token, attacker,
+		front_run_tx_hash, back_run_tx_hash, extracted_eth, extracted_usd)
+	SELECT $1, t.id, s.token, s.attacker, s.front_run_tx_hash, s.back_run_tx_hash, s.extracted_eth, s.extracted_usd
+	FROM UNNEST($2::TEXT[], $3::INTEGER[], $4::TEXT[], $5::TEXT[], $6::TEXT[], $7::TEXT[], $8::FLOAT[], $9::FLOAT[])
+		AS s(tx_hash, index, token, attacker, front_run_tx_hash, back_run_tx_hash, extracted_eth, extracted_usd)
+	JOIN "%[2]s" AS t ON t.chain_id = $1 AND t.tx_hash = s.tx_hash AND t.index = s.index
+	ON CONFLICT (trade_id, token) DO UPDATE SET
+		attacker = EXCLUDED.attacker,
+		front_run_tx_hash = EXCLUDED.front_run_tx_hash,
+		back_run_tx_hash = EXCLUDED.back_run_tx_hash,
+		extracted_eth = EXCLUDED.extracted_eth,
+		extracted_usd = EXCLUDED.extracted_usd;`
+)
+
+// SaveSandwiches replaces sandwiched trades of blocks in range [fromBlock, toBlock] with the given ones.
+func (tldb *TradeLogDB) SaveSandwiches(fromBlock, toBlock uint64, sandwiches []common.Sandwich) (err error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"from_block", fromBlock, "to_block", toBlock)
+		txHashes, tokens, attackers, frontRuns, backRuns []string
+		indexes                                          []int64
+		extractedETH, extractedUSD                       []float64
+	)
+	for _, s := range sandwiches {
+		txHashes = append(txHashes, s.TxHash.Hex())
+		indexes = append(indexes, int64(s.TradeIndex))
+		tokens = append(tokens, s.Token.Hex())
+		attackers = append(attackers, s.Attacker.Hex())
+		frontRuns = append(frontRuns, s.FrontRunTxHash.Hex())
+		backRuns = append(backRuns, s.BackRunTxHash.Hex())
+		extractedETH = append(extractedETH, s.ExtractedETH)
+		extractedUSD = append(extractedUSD, s.ExtractedUSD)
+	}
+
+	tx, err := tldb.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer pgsql.CommitOrRollback(tx, logger, &err)
+	deleteQuery := fmt.Sprintf(deleteSandwichesTemplate, schema.SandwichTableName, schema.TradeLogsTableName)
+	if _, err = tx.Exec(deleteQuery, tldb.chainID, fromBlock, toBlock); err != nil {
+		logger.Errorw("failed to delete sandwiches", "error", err)
+		return err
+	}
+	if len(sandwiches) == 0 {
+		return nil
+	}
+	insertQuery := fmt.Sprintf(insertSandwichesTemplate, schema.SandwichTableName, schema.TradeLogsTableName)
+	if _, err = tx.Exec(insertQuery, tldb.chainID,
+		pq.StringArray(txHashes),
+		pq.Array(indexes),
+		pq.StringArray(tokens),
+		pq.StringArray(attackers),
+		pq.StringArray(frontRuns),
+		pq.StringArray(backRuns),
+		pq.Array(extractedETH),
+		pq.Array(extractedUSD),
+	); err != nil {
+		logger.Errorw("failed to save sandwiches", "error", err)
+		return err
+	}
+	logger.Debugw("saved sandwiches", "sandwiches", len(sandwiches))
+	return nil
+}
+
+// GetMEVReport returns sandwiched trades between from and to, with value extracted from trades of each
+// integration wallet. All wallets are reported if wallets is empty.
+func (tldb *TradeLogDB) GetMEVReport(from, to time.Time, wallets []ethereum.Address) (common.MEVReport, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "from", from, "to", to)
+		rows   []struct {
+			Timestamp      time.Time `db:"timestamp"`
+			BlockNumber    uint64    `db:"block_number"`
+			TxHash         string    `db:"tx_hash"`
+			Index          uint      `db:"index"`
+			Wallet         string    `db:"wallet"`
+			Token          string    `db:"token"`
+			Attacker       string    `db:"attacker"`
+			FrontRunTxHash string    `db:"front_run_tx_hash"`
+			BackRunTxHash  string    `db:"back_run_tx_hash"`
+			ExtractedETH   float64   `db:"extracted_eth"`
+			ExtractedUSD   float64   `db:"extracted_usd"`
+		}
+		report = common.MEVReport{
+			From:       timeutil.TimeToTimestampMs(from),
+			To:         timeutil.TimeToTimestampMs(to),
+			Wallets:    []common.MEVWalletStats{},
+			Sandwiches: []common.Sandwich{},
+		}
+		args          = []interface{}{tldb.chainID, from, to}
+		walletFilter  string
+		walletIndexes = make(map[ethereum.Address]int)
+		walletTrades  = make(map[ethereum.Address]map[string]struct{})
+	)
+	if len(wallets) != 0 {
+		walletFilter = "AND w.address = ANY($4)"
+		args = append(args, addressesToHex(wallets))
+	}
+	query := fmt.Sprintf(`SELECT t.timestamp, t.block_number, t.tx_hash, t.index, w.address AS wallet,
+		s.token, s.attacker, s.front_run_tx_hash, s.back_run_tx_hash, s.extracted_eth, s.extracted_usd
+	FROM "%[1]s" AS s
+	JOIN "%[2]s" AS t ON t.id = s.trade_id
+	JOIN "%[3]s" AS w ON w.id = t.wallet_address_id
+	WHERE s.chain_id = $1 AND t.timestamp >= $2 AND t.timestamp <= $3 %[4]s
+	ORDER BY t.block_number, t.index, s.token;`,
+		schema.SandwichTableName, schema.TradeLogsTableName, schema.WalletTableName, walletFilter)
+	logger.Debugw("prepare statement", "stmt", query)
+	if err := tldb.db.Select(&rows, query, args...); err != nil {
+		logger.Errorw("failed to get sandwiches", "error", err)
+		return report, err
+	}
+
+	for _, r := range rows {
+		sandwich := common.Sandwich{
+			Timestamp:      timeutil.TimeToTimestampMs(r.Timestamp),
+			BlockNumber:    r.BlockNumber,
+			TxHash:         ethereum.HexToHash(r.TxHash),
+			TradeIndex:     r.Index,
+			Wallet:         ethereum.HexToAddress(r.Wallet),
+			Token:          ethereum.HexToAddress(r.Token),
+			Attacker:       ethereum.HexToAddress(r.Attacker),
+			FrontRunTxHash: ethereum.HexToHash(r.FrontRunTxHash),
+			BackRunTxHash:  ethereum.HexToHash(r.BackRunTxHash),
+			ExtractedETH:   r.ExtractedETH,
+			ExtractedUSD:   r.ExtractedUSD,
+		}
+		report.Sandwiches = append(report.Sandwiches, sandwich)
+		report.TotalExtractedETH += sandwich.ExtractedETH
+		report.TotalExtractedUSD += sandwich.ExtractedUSD
+
+		i, ok := walletIndexes[sandwich.Wallet]
+		if !ok {
+			i = len(report.Wallets)
+			walletIndexes[sandwich.Wallet] = i
+			walletTrades[sandwich.Wallet] = make(map[string]struct{})
+			report.Wallets = append(report.Wallets, common.MEVWalletStats{Wallet: sandwich.Wallet})
+		}
+		// a trade between two tokens could be sandwiched on both of them
+		walletTrades[sandwich.Wallet][fmt.Sprintf("%s-%d", r.TxHash, r.Index)] = struct{}{}
+		report.Wallets[i].SandwichedTrades = uint64(len(walletTrades[sandwich.Wallet]))
+		report.Wallets[i].ExtractedETH += sandwich.ExtractedETH
+		report.Wallets[i].ExtractedUSD += sandwich.ExtractedUSD
+	}
+	sort.SliceStable(report.Wallets, func(i, j int) bool {
+		return report.Wallets[i].ExtractedETH > report.Wallets[j].ExtractedETH
+	})
+	return report, nil
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/mev"
+)
+
+func TestSandwiches(t *testing.T) {
+	const dbName = "test_sandwiches"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		knc       = ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200")
+		reserve   = ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f")
+		attacker  = ethereum.HexToAddress("0x0000000000007f150bd6f54c40a34d7c3d5e9f56")
+		user      = ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b")
+		wallet    = ethereum.HexToAddress("0xf1aa99c69715f423086008eb9d06dc1e35cc504d")
+		timestamp = time.Date(2020, 7, 10, 9, 30, 0, 0, time.UTC)
+	)
+	newTrade := func(index uint, txHash string, sender ethereum.Address, buy bool, kncAmount, ethAmount float64) common.TradelogV4 {
+		trade := common.TradelogV4{
+			Timestamp:       timestamp,
+			BlockNumber:     100,
+			TransactionHash: ethereum.HexToHash(txHash),
+			Index:           index,
+			Version:         3,
+			User:            common.KyberUserInfo{UserAddress: sender},
+			TokenInfo: common.TradeTokenInfo{
+				SrcAddress:  knc,
+				DestAddress: blockchain.ETHAddr,
+			},
+			SrcReserveAddress: reserve,
+			EthAmount:         ethToWei(ethAmount),
+			OriginalEthAmount: ethToWei(ethAmount),
+			SrcAmount:         ethToWei(kncAmount),
+			DestAmount:        ethToWei(ethAmount),
+			WalletAddress:     wallet,
+			ETHUSDRate:        200,
+			TxDetail: common.TxDetail{
+				GasPrice:       big.NewInt(0),
+				TransactionFee: big.NewInt(0),
+				TxSender:       sender,
+			},
+		}
+		if buy {
+			trade.TokenInfo = common.TradeTokenInfo{SrcAddress: blockchain.ETHAddr, DestAddress: knc}
+			trade.SrcReserveAddress, trade.DstReserveAddress = ethereum.Address{}, reserve
+			trade.SrcAmount, trade.DestAmount = ethToWei(ethAmount), ethToWei(kncAmount)
+		}
+		return trade
+	}
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: []common.TradelogV4{
+		newTrade(1, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff01", attacker, true, 1000, 10),
+		newTrade(2, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff02", user, true, 450, 5),
+		newTrade(3, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff03", attacker, false, 1000, 10.5),
+	}}))
+
+	detector := mev.NewDetector(testStorage.sugar, testStorage, 10)
+	// running again over the same blocks does not duplicate sandwiches
+	for i := 0; i < 2; i++ {
+		require.NoError(t, detector.Run(95, 104))
+	}
+
+	report, err := testStorage.GetMEVReport(timestamp, timestamp.Add(time.Hour), nil)
+	require.NoError(t, err)
+	require.Len(t, report.Sandwiches, 1)
+	sandwich := report.Sandwiches[0]
+	assert.Equal(t, ethereum.HexToHash("0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff02"), sandwich.TxHash)
+	assert.Equal(t, attacker, sandwich.Attacker)
+	assert.Equal(t, knc, sandwich.Token)
+	assert.InDelta(t, 0.5, sandwich.ExtractedETH, 1e-6)
+	require.Len(t, report.Wallets, 1)
+	assert.Equal(t, wallet, report.Wallets[0].Wallet)
+	assert.Equal(t, uint64(1), report.Wallets[0].SandwichedTrades)
+	assert.InDelta(t, 100, report.Wallets[0].ExtractedUSD, 1e-4)
+	assert.InDelta(t, 100, report.TotalExtractedUSD, 1e-4)
+
+	report, err = testStorage.GetMEVReport(timestamp, timestamp.Add(time.Hour), []ethereum.Address{user})
+	require.NoError(t, err)
+	assert.Empty(t, report.Sandwiches)
+	assert.Empty(t, report.Wallets)
+
+	require.NoError(t, testStorage.DeleteTradeLogsFromBlock(100))
+	report, err = testStorage.GetMEVReport(timestamp, timestamp.Add(time.Hour), nil)
+	require.NoError(t, err)
+	assert.Empty(t, report.Sandwiches)
+}
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/KyberNetwork/reserve-stats/lib/timeutil"
@@ -13,20 +14,33 @@ var DateFunctionParams = map[string]string{
 	"d": "day",
 }
 
-// BuildDateTruncField for aggregated query
-func BuildDateTruncField(dateTruncParam string, timeZone int8) string {
-	if timeZone != 0 && dateTruncParam == "day" {
-		var intervalParse = fmt.Sprintf("interval '%d hour'", timeZone)
-		return "date_trunc('" + dateTruncParam + "', timestamp + " + intervalParse + ") - " + intervalParse
+// BuildDateTruncField for aggregated query, timestamp is truncated in given timezone so hours and days
+// start at local time, nil timezone is UTC.
+func BuildDateTruncField(dateTruncParam string, timezone *time.Location) string {
+	return DateTruncInTimezone(dateTruncParam, "timestamp", timezone)
+}
+
+// DateTruncInTimezone returns the expression truncating the timestamptz field in given timezone, it
+// handles half hour offsets and daylight saving time with AT TIME ZONE.
+func DateTruncInTimezone(dateTruncParam, field string, timezone *time.Location) string {
+	if timezone == nil || timezone == time.UTC {
+		return fmt.Sprintf("date_trunc('%s', %s)", dateTruncParam, field)
 	}
-	return `date_trunc('` + dateTruncParam + `', timestamp)`
+	// zone name is validated by time.LoadLocation, quoting is only a safety net
+	zone := "'" + strings.Replace(timezone.String(), "'", "''", -1) + "'"
+	return fmt.Sprintf("date_trunc('%[1]s', %[2]s AT TIME ZONE %[3]s) AT TIME ZONE %[3]s", dateTruncParam, field, zone)
 }
 
-// RoundTime returns time is rounded by day or hour
-// if time is rounded by day, it also use time zone.
-func RoundTime(t time.Time, freq string, timeZone int8) time.Time {
+// RoundTime returns time is rounded by day or hour in given timezone, nil timezone is UTC.
+func RoundTime(t time.Time, freq string, timezone *time.Location) time.Time {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+	t = t.In(timezone)
 	if freq == "hour" {
-		return t.Truncate(time.Hour)
+		_, offset := t.Zone()
+		d := time.Duration(offset) * time.Second
+		return t.Add(d).Truncate(time.Hour).Add(-d)
 	}
-	return timeutil.Midnight(t.In(time.FixedZone("", int(timeZone)*60*60)))
+	return timeutil.Midnight(t)
 }
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -11,12 +12,24 @@ import (
 
 func TestBuildDateTruncField(t *testing.T) {
 	sugar := testutil.MustNewDevelopmentSugaredLogger()
-	sugar.Infow("build date_trunc", "output", BuildDateTruncField("day", 7))
+	timezone, err := timeutil.ParseTimezone("7")
+	require.NoError(t, err)
+	sugar.Infow("build date_trunc", "output", BuildDateTruncField("day", timezone))
+	require.Equal(t, "date_trunc('day', timestamp AT TIME ZONE 'Etc/GMT-7') AT TIME ZONE 'Etc/GMT-7'",
+		BuildDateTruncField("day", timezone))
+	require.Equal(t, "date_trunc('hour', timestamp)", BuildDateTruncField("hour", time.UTC))
 }
 
 func TestRoundHourTime(t *testing.T) {
 	const fromTime = 1539250666000
 	from := timeutil.TimestampMsToTime(uint64(fromTime))
-	from = RoundTime(from, "date", 7)
-	require.Equal(t, "2018-10-10 17:00:00", from.UTC().Format(DefaultDateFormat))
+	timezone, err := timeutil.ParseTimezone("7")
+	require.NoError(t, err)
+	require.Equal(t, "2018-10-10 17:00:00", RoundTime(from, "date", timezone).UTC().Format(DefaultDateFormat))
+
+	// India is UTC+05:30, hours start at half past in UTC
+	timezone, err = timeutil.ParseTimezone("Asia/Kolkata")
+	require.NoError(t, err)
+	require.Equal(t, "2018-10-10 18:30:00", RoundTime(from, "date", timezone).UTC().Format(DefaultDateFormat))
+	require.Equal(t, "2018-10-11 09:30:00", RoundTime(from, "hour", timezone).UTC().Format(DefaultDateFormat))
 }
@@ -131,6 +131,13 @@ CREATE TABLE IF NOT EXISTS "split" (
 
 ALTER TABLE "split" ADD COLUMN IF NOT EXISTS eth_amount FLOAT DEFAULT 0;
 
+CREATE TABLE IF NOT EXISTS "` + BlockHashTableName + `" (
+	chain_id INTEGER NOT NULL DEFAULT 1,
+	block_number INTEGER NOT NULL,
+	hash TEXT NOT NULL,
+	PRIMARY KEY (chain_id, block_number)
+);
+
 CREATE TABLE IF NOT EXISTS "rebates" (
 	id SERIAL PRIMARY KEY,
 	fee_id INTEGER NOT NULL REFERENCES fee,
@@ -138,6 +145,127 @@ CREATE TABLE IF NOT EXISTS "rebates" (
 	percent INTEGER NOT NULL
 );
 
+-- chain_id is the EIP-155 ID of the chain the row is crawled from, existing rows are from Ethereum mainnet
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "wallet" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "token" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "reserve" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "` + TradeLogsTableName + `" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "` + BigTradeLogsTableName + `" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "fee" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "split" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "rebates" ADD COLUMN IF NOT EXISTS chain_id INTEGER NOT NULL DEFAULT 1;
+
+-- the same address or transaction hash could appear on different chains
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS users_address_key;
+CREATE UNIQUE INDEX IF NOT EXISTS "users_chain_address_key" ON "users"(chain_id, address);
+ALTER TABLE "wallet" DROP CONSTRAINT IF EXISTS wallet_address_key;
+CREATE UNIQUE INDEX IF NOT EXISTS "wallet_chain_address_key" ON "wallet"(chain_id, address);
+ALTER TABLE "token" DROP CONSTRAINT IF EXISTS token_address_key;
+CREATE UNIQUE INDEX IF NOT EXISTS "token_chain_address_key" ON "token"(chain_id, address);
+ALTER TABLE "reserve" DROP CONSTRAINT IF EXISTS reserve_pk;
+CREATE UNIQUE INDEX IF NOT EXISTS "reserve_chain_pk" ON "reserve"(chain_id, address, reserve_id, block_number);
+ALTER TABLE "` + TradeLogsTableName + `" DROP CONSTRAINT IF EXISTS tradelog_constraint;
+CREATE UNIQUE INDEX IF NOT EXISTS "tradelogs_chain_constraint" ON "` + TradeLogsTableName + `"(chain_id, tx_hash, index);
+CREATE INDEX IF NOT EXISTS "trade_chain_timestamp" ON "` + TradeLogsTableName + `"(chain_id, timestamp);
+CREATE INDEX IF NOT EXISTS "trade_chain_block_index" ON "` + TradeLogsTableName + `"(chain_id, block_number, index);
+
+-- OHLCV candles of tokens, recomputed from splits of the bucket whenever trade logs of the bucket are saved or deleted
+CREATE TABLE IF NOT EXISTS "` + OHLCVTableName + `" (
+	chain_id INTEGER NOT NULL DEFAULT 1,
+	token TEXT NOT NULL,
+	period TEXT NOT NULL,
+	bucket TIMESTAMPTZ NOT NULL,
+	open_eth FLOAT NOT NULL,
+	high_eth FLOAT NOT NULL,
+	low_eth FLOAT NOT NULL,
+	close_eth FLOAT NOT NULL,
+	open_usd FLOAT NOT NULL,
+	high_usd FLOAT NOT NULL,
+	low_usd FLOAT NOT NULL,
+	close_usd FLOAT NOT NULL,
+	volume_token FLOAT NOT NULL,
+	volume_eth FLOAT NOT NULL,
+	volume_usd FLOAT NOT NULL,
+	trade_count INTEGER NOT NULL,
+	PRIMARY KEY (chain_id, token, period, bucket)
+);
+
+-- trades probably sandwiched on a token, extracted_eth is the estimated profit of the attacker from the trade
+CREATE TABLE IF NOT EXISTS "` + SandwichTableName + `" (
+	id SERIAL PRIMARY KEY,
+	chain_id INTEGER NOT NULL DEFAULT 1,
+	trade_id INTEGER NOT NULL REFERENCES tradelogs,
+	token TEXT NOT NULL,
+	attacker TEXT NOT NULL,
+	front_run_tx_hash TEXT NOT NULL,
+	back_run_tx_hash TEXT NOT NULL,
+	extracted_eth FLOAT NOT NULL,
+	extracted_usd FLOAT NOT NULL,
+	CONSTRAINT sandwich_trade_token_key UNIQUE (trade_id, token)
+);
+
+-- slippage of splits in basis points from the rate quoted by the reserve at the previous block
+CREATE TABLE IF NOT EXISTS "` + SplitSlippageTableName + `" (
+	split_id INTEGER PRIMARY KEY REFERENCES split,
+	chain_id INTEGER NOT NULL DEFAULT 1,
+	quoted_rate FLOAT NOT NULL,
+	executed_rate FLOAT NOT NULL,
+	slippage_bps FLOAT NOT NULL
+);
+
+-- delivery state of big trades to every notifier sink, failed deliveries are retried until max attempts
+CREATE TABLE IF NOT EXISTS "` + BigTradeNotificationTableName + `" (
+	id SERIAL PRIMARY KEY,
+	chain_id INTEGER NOT NULL DEFAULT 1,
+	tradelog_id INTEGER NOT NULL REFERENCES tradelogs,
+	sink TEXT NOT NULL,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT big_trade_notification_sink_key UNIQUE (tradelog_id, sink)
+);
+
+-- ETH/USD rates of trades backfilled from alternative providers, kept side by side by provider
+CREATE TABLE IF NOT EXISTS "` + FiatPriceTableName + `" (
+	tradelog_id INTEGER NOT NULL REFERENCES tradelogs,
+	chain_id INTEGER NOT NULL DEFAULT 1,
+	provider TEXT NOT NULL,
+	eth_usd_rate FLOAT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (tradelog_id, provider)
+);
+
+-- block ranges to crawl shared by crawler instances, a running job is leased by its owner until lease_expires_at
+CREATE TABLE IF NOT EXISTS "` + CrawlJobTableName + `" (
+	id SERIAL PRIMARY KEY,
+	chain_id INTEGER NOT NULL DEFAULT 1,
+	from_block INTEGER NOT NULL,
+	to_block INTEGER NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	lease_owner TEXT NOT NULL DEFAULT '',
+	lease_expires_at TIMESTAMPTZ,
+	last_error TEXT NOT NULL DEFAULT '',
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT crawl_job_range_key UNIQUE (chain_id, from_block, to_block)
+);
+
+CREATE INDEX IF NOT EXISTS "crawl_job_status" ON "` + CrawlJobTableName + `"(chain_id, status, from_block);
+
+
+-- create_or_update_tradelogs used to be defined without _chain_id, drop it not to keep an overload
+-- writing tradelogs without chain id
+DROP FUNCTION IF EXISTS create_or_update_tradelogs(
+	tradelogs.id%TYPE, tradelogs.timestamp%TYPE, tradelogs.block_number%TYPE, tradelogs.tx_hash%TYPE,
+	tradelogs.eth_amount%TYPE, tradelogs.original_eth_amount%TYPE, TEXT, TEXT, TEXT, TEXT,
+	tradelogs.src_amount%TYPE, tradelogs.dst_amount%TYPE, tradelogs.integration_app%TYPE, tradelogs.ip%TYPE,
+	tradelogs.country%TYPE, tradelogs.eth_usd_rate%TYPE, tradelogs.eth_usd_provider%TYPE, tradelogs.index%TYPE,
+	tradelogs.kyced%TYPE, tradelogs.is_first_trade%TYPE, tradelogs.tx_sender%TYPE,
+	tradelogs.receiver_address%TYPE, tradelogs.gas_used%TYPE, tradelogs.gas_price%TYPE,
+	tradelogs.transaction_fee%TYPE, tradelogs.version%TYPE, TEXT[], TEXT[], FLOAT[], FLOAT[], FLOAT[], FLOAT[],
+	FLOAT[], INTEGER[], JSONB[], JSONB[], TEXT[], TEXT[], TEXT[], FLOAT[], FLOAT[], FLOAT[], INTEGER[]);
 
 -- create_or_update_tradelogs creates or update tradelogs
 CREATE OR REPLACE FUNCTION create_or_update_tradelogs(INOUT _id tradelogs.id%TYPE,
@@ -182,7 +310,8 @@ CREATE OR REPLACE FUNCTION create_or_update_tradelogs(INOUT _id tradelogs.id%TYP
 												_src_amounts FLOAT[],
 												_rate FLOAT[],
 												_dst_amounts FLOAT[],
-												_split_index INTEGER[]
+												_split_index INTEGER[],
+												_chain_id tradelogs.chain_id%TYPE
 												) AS
 $$
 DECLARE
@@ -194,16 +323,16 @@ BEGIN
 		INSERT INTO tradelogs (timestamp, block_number, tx_hash, eth_amount, 
 			original_eth_amount, user_address_id, src_address_id, dst_address_id, wallet_address_id, src_amount, dst_amount,
 			integration_app, ip, country, eth_usd_rate, eth_usd_provider, index, kyced, is_first_trade, tx_sender,
-			receiver_address, gas_used, gas_price, transaction_fee, version) 
+			receiver_address, gas_used, gas_price, transaction_fee, version, chain_id) 
 		VALUES (_timestamp,
 			_block_number,
 			_tx_hash,
 			_eth_amount,
 			_original_eth_amount,
-			(SELECT id FROM users WHERE address=_user_address),
-			(SELECT id FROM token WHERE address=_src_address),
-			(SELECT id FROM token WHERE address=_dst_address),
-			(SELECT id FROM wallet WHERE address=_wallet_address),
+			(SELECT id FROM users WHERE address=_user_address AND chain_id=_chain_id),
+			(SELECT id FROM token WHERE address=_src_address AND chain_id=_chain_id),
+			(SELECT id FROM token WHERE address=_dst_address AND chain_id=_chain_id),
+			(SELECT id FROM wallet WHERE address=_wallet_address AND chain_id=_chain_id),
 			_src_amount,
 			_dst_amount,
 			_integration_app,
@@ -219,8 +348,9 @@ BEGIN
 			_gas_used,
 			_gas_price,
 			_transaction_fee,
-			_version
-		) ON CONFLICT (tx_hash, index) DO UPDATE SET 
+			_version,
+			_chain_id
+		) ON CONFLICT (chain_id, tx_hash, index) DO UPDATE SET 
 			timestamp = _timestamp
 		 RETURNING id INTO _id;
     END IF;
@@ -240,7 +370,8 @@ BEGIN
 						reward,
 						rebate_wallets,
 						rebate_percents,
-						index
+						index,
+						chain_id
 					)
 					VALUES (_id, _address, 
 						_platform_wallets[_iterator],
@@ -251,7 +382,8 @@ BEGIN
 						_rewards[_iterator],
 						_rebate_wallets[_iterator],
 						_rebate_percents[_iterator],
-						_fee_indexes[_iterator]
+						_fee_indexes[_iterator],
+						_chain_id
 					) ON CONFLICT (trade_id, index) DO 
 					UPDATE SET reserve_address = _address
 					RETURNING id INTO _fee_id;
@@ -271,13 +403,14 @@ BEGIN
 						rate,
 						dst_amount,
 						index,
-						eth_amount
+						eth_amount,
+						chain_id
 					)
 					VALUES(
 						_id,
 						CASE 
-							WHEN _version = 4 THEN (SELECT MAX(id) FROM reserve WHERE reserve_id = _address)
-							ELSE (SELECT MIN(id) FROM reserve WHERE address = _address)
+							WHEN _version = 4 THEN (SELECT MAX(id) FROM reserve WHERE reserve_id = _address AND chain_id = _chain_id)
+							ELSE (SELECT MIN(id) FROM reserve WHERE address = _address AND chain_id = _chain_id)
 						END,
 						_src[_iterator],
 						_dst[_iterator],
@@ -288,7 +421,8 @@ BEGIN
 						CASE 
 							WHEN _src[_iterator] = '0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE' THEN _src_amounts[_iterator]
 							ELSE _dst_amounts[_iterator]
-						END
+						END,
+						_chain_id
 					) ON CONFLICT (trade_id, index) DO NOTHING;
 					_iterator := _iterator+1;
 				END LOOP;
@@ -13,4 +13,18 @@ const (
 	UserTableName = "users"
 	// BigTradeLogsTableName for store big trade
 	BigTradeLogsTableName = "big_tradelogs"
+	// BlockHashTableName table for hashes of crawled blocks, used to detect chain reorganization
+	BlockHashTableName = "block_hash"
+	// OHLCVTableName table for OHLCV candles of tokens
+	OHLCVTableName = "ohlcv"
+	// SandwichTableName table for trades probably sandwiched by a front run and a back run
+	SandwichTableName = "sandwich"
+	// SplitSlippageTableName table for slippage of splits from rates quoted by reserves
+	SplitSlippageTableName = "split_slippage"
+	// BigTradeNotificationTableName table for delivery state of big trades to notifier sinks
+	BigTradeNotificationTableName = "big_trade_notification"
+	// FiatPriceTableName table for ETH/USD rates of trades from alternative providers
+	FiatPriceTableName = "tradelog_fiat_price"
+	// CrawlJobTableName table for block ranges of the persistent crawl queue
+	CrawlJobTableName = "crawl_job"
 )
@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/lib/pgsql"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+const (
+	deleteSlippagesTemplate = `DELETE FROM "%[1]s" WHERE chain_id = $1 AND split_id IN (
+		SELECT s.id FROM "split" AS s JOIN "%[2]s" AS t ON t.id = s.trade_id
+		WHERE t.chain_id = $1 AND t.block_number >= $2 AND t.block_number <= $3
+	);`
+
+	insertSlippagesTemplate = `INSERT INTO "%[1]s" (split_id, chain_id, quoted_rate, executed_rate, slippage_bps)
+	VALUES (
+		UNNEST($1::INTEGER[]),
+		$2,
+		UNNEST($3::FLOAT[]),
+		UNNEST($4::FLOAT[]),
+		UNNEST($5::FLOAT[])
+	) ON CONFLICT (split_id) DO UPDATE SET
+		quoted_rate = EXCLUDED.quoted_rate,
+		executed_rate = EXCLUDED.executed_rate,
+		slippage_bps = EXCLUDED.slippage_bps;`
+
+	// slippageStatsTemplate groups slippage of splits by reserve, token, side and size bucket, size
+	// buckets are numbered from 1 by width_bucket.
+	slippageStatsTemplate = `WITH slippages AS (
+		SELECT r.address AS reserve,
+			CASE WHEN s.src = $4 THEN s.dst ELSE s.src END AS token,
+			CASE WHEN s.src = $4 THEN 'buy' ELSE 'sell' END AS side,
+			width_bucket(s.eth_amount, $5::FLOAT[]) AS size_bucket,
+			s.eth_amount, ss.slippage_bps
+		FROM "%[1]s" AS ss
+		JOIN "split" AS s ON s.id = ss.split_id
+		JOIN "%[2]s" AS t ON t.id = s.trade_id
+		JOIN "%[3]s" AS r ON r.id = s.reserve_id
+		WHERE ss.chain_id = $1 AND t.timestamp >= $2 AND t.timestamp <= $3
+	)
+	SELECT reserve, token, side, size_bucket,
+		COUNT(*) AS splits,
+		SUM(eth_amount) AS volume_eth,
+		COALESCE(SUM(slippage_bps * eth_amount) / NULLIF(SUM(eth_amount), 0), 0) AS weighted_slippage_bps,
+		percentile_cont(0.5) WITHIN GROUP (ORDER BY slippage_bps) AS median_slippage_bps,
+		percentile_cont(0.95) WITHIN GROUP (ORDER BY slippage_bps) AS p95_slippage_bps,
+		MAX(slippage_bps) AS max_slippage_bps
+	FROM slippages
+	WHERE %[4]s
+	GROUP BY reserve, token, side, size_bucket
+	ORDER BY reserve, token, side, size_bucket;`
+)
+
+// GetSplitExecutions returns the executed rates of splits between ETH and a token of trades in block
+// range [fromBlock, toBlock]. Splits before Katalyst have no rate recorded, their rate is derived
+// from the amounts.
+func (tldb *TradeLogDB) GetSplitExecutions(fromBlock, toBlock uint64) ([]common.SplitExecution, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"from_block", fromBlock, "to_block", toBlock)
+		rows []struct {
+			SplitID     uint64  `db:"split_id"`
+			BlockNumber uint64  `db:"block_number"`
+			Reserve     string  `db:"reserve"`
+			Src         string  `db:"src"`
+			Dst         string  `db:"dst"`
+			SrcAmount   float64 `db:"src_amount"`
+			DstAmount   float64 `db:"dst_amount"`
+			Rate        float64 `db:"rate"`
+			ETHAmount   float64 `db:"eth_amount"`
+		}
+		result []common.SplitExecution
+	)
+	query := fmt.Sprintf(`SELECT s.id AS split_id, t.block_number, r.address AS reserve, s.src, s.dst,
+		s.src_amount, COALESCE(s.dst_amount, 0) AS dst_amount, s.rate, COALESCE(s.eth_amount, 0) AS eth_amount
+	FROM "split" AS s
+	JOIN "%[1]s" AS t ON t.id = s.trade_id
+	JOIN "%[2]s" AS r ON r.id = s.reserve_id
+	WHERE t.chain_id = $1 AND t.block_number >= $2 AND t.block_number <= $3
+	ORDER BY s.id;`, schema.TradeLogsTableName, schema.ReserveTableName)
+	logger.Debugw("prepare statement", "stmt", query)
+	if err := tldb.db.Select(&rows, query, tldb.chainID, fromBlock, toBlock); err != nil {
+		logger.Errorw("failed to get splits", "error", err)
+		return nil, err
+	}
+	for _, r := range rows {
+		var (
+			src = ethereum.HexToAddress(r.Src)
+			dst = ethereum.HexToAddress(r.Dst)
+		)
+		if (src == blockchain.ETHAddr) == (dst == blockchain.ETHAddr) {
+			continue
+		}
+		rate := r.Rate
+		if rate == 0 && r.SrcAmount != 0 {
+			rate = r.DstAmount / r.SrcAmount
+		}
+		if rate == 0 {
+			continue
+		}
+		execution := common.SplitExecution{
+			SplitID:      r.SplitID,
+			BlockNumber:  r.BlockNumber,
+			Reserve:      ethereum.HexToAddress(r.Reserve),
+			Token:        src,
+			Buy:          src == blockchain.ETHAddr,
+			ExecutedRate: rate,
+			ETHAmount:    r.ETHAmount,
+		}
+		if execution.Buy {
+			execution.Token = dst
+		}
+		result = append(result, execution)
+	}
+	return result, nil
+}
+
+// SaveSlippages replaces slippage of splits of trades in block range [fromBlock, toBlock] with the given ones.
+func (tldb *TradeLogDB) SaveSlippages(fromBlock, toBlock uint64, slippages []common.SplitSlippage) (err error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(),
+			"from_block", fromBlock, "to_block", toBlock)
+		splitIDs                           []int64
+		quoted, executed, slippageBpsArray []float64
+	)
+	for _, s := range slippages {
+		splitIDs = append(splitIDs, int64(s.SplitID))
+		quoted = append(quoted, s.QuotedRate)
+		executed = append(executed, s.ExecutedRate)
+		slippageBpsArray = append(slippageBpsArray, s.SlippageBps)
+	}
+
+	tx, err := tldb.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer pgsql.CommitOrRollback(tx, logger, &err)
+	deleteQuery := fmt.Sprintf(deleteSlippagesTemplate, schema.SplitSlippageTableName, schema.TradeLogsTableName)
+	if _, err = tx.Exec(deleteQuery, tldb.chainID, fromBlock, toBlock); err != nil {
+		logger.Errorw("failed to delete slippages", "error", err)
+		return err
+	}
+	if len(slippages) == 0 {
+		return nil
+	}
+	insertQuery := fmt.Sprintf(insertSlippagesTemplate, schema.SplitSlippageTableName)
+	if _, err = tx.Exec(insertQuery, pq.Array(splitIDs), tldb.chainID,
+		pq.Array(quoted), pq.Array(executed), pq.Array(slippageBpsArray)); err != nil {
+		logger.Errorw("failed to save slippages", "error", err)
+		return err
+	}
+	logger.Debugw("saved slippages", "slippages", len(slippages))
+	return nil
+}
+
+// GetSlippageStats returns slippage of splits between from and to by reserve, token, side and size
+// bucket. Empty reserves or tokens are not filtered.
+func (tldb *TradeLogDB) GetSlippageStats(from, to time.Time, reserves, tokens []ethereum.Address) ([]common.SlippageStats, error) {
+	var (
+		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "from", from, "to", to)
+		args   = []interface{}{tldb.chainID, from, to, blockchain.ETHAddr.Hex(),
+			pq.Array(common.SlippageSizeBuckets)}
+		conditions = []string{"TRUE"}
+		rows       []struct {
+			Reserve          string  `db:"reserve"`
+			Token            string  `db:"token"`
+			Side             string  `db:"side"`
+			SizeBucket       int     `db:"size_bucket"`
+			Splits           uint64  `db:"splits"`
+			VolumeETH        float64 `db:"volume_eth"`
+			WeightedSlippage float64 `db:"weighted_slippage_bps"`
+			MedianSlippage   float64 `db:"median_slippage_bps"`
+			P95Slippage      float64 `db:"p95_slippage_bps"`
+			MaxSlippage      float64 `db:"max_slippage_bps"`
+		}
+		result = []common.SlippageStats{}
+	)
+	if len(reserves) != 0 {
+		args = append(args, addressesToHex(reserves))
+		conditions = append(conditions, fmt.Sprintf("reserve = ANY($%d)", len(args)))
+	}
+	if len(tokens) != 0 {
+		args = append(args, addressesToHex(tokens))
+		conditions = append(conditions, fmt.Sprintf("token = ANY($%d)", len(args)))
+	}
+	query := fmt.Sprintf(slippageStatsTemplate, schema.SplitSlippageTableName, schema.TradeLogsTableName,
+		schema.ReserveTableName, strings.Join(conditions, " AND "))
+	logger.Debugw("prepare statement", "stmt", query)
+	if err := tldb.db.Select(&rows, query, args...); err != nil {
+		logger.Errorw("failed to get slippage stats", "error", err)
+		return nil, err
+	}
+	for _, r := range rows {
+		result = append(result, common.SlippageStats{
+			Reserve:          ethereum.HexToAddress(r.Reserve),
+			Token:            ethereum.HexToAddress(r.Token),
+			Side:             r.Side,
+			SizeBucket:       common.SlippageSizeBucketName(r.SizeBucket - 1),
+			Splits:           r.Splits,
+			VolumeETH:        r.VolumeETH,
+			WeightedSlippage: r.WeightedSlippage,
+			MedianSlippage:   r.MedianSlippage,
+			P95Slippage:      r.P95Slippage,
+			MaxSlippage:      r.MaxSlippage,
+		})
+	}
+	return result, nil
+}
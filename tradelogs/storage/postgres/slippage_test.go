@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func TestSlippages(t *testing.T) {
+	const dbName = "test_slippages"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		knc       = ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200")
+		reserve   = ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f")
+		timestamp = time.Date(2020, 7, 10, 9, 30, 0, 0, time.UTC)
+	)
+	newTrade := func(block uint64, txHash string, kncAmount, ethAmount float64) common.TradelogV4 {
+		return common.TradelogV4{
+			Timestamp:       timestamp,
+			BlockNumber:     block,
+			TransactionHash: ethereum.HexToHash(txHash),
+			Version:         3,
+			User: common.KyberUserInfo{
+				UserAddress: ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b"),
+			},
+			TokenInfo: common.TradeTokenInfo{
+				SrcAddress:  knc,
+				DestAddress: blockchain.ETHAddr,
+			},
+			SrcReserveAddress: reserve,
+			EthAmount:         ethToWei(ethAmount),
+			OriginalEthAmount: ethToWei(ethAmount),
+			SrcAmount:         ethToWei(kncAmount),
+			DestAmount:        ethToWei(ethAmount),
+			ETHUSDRate:        200,
+			TxDetail: common.TxDetail{
+				GasPrice:       big.NewInt(0),
+				TransactionFee: big.NewInt(0),
+			},
+		}
+	}
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{Trades: []common.TradelogV4{
+		newTrade(100, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff01", 1000, 2),
+		newTrade(101, "0x33dcdbed63556a1d90b7e0f626bfaf20f6f532d2ae8bf24c22abb15c4e1fff02", 500, 1.5),
+	}}))
+
+	executions, err := testStorage.GetSplitExecutions(100, 101)
+	require.NoError(t, err)
+	require.Len(t, executions, 2)
+	for _, e := range executions {
+		assert.Equal(t, reserve, e.Reserve)
+		assert.Equal(t, knc, e.Token)
+		assert.False(t, e.Buy)
+	}
+	assert.Equal(t, uint64(100), executions[0].BlockNumber)
+	assert.InDelta(t, 0.002, executions[0].ExecutedRate, 1e-6)
+	assert.InDelta(t, 2, executions[0].ETHAmount, 1e-6)
+	assert.InDelta(t, 0.003, executions[1].ExecutedRate, 1e-6)
+
+	var slippages []common.SplitSlippage
+	for _, e := range executions {
+		slippages = append(slippages, common.SplitSlippage{
+			SplitID:      e.SplitID,
+			QuotedRate:   0.0025,
+			ExecutedRate: e.ExecutedRate,
+			SlippageBps:  (0.0025 - e.ExecutedRate) / 0.0025 * 10000,
+		})
+	}
+	// saving slippage of the same blocks again replaces the previous one
+	for i := 0; i < 2; i++ {
+		require.NoError(t, testStorage.SaveSlippages(100, 101, slippages))
+	}
+
+	stats, err := testStorage.GetSlippageStats(timestamp, timestamp.Add(time.Hour), []ethereum.Address{reserve}, nil)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, reserve, stats[0].Reserve)
+	assert.Equal(t, knc, stats[0].Token)
+	assert.Equal(t, "sell", stats[0].Side)
+	assert.Equal(t, "1-10", stats[0].SizeBucket)
+	assert.Equal(t, uint64(2), stats[0].Splits)
+	assert.InDelta(t, 3.5, stats[0].VolumeETH, 1e-6)
+	assert.InDelta(t, 2000.0/7, stats[0].WeightedSlippage, 1e-2)
+	assert.InDelta(t, 0, stats[0].MedianSlippage, 1e-2)
+	assert.InDelta(t, 2000, stats[0].MaxSlippage, 1e-2)
+
+	stats, err = testStorage.GetSlippageStats(timestamp, timestamp.Add(time.Hour), nil, []ethereum.Address{blockchain.ETHAddr})
+	require.NoError(t, err)
+	assert.Empty(t, stats)
+
+	require.NoError(t, testStorage.DeleteTradeLogsFromBlock(100))
+	stats, err = testStorage.GetSlippageStats(timestamp, timestamp.Add(time.Hour), nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, stats)
+}
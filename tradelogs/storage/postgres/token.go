@@ -13,17 +13,19 @@ import (
 
 const updateTokenSymbolTemplate = `INSERT INTO %[1]s(
 	address,
-	symbol
+	symbol,
+	chain_id
 ) VALUES (
 	unnest($1::TEXT[]), 
-	unnest($2::TEXT[])
-) ON CONFLICT ON CONSTRAINT %[1]s_address_key DO UPDATE SET symbol = EXCLUDED.symbol`
+	unnest($2::TEXT[]),
+	$3
+) ON CONFLICT (chain_id, address) DO UPDATE SET symbol = EXCLUDED.symbol`
 
 func (tldb *TradeLogDB) saveTokens(tx *sqlx.Tx, tokensArray []string) error {
 	var logger = tldb.sugar.With("func", caller.GetCurrentFunctionName())
 	query := fmt.Sprintf(insertionAddressTemplate, schema.TokenTableName)
 	logger.Debugw("updating tokens...", "query", query)
-	_, err := tx.Exec(query, pq.StringArray(tokensArray))
+	_, err := tx.Exec(query, pq.StringArray(tokensArray), tldb.chainID)
 	return err
 }
 
@@ -33,9 +35,9 @@ func (tldb *TradeLogDB) GetTokenSymbol(address string) (string, error) {
 		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName())
 		symbol string
 	)
-	query := fmt.Sprintf("SELECT symbol FROM %1s WHERE address = $1;", schema.TokenTableName)
+	query := fmt.Sprintf("SELECT symbol FROM %1s WHERE address = $1 AND chain_id = $2;", schema.TokenTableName)
 	logger.Debugw("get token symbol", "token", address, "query", query)
-	if err := tldb.db.Get(&symbol, query, ethereum.HexToAddress(address).Hex()); err != nil {
+	if err := tldb.db.Get(&symbol, query, ethereum.HexToAddress(address).Hex(), tldb.chainID); err != nil {
 		if err != sql.ErrNoRows {
 			return symbol, fmt.Errorf("failed to get token symbol: %s", err.Error())
 		}
@@ -48,6 +50,6 @@ func (tldb *TradeLogDB) UpdateTokens(tokensArray []string, symbolArray []string)
 	var logger = tldb.sugar.With("func", caller.GetCurrentFunctionName())
 	query := fmt.Sprintf(updateTokenSymbolTemplate, schema.TokenTableName)
 	logger.Debugw("updating token symbols ...", "query", query)
-	_, err := tldb.db.Exec(query, pq.StringArray(tokensArray), pq.StringArray(symbolArray))
+	_, err := tldb.db.Exec(query, pq.StringArray(tokensArray), pq.StringArray(symbolArray), tldb.chainID)
 	return err
 }
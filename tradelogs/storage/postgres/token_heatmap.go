@@ -11,7 +11,7 @@ import (
 )
 
 // GetTokenHeatmap returns map of country to eth_volume, token_volume, usd_volume filter by timestamp and asset
-func (tldb *TradeLogDB) GetTokenHeatmap(asset ethereum.Address, from, to time.Time, timezone int8) (map[string]common.Heatmap, error) {
+func (tldb *TradeLogDB) GetTokenHeatmap(asset ethereum.Address, from, to time.Time, timezone *time.Location) (map[string]common.Heatmap, error) {
 	var (
 		err               error
 		tokenHeatMapQuery string
@@ -21,7 +21,7 @@ func (tldb *TradeLogDB) GetTokenHeatmap(asset ethereum.Address, from, to time.Ti
 		"func", caller.GetCurrentFunctionName())
 
 	from = schema.RoundTime(from, "day", timezone)
-	to = schema.RoundTime(to, "day", timezone).Add(time.Hour * 24)
+	to = schema.RoundTime(to, "day", timezone).AddDate(0, 0, 1)
 	// nested query with filter by src_address_id and dst_address_id
 	tokenHeatMapQuery = `
 		SELECT country, 
@@ -32,14 +32,14 @@ func (tldb *TradeLogDB) GetTokenHeatmap(asset ethereum.Address, from, to time.Ti
 			SELECT country, src_amount AS token_volume, 
 				eth_amount, eth_amount * eth_usd_rate AS usd_volume
 			FROM "tradelogs"
-			WHERE timestamp >= $1 and timestamp < $2
+			WHERE timestamp >= $1 and timestamp < $2 AND chain_id = $4
 			AND EXISTS (SELECT NULL FROM "token" WHERE address = $3 and id = src_address_id)
 			AND country IS NOT NULL
 		UNION ALL
 			SELECT country, dst_amount AS token_volume, eth_amount, 
 				eth_amount*eth_usd_rate AS usd_volume
 			FROM "tradelogs"
-			WHERE timestamp >= $1 and timestamp < $2
+			WHERE timestamp >= $1 and timestamp < $2 AND chain_id = $4
 			AND EXISTS (SELECT NULL FROM "token" WHERE address = $3 and id = dst_address_id)
 			AND country IS NOT NULL
 		)a GROUP BY country
@@ -52,7 +52,7 @@ func (tldb *TradeLogDB) GetTokenHeatmap(asset ethereum.Address, from, to time.Ti
 		EthVolume   float64 `db:"eth_volume"`
 		UsdVolume   float64 `db:"usd_volume"`
 	}
-	err = tldb.db.Select(&records, tokenHeatMapQuery, from, to, asset.Hex())
+	err = tldb.db.Select(&records, tokenHeatMapQuery, from, to, asset.Hex(), tldb.chainID)
 	if err != nil {
 		return nil, err
 	}
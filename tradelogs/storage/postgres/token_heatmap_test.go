@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"testing"
+	"time"
 
 	ethereum "github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
@@ -35,7 +36,7 @@ func TestTradeLogDB_GetTokenHeatmap(t *testing.T) {
 	require.NoError(t, loadTestData(tldb.db, testDataFile))
 
 	integrationVol, err := tldb.GetTokenHeatmap(ethereum.HexToAddress(accessAddress),
-		fromTime, toTime, 0)
+		fromTime, toTime, time.UTC)
 	require.NoError(t, err)
 	require.Contains(t, integrationVol, country)
 	t.Logf("%+v", integrationVol[country])
@@ -11,7 +11,7 @@ import (
 )
 
 // GetTradeSummary returns trade summary group by day, eth_volume and usd volume are only accounted for not ETH-WETH trades
-func (tldb *TradeLogDB) GetTradeSummary(from, to time.Time, timezone int8) (map[uint64]*common.TradeSummary, error) {
+func (tldb *TradeLogDB) GetTradeSummary(from, to time.Time, timezone *time.Location) (map[uint64]*common.TradeSummary, error) {
 	var (
 		err           error
 		tradelogQuery string
@@ -20,7 +20,7 @@ func (tldb *TradeLogDB) GetTradeSummary(from, to time.Time, timezone int8) (map[
 	logger := tldb.sugar.With("from", from, "to", to, "time_zone", timezone,
 		"func", caller.GetCurrentFunctionName())
 	from = schema.RoundTime(from, "day", timezone)
-	to = schema.RoundTime(to, "day", timezone).Add(time.Hour * 24)
+	to = schema.RoundTime(to, "day", timezone).AddDate(0, 0, 1)
 	results := make(map[uint64]*common.TradeSummary)
 
 	tradelogQuery = `SELECT ` + timeField + ` AS time, 
@@ -29,7 +29,7 @@ func (tldb *TradeLogDB) GetTradeSummary(from, to time.Time, timezone int8) (map[
 		COUNT(CASE WHEN kyced THEN 1 END) AS kyced,
 		COUNT(CASE WHEN is_first_trade THEN 1 END) AS count_new_trades
 		FROM tradelogs
-		WHERE timestamp >= $1 AND timestamp < $2
+		WHERE timestamp >= $1 AND timestamp < $2 AND chain_id = $3
 		GROUP BY time
 	`
 	logger.Debugw("prepare statement", "stmt", tradelogQuery)
@@ -40,7 +40,7 @@ func (tldb *TradeLogDB) GetTradeSummary(from, to time.Time, timezone int8) (map[
 		CountNewTrades uint64    `db:"count_new_trades"`
 		Kyced          uint64    `db:"kyced"`
 	}
-	if err = tldb.db.Select(&countRecords, tradelogQuery, from, to); err != nil {
+	if err = tldb.db.Select(&countRecords, tradelogQuery, from, to, tldb.chainID); err != nil {
 		return nil, err
 	}
 
@@ -64,7 +64,7 @@ func (tldb *TradeLogDB) GetTradeSummary(from, to time.Time, timezone int8) (map[
 		SUM(eth_amount*eth_usd_rate) as total_usd_volume, 
 		AVG(eth_amount*eth_usd_rate) usd_per_trade, count(1) as total_trade 
 	FROM "tradelogs"
-	WHERE timestamp >= $1 AND timestamp < $2
+	WHERE timestamp >= $1 AND timestamp < $2 AND chain_id = $3
 	GROUP BY time
 	`, timeField, schema.TradeLogsTableName)
 	logger.Debugw("prepare statement", "stmt", tradelogQuery)
@@ -76,7 +76,7 @@ func (tldb *TradeLogDB) GetTradeSummary(from, to time.Time, timezone int8) (map[
 		UsdPerTrade    float64   `db:"usd_per_trade"`
 		TotalTrade     uint64    `db:"total_trade"`
 	}
-	err = tldb.db.Select(&records, tradelogQuery, from, to)
+	err = tldb.db.Select(&records, tradelogQuery, from, to, tldb.chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -28,7 +28,7 @@ func TestTradeLogDB_GetTradeSummary(t *testing.T) {
 	var (
 		fromTime = timeutil.TimestampMsToTime(1539216000000)
 		toTime   = timeutil.TimestampMsToTime(1539254666000)
-		timezone int8
+		timezone = time.UTC
 	)
 
 	tldb, err := newTestTradeLogPostgresql(dbName)
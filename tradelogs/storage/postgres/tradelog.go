@@ -2,7 +2,10 @@ package postgres
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
+	"sort"
+	"strings"
 
 	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
 	"github.com/KyberNetwork/reserve-stats/lib/caller"
@@ -10,6 +13,7 @@ import (
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
 	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 )
 
@@ -19,14 +23,15 @@ func (tldb *TradeLogDB) saveReserve(reserves []common.Reserve) error {
 		addresses, reserveIDs, rebateWallets []string
 		blockNumbers, reserveTypes           []uint64
 	)
-	query := `INSERT INTO reserve (address, reserve_id, rebate_wallet, block_number, reserve_type)
+	query := `INSERT INTO reserve (address, reserve_id, rebate_wallet, block_number, reserve_type, chain_id)
 	VALUES(
 		UNNEST($1::TEXT[]),
 		UNNEST($2::TEXT[]),
 		UNNEST($3::TEXT[]),
 		UNNEST($4::INTEGER[]),
-		UNNEST($5::INTEGER[])
-	) ON CONFLICT (address, reserve_id, block_number) DO NOTHING;`
+		UNNEST($5::INTEGER[]),
+		$6
+	) ON CONFLICT (chain_id, address, reserve_id, block_number) DO NOTHING;`
 	logger.Infow("save reserve", "query", query)
 	for _, r := range reserves {
 		addresses = append(addresses, r.Address.Hex())
@@ -36,7 +41,7 @@ func (tldb *TradeLogDB) saveReserve(reserves []common.Reserve) error {
 		reserveTypes = append(reserveTypes, r.ReserveType)
 	}
 	if _, err := tldb.db.Exec(query, pq.StringArray(addresses), pq.StringArray(reserveIDs), pq.StringArray(rebateWallets),
-		pq.Array(blockNumbers), pq.Array(reserveTypes)); err != nil {
+		pq.Array(blockNumbers), pq.Array(reserveTypes), tldb.chainID); err != nil {
 		logger.Errorw("failed to add reserve into db", "error", err)
 		return err
 	}
@@ -47,14 +52,15 @@ func (tldb *TradeLogDB) updateRebateWallet(reserves []common.Reserve) error {
 	var (
 		logger = tldb.sugar.With("func", caller.GetCurrentFunctionName())
 	)
-	query := `INSERT INTO reserve(address, reserve_id, rebate_wallet, block_number, reserve_type)
+	query := `INSERT INTO reserve(address, reserve_id, rebate_wallet, block_number, reserve_type, chain_id)
 		VALUES (
-		(SELECT address FROM reserve WHERE reserve_id = $1 order by block_number desc limit 1),
+		(SELECT address FROM reserve WHERE reserve_id = $1 AND chain_id = $4 order by block_number desc limit 1),
 		$1,
 		$2,
 		$3, 
-		(SELECT reserve_type FROM reserve WHERE reserve_id = $1 order by block_number desc limit 1)
-		) ON CONFLICT (address, reserve_id, block_number) DO NOTHING;`
+		(SELECT reserve_type FROM reserve WHERE reserve_id = $1 AND chain_id = $4 order by block_number desc limit 1),
+		$4
+		) ON CONFLICT (chain_id, address, reserve_id, block_number) DO NOTHING;`
 	logger.Infow("query update rebate wallet", "value", query)
 	tx, err := tldb.db.Beginx()
 	if err != nil {
@@ -62,7 +68,7 @@ func (tldb *TradeLogDB) updateRebateWallet(reserves []common.Reserve) error {
 	}
 	defer pgsql.CommitOrRollback(tx, logger, &err)
 	for _, r := range reserves {
-		if _, err := tx.Exec(query, ethereum.BytesToHash(r.ReserveID[:]).Hex(), r.RebateWallet.Hex(), r.BlockNumber); err != nil {
+		if _, err := tx.Exec(query, ethereum.BytesToHash(r.ReserveID[:]).Hex(), r.RebateWallet.Hex(), r.BlockNumber, tldb.chainID); err != nil {
 			return err
 		}
 	}
@@ -255,7 +261,9 @@ func (tldb *TradeLogDB) SaveTradeLogs(crResult *common.CrawlResult) (err error)
 		tokensArray         []string
 		records             []*record
 
-		users = make(map[ethereum.Address]struct{})
+		users       = make(map[ethereum.Address]struct{})
+		userRecords []*record // first record of each user
+		candles     = make(candleBuckets)
 	)
 	if crResult != nil {
 		if len(crResult.Reserves) > 0 {
@@ -277,17 +285,13 @@ func (tldb *TradeLogDB) SaveTradeLogs(crResult *common.CrawlResult) (err error)
 				return err
 			}
 
-			if _, ok := users[log.User.UserAddress]; ok {
-				r.IsFirstTrade = false
-			} else {
-				isFirstTrade, err := tldb.isFirstTrade(log.User.UserAddress)
-				if err != nil {
-					return err
-				}
-				r.IsFirstTrade = isFirstTrade
-			}
 			records = append(records, r)
-			users[log.User.UserAddress] = struct{}{}
+			if _, ok := users[log.User.UserAddress]; !ok {
+				users[log.User.UserAddress] = struct{}{}
+				userRecords = append(userRecords, r)
+			}
+			candles.add(log.TokenInfo.SrcAddress, log.Timestamp)
+			candles.add(log.TokenInfo.DestAddress, log.Timestamp)
 		}
 
 		for _, r := range records {
@@ -336,15 +340,24 @@ func (tldb *TradeLogDB) SaveTradeLogs(crResult *common.CrawlResult) (err error)
 			return err
 		}
 
-		for _, r := range records {
-			logger.Debugw("Record", "record", r)
+		// users are added and locked in address order, so concurrent saves do not deadlock
+		sort.Slice(userRecords, func(i, j int) bool { return userRecords[i].UserAddress < userRecords[j].UserAddress })
+		for _, r := range userRecords {
 			_, err = tx.NamedExec(insertionUserTemplate, r)
 			if err != nil {
 				logger.Infow("user", "address", r.UserAddress)
 				logger.Debugw("Error while add users", "error", err)
 				return err
 			}
+		}
+		userIDs, err := tldb.lockUsers(tx, userRecords)
+		if err != nil {
+			logger.Debugw("failed to lock users", "error", err)
+			return err
+		}
 
+		for _, r := range records {
+			logger.Debugw("Record", "record", r)
 			_, err = tx.NamedExec(insertionWalletTemplate, r)
 			if err != nil {
 				logger.Debugw("Error while add wallet", "error", err)
@@ -355,7 +368,7 @@ func (tldb *TradeLogDB) SaveTradeLogs(crResult *common.CrawlResult) (err error)
 			create_or_update_tradelogs(
 				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
 				$13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25,
-				$26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43
+				$26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44
 			);`
 			var tradelogID uint64
 			reserveAddresses, platformWallets, burns, rebates, rewards, platformFees, walletFees, feeIndexes, rebateWallets, rebatePercents, err := tldb.prepareFeeRecords(r)
@@ -411,24 +424,209 @@ func (tldb *TradeLogDB) SaveTradeLogs(crResult *common.CrawlResult) (err error)
 				pq.Array(rates),
 				pq.Array(dstAmounts),
 				pq.Array(splitIndexes),
+				r.ChainID,
 			); err != nil {
 				logger.Debugw("failed to save tradelogs", "error", err)
 				return err
 			}
 		}
 
+		if err = tldb.updateFirstTrades(tx, userIDs); err != nil {
+			logger.Debugw("failed to update first trades", "error", err)
+			return err
+		}
+
+		if err = tldb.refreshCandles(tx, candles); err != nil {
+			logger.Debugw("failed to refresh candles", "error", err)
+			return err
+		}
+
+		if len(crResult.Blocks) > 0 {
+			if err = tldb.saveBlockHashes(tx, crResult.Blocks); err != nil {
+				logger.Debugw("failed to save block hashes", "error", err)
+				return err
+			}
+		}
+
 		return err
 	}
 	return nil
 }
 
-func (tldb *TradeLogDB) isFirstTrade(userAddr ethereum.Address) (bool, error) {
-	query := `SELECT NOT EXISTS(SELECT NULL FROM "` + schema.UserTableName + `" WHERE address=$1);`
-	row := tldb.db.QueryRow(query, userAddr.Hex())
-	var result bool
-	if err := row.Scan(&result); err != nil {
-		tldb.sugar.Error(err)
-		return false, err
+// lockUsers locks rows of users of given records until the transaction ends and returns their ids.
+// Block ranges can be saved concurrently and out of order, first trades of a user are only updated by
+// the transaction holding the lock, which sees trades committed by the others.
+func (tldb *TradeLogDB) lockUsers(tx *sqlx.Tx, userRecords []*record) ([]int64, error) {
+	var (
+		addresses []string
+		ids       []int64
+	)
+	if len(userRecords) == 0 {
+		return nil, nil
+	}
+	for _, r := range userRecords {
+		addresses = append(addresses, r.UserAddress)
+	}
+	query := `SELECT id FROM "` + schema.UserTableName + `" WHERE chain_id = $1 AND address = ANY($2::TEXT[])
+	ORDER BY address FOR UPDATE;`
+	if err := tx.Select(&ids, query, tldb.chainID, pq.StringArray(addresses)); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// updateFirstTrades flags the earliest trade of given users by block number and index as their first
+// trade, and unflags the others. As users are locked, it is correct whatever the order ranges are saved.
+func (tldb *TradeLogDB) updateFirstTrades(tx *sqlx.Tx, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	query := `UPDATE "` + schema.TradeLogsTableName + `" AS a SET is_first_trade = (a.id = f.id)
+	FROM (
+		SELECT DISTINCT ON (user_address_id) user_address_id, id FROM "` + schema.TradeLogsTableName + `"
+		WHERE user_address_id = ANY($1::BIGINT[])
+		ORDER BY user_address_id, block_number, index
+	) AS f
+	WHERE a.user_address_id = f.user_address_id AND a.is_first_trade IS DISTINCT FROM (a.id = f.id);`
+	tldb.sugar.Debugw("updating first trades", "query", query, "users", len(userIDs))
+	_, err := tx.Exec(query, pq.Array(userIDs))
+	return err
+}
+
+const selectTradeLogsPageTemplate = `
+WITH page AS (
+	SELECT a.id FROM tradelogs AS a
+	WHERE %[1]s
+	ORDER BY a.block_number, a.index
+	%[2]s
+)
+SELECT a.id, a.timestamp AS timestamp, a.block_number, a.eth_amount, original_eth_amount, eth_usd_rate, 
+COALESCE(a.eth_usd_provider, '') AS eth_usd_provider,
+ARRAY_AGG(d.address) AS user_address,
+ARRAY_AGG(e.address) AS src_address, 
+ARRAY_AGG(f.address) AS dst_address,
+a.src_amount, 
+a.dst_amount, 
+ip, country, integration_app, 
+a.index, tx_hash, tx_sender, receiver_address, 
+ARRAY_AGG(w.address) as wallet_address,
+COALESCE(gas_used, 0) as gas_used, COALESCE(gas_price, 0) as gas_price, 
+COALESCE(transaction_fee, 0) as transaction_fee, 
+version,
+ARRAY_REMOVE(ARRAY_AGG(fee.reserve_address), NULL) as fee_reserve_address,
+ARRAY_REMOVE(ARRAY_AGG(fee.wallet_address), NULL) as fee_wallet_address,
+ARRAY_REMOVE(ARRAY_AGG(fee.wallet_fee), NULL) as wallet_fee,
+ARRAY_REMOVE(ARRAY_AGG(fee.platform_fee), NULL) as platform_fee,
+ARRAY_REMOVE(ARRAY_AGG(fee.burn), NULL) as burn,
+ARRAY_REMOVE(ARRAY_AGG(fee.rebate), NULL) as rebate,
+ARRAY_REMOVE(ARRAY_AGG(fee.reward), NULL) as reward,
+ARRAY_REMOVE(ARRAY_AGG(fee.index), NULL) as fee_index,
+ARRAY_REMOVE(ARRAY_AGG(fee.rebate_wallets), NULL) as rebate_wallets,
+ARRAY_REMOVE(ARRAY_AGG(fee.rebate_percents), NULL) as rebate_percents,
+
+ARRAY_AGG(COALESCE(sr.address, '')) FILTER (WHERE split.id IS NOT NULL) as split_reserve_address,
+ARRAY_AGG(split.src) FILTER (WHERE split.id IS NOT NULL) as split_src,
+ARRAY_AGG(split.dst) FILTER (WHERE split.id IS NOT NULL) as split_dst,
+ARRAY_AGG(split.src_amount) FILTER (WHERE split.id IS NOT NULL) as split_src_amount,
+ARRAY_AGG(split.rate) FILTER (WHERE split.id IS NOT NULL) as split_rate,
+ARRAY_AGG(split.dst_amount) FILTER (WHERE split.id IS NOT NULL) as split_dst_amount,
+ARRAY_AGG(split.index) FILTER (WHERE split.id IS NOT NULL) as split_index
+
+FROM tradelogs AS a
+INNER JOIN users AS d ON a.user_address_id = d.id
+INNER JOIN token AS e ON a.src_address_id = e.id
+INNER JOIN token AS f ON a.dst_address_id = f.id
+INNER JOIN wallet as w on a.wallet_address_id = w.id
+LEFT JOIN fee ON fee.trade_id = a.id
+LEFT JOIN split ON split.trade_id = a.id
+-- trades without split or reserve are kept, so every trade of the page is returned
+LEFT JOIN reserve sr ON sr.id = split.reserve_id
+WHERE a.id IN (SELECT id FROM page)
+GROUP BY a.id
+ORDER BY a.block_number, a.index;
+`
+
+func addressesToHex(addresses []ethereum.Address) pq.StringArray {
+	var result pq.StringArray
+	for _, addr := range addresses {
+		result = append(result, addr.Hex())
+	}
+	return result
+}
+
+// tradeLogsPageQuery builds the query to select trade logs matching given filter, all filters are applied
+// before pagination so only ids of the page are aggregated. Zero time and block bounds are not filtered.
+func (tldb *TradeLogDB) tradeLogsPageQuery(filter common.TradeLogFilter) (string, []interface{}) {
+	var (
+		args       = []interface{}{tldb.chainID}
+		conditions = []string{"a.chain_id = $1"}
+		limit      string
+	)
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if !filter.From.IsZero() {
+		addCondition("a.timestamp >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("a.timestamp <= $%d", filter.To)
+	}
+	if filter.FromBlock != 0 {
+		addCondition("a.block_number >= $%d", filter.FromBlock)
+	}
+	if filter.ToBlock != 0 {
+		addCondition("a.block_number <= $%d", filter.ToBlock)
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.BlockNumber, filter.After.LogIndex)
+		conditions = append(conditions, fmt.Sprintf("(a.block_number, a.index) > ($%d, $%d)", len(args)-1, len(args)))
+	}
+	if len(filter.Tokens) != 0 {
+		addCondition(`EXISTS (SELECT NULL FROM token WHERE address = ANY($%[1]d)
+		AND (token.id = a.src_address_id OR token.id = a.dst_address_id))`, addressesToHex(filter.Tokens))
+	}
+	if len(filter.Reserves) != 0 {
+		addCondition(`EXISTS (SELECT NULL FROM split JOIN reserve ON reserve.id = split.reserve_id
+		WHERE split.trade_id = a.id AND reserve.address = ANY($%[1]d))`, addressesToHex(filter.Reserves))
+	}
+	if len(filter.Wallets) != 0 {
+		addCondition(`EXISTS (SELECT NULL FROM wallet WHERE wallet.id = a.wallet_address_id AND wallet.address = ANY($%[1]d))`,
+			addressesToHex(filter.Wallets))
+	}
+	if len(filter.Users) != 0 {
+		addCondition(`EXISTS (SELECT NULL FROM users WHERE users.id = a.user_address_id AND users.address = ANY($%[1]d))`,
+			addressesToHex(filter.Users))
+	}
+	if len(filter.IntegrationApps) != 0 {
+		addCondition(`a.integration_app = ANY($%[1]d)`, pq.StringArray(filter.IntegrationApps))
+	}
+	if filter.Limit > 0 {
+		limit = fmt.Sprintf("LIMIT %d", filter.Limit)
+	}
+	return fmt.Sprintf(selectTradeLogsPageTemplate, strings.Join(conditions, " AND "), limit), args
+}
+
+// LoadTradeLogsPage returns trade logs matching given filter, ordered by block number and log index.
+func (tldb *TradeLogDB) LoadTradeLogsPage(filter common.TradeLogFilter) ([]common.TradelogV4, error) {
+	var (
+		logger      = tldb.sugar.With("func", caller.GetCurrentFunctionName(), "from", filter.From, "to", filter.To)
+		queryResult []tradeLogDBData
+		result      = make([]common.TradelogV4, 0)
+	)
+	query, args := tldb.tradeLogsPageQuery(filter)
+	logger.Debugw("loading trade logs page", "query", query)
+	if err := tldb.db.Select(&queryResult, query, args...); err != nil {
+		logger.Errorw("failed to get trade logs page from database", "error", err)
+		return nil, err
+	}
+	for _, r := range queryResult {
+		tradeLog, err := tldb.tradeLogFromDBData(r)
+		if err != nil {
+			logger.Errorw("cannot parse db data to trade log", "error", err)
+			return nil, err
+		}
+		result = append(result, tradeLog)
 	}
 	return result, nil
 }
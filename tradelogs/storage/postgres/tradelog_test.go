@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/blockchain"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
+)
+
+func TestSaveTradeLogsFirstTrade(t *testing.T) {
+	const dbName = "test_save_first_trade"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	var (
+		userA     = ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b")
+		userB     = ethereum.HexToAddress("0x0000000000007f150bd6f54c40a34d7c3d5e9f56")
+		timestamp = time.Date(2020, 7, 6, 0, 0, 0, 0, time.UTC)
+	)
+	trade := func(user ethereum.Address, block uint64) common.TradelogV4 {
+		return common.TradelogV4{
+			Timestamp:       timestamp.Add(time.Duration(block) * 15 * time.Second),
+			BlockNumber:     block,
+			TransactionHash: ethereum.HexToHash(fmt.Sprintf("0x%064x", block)),
+			Version:         3,
+			User:            common.KyberUserInfo{UserAddress: user},
+			TokenInfo: common.TradeTokenInfo{
+				SrcAddress:  ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200"),
+				DestAddress: blockchain.ETHAddr,
+			},
+			SrcReserveAddress: ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f"),
+			EthAmount:         ethToWei(1),
+			OriginalEthAmount: ethToWei(1),
+			SrcAmount:         ethToWei(100),
+			DestAmount:        ethToWei(1),
+			ETHUSDRate:        200,
+			TxDetail: common.TxDetail{
+				GasPrice:       big.NewInt(0),
+				TransactionFee: big.NewInt(0),
+			},
+		}
+	}
+	// the later range is saved first, as ranges of the crawl queue are
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{
+		Trades: []common.TradelogV4{trade(userA, 200), trade(userA, 201), trade(userB, 202)},
+	}))
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{
+		Trades: []common.TradelogV4{trade(userA, 100)},
+	}))
+
+	var records []struct {
+		BlockNumber  uint64 `db:"block_number"`
+		IsFirstTrade bool   `db:"is_first_trade"`
+	}
+	require.NoError(t, testStorage.db.Select(&records, `SELECT block_number, is_first_trade FROM "`+
+		schema.TradeLogsTableName+`" ORDER BY block_number`))
+	require.Len(t, records, 4)
+	for i, expected := range []bool{true, false, false, true} {
+		assert.Equal(t, expected, records[i].IsFirstTrade, "block %d", records[i].BlockNumber)
+	}
+}
+
+func TestLoadTradeLogsPageWithoutSplits(t *testing.T) {
+	const dbName = "test_load_page_without_splits"
+	testStorage, err := newTestTradeLogPostgresql(dbName)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, testStorage.tearDown(dbName))
+	}()
+
+	timestamp := time.Date(2020, 7, 6, 0, 0, 0, 0, time.UTC)
+	trade := func(block uint64, version uint) common.TradelogV4 {
+		return common.TradelogV4{
+			Timestamp:       timestamp.Add(time.Duration(block) * 15 * time.Second),
+			BlockNumber:     block,
+			TransactionHash: ethereum.HexToHash(fmt.Sprintf("0x%064x", block)),
+			Version:         version,
+			User:            common.KyberUserInfo{UserAddress: ethereum.HexToAddress("0x85c5c26dc2af5546341fc1988b9d178148b4838b")},
+			TokenInfo: common.TradeTokenInfo{
+				SrcAddress:  ethereum.HexToAddress("0xdd974d5c2e2928dea5f71b9825b8b646686bd200"),
+				DestAddress: blockchain.ETHAddr,
+			},
+			SrcReserveAddress: ethereum.HexToAddress("0x63825c174ab367968ec60f061753d3bbd36a0d8f"),
+			EthAmount:         ethToWei(1),
+			OriginalEthAmount: ethToWei(1),
+			SrcAmount:         ethToWei(100),
+			DestAmount:        ethToWei(1),
+			ETHUSDRate:        200,
+			TxDetail: common.TxDetail{
+				GasPrice:       big.NewInt(0),
+				TransactionFee: big.NewInt(0),
+			},
+		}
+	}
+	// the v4 trade has no T2E/E2T reserves, so no split is saved for it
+	require.NoError(t, testStorage.SaveTradeLogs(&common.CrawlResult{
+		Trades: []common.TradelogV4{trade(100, 3), trade(101, 4), trade(102, 3)},
+	}))
+
+	page, err := testStorage.LoadTradeLogsPage(common.TradeLogFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, uint64(100), page[0].BlockNumber)
+	assert.Len(t, page[0].Split, 1)
+	assert.Equal(t, uint64(101), page[1].BlockNumber)
+	assert.Empty(t, page[1].Split)
+
+	page, err = testStorage.LoadTradeLogsPage(common.TradeLogFilter{
+		After: &common.TradeLogCursor{BlockNumber: page[1].BlockNumber, LogIndex: page[1].Index},
+		Limit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, uint64(102), page[0].BlockNumber)
+}
@@ -18,14 +18,14 @@ func (tldb *TradeLogDB) GetUserList(fromTime, toTime time.Time) ([]common.UserIn
 			SUM(eth_amount * eth_usd_rate) total_usd_volume
 		FROM "tradelogs" a
 		INNER JOIN "users" b ON a.user_address_id =b.id
-		WHERE a.timestamp >= $1 and a.timestamp <= $2
+		WHERE a.timestamp >= $1 and a.timestamp <= $2 AND a.chain_id = $3
 		GROUP BY user_address
 	`
 	logger.Debugw("prepare statement", "stmt", userListQuery)
 
 	var result []common.UserInfo
 	if err := tldb.db.Select(&result, userListQuery, fromTime,
-		toTime); err != nil {
+		toTime, tldb.chainID); err != nil {
 		return nil, err
 	}
 	if len(result) == 0 {
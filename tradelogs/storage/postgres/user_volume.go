@@ -14,7 +14,8 @@ import (
 )
 
 // GetUserVolume returns user volume filter by user address in a time range group by day or hour
-func (tldb *TradeLogDB) GetUserVolume(userAddress ethereum.Address, from, to time.Time, freq string) (map[uint64]common.UserVolume, error) {
+func (tldb *TradeLogDB) GetUserVolume(userAddress ethereum.Address, from, to time.Time, freq string,
+	timezone *time.Location) (map[uint64]common.UserVolume, error) {
 	var (
 		timeField string
 		logger    = tldb.sugar.With("from", from, "to", to,
@@ -22,13 +23,13 @@ func (tldb *TradeLogDB) GetUserVolume(userAddress ethereum.Address, from, to tim
 	)
 	switch strings.ToLower(freq) {
 	case "h":
-		timeField = schema.BuildDateTruncField("hour", 0)
-		from = schema.RoundTime(from, "hour", 0)
-		to = schema.RoundTime(to, "hour", 0).Add(time.Hour)
+		timeField = schema.BuildDateTruncField("hour", timezone)
+		from = schema.RoundTime(from, "hour", timezone)
+		to = schema.RoundTime(to, "hour", timezone).Add(time.Hour)
 	case "d":
-		timeField = schema.BuildDateTruncField("day", 0)
-		from = schema.RoundTime(from, "day", 0)
-		to = schema.RoundTime(to, "day", 0).Add(time.Hour * 24)
+		timeField = schema.BuildDateTruncField("day", timezone)
+		from = schema.RoundTime(from, "day", timezone)
+		to = schema.RoundTime(to, "day", timezone).AddDate(0, 0, 1)
 
 	default:
 		return nil, fmt.Errorf("frequency not supported: %v", freq)
@@ -39,7 +40,7 @@ func (tldb *TradeLogDB) GetUserVolume(userAddress ethereum.Address, from, to tim
 			SUM(eth_amount) eth_volume,
 			SUM(eth_amount * eth_usd_rate) usd_volume
 		FROM "tradelogs" a
-		WHERE timestamp >= $1 AND timestamp < $2
+		WHERE timestamp >= $1 AND timestamp < $2 AND chain_id = $4
 		AND EXISTS (SELECT NULL FROM "users" WHERE user_address_id = id AND address = $3)
 		GROUP BY time;
 	`, timeField)
@@ -50,7 +51,7 @@ func (tldb *TradeLogDB) GetUserVolume(userAddress ethereum.Address, from, to tim
 		EthAmount float64   `db:"eth_volume"`
 		UsdAmount float64   `db:"usd_volume"`
 	}
-	if err := tldb.db.Select(&records, query, from, to.UTC(), userAddress.Hex()); err != nil {
+	if err := tldb.db.Select(&records, query, from, to.UTC(), userAddress.Hex(), tldb.chainID); err != nil {
 		return nil, err
 	}
 
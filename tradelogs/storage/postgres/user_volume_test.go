@@ -37,7 +37,7 @@ func TestTradeLogDB_GetUserVolume(t *testing.T) {
 		from        = timeutil.TimestampMsToTime(uint64(fromTime))
 		to          = timeutil.TimestampMsToTime(uint64(toTime))
 	)
-	userVolume, err := tldb.GetUserVolume(userAddress, from, to, freq)
+	userVolume, err := tldb.GetUserVolume(userAddress, from, to, freq, time.UTC)
 	require.NoError(t, err)
 	timeUnix, err := time.Parse(time.RFC3339, timeStamp)
 	require.NoError(t, err)
@@ -13,47 +13,49 @@ import (
 	"github.com/KyberNetwork/reserve-stats/tradelogs/storage/postgres/schema"
 )
 
-// GetAssetVolume returns eth_amount, usd_amount, volume filter by token addr in a time range group by day or hour
+// GetAssetVolume returns eth_amount, usd_amount, volume filter by token addr in a time range group by day or hour,
+// usd_amount is priced with the ETH/USD rates of provider, or the rates fixed at crawl time if provider is empty
 func (tldb *TradeLogDB) GetAssetVolume(token ethereum.Address, fromTime, toTime time.Time,
-	frequency string) (map[uint64]*common.VolumeStats, error) {
+	frequency, provider string, timezone *time.Location) (map[uint64]*common.VolumeStats, error) {
 	var (
 		err       error
 		timeField string
 		logger    = tldb.sugar.With("from", fromTime, "to", toTime, "frequency", frequency, "token", token.Hex(),
-			"func", caller.GetCurrentFunctionName())
+			"provider", provider, "func", caller.GetCurrentFunctionName())
 	)
 
 	switch strings.ToLower(frequency) {
 	case "h":
-		timeField = schema.BuildDateTruncField("hour", 0)
-		fromTime = schema.RoundTime(fromTime, "hour", 0)
-		toTime = schema.RoundTime(toTime, "hour", 0).Add(time.Hour)
+		timeField = schema.BuildDateTruncField("hour", timezone)
+		fromTime = schema.RoundTime(fromTime, "hour", timezone)
+		toTime = schema.RoundTime(toTime, "hour", timezone).Add(time.Hour)
 	case "d":
-		timeField = schema.BuildDateTruncField("day", 0)
-		fromTime = schema.RoundTime(fromTime, "day", 0)
-		toTime = schema.RoundTime(toTime, "day", 0).Add(time.Hour * 24)
+		timeField = schema.BuildDateTruncField("day", timezone)
+		fromTime = schema.RoundTime(fromTime, "day", timezone)
+		toTime = schema.RoundTime(toTime, "day", timezone).AddDate(0, 0, 1)
 
 	default:
 		return nil, fmt.Errorf("frequency not supported: %v", frequency)
 	}
 
+	args := []interface{}{fromTime, toTime, token.Hex(), tldb.chainID}
 	queryStmt := fmt.Sprintf(`
 		SELECT time, 
 		SUM(token_volume) token_volume, 
 		SUM(eth_amount) eth_volume,
-		SUM(eth_amount * eth_usd_rate) usd_volume
+		COALESCE(SUM(eth_amount * eth_usd_rate), 0) usd_volume
 		FROM (
-			SELECT %[1]s AS time, src_amount token_volume, eth_amount, eth_usd_rate
+			SELECT %[1]s AS time, src_amount token_volume, eth_amount, %[2]s AS eth_usd_rate
 			FROM tradelogs
 			WHERE EXISTS (SELECT NULL FROM "token" WHERE address = $3 AND id=src_address_id)
-				AND timestamp >= $1 AND timestamp < $2
+				AND timestamp >= $1 AND timestamp < $2 AND chain_id = $4
 			UNION ALL
-			SELECT %[1]s AS time, dst_amount token_volume, eth_amount, eth_usd_rate
+			SELECT %[1]s AS time, dst_amount token_volume, eth_amount, %[2]s AS eth_usd_rate
 			FROM "tradelogs" 
 			WHERE EXISTS (SELECT NULL FROM "token" WHERE address = $3 AND id=dst_address_id)
-				AND timestamp >= $1 AND timestamp < $2
+				AND timestamp >= $1 AND timestamp < $2 AND chain_id = $4
 		) a GROUP BY time;
-	`, timeField)
+	`, timeField, ethUSDRateField("tradelogs", provider, &args))
 	logger.Debugw("prepare statement", "stmt", queryStmt)
 
 	var records []struct {
@@ -62,7 +64,7 @@ func (tldb *TradeLogDB) GetAssetVolume(token ethereum.Address, fromTime, toTime
 		USDVolume   float64   `db:"usd_volume"`
 		Time        time.Time `db:"time"`
 	}
-	err = tldb.db.Select(&records, queryStmt, fromTime, toTime, token.Hex())
+	err = tldb.db.Select(&records, queryStmt, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -82,56 +84,58 @@ func (tldb *TradeLogDB) GetAssetVolume(token ethereum.Address, fromTime, toTime
 	return result, nil
 }
 
-// GetReserveVolume returns eth_amount, usd_amount, volume filter by reserve addr and token addr in a time range group by day or hour
+// GetReserveVolume returns eth_amount, usd_amount, volume filter by reserve addr and token addr in a time range group by day or hour,
+// usd_amount is priced with the ETH/USD rates of provider, or the rates fixed at crawl time if provider is empty
 func (tldb *TradeLogDB) GetReserveVolume(rsvAddr ethereum.Address, token ethereum.Address,
-	fromTime, toTime time.Time, frequency string) (map[uint64]*common.VolumeStats, error) {
+	fromTime, toTime time.Time, frequency, provider string, timezone *time.Location) (map[uint64]*common.VolumeStats, error) {
 	var (
 		err       error
 		timeField string
-		logger    = tldb.sugar.With("from", fromTime, "to", toTime, "frequency", frequency,
+		logger    = tldb.sugar.With("from", fromTime, "to", toTime, "frequency", frequency, "provider", provider,
 			"func", caller.GetCurrentFunctionName())
 	)
 
 	switch strings.ToLower(frequency) {
 	case "h":
-		timeField = schema.BuildDateTruncField("hour", 0)
-		fromTime = schema.RoundTime(fromTime, "hour", 0)
-		toTime = schema.RoundTime(toTime, "hour", 0).Add(time.Hour)
+		timeField = schema.BuildDateTruncField("hour", timezone)
+		fromTime = schema.RoundTime(fromTime, "hour", timezone)
+		toTime = schema.RoundTime(toTime, "hour", timezone).Add(time.Hour)
 	case "d":
-		timeField = schema.BuildDateTruncField("day", 0)
-		fromTime = schema.RoundTime(fromTime, "day", 0)
-		toTime = schema.RoundTime(toTime, "day", 0).Add(time.Hour * 24)
+		timeField = schema.BuildDateTruncField("day", timezone)
+		fromTime = schema.RoundTime(fromTime, "day", timezone)
+		toTime = schema.RoundTime(toTime, "day", timezone).AddDate(0, 0, 1)
 
 	default:
 		return nil, fmt.Errorf("frequency not supported: %v", frequency)
 	}
 
+	args := []interface{}{token.Hex(), rsvAddr.Hex(), fromTime, toTime, tldb.chainID}
 	reserveQuery := fmt.Sprintf(`
 		SELECT 
 			time, 
 			SUM(token_volume) token_volume, 
 			SUM(eth_amount) eth_volume,
-			SUM(eth_amount * eth_usd_rate) usd_volume
+			COALESCE(SUM(eth_amount * eth_usd_rate), 0) usd_volume
 		FROM (
 			SELECT %[1]s AS time, 
 				src_amount token_volume, 
 				eth_amount, 
-				eth_usd_rate
+				%[2]s AS eth_usd_rate
 			FROM "tradelogs" 
 			WHERE EXISTS (SELECT NULL FROM "token" WHERE address = $1 AND id=src_address_id)
 				AND EXISTS (SELECT NULL FROM "reserve" WHERE address = $2 AND (id= src_reserve_address_id OR id = dst_reserve_address_id))
-				AND timestamp >= $3 AND timestamp < $4 
+				AND timestamp >= $3 AND timestamp < $4 AND chain_id = $5
 			UNION ALL
 			SELECT %[1]s AS time, 
 				dst_amount token_volume, 
 				eth_amount, 
-				eth_usd_rate
+				%[2]s AS eth_usd_rate
 			FROM "tradelogs"
 			WHERE EXISTS (SELECT NULL FROM "token" WHERE address = $1 AND id=dst_address_id)
 				AND EXISTS (SELECT NULL FROM "reserve" WHERE address = $2 AND (id= src_reserve_address_id OR id = dst_reserve_address_id))
-				AND timestamp >= $3 AND timestamp < $4
+				AND timestamp >= $3 AND timestamp < $4 AND chain_id = $5
 			) a GROUP BY time
-	`, timeField)
+	`, timeField, ethUSDRateField("tradelogs", provider, &args))
 	logger.Debugw("prepare statement", "stmt", reserveQuery)
 	var records []struct {
 		TokenVolume float64   `db:"token_volume"`
@@ -140,7 +144,7 @@ func (tldb *TradeLogDB) GetReserveVolume(rsvAddr ethereum.Address, token ethereu
 		Time        time.Time `db:"time"`
 	}
 
-	err = tldb.db.Select(&records, reserveQuery, token.Hex(), rsvAddr.Hex(), fromTime, toTime)
+	err = tldb.db.Select(&records, reserveQuery, args...)
 	if err != nil {
 		return nil, err
 	}
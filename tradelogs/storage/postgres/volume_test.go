@@ -34,7 +34,7 @@ func TestTradeLogDB_GetAssetVolume(t *testing.T) {
 	}()
 	require.NoError(t, loadTestData(tldb.db, testDataFile))
 
-	volume, err := tldb.GetAssetVolume(ethereum.HexToAddress(ethAddress), from, to, freq)
+	volume, err := tldb.GetAssetVolume(ethereum.HexToAddress(ethAddress), from, to, freq, "", time.UTC)
 	require.NoError(t, err)
 	t.Logf("Volume result %v", volume)
 	timeUnix, err := time.Parse(time.RFC3339, timeStamp)
@@ -74,7 +74,7 @@ func TestTradeLogDB_GetReserveVolume(t *testing.T) {
 	}()
 	require.NoError(t, loadTestData(tldb.db, testDataFile))
 
-	volume, err := tldb.GetReserveVolume(ethereum.HexToAddress(rsvAddrStr), ethereum.HexToAddress(ethAddress), from, to, freq)
+	volume, err := tldb.GetReserveVolume(ethereum.HexToAddress(rsvAddrStr), ethereum.HexToAddress(ethAddress), from, to, freq, "", time.UTC)
 	t.Logf("Volume result %v", volume)
 	if err != nil {
 		t.Fatal(err)
@@ -12,7 +12,7 @@ import (
 
 // GetAggregatedWalletFee returns fee_amount filter by time, reserve addr, wallet addr group by hour or day
 func (tldb *TradeLogDB) GetAggregatedWalletFee(reserveAddr, walletAddr, freq string,
-	fromTime, toTime time.Time, timezone int8) (map[uint64]float64, error) {
+	fromTime, toTime time.Time, timezone *time.Location) (map[uint64]float64, error) {
 	var (
 		timeField string
 		err       error
@@ -29,7 +29,7 @@ func (tldb *TradeLogDB) GetAggregatedWalletFee(reserveAddr, walletAddr, freq str
 	case "d":
 		timeField = schema.BuildDateTruncField("day", timezone)
 		fromTime = schema.RoundTime(fromTime, "day", timezone)
-		toTime = schema.RoundTime(toTime, "day", timezone).Add(time.Hour * 24)
+		toTime = schema.RoundTime(toTime, "day", timezone).AddDate(0, 0, 1)
 
 	default:
 		return nil, fmt.Errorf("frequency not supported: %v", freq)
@@ -40,7 +40,7 @@ func (tldb *TradeLogDB) GetAggregatedWalletFee(reserveAddr, walletAddr, freq str
 		FROM (
 			SELECT %[1]s as time, src_wallet_fee_amount AS fee_amount 
 			FROM "tradelogs"
-			WHERE timestamp >= $1 and timestamp < $2
+			WHERE timestamp >= $1 and timestamp < $2 AND chain_id = $5
 			AND EXISTS (SELECT NULL FROM "wallet"
 				WHERE address = $3 and id = wallet_address_id)
 			AND EXISTS (SELECT NULL FROM "reserve"
@@ -48,7 +48,7 @@ func (tldb *TradeLogDB) GetAggregatedWalletFee(reserveAddr, walletAddr, freq str
 		UNION ALL
 			SELECT %[1]s as time, dst_wallet_fee_amount AS fee_amount 
 			FROM "tradelogs"
-			WHERE timestamp >= $1 and timestamp < $2
+			WHERE timestamp >= $1 and timestamp < $2 AND chain_id = $5
 			AND EXISTS (SELECT NULL FROM "wallet"
 				WHERE address = $3 and id = wallet_address_id)
 			AND EXISTS (SELECT NULL FROM "reserve"
@@ -62,7 +62,7 @@ func (tldb *TradeLogDB) GetAggregatedWalletFee(reserveAddr, walletAddr, freq str
 	}
 
 	logger.Debugw("prepare statement", "stmt", integrationQuery)
-	err = tldb.db.Select(&records, integrationQuery, fromTime, toTime, walletAddr, reserveAddr)
+	err = tldb.db.Select(&records, integrationQuery, fromTime, toTime, walletAddr, reserveAddr, tldb.chainID)
 	if err != nil {
 		return nil, err
 	}
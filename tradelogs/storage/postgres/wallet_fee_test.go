@@ -33,7 +33,7 @@ func TestInfluxStorage_GetAggregatedWalletFee(t *testing.T) {
 	}()
 	require.NoError(t, loadTestData(tldb.db, testDataFile))
 
-	integrationVol, err := tldb.GetAggregatedWalletFee(reserveAddr, walletAddr, "d", fromTime, toTime, 0)
+	integrationVol, err := tldb.GetAggregatedWalletFee(reserveAddr, walletAddr, "d", fromTime, toTime, time.UTC)
 	require.NoError(t, err)
 
 	timeUnix, err := time.Parse(time.RFC3339, timeStamp)
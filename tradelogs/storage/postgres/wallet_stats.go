@@ -11,7 +11,7 @@ import (
 )
 
 // GetWalletStats return wallet stats group by day
-func (tldb *TradeLogDB) GetWalletStats(from, to time.Time, walletAddr string, timezone int8) (map[uint64]common.WalletStats, error) {
+func (tldb *TradeLogDB) GetWalletStats(from, to time.Time, walletAddr string, timezone *time.Location) (map[uint64]common.WalletStats, error) {
 	var (
 		err error
 	)
@@ -24,7 +24,7 @@ func (tldb *TradeLogDB) GetWalletStats(from, to time.Time, walletAddr string, ti
 	)
 
 	from = schema.RoundTime(from, "day", timezone)
-	to = schema.RoundTime(to, "day", timezone).Add(time.Hour * 24)
+	to = schema.RoundTime(to, "day", timezone).AddDate(0, 0, 1)
 	timeField := schema.BuildDateTruncField("day", timezone)
 
 	walletStatsQuery := fmt.Sprintf(`
@@ -34,7 +34,7 @@ func (tldb *TradeLogDB) GetWalletStats(from, to time.Time, walletAddr string, ti
 			COUNT(CASE WHEN kyced THEN 1 END) AS kyced,
 			COUNT(CASE WHEN is_first_trade THEN 1 END) AS count_new_trades
 		FROM "tradelogs" 
-		WHERE timestamp >= $1 AND timestamp < $2
+		WHERE timestamp >= $1 AND timestamp < $2 AND chain_id = $4
 		AND EXISTS (SELECT NULL FROM "wallet" WHERE address = $3 AND id=wallet_address_id)
 		GROUP BY time
 	`, timeField)
@@ -47,7 +47,7 @@ func (tldb *TradeLogDB) GetWalletStats(from, to time.Time, walletAddr string, ti
 		CountNewTrades int64     `db:"count_new_trades"`
 		Kyced          int64     `db:"kyced"`
 	}
-	err = tldb.db.Select(&records, walletStatsQuery, from, to, walletAddr)
+	err = tldb.db.Select(&records, walletStatsQuery, from, to, walletAddr, tldb.chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +73,7 @@ func (tldb *TradeLogDB) GetWalletStats(from, to time.Time, walletAddr string, ti
 		AVG(eth_amount*eth_usd_rate) usd_per_trade, 
 		COUNT(1) as total_trade
 		FROM "tradelogs" 
-		WHERE timestamp >= $1 AND timestamp < $2
+		WHERE timestamp >= $1 AND timestamp < $2 AND chain_id = $4
 		AND EXISTS (SELECT NULL FROM "wallet" WHERE address = $3 AND id=wallet_address_id)
 		GROUP BY time
 	`, timeField)
@@ -87,7 +87,7 @@ func (tldb *TradeLogDB) GetWalletStats(from, to time.Time, walletAddr string, ti
 		UsdPerTrade    float64   `db:"usd_per_trade"`
 		TotalTrade     int64     `db:"total_trade"`
 	}
-	err = tldb.db.Select(&records2, walletStatsQuery, from, to, walletAddr)
+	err = tldb.db.Select(&records2, walletStatsQuery, from, to, walletAddr, tldb.chainID)
 	if err != nil {
 		return nil, err
 	}
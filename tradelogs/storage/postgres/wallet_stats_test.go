@@ -33,7 +33,7 @@ func TestTradeLogDB_GetWalletStats(t *testing.T) {
 	}()
 
 	require.NoError(t, loadTestData(tldb.db, testDataFile))
-	integrationVol, err := tldb.GetWalletStats(fromTime, toTime, walletAddress, 0)
+	integrationVol, err := tldb.GetWalletStats(fromTime, toTime, walletAddress, time.UTC)
 
 	require.NoError(t, err)
 
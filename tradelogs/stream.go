@@ -0,0 +1,269 @@
+package tradelogs
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	ether "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// LogSubscriber subscribes to logs matching the given filter. It is satisfied by *ethclient.Client
+// connected to a websocket or IPC endpoint.
+type LogSubscriber interface {
+	SubscribeFilterLogs(ctx context.Context, q ether.FilterQuery, ch chan<- types.Log) (ether.Subscription, error)
+}
+
+// StreamHandler handles trade logs of confirmed blocks in range [fromBlock, toBlock].
+type StreamHandler func(result *common.CrawlResult, fromBlock, toBlock uint64) error
+
+// handlerError is returned when StreamHandler fails, the streamer stops instead of retrying.
+type handlerError struct {
+	err error
+}
+
+func (e *handlerError) Error() string {
+	return e.err.Error()
+}
+
+// Streamer subscribes to trade events and assembles trade logs as soon as their blocks reach
+// the confirmation depth. When the subscription is not available or dropped, it falls back to
+// range polling, which also backfills the blocks missed while disconnected.
+type Streamer struct {
+	sugar         *zap.SugaredLogger
+	crawler       *Crawler
+	subscriber    LogSubscriber
+	confirmations uint64
+	maxBlocks     uint64
+	pollInterval  time.Duration
+}
+
+// NewStreamer returns a new Streamer instance.
+func NewStreamer(sugar *zap.SugaredLogger,
+	crawler *Crawler,
+	subscriber LogSubscriber,
+	confirmations, maxBlocks uint64,
+	pollInterval time.Duration) *Streamer {
+	return &Streamer{
+		sugar:         sugar,
+		crawler:       crawler,
+		subscriber:    subscriber,
+		confirmations: confirmations,
+		maxBlocks:     maxBlocks,
+		pollInterval:  pollInterval,
+	}
+}
+
+// Run streams trade logs from given block until ctx is cancelled. Only trades of the latest
+// network version are supported, older blocks should be crawled in polling mode.
+func (s *Streamer) Run(ctx context.Context, fromBlock uint64, handler StreamHandler) error {
+	var (
+		logger = s.sugar.With("func", caller.GetCurrentFunctionName())
+		next   = fromBlock
+		err    error
+	)
+	decoder, err := s.crawler.latestDecoder()
+	if err != nil {
+		return err
+	}
+	if fromBlock < decoder.startingBlock {
+		return fmt.Errorf("streaming mode only supports trades of network %s: from_block=%d starting_block=%d",
+			decoder.version, fromBlock, decoder.startingBlock)
+	}
+
+	for {
+		if next, err = s.poll(ctx, next, handler); err == nil {
+			next, err = s.stream(ctx, decoder, next, handler)
+		}
+		if ctx.Err() != nil {
+			logger.Infow("streaming stopped", "next_block", next)
+			return nil
+		}
+		if hErr, ok := err.(*handlerError); ok {
+			return hErr.err
+		}
+		logger.Warnw("streaming interrupted, falling back to range polling",
+			"next_block", next,
+			"retry_in", s.pollInterval,
+			"error", err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.pollInterval):
+		}
+	}
+}
+
+// confirmedBlock returns the latest block with enough confirmations.
+func (s *Streamer) confirmedBlock(ctx context.Context) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+	header, err := s.crawler.ethClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	if header.Number.Uint64() < s.confirmations {
+		return 0, nil
+	}
+	return header.Number.Uint64() - s.confirmations, nil
+}
+
+// poll crawls all confirmed blocks from next in ranges of maxBlocks,
+// it returns the next block to crawl.
+func (s *Streamer) poll(ctx context.Context, next uint64, handler StreamHandler) (uint64, error) {
+	confirmed, err := s.confirmedBlock(ctx)
+	if err != nil {
+		return next, err
+	}
+	for next <= confirmed && ctx.Err() == nil {
+		to := next + s.maxBlocks - 1
+		if to > confirmed {
+			to = confirmed
+		}
+		s.sugar.Debugw("polling trade logs", "from_block", next, "to_block", to)
+		result, err := s.crawler.GetTradeLogs(new(big.Int).SetUint64(next), new(big.Int).SetUint64(to), defaultTimeout)
+		if err != nil {
+			return next, err
+		}
+		if err = handler(result, next, to); err != nil {
+			return next, &handlerError{err: err}
+		}
+		next = to + 1
+	}
+	return next, nil
+}
+
+// stream subscribes to trade events and handles them once confirmed,
+// it returns the next block to crawl when the subscription ends.
+func (s *Streamer) stream(ctx context.Context, decoder TradeLogDecoder, next uint64, handler StreamHandler) (uint64, error) {
+	var (
+		logger  = s.sugar.With("func", caller.GetCurrentFunctionName())
+		logsCh  = make(chan types.Log, 1024)
+		pending = newPendingLogs()
+		query   = ether.FilterQuery{
+			Addresses: s.crawler.addresses,
+			Topics:    decoder.Topics(),
+		}
+	)
+	sub, err := s.subscriber.SubscribeFilterLogs(ctx, query, logsCh)
+	if err != nil {
+		return next, errors.Wrap(err, "failed to subscribe to trade events")
+	}
+	defer sub.Unsubscribe()
+	logger.Infow("subscribed to trade events", "next_block", next)
+
+	// logs of blocks mined before subscribing are never delivered
+	logs, err := s.crawler.fetchLogsWithTopics(new(big.Int).SetUint64(next), nil, defaultTimeout, decoder.Topics())
+	if err != nil {
+		return next, errors.Wrap(err, "failed to fetch unconfirmed trade events")
+	}
+	for _, log := range logs {
+		pending.add(log)
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return next, ctx.Err()
+		case err = <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return next, err
+		case log := <-logsCh:
+			if log.BlockNumber >= next {
+				pending.add(log)
+			}
+		case <-ticker.C:
+			if next, err = s.flush(ctx, decoder, next, pending, handler); err != nil {
+				return next, err
+			}
+		}
+	}
+}
+
+// flush assembles pending logs of all newly confirmed blocks and passes them to handler in ranges of
+// maxBlocks, as range polling does.
+func (s *Streamer) flush(ctx context.Context, decoder TradeLogDecoder, next uint64, pending *pendingLogs, handler StreamHandler) (uint64, error) {
+	confirmed, err := s.confirmedBlock(ctx)
+	if err != nil {
+		return next, err
+	}
+	for next <= confirmed {
+		to := next + s.maxBlocks - 1
+		if to > confirmed {
+			to = confirmed
+		}
+		// on failure, the popped logs are crawled again by range polling
+		result, err := s.crawler.assembleStreamedTradeLogs(decoder, pending.pop(to), new(big.Int).SetUint64(to), defaultTimeout)
+		if err != nil {
+			return next, err
+		}
+		s.sugar.Debugw("streamed trade logs", "from_block", next, "to_block", to, "trades", len(result.Trades))
+		if err = handler(result, next, to); err != nil {
+			return next, &handlerError{err: err}
+		}
+		next = to + 1
+	}
+	return next, nil
+}
+
+// assembleStreamedTradeLogs assembles trade logs from logs delivered by subscription. Hash of toBlock is
+// recorded in the result even if it has no logs, so streamed ranges without trades are not audited as gaps.
+func (crawler *Crawler) assembleStreamedTradeLogs(decoder TradeLogDecoder, logs []types.Log, toBlock *big.Int, timeout time.Duration) (*common.CrawlResult, error) {
+	result, err := decoder.Assemble(logs)
+	if err != nil {
+		return result, err
+	}
+	result.Blocks = blockInfosFromLogs(logs)
+	return crawler.enrichTradeLogs(result, toBlock, timeout)
+}
+
+// pendingLogs keeps logs of unconfirmed blocks, logs removed by chain reorganization are dropped.
+type pendingLogs struct {
+	logs map[string]types.Log
+}
+
+func newPendingLogs() *pendingLogs {
+	return &pendingLogs{logs: make(map[string]types.Log)}
+}
+
+func pendingLogKey(log types.Log) string {
+	return fmt.Sprintf("%s-%d", log.BlockHash.Hex(), log.Index)
+}
+
+func (p *pendingLogs) add(log types.Log) {
+	if log.Removed {
+		delete(p.logs, pendingLogKey(log))
+		return
+	}
+	p.logs[pendingLogKey(log)] = log
+}
+
+// pop removes and returns logs of blocks up to given block, ordered as they appear in the chain.
+func (p *pendingLogs) pop(toBlock uint64) []types.Log {
+	var result []types.Log
+	for key, log := range p.logs {
+		if log.BlockNumber <= toBlock {
+			result = append(result, log)
+			delete(p.logs, key)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].BlockNumber != result[j].BlockNumber {
+			return result[i].BlockNumber < result[j].BlockNumber
+		}
+		return result[i].Index < result[j].Index
+	})
+	return result
+}
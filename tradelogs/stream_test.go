@@ -0,0 +1,250 @@
+package tradelogs
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	ether "github.com/ethereum/go-ethereum"
+	ethereum "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
+	"github.com/KyberNetwork/reserve-stats/lib/testutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+func TestPendingLogs(t *testing.T) {
+	var (
+		blockA  = ethereum.HexToHash("0xa")
+		blockB  = ethereum.HexToHash("0xb")
+		blockB2 = ethereum.HexToHash("0xb2") // block b replaced by chain reorganization
+		blockC  = ethereum.HexToHash("0xc")
+		pending = newPendingLogs()
+	)
+
+	pending.add(types.Log{BlockNumber: 11, BlockHash: blockB, Index: 3})
+	pending.add(types.Log{BlockNumber: 10, BlockHash: blockA, Index: 2})
+	pending.add(types.Log{BlockNumber: 10, BlockHash: blockA, Index: 1})
+	// duplicated log delivered by both subscription and initial fetching
+	pending.add(types.Log{BlockNumber: 10, BlockHash: blockA, Index: 1})
+	pending.add(types.Log{BlockNumber: 12, BlockHash: blockC, Index: 1})
+	// reorg: block b is removed, its log is included in the new block
+	pending.add(types.Log{BlockNumber: 11, BlockHash: blockB, Index: 3, Removed: true})
+	pending.add(types.Log{BlockNumber: 11, BlockHash: blockB2, Index: 0})
+
+	assert.Equal(t, []types.Log{
+		{BlockNumber: 10, BlockHash: blockA, Index: 1},
+		{BlockNumber: 10, BlockHash: blockA, Index: 2},
+		{BlockNumber: 11, BlockHash: blockB2, Index: 0},
+	}, pending.pop(11))
+	assert.Empty(t, pending.pop(11))
+	assert.Equal(t, []types.Log{
+		{BlockNumber: 12, BlockHash: blockC, Index: 1},
+	}, pending.pop(12))
+}
+
+// fakeNode is the eth service of an in-process ethereum node without any trade event.
+type fakeNode struct {
+	mu     sync.Mutex
+	head   uint64
+	ranges []common.BlockRange // block ranges of logs requests with to block, as range polling does
+}
+
+func (n *fakeNode) setHead(head uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.head = head
+}
+
+func (n *fakeNode) polledRanges() []common.BlockRange {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]common.BlockRange(nil), n.ranges...)
+}
+
+// GetBlockByNumber serves eth_getBlockByNumber.
+func (n *fakeNode) GetBlockByNumber(number rpc.BlockNumber, fullTx bool) (*types.Header, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	block := uint64(number)
+	if number == rpc.LatestBlockNumber {
+		block = n.head
+	}
+	if block > n.head {
+		return nil, nil
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(block), Difficulty: big.NewInt(1)}, nil
+}
+
+// GetLogs serves eth_getLogs.
+func (n *fakeNode) GetLogs(query struct {
+	FromBlock rpc.BlockNumber `json:"fromBlock"`
+	ToBlock   rpc.BlockNumber `json:"toBlock"`
+}) ([]types.Log, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if query.ToBlock != rpc.LatestBlockNumber {
+		n.ranges = append(n.ranges, common.BlockRange{FromBlock: uint64(query.FromBlock), ToBlock: uint64(query.ToBlock)})
+	}
+	return []types.Log{}, nil
+}
+
+// fakeSubscriber calls subscribe with the number of the subscription, starting from 1.
+type fakeSubscriber struct {
+	mu        sync.Mutex
+	calls     int
+	subscribe func(call int) (ether.Subscription, error)
+}
+
+func (s *fakeSubscriber) SubscribeFilterLogs(ctx context.Context, q ether.FilterQuery, ch chan<- types.Log) (ether.Subscription, error) {
+	s.mu.Lock()
+	s.calls++
+	call := s.calls
+	s.mu.Unlock()
+	return s.subscribe(call)
+}
+
+func (s *fakeSubscriber) subscriptions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// newAliveSubscription returns a subscription that is never dropped.
+func newAliveSubscription() ether.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// streamRecorder is a StreamHandler recording handled block ranges.
+type streamRecorder struct {
+	mu     sync.Mutex
+	ranges []common.BlockRange
+}
+
+func (r *streamRecorder) handle(result *common.CrawlResult, fromBlock, toBlock uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ranges = append(r.ranges, common.BlockRange{FromBlock: fromBlock, ToBlock: toBlock})
+	// hash of the last block is recorded even without trade
+	if n := len(result.Blocks); n == 0 || result.Blocks[n-1].Number != toBlock {
+		return errors.New("hash of the last block is not recorded")
+	}
+	return nil
+}
+
+func (r *streamRecorder) handledRanges() []common.BlockRange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]common.BlockRange(nil), r.ranges...)
+}
+
+func (r *streamRecorder) handled(toBlock uint64) func() bool {
+	return func() bool {
+		ranges := r.handledRanges()
+		return len(ranges) != 0 && ranges[len(ranges)-1].ToBlock >= toBlock
+	}
+}
+
+// runStreamer runs a streamer of given node and subscriber from the starting block of the latest version,
+// it returns the starting block and a function stopping the streamer.
+func runStreamer(t *testing.T, node *fakeNode, subscriber LogSubscriber, recorder *streamRecorder) (uint64, func()) {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", node))
+	sb := deployment.StartingBlocks[deployment.Production]
+	crawler := &Crawler{
+		sugar:          testutil.MustNewDevelopmentSugaredLogger(),
+		ethClient:      ethclient.NewClient(rpc.DialInProc(server)),
+		startingBlocks: sb,
+	}
+	crawler.decoders = newVersionedDecoders(crawler)
+	streamer := NewStreamer(crawler.sugar, crawler, subscriber, 0, 100, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- streamer.Run(ctx, sb.V4(), recorder.handle)
+	}()
+	return sb.V4(), func() {
+		cancel()
+		require.NoError(t, <-done)
+		server.Stop()
+	}
+}
+
+func TestStreamerFallbackToPolling(t *testing.T) {
+	var (
+		sb         = deployment.StartingBlocks[deployment.Production]
+		node       = &fakeNode{head: sb.V4() + 149}
+		subscriber = &fakeSubscriber{subscribe: func(int) (ether.Subscription, error) {
+			return nil, errors.New("notifications not supported")
+		}}
+		recorder = &streamRecorder{}
+	)
+	from, stop := runStreamer(t, node, subscriber, recorder)
+	require.Eventually(t, recorder.handled(from+149), time.Second, time.Millisecond)
+
+	// new blocks are polled while subscription fails
+	node.setHead(from + 179)
+	require.Eventually(t, recorder.handled(from+179), time.Second, time.Millisecond)
+	stop()
+
+	expected := []common.BlockRange{
+		{FromBlock: from, ToBlock: from + 99},
+		{FromBlock: from + 100, ToBlock: from + 149},
+		{FromBlock: from + 150, ToBlock: from + 179},
+	}
+	assert.Equal(t, expected, recorder.handledRanges())
+	assert.Equal(t, expected, node.polledRanges())
+	assert.True(t, subscriber.subscriptions() >= 2)
+}
+
+func TestStreamerBackfillAfterReconnect(t *testing.T) {
+	var (
+		sb         = deployment.StartingBlocks[deployment.Production]
+		from       = sb.V4()
+		node       = &fakeNode{head: from + 49}
+		subscriber = &fakeSubscriber{}
+		recorder   = &streamRecorder{}
+	)
+	subscriber.subscribe = func(call int) (ether.Subscription, error) {
+		if call == 1 {
+			// blocks are mined while the subscription is dropped
+			node.setHead(from + 249)
+			return event.NewSubscription(func(<-chan struct{}) error {
+				return errors.New("connection lost")
+			}), nil
+		}
+		node.setHead(from + 259)
+		return newAliveSubscription(), nil
+	}
+	_, stop := runStreamer(t, node, subscriber, recorder)
+	require.Eventually(t, recorder.handled(from+259), time.Second, time.Millisecond)
+	stop()
+
+	assert.Equal(t, []common.BlockRange{
+		{FromBlock: from, ToBlock: from + 49},
+		{FromBlock: from + 50, ToBlock: from + 149},
+		{FromBlock: from + 150, ToBlock: from + 249},
+		// blocks after reconnection are streamed
+		{FromBlock: from + 250, ToBlock: from + 259},
+	}, recorder.handledRanges())
+	// missed blocks are backfilled by range polling
+	assert.Equal(t, []common.BlockRange{
+		{FromBlock: from, ToBlock: from + 49},
+		{FromBlock: from + 50, ToBlock: from + 149},
+		{FromBlock: from + 150, ToBlock: from + 249},
+	}, node.polledRanges())
+	assert.Equal(t, 2, subscriber.subscriptions())
+}
@@ -0,0 +1,174 @@
+package workers
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/KyberNetwork/reserve-stats/lib/caller"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+// QueueStorage is the trade logs storage with the persistent crawl queue shared by crawler instances.
+type QueueStorage interface {
+	LeaseCrawlJob(owner string, lease time.Duration, maxAttempts uint64) (*common.CrawlJob, error)
+	CompleteCrawlJob(id uint64, owner string) error
+	RenewCrawlJob(id uint64, owner string, lease time.Duration) error
+	FailCrawlJob(id uint64, owner, reason string, maxAttempts uint64) error
+	SaveTradeLogs(log *common.CrawlResult) error
+	SaveBigTrades(bigVolume float32, fromBlock uint64) error
+}
+
+// TradeLogsCrawler fetches trade logs of a block range.
+type TradeLogsCrawler interface {
+	GetTradeLogs(fromBlock, toBlock *big.Int, timeout time.Duration) (*common.CrawlResult, error)
+}
+
+// SplitRanges splits blocks from fromBlock to toBlock inclusive into ranges of at most maxBlocks blocks to
+// crawl. A range is decoded with the decoder of its from block, so ranges do not span any of given split
+// points, the starting blocks of newer versions. maxBlocks must be positive.
+func SplitRanges(fromBlock, toBlock, maxBlocks uint64, splitPoints []uint64) []common.BlockRange {
+	var result []common.BlockRange
+	for from := fromBlock; from <= toBlock; {
+		to := from + maxBlocks - 1
+		if to > toBlock {
+			to = toBlock
+		}
+		for _, splitPoint := range splitPoints {
+			if from < splitPoint && to >= splitPoint {
+				to = splitPoint - 1
+			}
+		}
+		result = append(result, common.BlockRange{FromBlock: from, ToBlock: to})
+		from = to + 1
+	}
+	return result
+}
+
+// QueueWorker crawls block ranges leased from the persistent crawl queue. Unlike Pool, ranges are not
+// saved in order, so several crawler instances can share the queue and a failed range does not stop
+// the others, it is retried until max attempts and dead-lettered after.
+type QueueWorker struct {
+	sugar        *zap.SugaredLogger
+	st           QueueStorage
+	crawler      TradeLogsCrawler
+	owner        string        // unique name of the crawler instance holding leases
+	lease        time.Duration // duration a range is leased to the worker, renewed while it is crawled
+	maxAttempts  uint64
+	bigVolume    float32 // for detect big trade
+	pollInterval time.Duration
+}
+
+// NewQueueWorker returns a new QueueWorker instance.
+func NewQueueWorker(sugar *zap.SugaredLogger, st QueueStorage, crawler TradeLogsCrawler, owner string,
+	lease time.Duration, maxAttempts uint64, bigVolume float32, pollInterval time.Duration) *QueueWorker {
+	return &QueueWorker{
+		sugar:        sugar,
+		st:           st,
+		crawler:      crawler,
+		owner:        owner,
+		lease:        lease,
+		maxAttempts:  maxAttempts,
+		bigVolume:    bigVolume,
+		pollInterval: pollInterval,
+	}
+}
+
+// ProcessNext leases the next range of the queue and crawls it. It returns false if there is no range to
+// lease. A failed crawl is recorded to the queue and is not returned as error.
+func (w *QueueWorker) ProcessNext() (bool, error) {
+	return w.processNext(w.owner)
+}
+
+// processNext is ProcessNext with the leases held by given owner.
+func (w *QueueWorker) processNext(owner string) (bool, error) {
+	job, err := w.st.LeaseCrawlJob(owner, w.lease, w.maxAttempts)
+	if err != nil || job == nil {
+		return false, err
+	}
+	logger := w.sugar.With(
+		"func", caller.GetCurrentFunctionName(),
+		"owner", owner,
+		"job_id", job.ID,
+		"from", job.FromBlock,
+		"to", job.ToBlock,
+		"attempts", job.Attempts,
+	)
+	logger.Infow("crawling leased range")
+	done := make(chan struct{})
+	go w.renewLease(job.ID, owner, done)
+	err = w.crawl(job)
+	close(done)
+	if err != nil {
+		logger.Errorw("failed to crawl leased range", "error", err)
+		return true, w.st.FailCrawlJob(job.ID, owner, err.Error(), w.maxAttempts)
+	}
+	if err = w.st.CompleteCrawlJob(job.ID, owner); err != nil {
+		return true, err
+	}
+	logger.Infow("crawled leased range")
+	return true, nil
+}
+
+// renewLease renews the lease of the job every third of the lease duration until done is closed, so a
+// range crawled longer than the lease duration is not leased to another worker.
+func (w *QueueWorker) renewLease(id uint64, owner string, done <-chan struct{}) {
+	ticker := time.NewTicker(w.lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := w.st.RenewCrawlJob(id, owner, w.lease); err != nil {
+				w.sugar.Warnw("failed to renew lease of crawl job",
+					"func", caller.GetCurrentFunctionName(),
+					"owner", owner,
+					"job_id", id,
+					"error", err)
+			}
+		}
+	}
+}
+
+func (w *QueueWorker) crawl(job *common.CrawlJob) error {
+	result, err := w.crawler.GetTradeLogs(new(big.Int).SetUint64(job.FromBlock),
+		new(big.Int).SetUint64(job.ToBlock), time.Second*5)
+	if err != nil {
+		return err
+	}
+	if err = w.st.SaveTradeLogs(result); err != nil {
+		return err
+	}
+	return w.st.SaveBigTrades(w.bigVolume, job.FromBlock)
+}
+
+// Run starts the given number of workers processing the queue, a worker sleeps for the poll interval
+// when there is no range to lease. Each worker holds leases with its own owner, the owner of the instance
+// suffixed by the worker index. It blocks forever.
+func (w *QueueWorker) Run(workers int) {
+	var (
+		logger = w.sugar.With("func", caller.GetCurrentFunctionName())
+		wg     sync.WaitGroup
+	)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		owner := fmt.Sprintf("%s-%d", w.owner, i)
+		go func() {
+			defer wg.Done()
+			for {
+				processed, err := w.processNext(owner)
+				if err != nil {
+					logger.Errorw("failed to process crawl queue", "error", err)
+				}
+				if !processed {
+					time.Sleep(w.pollInterval)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,142 @@
+package workers
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KyberNetwork/reserve-stats/lib/deployment"
+	"github.com/KyberNetwork/reserve-stats/lib/testutil"
+	"github.com/KyberNetwork/reserve-stats/tradelogs"
+	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
+)
+
+type mockQueueStorage struct {
+	jobs      []common.CrawlJob
+	completed []uint64
+	failed    map[uint64]string
+	saved     int
+
+	mu      sync.Mutex
+	renewed []string // owners renewing leases
+}
+
+func (s *mockQueueStorage) LeaseCrawlJob(owner string, lease time.Duration, maxAttempts uint64) (*common.CrawlJob, error) {
+	if len(s.jobs) == 0 {
+		return nil, nil
+	}
+	job := s.jobs[0]
+	s.jobs = s.jobs[1:]
+	return &job, nil
+}
+
+func (s *mockQueueStorage) CompleteCrawlJob(id uint64, owner string) error {
+	s.completed = append(s.completed, id)
+	return nil
+}
+
+func (s *mockQueueStorage) FailCrawlJob(id uint64, owner, reason string, maxAttempts uint64) error {
+	s.failed[id] = reason
+	return nil
+}
+
+func (s *mockQueueStorage) RenewCrawlJob(id uint64, owner string, lease time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.renewed = append(s.renewed, owner)
+	return nil
+}
+
+func (s *mockQueueStorage) renewedOwners() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.renewed
+}
+
+func (s *mockQueueStorage) SaveTradeLogs(log *common.CrawlResult) error {
+	s.saved++
+	return nil
+}
+
+func (s *mockQueueStorage) SaveBigTrades(bigVolume float32, fromBlock uint64) error {
+	return nil
+}
+
+type mockCrawler struct {
+	failedFrom uint64        // ranges from this block fail
+	delay      time.Duration // duration of crawling a range
+}
+
+func (c *mockCrawler) GetTradeLogs(fromBlock, toBlock *big.Int, timeout time.Duration) (*common.CrawlResult, error) {
+	time.Sleep(c.delay)
+	if fromBlock.Uint64() == c.failedFrom {
+		return nil, errors.New("node timeout")
+	}
+	return &common.CrawlResult{}, nil
+}
+
+func TestQueueWorker(t *testing.T) {
+	st := &mockQueueStorage{
+		jobs: []common.CrawlJob{
+			{ID: 1, FromBlock: 101, ToBlock: 200},
+			{ID: 2, FromBlock: 201, ToBlock: 300},
+		},
+		failed: make(map[uint64]string),
+	}
+	w := NewQueueWorker(testutil.MustNewDevelopmentSugaredLogger(), st, &mockCrawler{failedFrom: 101},
+		"test", time.Minute, 3, 100, time.Second)
+
+	// a failed range is recorded to the queue and does not stop the others
+	for i := 0; i < 2; i++ {
+		processed, err := w.ProcessNext()
+		require.NoError(t, err)
+		assert.True(t, processed)
+	}
+	processed, err := w.ProcessNext()
+	require.NoError(t, err)
+	assert.False(t, processed)
+
+	assert.Equal(t, map[uint64]string{1: "node timeout"}, st.failed)
+	assert.Equal(t, []uint64{2}, st.completed)
+	assert.Equal(t, 1, st.saved)
+}
+
+func TestQueueWorkerRenewLease(t *testing.T) {
+	st := &mockQueueStorage{
+		jobs:   []common.CrawlJob{{ID: 1, FromBlock: 101, ToBlock: 200}},
+		failed: make(map[uint64]string),
+	}
+	w := NewQueueWorker(testutil.MustNewDevelopmentSugaredLogger(), st, &mockCrawler{delay: 100 * time.Millisecond},
+		"test", 30*time.Millisecond, 3, 100, time.Second)
+
+	// the lease of a range crawled longer than the lease duration is renewed by its owner
+	processed, err := w.processNext("test-0")
+	require.NoError(t, err)
+	assert.True(t, processed)
+	assert.Equal(t, []uint64{1}, st.completed)
+	renewed := st.renewedOwners()
+	require.NotEmpty(t, renewed)
+	for _, owner := range renewed {
+		assert.Equal(t, "test-0", owner)
+	}
+
+	// no renewal after the range is crawled
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, st.renewedOwners(), len(renewed))
+}
+
+func TestSplitRanges(t *testing.T) {
+	startingBlocks := deployment.StartingBlocks[deployment.Production]
+	v4 := startingBlocks.V4()
+	assert.Equal(t, []common.BlockRange{
+		{FromBlock: v4 - 83, ToBlock: v4 - 1},
+		{FromBlock: v4, ToBlock: v4 + 99},
+		{FromBlock: v4 + 100, ToBlock: v4 + 117},
+	}, SplitRanges(v4-83, v4+117, 100, tradelogs.SplitPoints(startingBlocks)))
+	assert.Equal(t, []common.BlockRange{{FromBlock: v4, ToBlock: v4}}, SplitRanges(v4, v4, 100, tradelogs.SplitPoints(startingBlocks)))
+}
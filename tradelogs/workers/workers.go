@@ -16,6 +16,7 @@ import (
 	"github.com/KyberNetwork/reserve-stats/lib/caller"
 	"github.com/KyberNetwork/reserve-stats/lib/contracts"
 	"github.com/KyberNetwork/reserve-stats/lib/deployment"
+	"github.com/KyberNetwork/reserve-stats/lib/tokenrate"
 	"github.com/KyberNetwork/reserve-stats/tradelogs"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/common"
 	"github.com/KyberNetwork/reserve-stats/tradelogs/storage"
@@ -73,43 +74,66 @@ func retry(fn executeJob, attempts int, logger *zap.SugaredLogger) (*common.Craw
 	return result, err
 }
 
-func (fj *FetcherJob) fetch(sugar *zap.SugaredLogger) (*common.CrawlResult, error) {
-	logger := sugar.With(
-		"from", fj.from.String(),
-		"to", fj.to.String())
-	logger.Debugw("fetching trade logs")
-
-	bc, err := broadcast.NewClientFromContext(sugar, fj.c)
+// NewCrawlerFromContext returns a trade logs crawler configured with contract addresses of current deployment.
+func NewCrawlerFromContext(sugar *zap.SugaredLogger, c *cli.Context, bc broadcast.Interface,
+	etherscanClient *etherscan.Client, networkProxyAddr ethereum.Address) (*tradelogs.Crawler, error) {
+	client, err := blockchain.NewEthereumClientFromFlag(c)
 	if err != nil {
 		return nil, err
 	}
 
-	client, err := blockchain.NewEthereumClientFromFlag(fj.c)
+	startingBlocks := deployment.MustGetStartingBlocksFromContext(c)
+	addresses := []ethereum.Address{contracts.PricingContractAddress().MustGetOneFromContext(c)}
+	addresses = append(addresses, contracts.NetworkContractAddress().MustGetOneFromContext(c))
+	addresses = append(addresses, contracts.BurnerContractAddress().MustGetOneFromContext(c))
+	addresses = append(addresses, contracts.ProxyContractAddress().MustGetOneFromContext(c))
+	addresses = append(addresses, contracts.OldBurnerContractAddress().MustGetFromContext(c)...)
+	addresses = append(addresses, contracts.OldNetworkContractAddress().MustGetFromContext(c)...)
+	addresses = append(addresses, contracts.OldProxyContractAddress().MustGetFromContext(c)...)
+	addresses = append(addresses, contracts.KyberFeeHandlerContractAddress().MustGetFromContext(c)...)
+	addresses = append(addresses, contracts.KyberStorageContractAddress().MustGetFromContext(c)...)
+
+	// logger.Fatalw("addresses", "addresses", addresses)
+
+	volumeExcludedReserve := contracts.VolumeExcludedReserves().MustGetFromContext(c)
+
+	kyberStorageAddr := contracts.KyberStorageContractAddress().MustGetOneFromContext(c)
+	feeHandlerAddr := contracts.KyberFeeHandlerContractAddress().MustGetOneFromContext(c)
+	kyberNetworkAddr := contracts.NetworkContractAddress().MustGetOneFromContext(c)
+
+	internalTxProvider, err := tradelogs.NewInternalTxProviderFromContext(sugar, c, etherscanClient)
 	if err != nil {
 		return nil, err
 	}
 
-	startingBlocks := deployment.MustGetStartingBlocksFromContext(fj.c)
-	addresses := []ethereum.Address{contracts.PricingContractAddress().MustGetOneFromContext(fj.c)}
-	addresses = append(addresses, contracts.NetworkContractAddress().MustGetOneFromContext(fj.c))
-	addresses = append(addresses, contracts.BurnerContractAddress().MustGetOneFromContext(fj.c))
-	addresses = append(addresses, contracts.ProxyContractAddress().MustGetOneFromContext(fj.c))
-	addresses = append(addresses, contracts.OldBurnerContractAddress().MustGetFromContext(fj.c)...)
-	addresses = append(addresses, contracts.OldNetworkContractAddress().MustGetFromContext(fj.c)...)
-	addresses = append(addresses, contracts.OldProxyContractAddress().MustGetFromContext(fj.c)...)
-	addresses = append(addresses, contracts.KyberFeeHandlerContractAddress().MustGetFromContext(fj.c)...)
-	addresses = append(addresses, contracts.KyberStorageContractAddress().MustGetFromContext(fj.c)...)
+	// trades are valued in the chain native token, so USD rates are of the native token, not always ETH
+	chain := deployment.MustGetChainFromContext(c)
+	rateProvider, err := tokenrate.NewNativeTokenUSDRateProvider(coingecko.New(), chain.NativeToken)
+	if err != nil {
+		return nil, err
+	}
 
-	// logger.Fatalw("addresses", "addresses", addresses)
+	crawler, err := tradelogs.NewCrawler(sugar, client, bc, rateProvider, addresses, startingBlocks,
+		internalTxProvider, volumeExcludedReserve, chain.WrappedNativeToken,
+		networkProxyAddr, kyberStorageAddr, feeHandlerAddr, kyberNetworkAddr)
+	if err != nil {
+		return nil, err
+	}
+	return crawler, nil
+}
 
-	volumeExcludedReserve := contracts.VolumeExcludedReserves().MustGetFromContext(fj.c)
+func (fj *FetcherJob) fetch(sugar *zap.SugaredLogger) (*common.CrawlResult, error) {
+	logger := sugar.With(
+		"from", fj.from.String(),
+		"to", fj.to.String())
+	logger.Debugw("fetching trade logs")
 
-	kyberStorageAddr := contracts.KyberStorageContractAddress().MustGetOneFromContext(fj.c)
-	feeHandlerAddr := contracts.KyberFeeHandlerContractAddress().MustGetOneFromContext(fj.c)
-	kyberNetworkAddr := contracts.NetworkContractAddress().MustGetOneFromContext(fj.c)
+	bc, err := broadcast.NewClientFromContext(sugar, fj.c)
+	if err != nil {
+		return nil, err
+	}
 
-	crawler, err := tradelogs.NewCrawler(logger, client, bc, coingecko.New(), addresses, startingBlocks,
-		fj.etherscanClient, volumeExcludedReserve, fj.networkProxyAddr, kyberStorageAddr, feeHandlerAddr, kyberNetworkAddr)
+	crawler, err := NewCrawlerFromContext(logger, fj.c, bc, fj.etherscanClient, fj.networkProxyAddr)
 	if err != nil {
 		return nil, err
 	}